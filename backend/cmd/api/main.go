@@ -1,20 +1,35 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"link-storage/internal/config"
 	"link-storage/internal/handler/auth_handler"
+	"link-storage/internal/handler/inbound_email_handler"
 	"link-storage/internal/handler/link_handler"
+	"link-storage/internal/handler/webhook_handler"
+	"link-storage/internal/handler/workspace_handler"
 	"link-storage/internal/middleware"
 	"link-storage/internal/repository/auth_repository"
 	"link-storage/internal/repository/link_repository"
+	"link-storage/internal/repository/webhook_repository"
+	"link-storage/internal/repository/workspace_repository"
 	"link-storage/internal/service/auth_service"
 	"link-storage/internal/service/link_service"
+	"link-storage/internal/service/webhook_service"
+	"link-storage/internal/service/workspace_service"
 	"link-storage/pkg/database"
+	"link-storage/pkg/events"
 	"link-storage/pkg/logger"
+	"link-storage/pkg/mailer"
+	"link-storage/pkg/scheduler"
+	"link-storage/pkg/smtpd"
+	"link-storage/pkg/utils/parseurl"
 	"log"
 	"net/http"
 	"time"
+	// Часовые пояса дайджестов не зависят от tzdata в образе
+	_ "time/tzdata"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -55,10 +70,140 @@ func main() {
 	// Repositories
 	authRepo := auth_repository.New(appDb, appLogger)
 	linkRepo := link_repository.New(appDb.Pool, appLogger)
+	workspaceRepo := workspace_repository.New(appDb.Pool, appLogger)
+	webhookRepo := webhook_repository.New(appDb.Pool, appLogger)
+
+	// Events
+	eventBus := events.NewBus(appLogger)
 
 	// Services
 	authService := auth_service.New(authRepo, appLogger, cfg.Secret.Jwt)
-	linkService := link_service.New(linkRepo, appLogger, cfg.Media.FavIconsPath)
+	workspaceService := workspace_service.New(workspaceRepo, appLogger)
+	appMailer := mailer.New(mailer.Options{
+		Host:     cfg.Email.Smtp.Host,
+		Port:     cfg.Email.Smtp.Port,
+		Username: cfg.Email.Smtp.Username,
+		Password: cfg.Email.Smtp.Password,
+		From:     cfg.Email.From,
+	})
+
+	webhookService := webhook_service.New(webhookRepo, appLogger, webhook_service.Options{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		RetryBase:   cfg.Webhooks.RetryBase,
+		RetryMax:    cfg.Webhooks.RetryMax,
+		BatchSize:   cfg.Webhooks.BatchSize,
+		Workers:     cfg.Webhooks.Workers,
+		Retention:   time.Duration(cfg.Webhooks.RetentionDays) * 24 * time.Hour,
+		Client: parseurl.ClientOptions{
+			Timeout:              cfg.Webhooks.Timeout,
+			AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+		},
+	})
+	eventBus.Subscribe(webhookService.HandleEvent)
+
+	// Каналы доставки напоминаний о ссылках
+	var reminderNotifiers []link_service.ReminderNotifier
+	for _, notifier := range cfg.Reminders.Notifiers {
+		switch notifier {
+		case "email":
+			reminderNotifiers = append(reminderNotifiers, link_service.NewEmailReminderNotifier(appMailer, cfg.Server.AppURL))
+		case "webhook":
+			reminderNotifiers = append(reminderNotifiers, link_service.NewEventReminderNotifier(eventBus))
+		}
+	}
+
+	linkService := link_service.New(linkRepo, appLogger, appMailer, eventBus, link_service.Options{
+		FavIconsPath:         cfg.Media.FavIconsPath,
+		LinkSignSecret:       cfg.Secret.Hash,
+		LinkGoTokenTTL:       cfg.Links.GoTokenTTL,
+		UsePageCanonical:     cfg.Links.UsePageCanonical,
+		VisitsRawRetention:   time.Duration(cfg.Visits.RawRetentionDays) * 24 * time.Hour,
+		VisitsDailyRetention: time.Duration(cfg.Visits.DailyRetentionDays) * 24 * time.Hour,
+		LinkCheck: link_service.LinkCheckOptions{
+			Recheck:         cfg.LinkCheck.Recheck,
+			BatchSize:       cfg.LinkCheck.BatchSize,
+			Workers:         cfg.LinkCheck.Workers,
+			HostConcurrency: cfg.LinkCheck.HostConcurrency,
+			HostDelay:       cfg.LinkCheck.HostDelay,
+			ArchiveAfter:    cfg.LinkCheck.ArchiveAfter,
+			Client:          parseurl.ClientOptions{Timeout: cfg.LinkCheck.Timeout},
+		},
+		Metadata: parseurl.ClientOptions{
+			Timeout:              cfg.Links.MetadataTimeout,
+			AllowPrivateNetworks: cfg.Links.MetadataAllowPrivateNetworks,
+		},
+		TrashRetention:   time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour,
+		BulkJobThreshold: cfg.Links.BulkJobThreshold,
+		BulkJobRetention: cfg.Links.BulkJobRetention,
+		AppURL:           cfg.Server.AppURL,
+		InviteTTL:        cfg.Links.InviteTTL,
+		FeedPoll: link_service.FeedPollOptions{
+			Refresh:   cfg.FeedPoll.Refresh,
+			BatchSize: cfg.FeedPoll.BatchSize,
+			MaxItems:  cfg.FeedPoll.MaxItems,
+			Client: parseurl.ClientOptions{
+				Timeout:              cfg.FeedPoll.Timeout,
+				AllowPrivateNetworks: cfg.FeedPoll.AllowPrivateNetworks,
+			},
+		},
+		Events: link_service.EventStreamOptions{
+			BufferSize: cfg.Events.BufferSize,
+			Heartbeat:  cfg.Events.Heartbeat,
+		},
+		InboundEmail: link_service.InboundEmailOptions{
+			Domain:    cfg.InboundEmail.Domain,
+			LocalPart: cfg.InboundEmail.LocalPart,
+			MaxLinks:  cfg.InboundEmail.MaxLinks,
+		},
+		Reminders: link_service.ReminderOptions{
+			Notifiers:   reminderNotifiers,
+			BatchSize:   cfg.Reminders.BatchSize,
+			MaxAttempts: cfg.Reminders.MaxAttempts,
+			RetryBase:   cfg.Reminders.RetryBase,
+			RetryMax:    cfg.Reminders.RetryMax,
+		},
+		Digest: link_service.DigestOptions{
+			BatchSize: cfg.Digest.BatchSize,
+		},
+	})
+
+	// Background jobs
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	go func() {
+		if err := linkService.BackfillCanonicalURLs(jobsCtx); err != nil {
+			appLogger.Error(err, "main.BackfillCanonicalURLs")
+		}
+	}()
+
+	// Прием писем встроенным SMTP-сервером
+	if cfg.InboundEmail.SMTPAddr != "" {
+		smtpServer := smtpd.New(smtpd.Options{
+			Addr:            cfg.InboundEmail.SMTPAddr,
+			Domain:          cfg.InboundEmail.Domain,
+			MaxSize:         cfg.InboundEmail.MaxSize,
+			MaxRecipients:   10,
+			Timeout:         cfg.InboundEmail.Timeout,
+			AcceptRecipient: linkService.IsInboundEmailAddress,
+		}, inbound_email_handler.SMTPHandler(linkService), appLogger)
+
+		go func() {
+			if err := smtpServer.ListenAndServe(jobsCtx); err != nil {
+				appLogger.Error(err, "main.SMTPServer")
+			}
+		}()
+	}
+
+	scheduler.Every(jobsCtx, cfg.Visits.RollupInterval, "RollupLinkVisits", appLogger, linkService.RollupLinkVisits)
+	scheduler.Every(jobsCtx, cfg.Visits.FrecencyInterval, "RecalculateFrecency", appLogger, linkService.RecalculateFrecency)
+	scheduler.Every(jobsCtx, cfg.LinkCheck.Interval, "CheckLinks", appLogger, linkService.CheckLinks)
+	scheduler.Every(jobsCtx, cfg.FeedPoll.Interval, "PollFeedSubscriptions", appLogger, linkService.PollFeedSubscriptions)
+	scheduler.Every(jobsCtx, cfg.Reminders.Interval, "SendLinkReminders", appLogger, linkService.SendLinkReminders)
+	scheduler.Every(jobsCtx, cfg.Digest.Interval, "SendDigests", appLogger, linkService.SendDigests)
+	scheduler.Every(jobsCtx, cfg.Trash.PurgeInterval, "PurgeTrash", appLogger, linkService.PurgeTrash)
+	scheduler.Every(jobsCtx, cfg.Webhooks.DispatchInterval, "DispatchWebhooks", appLogger, webhookService.DispatchWebhooks)
+	scheduler.Every(jobsCtx, cfg.Webhooks.PurgeInterval, "PurgeWebhookDeliveries", appLogger, webhookService.PurgeWebhookDeliveries)
 
 	// Server
 	router := chi.NewRouter()
@@ -67,15 +212,22 @@ func main() {
 	router.Use(chiMiddleware.Recoverer)
 	router.Use(middleware.SecurityHeaders)
 	router.Use(chiMiddleware.RequestID)
-	router.Use(chiMiddleware.Timeout(30 * time.Second))
+	// Поток событий живет дольше таймаута запроса
+	router.Use(middleware.Timeout(30*time.Second, middleware.EventsPath))
 	router.Use(httprate.LimitByIP(100, 1*time.Minute))
 	router.Use(middleware.NewCORSMiddleware(cfg.Server.Cors).Handler)
 	router.Use(middleware.RequireJSONContentType)
-	router.Use(middleware.AuthMiddleware(authService, appLogger))
+	router.Use(middleware.AuthMiddleware(authService, workspaceService, appLogger))
 
 	// Handlers
 	auth_handler.New(router, authService, appLogger)
 	link_handler.New(router, linkService, appLogger)
+	workspace_handler.New(router, workspaceService, appLogger)
+	webhook_handler.New(router, webhookService, appLogger)
+	inbound_email_handler.New(router, linkService, inbound_email_handler.Options{
+		WebhookSecret: cfg.InboundEmail.WebhookSecret,
+		MaxSize:       cfg.InboundEmail.MaxSize,
+	}, appLogger)
 
 	// Run
 	runServer := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -24,6 +25,8 @@ type Config struct {
 		Port int      `env:"SERVER_PORT" env-required:"true"`
 		Host string   `env:"SERVER_HOST" env-required:"true"`
 		Cors []string `env:"SERVER_CORS" env-required:"true" env-separator:","`
+		// AppURL адрес клиентского приложения для ссылок в письмах
+		AppURL string `env:"APP_URL" env-default:"http://localhost:5173"`
 	}
 	Email struct {
 		Smtp struct {
@@ -32,11 +35,99 @@ type Config struct {
 			Username string `env:"EMAIL_SMTP_USERNAME" env-required:"true"`
 			Password string `env:"EMAIL_SMTP_PASSWORD" env-required:"true"`
 		}
+		From string `env:"EMAIL_FROM" env-default:""`
 	}
 	LogLevel string `env:"LOG_LEVEL" env-default:"info"`
 	Media    struct {
 		FavIconsPath string `env:"ICONS_DIR" env-default:"./media/favicons"`
 	}
+	Links struct {
+		UsePageCanonical bool          `env:"LINKS_USE_PAGE_CANONICAL" env-default:"false"`
+		BulkJobThreshold int           `env:"LINKS_BULK_JOB_THRESHOLD" env-default:"100"`
+		BulkJobRetention time.Duration `env:"LINKS_BULK_JOB_RETENTION" env-default:"1h"`
+		InviteTTL        time.Duration `env:"LINKS_INVITE_TTL" env-default:"168h"`
+		// GoTokenTTL срок действия подписанной ссылки перехода /go/{id}?token=
+		GoTokenTTL time.Duration `env:"LINKS_GO_TOKEN_TTL" env-default:"168h"`
+		// MetadataTimeout ограничение загрузки страницы и favicon при сохранении ссылки
+		MetadataTimeout time.Duration `env:"LINKS_METADATA_TIMEOUT" env-default:"10s"`
+		// MetadataAllowPrivateNetworks разрешает загружать страницы с локальных и приватных адресов.
+		// По умолчанию выключено: адрес ссылки задает пользователь, лента или письмо
+		MetadataAllowPrivateNetworks bool `env:"LINKS_METADATA_ALLOW_PRIVATE_NETWORKS" env-default:"false"`
+	}
+	Visits struct {
+		RawRetentionDays   int           `env:"VISITS_RAW_RETENTION_DAYS" env-default:"30"`
+		DailyRetentionDays int           `env:"VISITS_DAILY_RETENTION_DAYS" env-default:"730"`
+		RollupInterval     time.Duration `env:"VISITS_ROLLUP_INTERVAL" env-default:"1h"`
+		FrecencyInterval   time.Duration `env:"VISITS_FRECENCY_INTERVAL" env-default:"6h"`
+	}
+	LinkCheck struct {
+		Interval        time.Duration `env:"LINK_CHECK_INTERVAL" env-default:"10m"`
+		Recheck         time.Duration `env:"LINK_CHECK_RECHECK" env-default:"24h"`
+		BatchSize       int           `env:"LINK_CHECK_BATCH_SIZE" env-default:"200"`
+		Workers         int           `env:"LINK_CHECK_WORKERS" env-default:"10"`
+		HostConcurrency int           `env:"LINK_CHECK_HOST_CONCURRENCY" env-default:"2"`
+		HostDelay       time.Duration `env:"LINK_CHECK_HOST_DELAY" env-default:"1s"`
+		Timeout         time.Duration `env:"LINK_CHECK_TIMEOUT" env-default:"15s"`
+		ArchiveAfter    int           `env:"LINK_CHECK_ARCHIVE_AFTER" env-default:"0"`
+	}
+	FeedPoll struct {
+		Interval  time.Duration `env:"FEED_POLL_INTERVAL" env-default:"5m"`
+		Refresh   time.Duration `env:"FEED_POLL_REFRESH" env-default:"1h"`
+		BatchSize int           `env:"FEED_POLL_BATCH_SIZE" env-default:"50"`
+		MaxItems  int           `env:"FEED_POLL_MAX_ITEMS" env-default:"20"`
+		Timeout   time.Duration `env:"FEED_POLL_TIMEOUT" env-default:"15s"`
+		// AllowPrivateNetworks разрешает ленты на локальных и приватных адресах (внутренние сервисы).
+		// По умолчанию выключено: защита от запросов во внутреннюю сеть по адресу из подписки
+		AllowPrivateNetworks bool `env:"FEED_POLL_ALLOW_PRIVATE_NETWORKS" env-default:"false"`
+	}
+	Webhooks struct {
+		DispatchInterval time.Duration `env:"WEBHOOKS_DISPATCH_INTERVAL" env-default:"5s"`
+		MaxAttempts      int           `env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+		RetryBase        time.Duration `env:"WEBHOOKS_RETRY_BASE" env-default:"30s"`
+		RetryMax         time.Duration `env:"WEBHOOKS_RETRY_MAX" env-default:"6h"`
+		BatchSize        int           `env:"WEBHOOKS_BATCH_SIZE" env-default:"100"`
+		Workers          int           `env:"WEBHOOKS_WORKERS" env-default:"10"`
+		Timeout          time.Duration `env:"WEBHOOKS_TIMEOUT" env-default:"10s"`
+		RetentionDays    int           `env:"WEBHOOKS_RETENTION_DAYS" env-default:"30"`
+		PurgeInterval    time.Duration `env:"WEBHOOKS_PURGE_INTERVAL" env-default:"1h"`
+		// AllowPrivateNetworks разрешает доставку на локальные и приватные адреса (внутренние сервисы).
+		// По умолчанию выключено: адрес webhook задает пользователь, без запрета он мог бы обращаться во внутреннюю сеть
+		AllowPrivateNetworks bool `env:"WEBHOOKS_ALLOW_PRIVATE_NETWORKS" env-default:"false"`
+	}
+	Events struct {
+		BufferSize int           `env:"EVENTS_BUFFER_SIZE" env-default:"500"`
+		Heartbeat  time.Duration `env:"EVENTS_HEARTBEAT" env-default:"20s"`
+	}
+	Reminders struct {
+		Interval  time.Duration `env:"REMINDERS_INTERVAL" env-default:"1m"`
+		BatchSize int           `env:"REMINDERS_BATCH_SIZE" env-default:"100"`
+		// Notifiers каналы доставки: email - письмом, webhook - событием link.reminder для webhooks
+		Notifiers []string `env:"REMINDERS_NOTIFIERS" env-default:"email" env-separator:","`
+		// MaxAttempts сколько раз пробовать отправить напоминание, если канал доставки вернул ошибку
+		MaxAttempts int           `env:"REMINDERS_MAX_ATTEMPTS" env-default:"5"`
+		RetryBase   time.Duration `env:"REMINDERS_RETRY_BASE" env-default:"1m"`
+		RetryMax    time.Duration `env:"REMINDERS_RETRY_MAX" env-default:"1h"`
+	}
+	Digest struct {
+		Interval  time.Duration `env:"DIGEST_INTERVAL" env-default:"5m"`
+		BatchSize int           `env:"DIGEST_BATCH_SIZE" env-default:"50"`
+	}
+	InboundEmail struct {
+		// Domain домен адресов save+<token>@domain, пусто - прием писем выключен
+		Domain    string `env:"INBOUND_EMAIL_DOMAIN" env-default:""`
+		LocalPart string `env:"INBOUND_EMAIL_LOCAL_PART" env-default:"save"`
+		MaxLinks  int    `env:"INBOUND_EMAIL_MAX_LINKS" env-default:"20"`
+		MaxSize   int64  `env:"INBOUND_EMAIL_MAX_SIZE" env-default:"10485760"`
+		// SMTPAddr адрес встроенного SMTP-сервера, например :2525, пусто - выключен
+		SMTPAddr string        `env:"INBOUND_EMAIL_SMTP_ADDR" env-default:""`
+		Timeout  time.Duration `env:"INBOUND_EMAIL_TIMEOUT" env-default:"1m"`
+		// WebhookSecret секрет webhook /inbound/email, пусто - webhook выключен
+		WebhookSecret string `env:"INBOUND_EMAIL_WEBHOOK_SECRET" env-default:""`
+	}
+	Trash struct {
+		RetentionDays int           `env:"TRASH_RETENTION_DAYS" env-default:"30"`
+		PurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+	}
 }
 
 func New() (*Config, error) {
@@ -94,6 +185,102 @@ func (c *Config) validate() error {
 		return fmt.Errorf("JWT secret must be at least 32 characters long")
 	}
 
+	// Валидация хранения посещений
+	if c.Visits.RawRetentionDays < 1 {
+		return fmt.Errorf("visits raw retention must be at least 1 day")
+	}
+
+	if c.Visits.DailyRetentionDays < 0 {
+		return fmt.Errorf("invalid visits daily retention: %d", c.Visits.DailyRetentionDays)
+	}
+
+	// Валидация проверки ссылок
+	if c.LinkCheck.BatchSize < 1 || c.LinkCheck.Workers < 1 || c.LinkCheck.HostConcurrency < 1 {
+		return fmt.Errorf("link check batch size, workers and host concurrency must be positive")
+	}
+
+	if c.LinkCheck.ArchiveAfter < 0 {
+		return fmt.Errorf("invalid link check archive after: %d", c.LinkCheck.ArchiveAfter)
+	}
+
+	// Валидация загрузки внешних лент
+	if c.FeedPoll.Interval <= 0 || c.FeedPoll.Refresh <= 0 {
+		return fmt.Errorf("feed poll interval and refresh must be positive")
+	}
+
+	if c.FeedPoll.BatchSize < 1 || c.FeedPoll.MaxItems < 1 {
+		return fmt.Errorf("feed poll batch size and max items must be positive")
+	}
+
+	// Валидация webhooks
+	if c.Webhooks.MaxAttempts < 1 || c.Webhooks.BatchSize < 1 || c.Webhooks.Workers < 1 {
+		return fmt.Errorf("webhooks max attempts, batch size and workers must be positive")
+	}
+
+	if c.Webhooks.RetryBase <= 0 || c.Webhooks.RetryMax < c.Webhooks.RetryBase {
+		return fmt.Errorf("webhooks retry base must be positive and not greater than retry max")
+	}
+
+	if c.Webhooks.RetentionDays < 1 {
+		return fmt.Errorf("webhooks retention must be at least 1 day")
+	}
+
+	// Валидация потока событий
+	if c.Events.BufferSize < 1 || c.Events.Heartbeat <= 0 {
+		return fmt.Errorf("events buffer size and heartbeat must be positive")
+	}
+
+	// Валидация напоминаний
+	if c.Reminders.Interval <= 0 || c.Reminders.BatchSize < 1 || c.Reminders.MaxAttempts < 1 {
+		return fmt.Errorf("reminders interval, batch size and max attempts must be positive")
+	}
+
+	if c.Reminders.RetryBase <= 0 || c.Reminders.RetryMax < c.Reminders.RetryBase {
+		return fmt.Errorf("reminders retry base must be positive and not greater than retry max")
+	}
+
+	for _, notifier := range c.Reminders.Notifiers {
+		if notifier != "email" && notifier != "webhook" {
+			return fmt.Errorf("invalid reminders notifier: %q", notifier)
+		}
+	}
+
+	// Валидация дайджестов
+	if c.Digest.Interval <= 0 || c.Digest.BatchSize < 1 {
+		return fmt.Errorf("digest interval and batch size must be positive")
+	}
+
+	// Валидация приема писем
+	if (c.InboundEmail.SMTPAddr != "" || c.InboundEmail.WebhookSecret != "") && c.InboundEmail.Domain == "" {
+		return fmt.Errorf("inbound email domain is required when SMTP listener or webhook is enabled")
+	}
+
+	if c.InboundEmail.LocalPart == "" || c.InboundEmail.MaxLinks < 1 || c.InboundEmail.MaxSize < 1 || c.InboundEmail.Timeout <= 0 {
+		return fmt.Errorf("inbound email local part, max links, max size and timeout must be set")
+	}
+
+	if c.InboundEmail.WebhookSecret != "" && len(c.InboundEmail.WebhookSecret) < 16 {
+		return fmt.Errorf("inbound email webhook secret must be at least 16 characters long")
+	}
+
+	// Валидация массовых действий
+	if c.Links.BulkJobThreshold < 1 {
+		return fmt.Errorf("links bulk job threshold must be positive")
+	}
+
+	if c.Links.InviteTTL <= 0 {
+		return fmt.Errorf("links invite TTL must be positive")
+	}
+
+	if c.Links.GoTokenTTL <= 0 {
+		return fmt.Errorf("links go token TTL must be positive")
+	}
+
+	// Валидация корзины
+	if c.Trash.RetentionDays < 1 {
+		return fmt.Errorf("trash retention must be at least 1 day")
+	}
+
 	// Валидация CORS
 	if len(c.Server.Cors) == 0 {
 		return fmt.Errorf("at least one CORS origin must be specified")
@@ -0,0 +1,82 @@
+package auth_handler
+
+import (
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (a *authHandler) apiTokenList(w http.ResponseWriter, r *http.Request) {
+	op := "auth_handler.apiTokenList"
+
+	user := middleware.GetCurrentUserFromContext(r.Context())
+	if user == nil {
+		response.WriteError(w, app_errors.Unauthorized(op))
+		return
+	}
+
+	tokens, err := a.service.GetAPITokens(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, tokens)
+}
+
+// apiTokenCreate выпускает персональный токен, значение токена есть только в этом ответе
+func (a *authHandler) apiTokenCreate(w http.ResponseWriter, r *http.Request) {
+	op := "auth_handler.apiTokenCreate"
+
+	user := middleware.GetCurrentUserFromContext(r.Context())
+	if user == nil {
+		response.WriteError(w, app_errors.Unauthorized(op))
+		return
+	}
+
+	tokenRequest, err := request.ParseRequestBody[models.APITokenCreate](r)
+	if err != nil || tokenRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := tokenRequest.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	token, err := a.service.CreateAPIToken(r.Context(), user.ID, tokenRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.WriteSuccess(w, token)
+}
+
+func (a *authHandler) apiTokenDelete(w http.ResponseWriter, r *http.Request) {
+	op := "auth_handler.apiTokenDelete"
+
+	user := middleware.GetCurrentUserFromContext(r.Context())
+	if user == nil {
+		response.WriteError(w, app_errors.Unauthorized(op))
+		return
+	}
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("Токен не найден", op))
+		return
+	}
+
+	if err := a.service.DeleteAPIToken(r.Context(), user.ID, id); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
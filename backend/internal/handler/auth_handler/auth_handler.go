@@ -43,6 +43,14 @@ func New(r *chi.Mux, service auth_service.AuthService, logger logger.AppLogger)
 		r.Get("/profile", h.profile)
 		r.Post("/refresh-token", h.refreshToken)
 	})
+
+	// Персональные токены доступа
+	r.Route("/api/v1/tokens", func(r chi.Router) {
+		r.Use(httprate.LimitByIP(5, 1*time.Second))
+		r.Get("/", h.apiTokenList)
+		r.Post("/", h.apiTokenCreate)
+		r.Delete("/{id}", h.apiTokenDelete)
+	})
 }
 
 func (a *authHandler) register(w http.ResponseWriter, r *http.Request) {
@@ -160,6 +168,7 @@ func (a *authHandler) login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	a.logger.Info("Вход успешен", op, "email", loginReq.Email)
+	setAccessTokenCookie(w, r, sessionResponse.AccessToken)
 	response.WriteSuccess(w, sessionResponse)
 }
 
@@ -201,6 +210,7 @@ func (a *authHandler) refreshToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	a.logger.Info("Refresh token успешен", op, "user_id", sessionResponse.User.ID)
+	setAccessTokenCookie(w, r, sessionResponse.AccessToken)
 	response.WriteSuccess(w, sessionResponse)
 }
 
@@ -212,3 +222,17 @@ func (a *authHandler) profile(w http.ResponseWriter, r *http.Request) {
 	}
 	response.WriteError(w, app_errors.Unauthorized("Пользователь не авторизован"))
 }
+
+// setAccessTokenCookie дублирует access token в HttpOnly cookie, чтобы переходы по ссылкам
+// вида /go/{id} из браузера были авторизованы без заголовка Authorization
+func setAccessTokenCookie(w http.ResponseWriter, r *http.Request, accessToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.AccessTokenCookie,
+		Value:    accessToken,
+		Path:     "/",
+		MaxAge:   int(time.Hour.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,115 @@
+package inbound_email_handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"link-storage/internal/service/link_service"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/response"
+	"link-storage/pkg/smtpd"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httprate"
+)
+
+// SecretHeader заголовок с общим секретом webhook приема писем
+const SecretHeader = "X-Inbound-Secret"
+
+// Options настройки приема писем
+type Options struct {
+	// WebhookSecret секрет webhook, пусто - webhook выключен
+	WebhookSecret string
+	// MaxSize предельный размер письма в байтах
+	MaxSize int64
+}
+
+type inboundEmailHandler struct {
+	service link_service.LinkService
+	options Options
+	logger  logger.AppLogger
+}
+
+// New регистрирует webhook приема писем: POST /inbound/email с письмом RFC 822 в теле.
+// Адресаты - параметры recipient, без них - из заголовков письма
+func New(r *chi.Mux, service link_service.LinkService, options Options, logger logger.AppLogger) {
+	if r == nil {
+		panic("inbound_email_handler.New: получен nil router")
+	}
+
+	if service == nil {
+		panic("inbound_email_handler.New: получен nil service")
+	}
+
+	h := &inboundEmailHandler{
+		service: service,
+		options: options,
+		logger:  logger,
+	}
+
+	r.Route("/inbound", func(r chi.Router) {
+		r.Use(httprate.LimitByIP(10, 1*time.Second))
+		r.Post("/email", h.inboundEmail)
+	})
+}
+
+func (h *inboundEmailHandler) inboundEmail(w http.ResponseWriter, r *http.Request) {
+	op := "inbound_email_handler.inboundEmail"
+
+	if h.options.WebhookSecret == "" {
+		response.WriteError(w, app_errors.NotFound("Прием писем не настроен", op))
+		return
+	}
+
+	secret := r.Header.Get(SecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(h.options.WebhookSecret)) != 1 {
+		h.logger.Warn("Письмо с неверным секретом", op, "remote_addr", r.RemoteAddr)
+		response.WriteError(w, app_errors.Unauthorized(op))
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.options.MaxSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.WriteError(w, app_errors.New(http.StatusRequestEntityTooLarge, "BAD_REQUEST", "Письмо слишком большое", op))
+			return
+		}
+		response.WriteError(w, app_errors.BadRequest("Не удалось прочитать письмо", op))
+		return
+	}
+
+	result, err := h.service.SaveInboundEmail(r.Context(), r.URL.Query()["recipient"], data)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, result)
+}
+
+// SMTPHandler обработчик писем встроенного SMTP-сервера. Неизвестный адрес и неверное письмо -
+// постоянный отказ, чтобы отправитель не повторял доставку
+func SMTPHandler(service link_service.LinkService) smtpd.Handler {
+	return func(ctx context.Context, envelope *smtpd.Envelope) error {
+		_, err := service.SaveInboundEmail(ctx, envelope.To, envelope.Data)
+		if err == nil {
+			return nil
+		}
+
+		var appErr *app_errors.AppError
+		if errors.As(err, &appErr) {
+			switch {
+			case appErr.Code == http.StatusNotFound:
+				return &smtpd.Error{Code: 550, Message: "Mailbox unavailable"}
+			case appErr.Code < http.StatusInternalServerError:
+				return &smtpd.Error{Code: 554, Message: "Message rejected"}
+			}
+		}
+		return err
+	}
+}
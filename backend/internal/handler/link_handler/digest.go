@@ -0,0 +1,126 @@
+package link_handler
+
+import (
+	"html/template"
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// unsubscribePageTemplate страница отписки от дайджеста. GET только спрашивает подтверждение:
+// почтовые сканеры открывают ссылки из писем. Отписка в один клик из почтового клиента - POST
+var unsubscribePageTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<meta name="referrer" content="no-referrer">
+<title>Отписка от дайджеста</title>
+<style>` + savePageStyle + `</style>
+</head>
+<body>
+<main>
+{{- if .NotFound}}
+<h1>Подписка не найдена</h1>
+<p>Ссылка устарела или вы уже отписались.</p>
+{{- else if .Done}}
+<h1>Вы отписались</h1>
+<p>Дайджест больше не будет приходить. Включить его снова можно в настройках приложения.</p>
+{{- else}}
+<h1>Отписаться от дайджеста?</h1>
+<form method="post">
+<button type="submit">Отписаться</button>
+</form>
+{{- end}}
+</main>
+</body>
+</html>`))
+
+type unsubscribePage struct {
+	Done     bool
+	NotFound bool
+}
+
+func (h *linkHandler) digestSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.service.GetDigestSettings(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, settings)
+}
+
+func (h *linkHandler) digestSettingsSave(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.digestSettingsSave"
+
+	settingsSave, err := request.ParseRequestBody[models.DigestSettingsSave](r)
+	if err != nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+	if settingsSave == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := settingsSave.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	settings, err := h.service.SaveDigestSettings(r.Context(), settingsSave)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, settings)
+}
+
+// digestPreview письмо дайджеста, каким оно ушло бы сейчас
+func (h *linkHandler) digestPreview(w http.ResponseWriter, r *http.Request) {
+	message, err := h.service.PreviewDigest(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, message)
+}
+
+// digestUnsubscribe отписка по ссылке из письма: GET - подтверждение, POST - отписка
+func (h *linkHandler) digestUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.digestUnsubscribe"
+
+	page := &unsubscribePage{}
+	status := http.StatusOK
+
+	if r.Method == http.MethodPost {
+		if err := h.service.UnsubscribeDigest(r.Context(), chi.URLParam(r, "token")); err != nil {
+			if !app_errors.IsNotFound(err) {
+				h.logger.Error(err, op)
+				http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+				return
+			}
+			page.NotFound = true
+			status = http.StatusNotFound
+		} else {
+			page.Done = true
+		}
+	}
+
+	w.Header().Set("Content-Security-Policy", savePageCSP)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := unsubscribePageTemplate.Execute(w, page); err != nil {
+		h.logger.Error(err, op)
+	}
+}
@@ -0,0 +1,109 @@
+package link_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/pkg/events"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"net/http"
+	"time"
+)
+
+// sseRetry через сколько миллисекунд EventSource переподключается после обрыва
+const sseRetry = 3000
+
+// sseEventNames события, которые уходят в поток SSE, и их имена для клиента
+var sseEventNames = map[string]string{
+	models.EventLinkCreated:       "link.created",
+	models.EventLinkUpdated:       "link.updated",
+	models.EventLinkDeleted:       "link.deleted",
+	models.EventLinkMetadataReady: "link.metadata_ready",
+	models.EventLinkGroupCreated:  "group.created",
+	models.EventLinkGroupUpdated:  "group.updated",
+	models.EventLinkGroupDeleted:  "group.deleted",
+}
+
+// events поток Server-Sent Events с изменениями ссылок и групп. Продолжает поток с Last-Event-ID
+// (заголовок или параметр last_event_id), если событие еще в буфере, иначе первым шлет reset -
+// клиенту нужно перечитать данные
+func (h *linkHandler) events(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.events"
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID, _ = request.GetQueryValueFromRequest(r, "last_event_id")
+	}
+
+	stream, err := h.service.SubscribeEvents(r.Context(), lastEventID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+	defer stream.Cancel()
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Отключает буферизацию ответа в nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+	if lastEventID != "" && !stream.Resumed {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, envelope := range stream.Replay {
+		h.writeEvent(w, envelope, stream.Visible, op)
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.Warn("Поток событий не поддерживает отправку частями", op, "error", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(stream.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case envelope, ok := <-stream.C:
+			if !ok {
+				// Клиент не успевал читать: переподключится и дочитает из буфера
+				return
+			}
+			if !h.writeEvent(w, envelope, stream.Visible, op) {
+				continue
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent пишет событие в поток, если клиенту оно нужно и доступно. Возвращает, было ли что-то записано
+func (h *linkHandler) writeEvent(w http.ResponseWriter, envelope events.Envelope, visible func(events.Event) bool, op string) bool {
+	name, ok := sseEventNames[envelope.Event.Type]
+	if !ok || !visible(envelope.Event) {
+		return false
+	}
+
+	event := envelope.Event
+	event.Type = name
+	data, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error(err, op, "event_type", envelope.Event.Type)
+		return false
+	}
+
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", envelope.ID, name, data)
+	return true
+}
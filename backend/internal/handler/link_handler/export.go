@@ -0,0 +1,104 @@
+package link_handler
+
+import (
+	"encoding/json"
+	"html/template"
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"time"
+)
+
+// exportBookmarksTemplate формат закладок Netscape, который импортируют браузеры. Заметки и цитаты
+// попадают в описание закладки <DD>
+var exportBookmarksTemplate = template.Must(template.New("bookmarks").Funcs(template.FuncMap{
+	// noteHTML HTML заметки уже очищен рендером Markdown
+	"noteHTML": func(note *models.LinkNote) template.HTML { return template.HTML(note.HTML) },
+	"unix":     func(t time.Time) int64 { return t.Unix() },
+}).Parse(`<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+{{- range .}}
+{{- if .Folder}}
+<DT><H3>{{.Folder}}</H3>
+<DL><p>
+{{- end}}
+{{- range .Links}}
+<DT><A HREF="{{.URL}}" ADD_DATE="{{unix .CreatedAt}}" LAST_MODIFIED="{{unix .UpdatedAt}}">{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</A>
+{{- if or .Description .Notes .Highlights}}
+<DD>{{.Description}}
+{{- range .Notes}}
+{{noteHTML .}}
+{{- end}}
+{{- range .Highlights}}
+<blockquote>{{.Quote}}</blockquote>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Folder}}
+</DL><p>
+{{- end}}
+{{- end}}
+</DL><p>
+`))
+
+// exportFolder ссылки одной группы в выгрузке, пустое имя - ссылки без группы
+type exportFolder struct {
+	Folder string
+	Links  []*models.ExportLink
+}
+
+// linkExport выгрузка ссылок активного пространства с заметками и цитатами:
+// ?format=json (по умолчанию) или ?format=html - закладки Netscape
+func (h *linkHandler) linkExport(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkExport"
+
+	format, _ := request.GetQueryValueFromRequest(r, "format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "html" {
+		response.WriteError(w, app_errors.BadRequest("неверный формат выгрузки", op))
+		return
+	}
+
+	links, err := h.service.ExportLinks(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="links.`+format+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(links); err != nil {
+			h.logger.Error(err, op)
+		}
+		return
+	}
+
+	// Ссылки уже отсортированы по группе
+	var folders []*exportFolder
+	for _, link := range links {
+		name := ""
+		if link.GroupName != nil {
+			name = *link.GroupName
+		}
+		if len(folders) == 0 || folders[len(folders)-1].Folder != name {
+			folders = append(folders, &exportFolder{Folder: name})
+		}
+		folder := folders[len(folders)-1]
+		folder.Links = append(folder.Links, link)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := exportBookmarksTemplate.Execute(w, folders); err != nil {
+		h.logger.Error(err, op)
+	}
+}
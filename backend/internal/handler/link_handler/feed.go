@@ -0,0 +1,184 @@
+package link_handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/pkg/feed"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// feedSourceFromRequest источник ленты из пути: /link-groups/{id}/feed или /tags/{id}/feed
+func feedSourceFromRequest(r *http.Request) (models.FeedSource, bool) {
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		return models.FeedSource{}, false
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v1/tags/") {
+		return models.FeedSource{TagID: &id}, true
+	}
+	return models.FeedSource{LinkGroupID: &id}, true
+}
+
+func (h *linkHandler) feedToken(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feedToken"
+
+	source, ok := feedSourceFromRequest(r)
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("источник ленты не найден", op))
+		return
+	}
+
+	feedToken, err := h.service.GetFeedToken(r.Context(), source)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, feedToken)
+}
+
+func (h *linkHandler) feedTokenCreate(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feedTokenCreate"
+
+	source, ok := feedSourceFromRequest(r)
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("источник ленты не найден", op))
+		return
+	}
+
+	feedToken, err := h.service.CreateFeedToken(r.Context(), source)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, feedToken)
+}
+
+func (h *linkHandler) feedTokenDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feedTokenDelete"
+
+	source, ok := feedSourceFromRequest(r)
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("источник ленты не найден", op))
+		return
+	}
+
+	if err := h.service.DeleteFeedToken(r.Context(), source); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// feed лента /feeds/{token}/{format} без авторизации, поддерживает условный GET по ETag и Last-Modified
+func (h *linkHandler) feed(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feed"
+
+	format, err := models.ParseFeedFormat(r.PathValue("format"))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	linkFeed, err := h.service.GetFeed(r.Context(), r.PathValue("token"))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	etag := feedETag(linkFeed, format)
+	lastModified := linkFeed.UpdatedAt.UTC().Truncate(time.Second)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	if feedNotModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	items := make([]*feed.Item, 0, len(linkFeed.Items))
+	for _, item := range linkFeed.Items {
+		title := item.Title
+		if title == "" {
+			title = item.URL
+		}
+		items = append(items, &feed.Item{
+			ID:          fmt.Sprintf("urn:link-storage:link:%d", item.LinkID),
+			URL:         item.URL,
+			Title:       title,
+			Description: item.Description,
+			Image:       item.PreviewImage,
+			Categories:  item.Tags,
+			Published:   item.CreatedAt,
+			Updated:     item.UpdatedAt,
+		})
+	}
+
+	out := &feed.Feed{
+		ID:          fmt.Sprintf("urn:link-storage:feed:%d", linkFeed.ID),
+		Title:       linkFeed.Title,
+		Description: linkFeed.Description,
+		Link:        linkFeed.Link,
+		Updated:     linkFeed.UpdatedAt,
+		Items:       items,
+	}
+
+	var body []byte
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == models.FeedFormatRSS {
+		contentType = "application/rss+xml; charset=utf-8"
+		body, err = feed.RSS(out)
+	} else {
+		body, err = feed.Atom(out)
+	}
+	if err != nil {
+		h.logger.Error(err, op, "feed_id", linkFeed.ID)
+		response.WriteError(w, app_errors.Internal(err, op))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+// feedETag меняется при изменении любой ссылки ленты, составе ленты или ее заголовка
+func feedETag(linkFeed *models.Feed, format models.FeedFormat) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%d|%s|%s|%d", format, linkFeed.ID, linkFeed.Title, linkFeed.Description, linkFeed.UpdatedAt.UnixNano())
+	for _, item := range linkFeed.Items {
+		fmt.Fprintf(h, "|%d", item.LinkID)
+	}
+	return `"` + base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// feedNotModified проверка условного GET: If-None-Match важнее If-Modified-Since
+func feedNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, value := range strings.Split(ifNoneMatch, ",") {
+			value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+			if value == etag || value == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		if since, err := http.ParseTime(ifModifiedSince); err == nil {
+			return !lastModified.After(since)
+		}
+	}
+	return false
+}
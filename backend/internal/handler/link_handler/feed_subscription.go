@@ -0,0 +1,104 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) feedSubscriptions(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feedSubscriptions"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	subscriptions, err := h.service.GetFeedSubscriptions(r.Context(), linkGroupID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, subscriptions)
+}
+
+func (h *linkHandler) feedSubscriptionCreate(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feedSubscriptionCreate"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	subscriptionRequest, err := request.ParseRequestBody[models.FeedSubscriptionCreate](r)
+	if err != nil || subscriptionRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := subscriptionRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	subscription, err := h.service.CreateFeedSubscription(r.Context(), linkGroupID, subscriptionRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, subscription)
+}
+
+func (h *linkHandler) feedSubscriptionDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feedSubscriptionDelete"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	subscriptionID, ok := request.GetIntFromRequest(r, "subscription_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("подписка не найдена", op))
+		return
+	}
+
+	if err := h.service.DeleteFeedSubscription(r.Context(), linkGroupID, subscriptionID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// feedSubscriptionRefresh загружает ленту сразу и возвращает подписку с результатом загрузки
+func (h *linkHandler) feedSubscriptionRefresh(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.feedSubscriptionRefresh"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	subscriptionID, ok := request.GetIntFromRequest(r, "subscription_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("подписка не найдена", op))
+		return
+	}
+
+	subscription, err := h.service.RefreshFeedSubscription(r.Context(), linkGroupID, subscriptionID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, subscription)
+}
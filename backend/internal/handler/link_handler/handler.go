@@ -24,16 +24,113 @@ func New(r *chi.Mux, service link_service.LinkService, logger logger.AppLogger)
 		// LinkGroup
 		r.Use(httprate.LimitByIP(5, 1*time.Second))
 		r.Post("/link-groups", h.linkGroupCreate)
+		r.Put("/link-groups/order", h.linkGroupsOrder)
+		r.Get("/link-groups/tree", h.linkGroupTree)
 		r.Put("/link-groups/{id}", h.linkGroupUpdate)
 		r.Delete("/link-groups/{id}", h.linkGroupDelete)
 		r.Get("/link-groups", h.linkGroupList)
+		r.Get("/link-groups/{id}/visits", h.linkGroupVisits)
+		r.Put("/link-groups/{id}/links/order", h.linksOrder)
+		r.Put("/link-groups/{id}/parent", h.linkGroupParentSet)
+		r.Get("/link-groups/{id}/delete-preview", h.linkGroupDeletePreview)
+		r.Get("/link-groups/{id}/history", h.linkGroupHistory)
+		// LinkGroupMember
+		r.Get("/link-groups/{id}/members", h.linkGroupMembers)
+		r.Put("/link-groups/{id}/members/{user_id}", h.linkGroupMemberSet)
+		r.Delete("/link-groups/{id}/members/{user_id}", h.linkGroupMemberDelete)
+		r.Post("/link-groups/{id}/invites", h.linkGroupInviteCreate)
+		r.Get("/link-groups/{id}/invites", h.linkGroupInvites)
+		r.Delete("/link-groups/{id}/invites/{invite_id}", h.linkGroupInviteDelete)
+		r.Post("/link-group-invites/{token}/accept", h.linkGroupInviteAccept)
+		// LinkGroupShare
+		r.Get("/link-groups/{id}/share", h.linkGroupShare)
+		r.Put("/link-groups/{id}/share", h.linkGroupShareSave)
+		r.Delete("/link-groups/{id}/share", h.linkGroupShareDelete)
+		// Feed
+		r.Get("/link-groups/{id}/feed", h.feedToken)
+		r.Post("/link-groups/{id}/feed", h.feedTokenCreate)
+		r.Delete("/link-groups/{id}/feed", h.feedTokenDelete)
+		r.Get("/tags/{id}/feed", h.feedToken)
+		r.Post("/tags/{id}/feed", h.feedTokenCreate)
+		r.Delete("/tags/{id}/feed", h.feedTokenDelete)
+		// FeedSubscription
+		r.Get("/link-groups/{id}/subscriptions", h.feedSubscriptions)
+		r.Post("/link-groups/{id}/subscriptions", h.feedSubscriptionCreate)
+		r.Delete("/link-groups/{id}/subscriptions/{subscription_id}", h.feedSubscriptionDelete)
+		r.Post("/link-groups/{id}/subscriptions/{subscription_id}/refresh", h.feedSubscriptionRefresh)
 		// Link
 		r.Post("/links", h.linkCreate)
 		r.Post("/links/refresh-icon/{id}", h.linkRefreshIcon)
 		r.Post("/links/visited/{id}", h.linkVisitedPlus)
 		r.Get("/links/top-visited", h.getLinkTopVisited)
-		//r.Put("/links/{id}", h.linkUpdate)
-		//r.Delete("/links/{id}", h.linkDelete)
+		r.Get("/links/recent-visited", h.getLinkRecentVisited)
+		r.Get("/links/launcher", h.linkLauncher)
+		r.Get("/links/broken", h.linkBrokenList)
+		r.Get("/links/duplicates", h.linkDuplicates)
+		r.Post("/links/merge", h.linkMerge)
+		r.Post("/links/bulk", h.linkBulk)
+		r.Get("/links/bulk/{job_id}", h.linkBulkJob)
+		r.Get("/links/{id}/visits", h.linkVisits)
+		r.Get("/links/{id}/go-url", h.linkGoURL)
+		r.Put("/links/{id}/slug", h.linkSlugSet)
+		r.Delete("/links/{id}/slug", h.linkSlugDelete)
+		r.Get("/links/{id}/slug/stats", h.linkSlugStats)
+		r.Put("/links/{id}/read", h.linkRead)
+		r.Delete("/links/{id}/read", h.linkRead)
+		r.Put("/links/{id}/reminder", h.linkReminderSet)
+		r.Delete("/links/{id}/reminder", h.linkReminderDelete)
+		r.Get("/links/{id}/notes", h.linkNotes)
+		r.Post("/links/{id}/notes", h.linkNoteCreate)
+		r.Put("/links/{id}/notes/{note_id}", h.linkNoteUpdate)
+		r.Delete("/links/{id}/notes/{note_id}", h.linkNoteDelete)
+		r.Get("/links/{id}/highlights", h.linkHighlights)
+		r.Post("/links/{id}/highlights", h.linkHighlightCreate)
+		r.Delete("/links/{id}/highlights/{highlight_id}", h.linkHighlightDelete)
+		r.Get("/links/{id}/history", h.linkHistory)
+		r.Post("/links/{id}/history/{history_id}/revert", h.linkRevert)
+		r.Put("/links/{id}", h.linkUpdate)
+		r.Delete("/links/{id}", h.linkDelete)
 		r.Get("/links", h.linkList)
+		// InboundEmail
+		r.Get("/inbound-email", h.inboundEmail)
+		r.Post("/inbound-email", h.inboundEmailCreate)
+		r.Delete("/inbound-email", h.inboundEmailDelete)
+		// Digest
+		r.Get("/digest", h.digestSettings)
+		r.Put("/digest", h.digestSettingsSave)
+		r.Get("/digest/preview", h.digestPreview)
+		r.Get("/digest/unsubscribe/{token}", h.digestUnsubscribe)
+		r.Post("/digest/unsubscribe/{token}", h.digestUnsubscribe)
+		// Export
+		r.Get("/export", h.linkExport)
+		// Events
+		r.Get("/events", h.events)
+		// Trash
+		r.Get("/trash", h.trashList)
+		r.Post("/trash/{type}/{id}/restore", h.trashRestore)
+	})
+
+	// Redirect
+	r.Group(func(r chi.Router) {
+		r.Use(httprate.LimitByIP(10, 1*time.Second))
+		r.Get("/go/{key}", h.linkGo)
+		r.Get("/s/{slug}", h.linkShortGo)
+	})
+
+	// Save: букмарклет и Web Share Target
+	r.Group(func(r chi.Router) {
+		r.Use(httprate.LimitByIP(5, 1*time.Second))
+		r.Get("/save", h.linkSaveForm)
+		r.Post("/save", h.linkSave)
+		r.Post("/share", h.linkShare)
+	})
+
+	// Public
+	r.Group(func(r chi.Router) {
+		r.Use(httprate.LimitByIP(5, 1*time.Second))
+		r.Get("/p/{slug}", h.publicLinkGroup)
+		r.Post("/p/{slug}", h.publicLinkGroup)
+		r.Get("/p/{slug}/favicons/{link_id}", h.publicLinkFavicon)
+		r.Get("/feeds/{token}/{format}", h.feed)
 	})
 }
@@ -0,0 +1,35 @@
+package link_handler
+
+import (
+	"link-storage/pkg/response"
+	"net/http"
+)
+
+func (h *linkHandler) inboundEmail(w http.ResponseWriter, r *http.Request) {
+	inbox, err := h.service.GetInboundEmail(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, inbox)
+}
+
+func (h *linkHandler) inboundEmailCreate(w http.ResponseWriter, r *http.Request) {
+	inbox, err := h.service.CreateInboundEmail(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, inbox)
+}
+
+func (h *linkHandler) inboundEmailDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteInboundEmail(r.Context()); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
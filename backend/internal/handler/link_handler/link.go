@@ -58,11 +58,49 @@ func (h *linkHandler) linkRefreshIcon(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *linkHandler) linkList(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkList"
+
 	page, pageSize := request.GetPaginateFromRequest(r)
 	name, _ := request.GetQueryValueFromRequest(r, "q")
-	linkGroupID, _ := request.GetIntFromRequest(r, "link_group_id")
+	linkGroupID, _ := request.GetQueryIntValueFromRequest(r, "link_group_id")
+	includeSubgroups, _ := request.GetQueryBoolValueFromRequest(r, "include_subgroups")
+	broken, _ := request.GetQueryBoolValueFromRequest(r, "broken")
+	// state=unread - список чтения
+	state, _ := request.GetQueryValueFromRequest(r, "state")
+
+	filter := models.LinkFilter{
+		LinkGroupID:      linkGroupID,
+		IncludeSubgroups: includeSubgroups,
+		Name:             name,
+		Broken:           broken,
+		State:            models.LinkReadState(state),
+	}
+
+	if filter.State != "" && !filter.State.IsValid() {
+		response.WriteError(w, app_errors.BadRequest("Неверное состояние: unread или read", op))
+		return
+	}
+
+	linkList, err := h.service.GetLinksByUserIDWithPagination(r.Context(), filter, page, pageSize)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, linkList)
+}
+
+// linkBrokenList отчет по ссылкам, которые не открылись при последней проверке
+func (h *linkHandler) linkBrokenList(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := request.GetPaginateFromRequest(r)
+	linkGroupID, _ := request.GetQueryIntValueFromRequest(r, "link_group_id")
+
+	filter := models.LinkFilter{
+		LinkGroupID: linkGroupID,
+		Broken:      true,
+	}
 
-	linkList, err := h.service.GetLinksByUserIDWithPagination(r.Context(), linkGroupID, page, pageSize, name)
+	linkList, err := h.service.GetLinksByUserIDWithPagination(r.Context(), filter, page, pageSize)
 	if err != nil {
 		response.WriteError(w, err)
 		return
@@ -71,17 +109,17 @@ func (h *linkHandler) linkList(w http.ResponseWriter, r *http.Request) {
 	response.WriteSuccess(w, linkList)
 }
 
-// linkVisitedPlus при каждом посещении ссылки двинем счетчик
-func (h *linkHandler) linkVisitedPlus(w http.ResponseWriter, r *http.Request) {
-	op := "linkHandler.linkVisitedPlus"
+// linkDelete перемещает ссылку в корзину
+func (h *linkHandler) linkDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkDelete"
 
 	linkID, ok := request.GetIntFromRequest(r, "id")
 	if !ok {
-		response.WriteError(w, app_errors.BadRequest("Невалидный url", op))
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
 		return
 	}
 
-	if err := h.service.LinkVisitedPlus(r.Context(), linkID); err != nil {
+	if err := h.service.DeleteLink(r.Context(), linkID); err != nil {
 		response.WriteError(w, err)
 		return
 	}
@@ -89,17 +127,31 @@ func (h *linkHandler) linkVisitedPlus(w http.ResponseWriter, r *http.Request) {
 	response.WriteSuccess(w, nil)
 }
 
-func (h *linkHandler) getLinkTopVisited(w http.ResponseWriter, r *http.Request) {
-	links, err := h.service.GetLinksTopVisited(r.Context())
-	if err != nil {
+func (h *linkHandler) linkUpdate(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkUpdate"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	linkUpdate, err := request.ParseRequestBody[models.LinkUpdate](r)
+	if err != nil || linkUpdate == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := linkUpdate.Validate(); err != nil {
 		response.WriteError(w, err)
 		return
 	}
 
-	if links == nil {
-		response.WriteSuccess(w, []models.Link{})
+	link, err := h.service.UpdateLink(r.Context(), linkID, linkUpdate)
+	if err != nil {
+		response.WriteError(w, err)
 		return
 	}
 
-	response.WriteSuccess(w, links)
+	response.WriteSuccess(w, link)
 }
@@ -0,0 +1,49 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// linkBulk выполняет действие над набором ссылок. Большой набор уходит в фон: ответ 202 с ID задачи
+func (h *linkHandler) linkBulk(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkBulk"
+
+	bulk, err := request.ParseRequestBody[models.LinkBulk](r)
+	if err != nil || bulk == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := bulk.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	job, err := h.service.BulkLinks(r.Context(), bulk)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if job.ID != "" {
+		response.WriteAccepted(w, job)
+		return
+	}
+	response.WriteSuccess(w, job)
+}
+
+func (h *linkHandler) linkBulkJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.GetLinkBulkJob(r.Context(), chi.URLParam(r, "job_id"))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, job)
+}
@@ -0,0 +1,42 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) linkDuplicates(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.service.GetLinkDuplicates(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, groups)
+}
+
+func (h *linkHandler) linkMerge(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkMerge"
+
+	linkMerge, err := request.ParseRequestBody[models.LinkMerge](r)
+	if err != nil || linkMerge == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := linkMerge.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	link, err := h.service.MergeLinks(r.Context(), linkMerge)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, link)
+}
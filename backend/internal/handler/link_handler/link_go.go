@@ -0,0 +1,69 @@
+package link_handler
+
+import (
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+// linkGo переход по ссылке (ID или короткое имя): учитывает посещение и отвечает редиректом 302
+func (h *linkHandler) linkGo(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGo"
+
+	key := r.PathValue("key")
+	if key == "" {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	token, _ := request.GetQueryValueFromRequest(r, "token")
+
+	target, err := h.service.GoToLink(r.Context(), key, token, r.Referer(), r.UserAgent())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// linkShortGo переход по короткой ссылке /s/{slug}
+func (h *linkHandler) linkShortGo(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkShortGo"
+
+	slug := r.PathValue("slug")
+	if slug == "" {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	token, _ := request.GetQueryValueFromRequest(r, "token")
+
+	target, err := h.service.GoToShortLink(r.Context(), slug, token, r.Referer(), r.UserAgent())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// linkGoURL путь редиректа с подписанным токеном, которым владелец может поделиться
+func (h *linkHandler) linkGoURL(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGoURL"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	goURL, err := h.service.GetLinkGoURL(r.Context(), linkID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, goURL)
+}
@@ -79,7 +79,18 @@ func (h *linkHandler) linkGroupDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeleteLinkGroup(r.Context(), linkGroupID); err != nil {
+	// children=delete - удалить подгруппы вместе с группой, по умолчанию они поднимаются на уровень выше.
+	// links - что сделать со ссылками, по умолчанию они остаются без группы
+	children, _ := request.GetQueryValueFromRequest(r, "children")
+	links, _ := request.GetQueryValueFromRequest(r, "links")
+
+	params, err := models.ParseLinkGroupDelete(children, links)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteLinkGroup(r.Context(), linkGroupID, params); err != nil {
 		response.WriteError(w, err)
 		return
 	}
@@ -87,6 +98,34 @@ func (h *linkHandler) linkGroupDelete(w http.ResponseWriter, r *http.Request) {
 	response.WriteSuccess(w, nil)
 }
 
+// linkGroupDeletePreview сколько ссылок затронет удаление группы с теми же параметрами
+func (h *linkHandler) linkGroupDeletePreview(w http.ResponseWriter, r *http.Request) {
+	op := "link_handler.linkGroupDeletePreview"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	children, _ := request.GetQueryValueFromRequest(r, "children")
+	links, _ := request.GetQueryValueFromRequest(r, "links")
+
+	params, err := models.ParseLinkGroupDelete(children, links)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	preview, err := h.service.GetLinkGroupDeletePreview(r.Context(), linkGroupID, params.LiftChildren)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, preview)
+}
+
 func (h *linkHandler) linkGroupList(w http.ResponseWriter, r *http.Request) {
 	page, pageSize := request.GetPaginateFromRequest(r)
 	name, _ := request.GetQueryValueFromRequest(r, "name")
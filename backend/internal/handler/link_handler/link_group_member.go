@@ -0,0 +1,174 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) linkGroupMembers(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupMembers"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	members, err := h.service.GetLinkGroupMembers(r.Context(), linkGroupID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, members)
+}
+
+func (h *linkHandler) linkGroupMemberSet(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupMemberSet"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	userID, ok := request.GetIntFromRequest(r, "user_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("участник не найден", op))
+		return
+	}
+
+	memberRequest, err := request.ParseRequestBody[models.LinkGroupMemberSet](r)
+	if err != nil || memberRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := memberRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	if err := h.service.SetLinkGroupMemberRole(r.Context(), linkGroupID, userID, memberRequest); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// linkGroupMemberDelete исключает участника или выводит из группы текущего пользователя
+func (h *linkHandler) linkGroupMemberDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupMemberDelete"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	userID, ok := request.GetIntFromRequest(r, "user_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("участник не найден", op))
+		return
+	}
+
+	if err := h.service.DeleteLinkGroupMember(r.Context(), linkGroupID, userID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *linkHandler) linkGroupInviteCreate(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupInviteCreate"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	inviteRequest, err := request.ParseRequestBody[models.LinkGroupInviteCreate](r)
+	if err != nil || inviteRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := inviteRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	invite, err := h.service.InviteToLinkGroup(r.Context(), linkGroupID, inviteRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, invite)
+}
+
+func (h *linkHandler) linkGroupInvites(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupInvites"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	invites, err := h.service.GetLinkGroupInvites(r.Context(), linkGroupID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, invites)
+}
+
+func (h *linkHandler) linkGroupInviteDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupInviteDelete"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	inviteID, ok := request.GetIntFromRequest(r, "invite_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("приглашение не найдено", op))
+		return
+	}
+
+	if err := h.service.DeleteLinkGroupInvite(r.Context(), linkGroupID, inviteID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// linkGroupInviteAccept принимает приглашение по токену из письма
+func (h *linkHandler) linkGroupInviteAccept(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupInviteAccept"
+
+	token := r.PathValue("token")
+	if token == "" {
+		response.WriteError(w, app_errors.NotFound("приглашение не найдено", op))
+		return
+	}
+
+	linkGroup, err := h.service.AcceptLinkGroupInvite(r.Context(), token)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, linkGroup)
+}
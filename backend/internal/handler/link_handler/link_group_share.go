@@ -0,0 +1,188 @@
+package link_handler
+
+import (
+	"errors"
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"strings"
+)
+
+// SharePasswordHeader заголовок с паролем защищенной публичной страницы для JSON-клиентов
+const SharePasswordHeader = "X-Share-Password"
+
+// maxSharePasswordFormSize ограничение тела формы пароля публичной страницы
+const maxSharePasswordFormSize = 4 << 10
+
+func (h *linkHandler) linkGroupShare(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupShare"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	share, err := h.service.GetLinkGroupShare(r.Context(), linkGroupID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, share)
+}
+
+func (h *linkHandler) linkGroupShareSave(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupShareSave"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	shareRequest, err := request.ParseRequestBody[models.LinkGroupShareSave](r)
+	if err != nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+	// Пустое тело - публикация без пароля и срока
+	if shareRequest == nil {
+		shareRequest = &models.LinkGroupShareSave{}
+	}
+
+	if err := shareRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	share, err := h.service.SaveLinkGroupShare(r.Context(), linkGroupID, shareRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, share)
+}
+
+func (h *linkHandler) linkGroupShareDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupShareDelete"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	if err := h.service.DeleteLinkGroupShare(r.Context(), linkGroupID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// publicLinkGroup публичная страница группы /p/{slug} без авторизации. Отвечает JSON, если клиент
+// просит application/json (пароль - в заголовке X-Share-Password), иначе HTML-страницей с формой пароля
+func (h *linkHandler) publicLinkGroup(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.publicLinkGroup"
+
+	slug := r.PathValue("slug")
+	if slug == "" {
+		response.WriteError(w, app_errors.NotFound("Страница не найдена", op))
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		linkGroup, err := h.service.GetPublicLinkGroup(r.Context(), slug, r.Header.Get(SharePasswordHeader))
+		if err != nil {
+			response.WriteError(w, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		response.WriteSuccess(w, linkGroup)
+		return
+	}
+
+	password := ""
+	if r.Method == http.MethodPost {
+		r.Body = http.MaxBytesReader(w, r.Body, maxSharePasswordFormSize)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+			return
+		}
+		password = r.PostForm.Get("password")
+	}
+
+	linkGroup, err := h.service.GetPublicLinkGroup(r.Context(), slug, password)
+
+	page := struct {
+		Group         *models.PublicLinkGroup
+		WrongPassword bool
+	}{Group: linkGroup}
+
+	status := http.StatusOK
+	if err != nil {
+		var appErr *app_errors.AppError
+		if errors.As(err, &appErr) {
+			status = appErr.Code
+		} else {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	// Без пароля и с неверным паролем показываем форму пароля, остальные ошибки - текстом
+	switch status {
+	case http.StatusOK, http.StatusUnauthorized:
+	case http.StatusForbidden:
+		page.WrongPassword = true
+	case http.StatusNotFound:
+		http.Error(w, "Страница не найдена", http.StatusNotFound)
+		return
+	default:
+		h.logger.Error(err, op, "slug", slug)
+		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Security-Policy", sharePageCSP)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := sharePageTemplate.Execute(w, page); err != nil {
+		h.logger.Error(err, op, "slug", slug)
+	}
+}
+
+// publicLinkFavicon иконка ссылки публичной страницы /p/{slug}/favicons/{link_id} без авторизации
+func (h *linkHandler) publicLinkFavicon(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.publicLinkFavicon"
+
+	linkID, ok := request.GetIntFromRequest(r, "link_id")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	path, err := h.service.GetPublicLinkFavicon(r.Context(), r.PathValue("slug"), linkID)
+	if err != nil {
+		// Защищенная паролем публикация иконки не раздает, как и отсутствующая
+		var appErr *app_errors.AppError
+		if errors.As(err, &appErr) && (appErr.Code == http.StatusNotFound || appErr.Code == http.StatusUnauthorized) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.Error(err, op, "link_id", linkID)
+		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		return
+	}
+
+	// Иконка может быть SVG: открытая напрямую, она не должна исполнять скрипты
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	http.ServeFile(w, r, path)
+}
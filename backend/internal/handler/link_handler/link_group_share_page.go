@@ -0,0 +1,83 @@
+package link_handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+)
+
+// sharePageStyle стили публичной страницы. Встроены в страницу, CSP разрешает их только по хешу
+const sharePageStyle = `
+body{margin:0;font-family:system-ui,-apple-system,"Segoe UI",Roboto,sans-serif;color:#1f2328;background:#f6f8fa}
+main{max-width:760px;margin:0 auto;padding:32px 16px}
+h1{margin:0 0 8px;font-size:28px}
+h2{margin:24px 0 8px;font-size:20px}
+section section{margin-left:16px}
+.description{margin:0 0 16px;color:#59636e}
+ul{list-style:none;margin:0;padding:0}
+li{display:flex;gap:12px;align-items:flex-start;padding:12px;margin-bottom:8px;background:#fff;border:1px solid #d1d9e0;border-radius:8px}
+li img{width:16px;height:16px;margin-top:3px;flex:none}
+li a{color:#0969da;font-weight:600;text-decoration:none;word-break:break-word}
+li p{margin:4px 0 0;color:#59636e;font-size:14px}
+form{display:flex;gap:8px;margin-top:16px}
+input{flex:1;padding:8px;border:1px solid #d1d9e0;border-radius:6px;font-size:16px}
+button{padding:8px 16px;border:0;border-radius:6px;background:#1f883d;color:#fff;font-size:16px}
+.error{color:#d1242f}
+`
+
+// sharePageCSP политика страницы: как у API, плюс встроенные стили по хешу. Иконки раздаются
+// с того же сервера, img-src задан явно, чтобы не зависеть от default-src
+var sharePageCSP = func() string {
+	sum := sha256.Sum256([]byte(sharePageStyle))
+	return "default-src 'self'; style-src 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'; img-src 'self'"
+}()
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Group}}{{.Group.Name}}{{else}}Страница защищена паролем{{end}}</title>
+<style>` + sharePageStyle + `</style>
+</head>
+<body>
+<main>
+{{- with .Group}}
+<h1>{{.Name}}</h1>
+{{template "content" .}}
+{{- else}}
+<h1>Страница защищена паролем</h1>
+{{- if .WrongPassword}}
+<p class="error">Неверный пароль</p>
+{{- end}}
+<form method="post">
+<input type="password" name="password" placeholder="Пароль" autofocus required>
+<button type="submit">Открыть</button>
+</form>
+{{- end}}
+</main>
+</body>
+</html>
+{{define "content"}}
+{{- if .Description}}
+<p class="description">{{.Description}}</p>
+{{- end}}
+<ul>
+{{- range .Links}}
+<li>
+{{- if .FaviconURL}}<img src="{{.FaviconURL}}" alt="">{{end}}
+<div>
+<a href="{{.URL}}" rel="noopener noreferrer nofollow">{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</a>
+{{- if .Description}}<p>{{.Description}}</p>{{end}}
+</div>
+</li>
+{{- end}}
+</ul>
+{{- range .Groups}}
+<section>
+<h2>{{.Name}}</h2>
+{{template "content" .}}
+</section>
+{{- end}}
+{{end}}`))
@@ -0,0 +1,43 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) linkGroupTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.GetLinkGroupTree(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, tree)
+}
+
+func (h *linkHandler) linkGroupParentSet(w http.ResponseWriter, r *http.Request) {
+	op := "link_handler.linkGroupParentSet"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	parentSet, err := request.ParseRequestBody[models.LinkGroupParentSet](r)
+	if err != nil || parentSet == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	linkGroup, err := h.service.SetLinkGroupParent(r.Context(), linkGroupID, parentSet)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, linkGroup)
+}
@@ -0,0 +1,69 @@
+package link_handler
+
+import (
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) linkHistory(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkHistory"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	entries, err := h.service.GetLinkHistory(r.Context(), linkID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, entries)
+}
+
+// linkRevert возвращает ссылку к версии из истории
+func (h *linkHandler) linkRevert(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkRevert"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	historyID, ok := request.GetIntFromRequest(r, "history_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("версия ссылки не найдена", op))
+		return
+	}
+
+	link, err := h.service.RevertLink(r.Context(), linkID, int64(historyID))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, link)
+}
+
+func (h *linkHandler) linkGroupHistory(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupHistory"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("группа ссылок не найдена", op))
+		return
+	}
+
+	entries, err := h.service.GetLinkGroupHistory(r.Context(), linkGroupID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, entries)
+}
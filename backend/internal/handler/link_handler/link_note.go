@@ -0,0 +1,191 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+// linkNotes заметки к ссылке, ?render=html - вместе с HTML
+func (h *linkHandler) linkNotes(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkNotes"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	render, _ := request.GetQueryValueFromRequest(r, "render")
+	if render != "" && render != "html" {
+		response.WriteError(w, app_errors.BadRequest("неверный параметр render", op))
+		return
+	}
+
+	notes, err := h.service.GetLinkNotes(r.Context(), linkID, render == "html")
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, notes)
+}
+
+func (h *linkHandler) linkNoteCreate(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkNoteCreate"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	noteSave, err := request.ParseRequestBody[models.LinkNoteSave](r)
+	if err != nil || noteSave == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := noteSave.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	note, err := h.service.CreateLinkNote(r.Context(), linkID, noteSave)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, note)
+}
+
+func (h *linkHandler) linkNoteUpdate(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkNoteUpdate"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	noteID, ok := request.GetIntFromRequest(r, "note_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("заметка не найдена", op))
+		return
+	}
+
+	noteSave, err := request.ParseRequestBody[models.LinkNoteSave](r)
+	if err != nil || noteSave == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := noteSave.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	note, err := h.service.UpdateLinkNote(r.Context(), linkID, noteID, noteSave)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, note)
+}
+
+func (h *linkHandler) linkNoteDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkNoteDelete"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	noteID, ok := request.GetIntFromRequest(r, "note_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("заметка не найдена", op))
+		return
+	}
+
+	if err := h.service.DeleteLinkNote(r.Context(), linkID, noteID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *linkHandler) linkHighlights(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkHighlights"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	highlights, err := h.service.GetLinkHighlights(r.Context(), linkID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, highlights)
+}
+
+func (h *linkHandler) linkHighlightCreate(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkHighlightCreate"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	highlightCreate, err := request.ParseRequestBody[models.LinkHighlightCreate](r)
+	if err != nil || highlightCreate == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := highlightCreate.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	highlight, err := h.service.CreateLinkHighlight(r.Context(), linkID, highlightCreate)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, highlight)
+}
+
+func (h *linkHandler) linkHighlightDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkHighlightDelete"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	highlightID, ok := request.GetIntFromRequest(r, "highlight_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("цитата не найдена", op))
+		return
+	}
+
+	if err := h.service.DeleteLinkHighlight(r.Context(), linkID, highlightID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
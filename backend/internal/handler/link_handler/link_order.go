@@ -0,0 +1,59 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) linkGroupsOrder(w http.ResponseWriter, r *http.Request) {
+	op := "link_handler.linkGroupsOrder"
+
+	order, err := request.ParseRequestBody[models.PositionOrder](r)
+	if err != nil || order == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := order.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := h.service.SetLinkGroupsOrder(r.Context(), order); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *linkHandler) linksOrder(w http.ResponseWriter, r *http.Request) {
+	op := "link_handler.linksOrder"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	order, err := request.ParseRequestBody[models.PositionOrder](r)
+	if err != nil || order == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := order.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := h.service.SetLinksOrder(r.Context(), linkGroupID, order); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
@@ -0,0 +1,83 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+// linkRead отмечает ссылку прочитанной (PUT) или возвращает в список чтения (DELETE)
+func (h *linkHandler) linkRead(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkRead"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	state := models.LinkStateRead
+	if r.Method == http.MethodDelete {
+		state = models.LinkStateUnread
+	}
+
+	link, err := h.service.SetLinkReadState(r.Context(), linkID, state)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, link)
+}
+
+func (h *linkHandler) linkReminderSet(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkReminderSet"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	reminderSet, err := request.ParseRequestBody[models.LinkReminderSet](r)
+	if err != nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+	if reminderSet == nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+
+	if err := reminderSet.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	link, err := h.service.SetLinkReminder(r.Context(), linkID, reminderSet)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, link)
+}
+
+func (h *linkHandler) linkReminderDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkReminderDelete"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	if err := h.service.DeleteLinkReminder(r.Context(), linkID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
@@ -0,0 +1,296 @@
+package link_handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxSaveFormSize ограничение тела формы сохранения и Web Share Target (файлы не принимаются)
+const maxSaveFormSize = 64 << 10
+
+// shareTextURL первый адрес в тексте: приложения часто передают ссылку в text, а не в url
+var shareTextURL = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// savePageStyle стили страницы сохранения, CSP разрешает их только по хешу
+const savePageStyle = `
+body{margin:0;font-family:system-ui,-apple-system,"Segoe UI",Roboto,sans-serif;color:#1f2328;background:#f6f8fa}
+main{max-width:480px;margin:0 auto;padding:16px}
+h1{margin:0 0 12px;font-size:20px}
+label{display:block;margin:12px 0 4px;font-size:14px;color:#59636e}
+input,select{box-sizing:border-box;width:100%;padding:8px;border:1px solid #d1d9e0;border-radius:6px;font-size:16px;background:#fff}
+fieldset{margin:12px 0 0;padding:8px;border:1px solid #d1d9e0;border-radius:6px}
+fieldset label{display:inline-flex;gap:4px;align-items:center;margin:4px 12px 4px 0;color:#1f2328}
+fieldset input{width:auto}
+button{margin-top:16px;padding:8px 16px;border:0;border-radius:6px;background:#1f883d;color:#fff;font-size:16px}
+a{color:#0969da;word-break:break-word}
+.error{color:#d1242f}
+`
+
+// savePageCSP без скриптов, форма отправляется только на свой адрес
+var savePageCSP = func() string {
+	sum := sha256.Sum256([]byte(savePageStyle))
+	return "default-src 'self'; style-src 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'; form-action 'self'"
+}()
+
+var savePageTemplate = template.Must(template.New("save").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<meta name="referrer" content="no-referrer">
+<title>Сохранить ссылку</title>
+<style>` + savePageStyle + `</style>
+</head>
+<body>
+<main>
+{{- if .LoginRequired}}
+<h1>Нужно войти</h1>
+<p>Войдите в приложение в этом браузере или добавьте в букмарклет персональный токен доступа.</p>
+{{- else if .Saved}}
+<h1>Ссылка сохранена</h1>
+<p><a href="{{.Saved.URL}}" rel="noopener noreferrer nofollow">{{if .Saved.Title}}{{.Saved.Title}}{{else}}{{.Saved.URL}}{{end}}</a></p>
+<p>Окно можно закрыть.</p>
+{{- else}}
+<h1>Сохранить ссылку</h1>
+{{- if .Error}}
+<p class="error">{{.Error}}</p>
+{{- end}}
+<form method="post" action="{{.Action}}">
+<label for="url">Адрес</label>
+<input type="url" id="url" name="url" value="{{.URL}}" required>
+<label for="title">Название</label>
+<input type="text" id="title" name="title" value="{{.Title}}">
+{{- if .Groups}}
+<label for="link_group_id">Группа</label>
+<select id="link_group_id" name="link_group_id">
+<option value="">Без группы</option>
+{{- range .Groups}}
+<option value="{{.ID}}"{{if .Selected}} selected{{end}}>{{.Name}}</option>
+{{- end}}
+</select>
+{{- end}}
+{{- if .Tags}}
+<fieldset>
+<legend>Теги</legend>
+{{- range .Tags}}
+<label><input type="checkbox" name="tag_ids" value="{{.ID}}"{{if .Selected}} checked{{end}}>{{.Name}}</label>
+{{- end}}
+</fieldset>
+{{- end}}
+<button type="submit">Сохранить</button>
+</form>
+{{- end}}
+</main>
+</body>
+</html>`))
+
+// saveOption группа или тег в списке выбора
+type saveOption struct {
+	ID       int
+	Name     string
+	Selected bool
+}
+
+type savePage struct {
+	LoginRequired bool
+	Saved         *models.Link
+	Error         string
+	Action        string
+	URL           string
+	Title         string
+	Groups        []saveOption
+	Tags          []saveOption
+}
+
+// linkSaveForm форма сохранения ссылки для букмарклета: GET /save?url=&title=
+func (h *linkHandler) linkSaveForm(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	h.writeSavePage(w, r, http.StatusOK, &savePage{
+		URL:   strings.TrimSpace(query.Get("url")),
+		Title: strings.TrimSpace(query.Get("title")),
+	}, nil, nil)
+}
+
+// linkShare Web Share Target: принимает поля title, text и url формы и показывает форму сохранения
+func (h *linkHandler) linkShare(w http.ResponseWriter, r *http.Request) {
+	if !h.parseSaveForm(w, r) {
+		return
+	}
+
+	title := strings.TrimSpace(r.PostForm.Get("title"))
+	text := strings.TrimSpace(r.PostForm.Get("text"))
+	link := strings.TrimSpace(r.PostForm.Get("url"))
+
+	if link == "" {
+		link = shareTextURL.FindString(text)
+		text = strings.TrimSpace(strings.Replace(text, link, "", 1))
+	}
+	if title == "" {
+		title = text
+	}
+
+	h.writeSavePage(w, r, http.StatusOK, &savePage{URL: link, Title: title}, nil, nil)
+}
+
+// linkSave сохраняет ссылку из формы. Форма с cookie защищена от подделки SameSite=Lax:
+// с чужого сайта POST приходит без cookie
+func (h *linkHandler) linkSave(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkSave"
+
+	if !h.parseSaveForm(w, r) {
+		return
+	}
+
+	linkCreate := &models.LinkCreate{
+		URL:   strings.TrimSpace(r.PostForm.Get("url")),
+		Title: strings.TrimSpace(r.PostForm.Get("title")),
+	}
+	page := &savePage{URL: linkCreate.URL, Title: linkCreate.Title}
+
+	if value := r.PostForm.Get("link_group_id"); value != "" {
+		linkGroupID, err := strconv.Atoi(value)
+		if err != nil {
+			h.writeSavePage(w, r, http.StatusBadRequest, page, nil, app_errors.BadRequest("Неверная группа", op))
+			return
+		}
+		linkCreate.LinkGroupID = &linkGroupID
+	}
+	for _, value := range r.PostForm["tag_ids"] {
+		tagID, err := strconv.Atoi(value)
+		if err != nil {
+			h.writeSavePage(w, r, http.StatusBadRequest, page, linkCreate, app_errors.BadRequest("Неверный тег", op))
+			return
+		}
+		linkCreate.TagIDs = append(linkCreate.TagIDs, tagID)
+	}
+
+	if err := linkCreate.Validate(); err != nil {
+		h.writeSavePage(w, r, http.StatusBadRequest, page, linkCreate, err)
+		return
+	}
+
+	link, err := h.service.CreateLink(r.Context(), linkCreate)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var appErr *app_errors.AppError
+		if errors.As(err, &appErr) {
+			status = appErr.Code
+		}
+		h.writeSavePage(w, r, status, page, linkCreate, err)
+		return
+	}
+
+	page.Saved = link
+	h.writeSavePage(w, r, http.StatusOK, page, nil, nil)
+}
+
+// parseSaveForm разбирает urlencoded или multipart форму, при ошибке отвечает 400
+func (h *linkHandler) parseSaveForm(w http.ResponseWriter, r *http.Request) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSaveFormSize)
+	if err := r.ParseMultipartForm(maxSaveFormSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeSavePage отвечает страницей сохранения: без пользователя - просьбой войти, для формы подгружает
+// группы и теги активного пространства. selected - выбранные в отправленной форме группа и теги
+func (h *linkHandler) writeSavePage(w http.ResponseWriter, r *http.Request, status int, page *savePage, selected *models.LinkCreate, pageErr error) {
+	op := "linkHandler.writeSavePage"
+
+	if pageErr != nil {
+		var appErr *app_errors.AppError
+		switch {
+		case errors.As(pageErr, &appErr) && appErr.Code == http.StatusUnauthorized:
+			page.LoginRequired = true
+		case errors.As(pageErr, &appErr) && appErr.Code < http.StatusInternalServerError:
+			page.Error = appErr.Message
+		default:
+			h.logger.Error(pageErr, op)
+			page.Error = "Не удалось сохранить ссылку, попробуйте позже"
+		}
+	}
+
+	if !page.LoginRequired && page.Saved == nil {
+		if err := h.loadSaveOptions(r, page, selected); err != nil {
+			var appErr *app_errors.AppError
+			if !errors.As(err, &appErr) || appErr.Code != http.StatusUnauthorized {
+				h.logger.Error(err, op)
+				http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+				return
+			}
+			page.LoginRequired = true
+		}
+	}
+	if page.LoginRequired {
+		status = http.StatusUnauthorized
+	}
+
+	// Форма отправляется туда же, токен и пространство из адреса букмарклета сохраняются
+	action := url.Values{}
+	for _, key := range []string{middleware.APITokenQuery, middleware.WorkspaceQuery} {
+		if value := r.URL.Query().Get(key); value != "" {
+			action.Set(key, value)
+		}
+	}
+	page.Action = "/save"
+	if len(action) > 0 {
+		page.Action += "?" + action.Encode()
+	}
+
+	w.Header().Set("Content-Security-Policy", savePageCSP)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := savePageTemplate.Execute(w, page); err != nil {
+		h.logger.Error(err, op)
+	}
+}
+
+// loadSaveOptions группы (с отступом по вложенности) и теги активного пространства для формы
+func (h *linkHandler) loadSaveOptions(r *http.Request, page *savePage, selected *models.LinkCreate) error {
+	tree, err := h.service.GetLinkGroupTree(r.Context())
+	if err != nil {
+		return err
+	}
+	tags, err := h.service.GetTags(r.Context())
+	if err != nil {
+		return err
+	}
+
+	var addGroups func(nodes []*models.LinkGroupNode, depth int)
+	addGroups = func(nodes []*models.LinkGroupNode, depth int) {
+		for _, node := range nodes {
+			page.Groups = append(page.Groups, saveOption{
+				ID:       node.ID,
+				Name:     strings.Repeat("  ", depth) + node.Name,
+				Selected: selected != nil && selected.LinkGroupID != nil && *selected.LinkGroupID == node.ID,
+			})
+			addGroups(node.Children, depth+1)
+		}
+	}
+	addGroups(tree, 0)
+
+	for _, tag := range tags {
+		page.Tags = append(page.Tags, saveOption{
+			ID:       tag.ID,
+			Name:     tag.Name,
+			Selected: selected != nil && slices.Contains(selected.TagIDs, tag.ID),
+		})
+	}
+	return nil
+}
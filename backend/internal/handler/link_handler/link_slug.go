@@ -0,0 +1,83 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) linkSlugSet(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkSlugSet"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	slugSet, err := request.ParseRequestBody[models.LinkSlugSet](r)
+	if err != nil {
+		response.WriteError(w, app_errors.BadRequest("неверный формат запроса", op))
+		return
+	}
+	if slugSet == nil {
+		slugSet = &models.LinkSlugSet{}
+	}
+
+	if err := slugSet.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	link, err := h.service.SetLinkSlug(r.Context(), linkID, slugSet)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, link)
+}
+
+func (h *linkHandler) linkSlugDelete(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkSlugDelete"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	if err := h.service.DeleteLinkSlug(r.Context(), linkID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *linkHandler) linkSlugStats(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkSlugStats"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	periodStr, _ := request.GetQueryValueFromRequest(r, "period")
+	period, err := models.ParseVisitPeriod(periodStr, models.VisitPeriodMonth)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	stats, err := h.service.GetLinkSlugStats(r.Context(), linkID, period)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, stats)
+}
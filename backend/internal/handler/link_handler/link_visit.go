@@ -0,0 +1,143 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+// linkVisitedPlus при каждом посещении ссылки двинем счетчик
+func (h *linkHandler) linkVisitedPlus(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkVisitedPlus"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.BadRequest("Невалидный url", op))
+		return
+	}
+
+	if err := h.service.LinkVisitedPlus(r.Context(), linkID, r.Referer(), r.UserAgent()); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *linkHandler) getLinkTopVisited(w http.ResponseWriter, r *http.Request) {
+	periodStr, _ := request.GetQueryValueFromRequest(r, "period")
+	period, err := models.ParseVisitPeriod(periodStr, models.VisitPeriodAll)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	links, err := h.service.GetLinksTopVisited(r.Context(), period)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if links == nil {
+		response.WriteSuccess(w, []models.LinkTopVisited{})
+		return
+	}
+
+	response.WriteSuccess(w, links)
+}
+
+func (h *linkHandler) getLinkRecentVisited(w http.ResponseWriter, r *http.Request) {
+	links, err := h.service.GetLinksRecentVisited(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if links == nil {
+		response.WriteSuccess(w, []models.LinkRecentVisited{})
+		return
+	}
+
+	response.WriteSuccess(w, links)
+}
+
+func (h *linkHandler) linkVisits(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkVisits"
+
+	linkID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("ссылка не найдена", op))
+		return
+	}
+
+	periodStr, _ := request.GetQueryValueFromRequest(r, "period")
+	period, err := models.ParseVisitPeriod(periodStr, models.VisitPeriodMonth)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	visits, err := h.service.GetLinkVisitsPerDay(r.Context(), linkID, period)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if visits == nil {
+		response.WriteSuccess(w, []models.VisitsPerDay{})
+		return
+	}
+
+	response.WriteSuccess(w, visits)
+}
+
+func (h *linkHandler) linkGroupVisits(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.linkGroupVisits"
+
+	linkGroupID, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("Группа не найдена", op))
+		return
+	}
+
+	periodStr, _ := request.GetQueryValueFromRequest(r, "period")
+	period, err := models.ParseVisitPeriod(periodStr, models.VisitPeriodMonth)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	visits, err := h.service.GetLinkGroupVisitsPerDay(r.Context(), linkGroupID, period)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if visits == nil {
+		response.WriteSuccess(w, []models.VisitsPerDay{})
+		return
+	}
+
+	response.WriteSuccess(w, visits)
+}
+
+// linkLauncher быстрый поиск ссылок по началу заголовка или домена с ранжированием по frecency
+func (h *linkHandler) linkLauncher(w http.ResponseWriter, r *http.Request) {
+	prefix, _ := request.GetQueryValueFromRequest(r, "q")
+	limit, _ := request.GetQueryIntValueFromRequest(r, "limit")
+
+	links, err := h.service.GetLinksForLauncher(r.Context(), prefix, limit)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if links == nil {
+		response.WriteSuccess(w, []models.Link{})
+		return
+	}
+
+	response.WriteSuccess(w, links)
+}
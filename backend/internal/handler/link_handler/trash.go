@@ -0,0 +1,51 @@
+package link_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+)
+
+func (h *linkHandler) trashList(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := request.GetPaginateFromRequest(r)
+	typeStr, _ := request.GetQueryValueFromRequest(r, "type")
+
+	itemType, err := models.ParseTrashItemType(typeStr, true)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	trash, err := h.service.GetTrash(r.Context(), itemType, page, pageSize)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, trash)
+}
+
+func (h *linkHandler) trashRestore(w http.ResponseWriter, r *http.Request) {
+	op := "linkHandler.trashRestore"
+
+	itemType, err := models.ParseTrashItemType(r.PathValue("type"), false)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("элемент не найден", op))
+		return
+	}
+
+	if err := h.service.RestoreFromTrash(r.Context(), itemType, id); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
@@ -0,0 +1,189 @@
+package webhook_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/internal/service/webhook_service"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httprate"
+)
+
+type webhookHandler struct {
+	service webhook_service.WebhookService
+	logger  logger.AppLogger
+}
+
+func New(r *chi.Mux, service webhook_service.WebhookService, logger logger.AppLogger) {
+	if r == nil {
+		panic("webhook_handler.New: получен nil router")
+	}
+
+	if service == nil {
+		panic("webhook_handler.New: получен nil service")
+	}
+
+	h := &webhookHandler{
+		service: service,
+		logger:  logger,
+	}
+
+	r.Route("/api/v1/webhooks", func(r chi.Router) {
+		r.Use(httprate.LimitByIP(5, 1*time.Second))
+		r.Get("/", h.webhookList)
+		r.Post("/", h.webhookCreate)
+		r.Put("/{id}", h.webhookUpdate)
+		r.Delete("/{id}", h.webhookDelete)
+		r.Post("/{id}/ping", h.webhookPing)
+		// WebhookDelivery
+		r.Get("/{id}/deliveries", h.webhookDeliveries)
+		r.Post("/{id}/deliveries/{delivery_id}/redeliver", h.webhookRedeliver)
+	})
+}
+
+func (h *webhookHandler) webhookList(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.GetWebhooks(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, webhooks)
+}
+
+func (h *webhookHandler) webhookCreate(w http.ResponseWriter, r *http.Request) {
+	op := "webhookHandler.webhookCreate"
+
+	webhookRequest, err := request.ParseRequestBody[models.WebhookSave](r)
+	if err != nil || webhookRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := webhookRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(r.Context(), webhookRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, webhook)
+}
+
+func (h *webhookHandler) webhookUpdate(w http.ResponseWriter, r *http.Request) {
+	op := "webhookHandler.webhookUpdate"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("webhook не найден", op))
+		return
+	}
+
+	webhookRequest, err := request.ParseRequestBody[models.WebhookSave](r)
+	if err != nil || webhookRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := webhookRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(r.Context(), id, webhookRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, webhook)
+}
+
+func (h *webhookHandler) webhookDelete(w http.ResponseWriter, r *http.Request) {
+	op := "webhookHandler.webhookDelete"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("webhook не найден", op))
+		return
+	}
+
+	if err := h.service.DeleteWebhook(r.Context(), id); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// webhookPing отправляет проверочное событие и возвращает результат доставки
+func (h *webhookHandler) webhookPing(w http.ResponseWriter, r *http.Request) {
+	op := "webhookHandler.webhookPing"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("webhook не найден", op))
+		return
+	}
+
+	delivery, err := h.service.PingWebhook(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, delivery)
+}
+
+func (h *webhookHandler) webhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	op := "webhookHandler.webhookDeliveries"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("webhook не найден", op))
+		return
+	}
+
+	page, pageSize := request.GetPaginateFromRequest(r)
+
+	deliveries, err := h.service.GetWebhookDeliveries(r.Context(), id, page, pageSize)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, deliveries)
+}
+
+func (h *webhookHandler) webhookRedeliver(w http.ResponseWriter, r *http.Request) {
+	op := "webhookHandler.webhookRedeliver"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("webhook не найден", op))
+		return
+	}
+
+	deliveryID, ok := request.GetIntFromRequest(r, "delivery_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("доставка не найдена", op))
+		return
+	}
+
+	delivery, err := h.service.RedeliverWebhookDelivery(r.Context(), id, int64(deliveryID))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, delivery)
+}
@@ -0,0 +1,261 @@
+package workspace_handler
+
+import (
+	"link-storage/internal/models"
+	"link-storage/internal/service/workspace_service"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/request"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httprate"
+)
+
+type workspaceHandler struct {
+	service workspace_service.WorkspaceService
+	logger  logger.AppLogger
+}
+
+func New(r *chi.Mux, service workspace_service.WorkspaceService, logger logger.AppLogger) {
+	if r == nil {
+		panic("workspace_handler.New: получен nil router")
+	}
+
+	if service == nil {
+		panic("workspace_handler.New: получен nil service")
+	}
+
+	h := &workspaceHandler{
+		service: service,
+		logger:  logger,
+	}
+
+	r.Route("/api/v1/workspaces", func(r chi.Router) {
+		r.Use(httprate.LimitByIP(5, 1*time.Second))
+		r.Get("/", h.workspaceList)
+		r.Post("/", h.workspaceCreate)
+		r.Put("/{id}", h.workspaceUpdate)
+		r.Delete("/{id}", h.workspaceDelete)
+		// WorkspaceMember
+		r.Get("/{id}/members", h.workspaceMembers)
+		r.Post("/{id}/members", h.workspaceMemberAdd)
+		r.Put("/{id}/members/{user_id}", h.workspaceMemberSet)
+		r.Delete("/{id}/members/{user_id}", h.workspaceMemberDelete)
+		r.Post("/{id}/transfer", h.workspaceTransfer)
+	})
+}
+
+func (h *workspaceHandler) workspaceList(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := h.service.GetWorkspaces(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, workspaces)
+}
+
+func (h *workspaceHandler) workspaceCreate(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceCreate"
+
+	workspaceRequest, err := request.ParseRequestBody[models.WorkspaceSave](r)
+	if err != nil || workspaceRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := workspaceRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	workspace, err := h.service.CreateWorkspace(r.Context(), workspaceRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, workspace)
+}
+
+func (h *workspaceHandler) workspaceUpdate(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceUpdate"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("пространство не найдено", op))
+		return
+	}
+
+	workspaceRequest, err := request.ParseRequestBody[models.WorkspaceSave](r)
+	if err != nil || workspaceRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := workspaceRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	workspace, err := h.service.UpdateWorkspace(r.Context(), id, workspaceRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, workspace)
+}
+
+func (h *workspaceHandler) workspaceDelete(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceDelete"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("пространство не найдено", op))
+		return
+	}
+
+	if err := h.service.DeleteWorkspace(r.Context(), id); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *workspaceHandler) workspaceMembers(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceMembers"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("пространство не найдено", op))
+		return
+	}
+
+	members, err := h.service.GetWorkspaceMembers(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, members)
+}
+
+func (h *workspaceHandler) workspaceMemberAdd(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceMemberAdd"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("пространство не найдено", op))
+		return
+	}
+
+	memberRequest, err := request.ParseRequestBody[models.WorkspaceMemberAdd](r)
+	if err != nil || memberRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := memberRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	member, err := h.service.AddWorkspaceMember(r.Context(), id, memberRequest)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, member)
+}
+
+func (h *workspaceHandler) workspaceMemberSet(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceMemberSet"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("пространство не найдено", op))
+		return
+	}
+
+	userID, ok := request.GetIntFromRequest(r, "user_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("участник не найден", op))
+		return
+	}
+
+	memberRequest, err := request.ParseRequestBody[models.WorkspaceMemberSet](r)
+	if err != nil || memberRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := memberRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	if err := h.service.SetWorkspaceMemberRole(r.Context(), id, userID, memberRequest); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// workspaceMemberDelete исключает участника или выводит из пространства текущего пользователя
+func (h *workspaceHandler) workspaceMemberDelete(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceMemberDelete"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("пространство не найдено", op))
+		return
+	}
+
+	userID, ok := request.GetIntFromRequest(r, "user_id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("участник не найден", op))
+		return
+	}
+
+	if err := h.service.DeleteWorkspaceMember(r.Context(), id, userID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *workspaceHandler) workspaceTransfer(w http.ResponseWriter, r *http.Request) {
+	op := "workspaceHandler.workspaceTransfer"
+
+	id, ok := request.GetIntFromRequest(r, "id")
+	if !ok {
+		response.WriteError(w, app_errors.NotFound("пространство не найдено", op))
+		return
+	}
+
+	transferRequest, err := request.ParseRequestBody[models.WorkspaceOwnerTransfer](r)
+	if err != nil || transferRequest == nil {
+		response.WriteError(w, app_errors.BadRequest("Неверный формат запроса", op))
+		return
+	}
+
+	if err := transferRequest.Validate(); err != nil {
+		response.WriteError(w, app_errors.BadRequestWithError(err, "", op))
+		return
+	}
+
+	if err := h.service.TransferWorkspaceOwnership(r.Context(), id, transferRequest); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
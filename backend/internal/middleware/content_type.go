@@ -7,8 +7,10 @@ import (
 
 func RequireJSONContentType(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Проверяем только методы, которые могут иметь тело
-		if r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH" {
+		// Проверяем только методы, которые могут иметь тело. Форма пароля публичной страницы /p/
+		// и страницы сохранения ссылки и отписки - обычные HTML-формы, /inbound/ принимает письма RFC 822
+		if (r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH") && !strings.HasPrefix(r.URL.Path, "/p/") &&
+			!strings.HasPrefix(r.URL.Path, "/inbound/") && !strings.HasPrefix(r.URL.Path, DigestUnsubscribePath) && !isSavePath(r.URL.Path) {
 			contentType := r.Header.Get("Content-Type")
 
 			if !strings.HasPrefix(contentType, "application/json") {
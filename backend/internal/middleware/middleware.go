@@ -8,6 +8,7 @@ import (
 	"link-storage/pkg/response"
 	"link-storage/pkg/types/app_errors"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -15,7 +16,37 @@ type contextKey string
 
 const UserContextKey = contextKey("user")
 
-func AuthMiddleware(authService auth_service.AuthService, logger logger.AppLogger) func(http.Handler) http.Handler {
+// AccessTokenCookie имя cookie с access token (для переходов по ссылкам без заголовка Authorization)
+const AccessTokenCookie = "access_token"
+
+// WorkspaceHeader заголовок с ID активного рабочего пространства, без него - личное пространство
+const WorkspaceHeader = "X-Workspace-ID"
+
+// WorkspaceQuery параметр с ID пространства для потока событий: EventSource не передает заголовки
+const WorkspaceQuery = "workspace_id"
+
+// EventsPath поток Server-Sent Events
+const EventsPath = "/api/v1/events"
+
+// SavePath и SharePath сохранение ссылки из букмарклета и Web Share Target. Это HTML-страницы,
+// которые браузер открывает без заголовков: токен и пространство могут прийти в параметрах запроса
+const (
+	SavePath  = "/save"
+	SharePath = "/share"
+)
+
+// DigestUnsubscribePath отписка от дайджеста по ссылке из письма: HTML-форма без входа в приложение
+const DigestUnsubscribePath = "/api/v1/digest/unsubscribe/"
+
+// APITokenQuery параметр с персональным токеном для букмарклета
+const APITokenQuery = "api_token"
+
+// WorkspaceResolver пространство, в котором состоит пользователь, с его ролью. workspaceID 0 - личное пространство
+type WorkspaceResolver interface {
+	GetActiveWorkspace(ctx context.Context, userID, workspaceID int) (*models.Workspace, error)
+}
+
+func AuthMiddleware(authService auth_service.AuthService, workspaces WorkspaceResolver, logger logger.AppLogger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			op := "middleware.AuthMiddleware"
@@ -31,21 +62,38 @@ func AuthMiddleware(authService auth_service.AuthService, logger logger.AppLogge
 				return
 			}
 
-			var token string
+			// Для путей с необязательной авторизацией пропускаем запрос без пользователя,
+			// проверку доступа (например, по подписанному токену) выполнит обработчик
+			optional := isOptionalAuthPath(r.URL.Path)
 
-			// Проверка заголовка
-			authHeader := r.Header.Get("Authorization")
-			if strings.HasPrefix(authHeader, "Bearer ") {
-				token = strings.TrimPrefix(authHeader, "Bearer ")
+			// Cookie принимается только там, где браузер не может передать заголовок: переходы, страницы
+			// сохранения и поток событий (EventSource). Остальной JSON API работает только с заголовком
+			token := getTokenFromRequest(r, optional || r.URL.Path == EventsPath)
+			// Букмарклет передает персональный токен в адресе, он важнее cookie
+			if apiToken := r.URL.Query().Get(APITokenQuery); apiToken != "" && isSavePath(r.URL.Path) {
+				token = apiToken
 			}
-
 			if token == "" {
+				if optional {
+					next.ServeHTTP(w, r)
+					return
+				}
 				response.WriteError(w, app_errors.Unauthorized(op))
 				return
 			}
 
-			currentUser, err := authService.VerifyJwt(token)
+			var currentUser *models.CurrentUser
+			var err error
+			if strings.HasPrefix(token, models.APITokenPrefix) {
+				currentUser, err = authService.VerifyAPIToken(r.Context(), token)
+			} else {
+				currentUser, err = authService.VerifyJwt(token)
+			}
 			if err != nil || currentUser == nil {
+				if optional {
+					next.ServeHTTP(w, r)
+					return
+				}
 				logger.Warn("Доступ с невалидным токеном", op, "token", token)
 				response.WriteError(w, app_errors.Unauthorized(op))
 				return
@@ -61,7 +109,29 @@ func AuthMiddleware(authService auth_service.AuthService, logger logger.AppLogge
 				return
 			}
 
+			workspaceID := 0
+			header := r.Header.Get(WorkspaceHeader)
+			if header == "" && (r.URL.Path == EventsPath || isSavePath(r.URL.Path)) {
+				header = r.URL.Query().Get(WorkspaceQuery)
+			}
+			if header != "" {
+				workspaceID, err = strconv.Atoi(header)
+				if err != nil || workspaceID <= 0 {
+					response.WriteError(w, app_errors.BadRequest("Неверный заголовок "+WorkspaceHeader, op))
+					return
+				}
+			}
+
+			workspace, err := workspaces.GetActiveWorkspace(r.Context(), currentUser.ID, workspaceID)
+			if err != nil {
+				response.WriteError(w, err)
+				return
+			}
+			currentUser.WorkspaceID = workspace.ID
+			currentUser.WorkspaceRole = workspace.Role
+
 			ctx := context.WithValue(r.Context(), UserContextKey, currentUser)
+			ctx = models.WithHistoryActor(ctx, currentUser.ID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -69,8 +139,11 @@ func AuthMiddleware(authService auth_service.AuthService, logger logger.AppLogge
 
 // Вспомогательные функции остаются без изменений
 func isPublicPath(path string) bool {
-	if strings.HasPrefix(path, "/api/v1/auth/activate") {
-		return true
+	publicPrefix := []string{"/api/v1/auth/activate", "/p/", "/feeds/", "/inbound/", DigestUnsubscribePath}
+	for _, p := range publicPrefix {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
 	}
 
 	publicPaths := []string{
@@ -86,6 +159,45 @@ func isPublicPath(path string) bool {
 	return false
 }
 
+// isOptionalAuthPath пути, доступные и без авторизации
+func isOptionalAuthPath(path string) bool {
+	// Страницы сохранения сами предлагают войти
+	if isSavePath(path) {
+		return true
+	}
+
+	optionalPrefix := []string{"/go/", "/s/"}
+
+	for _, p := range optionalPrefix {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSavePath(path string) bool {
+	return path == SavePath || path == SharePath
+}
+
+// getTokenFromRequest токен из заголовка Authorization, при withCookie - еще и из cookie
+func getTokenFromRequest(r *http.Request, withCookie bool) string {
+	authHeader := r.Header.Get("Authorization")
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+
+	if !withCookie {
+		return ""
+	}
+
+	if cookie, err := r.Cookie(AccessTokenCookie); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
 func isAdminPath(path string) bool {
 	adminPrefix := []string{"/api/v1/admin", "api/v2/admin", "api/v3/admin"}
 
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetTokenFromRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		cookie     string
+		withCookie bool
+		want       string
+	}{
+		{name: "заголовок", header: "Bearer header-token", want: "header-token"},
+		{name: "заголовок важнее cookie", header: "Bearer header-token", cookie: "cookie-token", withCookie: true, want: "header-token"},
+		{name: "cookie для переходов", cookie: "cookie-token", withCookie: true, want: "cookie-token"},
+		{name: "cookie для JSON API не принимается", cookie: "cookie-token", want: ""},
+		{name: "не Bearer", header: "Basic abc", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: tt.cookie})
+			}
+
+			if got := getTokenFromRequest(r, tt.withCookie); got != tt.want {
+				t.Errorf("getTokenFromRequest() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Timeout ограничивает время обработки запроса, кроме потоковых путей: поток событий
+// открыт, пока клиент подключен, и закрывается по отмене контекста запроса
+func Timeout(timeout time.Duration, streamPaths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limited := chiMiddleware.Timeout(timeout)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(streamPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
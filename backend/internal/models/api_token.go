@@ -0,0 +1,41 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// APITokenPrefix начало персонального токена, по нему токен отличается от JWT
+const APITokenPrefix = "lsp_"
+
+// APIToken персональный токен доступа. Сам токен отдается один раз при создании, в БД - только хеш
+type APIToken struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"-"`
+	Name      string `json:"name"`
+	TokenHash string `json:"-"`
+	// Hint начало токена, чтобы узнать его в списке
+	Hint       string     `json:"hint"`
+	Token      string     `json:"token,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APITokenCreate struct {
+	Name string `json:"name"`
+}
+
+func (t *APITokenCreate) Validate() error {
+	op := "APITokenCreate.Validate"
+
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return app_errors.BadRequest("Название токена не может быть пустым", op)
+	}
+	if utf8.RuneCountInString(t.Name) > 100 {
+		return app_errors.BadRequest("Название токена не должно быть длиннее 100 символов", op)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// DigestFrequency периодичность дайджеста
+type DigestFrequency string
+
+const (
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// Period длительность периода, за который собирается дайджест
+func (f DigestFrequency) Period() time.Duration {
+	if f == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// DigestSettings расписание дайджеста пользователя
+type DigestSettings struct {
+	UserID    int             `json:"-"`
+	IsEnabled bool            `json:"is_enabled"`
+	Frequency DigestFrequency `json:"frequency"`
+	// Hour час отправки в часовом поясе Timezone
+	Hour int `json:"hour"`
+	// Weekday день недели еженедельного дайджеста, 0 - воскресенье
+	Weekday          int        `json:"weekday"`
+	Timezone         string     `json:"timezone"`
+	UnsubscribeToken string     `json:"-"`
+	LastSentAt       *time.Time `json:"last_sent_at"`
+	NextSendAt       *time.Time `json:"next_send_at"`
+	// UserEmail и UserName получатель, заполняются для отправки
+	UserEmail string `json:"-"`
+	UserName  string `json:"-"`
+}
+
+// NextSend ближайшее после now время отправки: Hour по местному времени в нужный день.
+// Переход на летнее время сдвигает отправку, как и обычные часы
+func (s *DigestSettings) NextSend(now time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := now.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, location)
+	for !next.After(now) || (s.Frequency == DigestWeekly && int(next.Weekday()) != s.Weekday) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1, s.Hour, 0, 0, 0, location)
+	}
+	return next, nil
+}
+
+type DigestSettingsSave struct {
+	IsEnabled bool            `json:"is_enabled"`
+	Frequency DigestFrequency `json:"frequency"`
+	Hour      int             `json:"hour"`
+	Weekday   int             `json:"weekday"`
+	Timezone  string          `json:"timezone"`
+}
+
+func (ds *DigestSettingsSave) Validate() error {
+	op := "DigestSettingsSave.Validate"
+
+	if ds.Frequency != DigestDaily && ds.Frequency != DigestWeekly {
+		return app_errors.BadRequest("Периодичность: daily или weekly", op)
+	}
+
+	if ds.Hour < 0 || ds.Hour > 23 {
+		return app_errors.BadRequest("Час отправки: от 0 до 23", op)
+	}
+
+	if ds.Weekday < 0 || ds.Weekday > 6 {
+		return app_errors.BadRequest("День недели: от 0 (воскресенье) до 6", op)
+	}
+
+	if ds.Timezone == "" {
+		ds.Timezone = "UTC"
+	}
+	// Local зависит от сервера, а не от пользователя
+	if _, err := time.LoadLocation(ds.Timezone); err != nil || ds.Timezone == "Local" || len(ds.Timezone) > 64 {
+		return app_errors.BadRequest("Неизвестный часовой пояс", op)
+	}
+
+	return nil
+}
+
+// DigestLink ссылка в разделе дайджеста
+type DigestLink struct {
+	ID             int     `json:"id"`
+	URL            string  `json:"url"`
+	Title          string  `json:"title"`
+	LinkGroupName  *string `json:"link_group_name,omitempty"`
+	ReadingMinutes *int    `json:"reading_minutes,omitempty"`
+	// Visits переходы за период, для раздела популярных ссылок
+	Visits int `json:"visits,omitempty"`
+}
+
+// Digest содержимое дайджеста за период с Since по Until
+type Digest struct {
+	Frequency   DigestFrequency `json:"frequency"`
+	Since       time.Time       `json:"since"`
+	Until       time.Time       `json:"until"`
+	SharedLinks []*DigestLink   `json:"shared_links"`
+	UnreadLinks []*DigestLink   `json:"unread_links"`
+	// UnreadTotal всего непрочитанных, в UnreadLinks - только последние
+	UnreadTotal int           `json:"unread_total"`
+	BrokenLinks []*DigestLink `json:"broken_links"`
+	TopVisited  []*DigestLink `json:"top_visited"`
+}
+
+// IsEmpty за период нечего рассказать
+func (d *Digest) IsEmpty() bool {
+	return len(d.SharedLinks) == 0 && len(d.UnreadLinks) == 0 && len(d.BrokenLinks) == 0 && len(d.TopVisited) == 0
+}
+
+// DigestMessage письмо дайджеста
+type DigestMessage struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
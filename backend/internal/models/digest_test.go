@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestDigestSettingsNextSend(t *testing.T) {
+	utc := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	// 19.10.2026 - понедельник. Берлин переходит на летнее время 29.03, на зимнее - 25.10
+	tests := []struct {
+		name     string
+		settings DigestSettings
+		now      time.Time
+		want     time.Time
+	}{
+		{
+			name:     "ежедневно, час еще не наступил",
+			settings: DigestSettings{Frequency: DigestDaily, Hour: 9, Timezone: "UTC"},
+			now:      utc(time.October, 19, 8),
+			want:     utc(time.October, 19, 9),
+		},
+		{
+			name:     "ежедневно, ровно в час отправки - следующий день",
+			settings: DigestSettings{Frequency: DigestDaily, Hour: 9, Timezone: "UTC"},
+			now:      utc(time.October, 19, 9),
+			want:     utc(time.October, 20, 9),
+		},
+		{
+			name:     "ежедневно, через конец месяца",
+			settings: DigestSettings{Frequency: DigestDaily, Hour: 0, Timezone: "UTC"},
+			now:      utc(time.October, 31, 12),
+			want:     utc(time.November, 1, 0),
+		},
+		{
+			name:     "еженедельно, в тот же день до часа отправки",
+			settings: DigestSettings{Frequency: DigestWeekly, Hour: 9, Weekday: 1, Timezone: "UTC"},
+			now:      utc(time.October, 19, 8),
+			want:     utc(time.October, 19, 9),
+		},
+		{
+			name:     "еженедельно, в тот же день после часа - через неделю",
+			settings: DigestSettings{Frequency: DigestWeekly, Hour: 9, Weekday: 1, Timezone: "UTC"},
+			now:      utc(time.October, 19, 10),
+			want:     utc(time.October, 26, 9),
+		},
+		{
+			name:     "еженедельно, воскресенье",
+			settings: DigestSettings{Frequency: DigestWeekly, Hour: 18, Weekday: 0, Timezone: "UTC"},
+			now:      utc(time.October, 21, 12),
+			want:     utc(time.October, 25, 18),
+		},
+		{
+			name:     "часовой пояс впереди UTC: местный день уже следующий",
+			settings: DigestSettings{Frequency: DigestDaily, Hour: 9, Timezone: "Asia/Tokyo"},
+			now:      utc(time.October, 19, 1),
+			want:     utc(time.October, 20, 0),
+		},
+		{
+			name:     "часовой пояс позади UTC: местный день еще предыдущий",
+			settings: DigestSettings{Frequency: DigestDaily, Hour: 23, Timezone: "America/New_York"},
+			now:      utc(time.October, 20, 2),
+			want:     utc(time.October, 20, 3),
+		},
+		{
+			name:     "еженедельно, день недели по местному времени",
+			settings: DigestSettings{Frequency: DigestWeekly, Hour: 8, Weekday: 2, Timezone: "Asia/Tokyo"},
+			now:      utc(time.October, 19, 12),
+			want:     utc(time.October, 19, 23),
+		},
+		{
+			name:     "переход на летнее время: тот же местный час",
+			settings: DigestSettings{Frequency: DigestDaily, Hour: 9, Timezone: "Europe/Berlin"},
+			now:      utc(time.March, 28, 9),
+			want:     utc(time.March, 29, 7),
+		},
+		{
+			name:     "переход на зимнее время: тот же местный час",
+			settings: DigestSettings{Frequency: DigestDaily, Hour: 9, Timezone: "Europe/Berlin"},
+			now:      utc(time.October, 24, 8),
+			want:     utc(time.October, 25, 8),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.settings.NextSend(tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextSend(%s) = %s, ожидалось %s", tt.now, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestSettingsNextSendUnknownTimezone(t *testing.T) {
+	settings := DigestSettings{Frequency: DigestDaily, Hour: 9, Timezone: "Mars/Olympus"}
+	if _, err := settings.NextSend(time.Now()); err == nil {
+		t.Error("ожидалась ошибка для неизвестного часового пояса")
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import "strings"
+
+// Типы событий шины. Подписка на webhook может указать тип целиком, категорию "link.*" или "*"
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkVisited = "link.visited"
+	// EventLinkMetadataReady получены заголовок и favicon страницы
+	EventLinkMetadataReady = "link.metadata_ready"
+	EventLinkGroupCreated  = "link_group.created"
+	EventLinkGroupUpdated  = "link_group.updated"
+	EventLinkGroupDeleted  = "link_group.deleted"
+	// EventLinkReminder наступило напоминание о ссылке
+	EventLinkReminder = "link.reminder"
+	// EventPing проверочное событие webhook, в шину не публикуется
+	EventPing = "ping"
+)
+
+var eventTypes = map[string]bool{
+	EventLinkCreated:       true,
+	EventLinkUpdated:       true,
+	EventLinkDeleted:       true,
+	EventLinkVisited:       true,
+	EventLinkMetadataReady: true,
+	EventLinkReminder:      true,
+	EventLinkGroupCreated:  true,
+	EventLinkGroupUpdated:  true,
+	EventLinkGroupDeleted:  true,
+}
+
+// IsEventPattern тип события, категория вида "link.*" или "*"
+func IsEventPattern(pattern string) bool {
+	if pattern == "*" || eventTypes[pattern] {
+		return true
+	}
+
+	category, ok := strings.CutSuffix(pattern, ".*")
+	if !ok {
+		return false
+	}
+	for eventType := range eventTypes {
+		if strings.HasPrefix(eventType, category+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// EventRef данные события об удаленной сущности
+type EventRef struct {
+	ID int `json:"id"`
+}
+
+// LinkVisitedEvent данные события перехода по ссылке
+type LinkVisitedEvent struct {
+	LinkID int    `json:"link_id"`
+	Slug   string `json:"slug,omitempty"`
+	Client string `json:"client,omitempty"`
+}
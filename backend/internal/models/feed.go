@@ -0,0 +1,64 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// FeedFormat формат ленты
+type FeedFormat string
+
+const (
+	FeedFormatAtom FeedFormat = "atom"
+	FeedFormatRSS  FeedFormat = "rss"
+)
+
+func ParseFeedFormat(value string) (FeedFormat, error) {
+	switch format := FeedFormat(value); format {
+	case FeedFormatAtom, FeedFormatRSS:
+		return format, nil
+	}
+	return "", app_errors.NotFound("Лента не найдена", "ParseFeedFormat")
+}
+
+// FeedSource источник ленты: группа ссылок с подгруппами или тег, задано ровно одно
+type FeedSource struct {
+	LinkGroupID *int
+	TagID       *int
+}
+
+// FeedToken секретный токен ленты. Токен в пути ленты заменяет авторизацию
+type FeedToken struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	LinkGroupID *int      `json:"link_group_id,omitempty"`
+	TagID       *int      `json:"tag_id,omitempty"`
+	Token       string    `json:"token"`
+	CreatedAt   time.Time `json:"created_at"`
+	// AtomPath и RSSPath пути ленты в форматах Atom и RSS
+	AtomPath string `json:"atom_path"`
+	RSSPath  string `json:"rss_path"`
+}
+
+// Feed последние ссылки источника для ленты
+type Feed struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Link адрес клиентского приложения
+	Link string `json:"link"`
+	// UpdatedAt последнее изменение ссылок ленты, для пустой ленты - создание токена
+	UpdatedAt time.Time   `json:"updated_at"`
+	Items     []*FeedItem `json:"items"`
+}
+
+type FeedItem struct {
+	LinkID       int       `json:"link_id"`
+	URL          string    `json:"url"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	PreviewImage string    `json:"preview_image,omitempty"`
+	Tags         []string  `json:"tags"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
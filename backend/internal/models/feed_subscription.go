@@ -0,0 +1,55 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FeedSubscription подписка группы на внешнюю ленту
+type FeedSubscription struct {
+	ID            int        `json:"id"`
+	LinkGroupID   int        `json:"link_group_id"`
+	UserID        int        `json:"user_id"`
+	URL           string     `json:"url"`
+	Title         string     `json:"title"`
+	ETag          string     `json:"-"`
+	LastModified  string     `json:"-"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastStatus    *int       `json:"last_status"`
+	LastError     string     `json:"last_error"`
+	ErrorCount    int        `json:"error_count"`
+	NextFetchAt   time.Time  `json:"next_fetch_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// WorkspaceID пространство группы, в нем создаются ссылки
+	WorkspaceID int `json:"-"`
+}
+
+// FeedFetchResult результат загрузки ленты для сохранения в подписке
+type FeedFetchResult struct {
+	Title        string
+	ETag         string
+	LastModified string
+	Status       *int
+	// Error пустая - загрузка успешна
+	Error       string
+	NextFetchAt time.Time
+}
+
+type FeedSubscriptionCreate struct {
+	URL string `json:"url"`
+}
+
+func (f *FeedSubscriptionCreate) Validate() error {
+	op := "FeedSubscriptionCreate.Validate"
+
+	f.URL = strings.TrimSpace(f.URL)
+	u, err := url.Parse(f.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return app_errors.BadRequest("Неверный адрес ленты, нужен http или https", op)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// EntityType тип сущности в истории изменений
+type EntityType string
+
+const (
+	EntityLink      EntityType = "link"
+	EntityLinkGroup EntityType = "link_group"
+	EntityTag       EntityType = "tag"
+)
+
+// HistoryAction действие над сущностью
+type HistoryAction string
+
+const (
+	HistoryCreate  HistoryAction = "create"
+	HistoryUpdate  HistoryAction = "update"
+	HistoryDelete  HistoryAction = "delete"
+	HistoryRestore HistoryAction = "restore"
+	HistoryRevert  HistoryAction = "revert"
+)
+
+// historyActorKey ключ контекста с автором изменений для истории
+type historyActorKey struct{}
+
+// WithHistoryActor контекст, изменения в котором записываются в историю от имени actorID
+func WithHistoryActor(ctx context.Context, actorID int) context.Context {
+	return context.WithValue(ctx, historyActorKey{}, actorID)
+}
+
+// HistoryActorFromContext автор изменений из контекста, nil - фоновая задача
+func HistoryActorFromContext(ctx context.Context) *int {
+	if actorID, ok := ctx.Value(historyActorKey{}).(int); ok {
+		return &actorID
+	}
+	return nil
+}
+
+// FieldChange значение поля до и после изменения
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type HistoryEntry struct {
+	ID         int64      `json:"id"`
+	EntityType EntityType `json:"entity_type"`
+	EntityID   int        `json:"entity_id"`
+	UserID     int        `json:"user_id"`
+	// ActorID кто внес изменение, nil - система
+	ActorID   *int                   `json:"actor_id"`
+	ActorName *string                `json:"actor_name,omitempty"`
+	Action    HistoryAction          `json:"action"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// InboundEmail секретный адрес пользователя для сохранения ссылок письмом
+type InboundEmail struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"-"`
+	WorkspaceID int       `json:"workspace_id"`
+	Token       string    `json:"-"`
+	Address     string    `json:"address"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Role роль пользователя в пространстве токена, заполняется при приеме письма
+	Role WorkspaceRole `json:"-"`
+}
+
+// InboundEmailResult результат разбора письма
+type InboundEmailResult struct {
+	// LinkIDs сохраненные ссылки
+	LinkIDs []int `json:"link_ids"`
+	// Skipped ссылки, которые уже были сохранены, и неверные адреса
+	Skipped int `json:"skipped"`
+}
@@ -2,47 +2,114 @@ package models
 
 import (
 	"link-storage/pkg/types/app_errors"
+	"slices"
 	"time"
 )
 
 type Link struct {
-	ID           int       `json:"id"`
-	UserID       int       `json:"user_id"`
-	LinkGroupID  *int      `json:"link_group_id,omitempty"`
-	URL          string    `json:"url"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description"`
-	FaviconURL   string    `json:"favicon_url,omitempty"`
-	PreviewImage string    `json:"preview_image,omitempty"`
-	IsArchived   bool      `json:"is_archived"`
-	IsFavorite   bool      `json:"is_favorite"`
-	ClickCount   int       `json:"click_count"`
-	LastVisited  time.Time `json:"last_visited"`
-	Position     int       `json:"position"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	WorkspaceID   int        `json:"workspace_id"`
+	LinkGroupID   *int       `json:"link_group_id,omitempty"`
+	URL           string     `json:"url"`
+	CanonicalURL  *string    `json:"canonical_url,omitempty"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	FaviconURL    string     `json:"favicon_url,omitempty"`
+	PreviewImage  string     `json:"preview_image,omitempty"`
+	IsArchived    bool       `json:"is_archived"`
+	IsFavorite    bool       `json:"is_favorite"`
+	ClickCount    int        `json:"click_count"`
+	LastVisited   time.Time  `json:"last_visited"`
+	Frecency      int        `json:"frecency"`
+	Slug          *string    `json:"slug,omitempty"`
+	SlugExpiresAt *time.Time `json:"slug_expires_at,omitempty"`
+	SlugIsPublic  bool       `json:"slug_is_public"`
+	HTTPStatus    *int       `json:"http_status,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	FinalURL      string     `json:"final_url,omitempty"`
+	CheckFailures int        `json:"check_failures"`
+	// ReadAt когда ссылка прочитана, nil - ссылка в списке чтения
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+	ReadingMinutes *int       `json:"reading_minutes,omitempty"`
+	RemindAt       *time.Time `json:"remind_at,omitempty"`
+	RemindUserID   *int       `json:"remind_user_id,omitempty"`
+	Position       int        `json:"position"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsSlugActive есть ли у ссылки действующая короткая ссылка
+func (l *Link) IsSlugActive(now time.Time) bool {
+	return l.Slug != nil && (l.SlugExpiresAt == nil || now.Before(*l.SlugExpiresAt))
+}
+
+// IsBroken при последней проверке ссылка не открылась
+func (l *Link) IsBroken() bool {
+	return l.CheckFailures > 0
+}
+
+// LinkFilter фильтр списка ссылок
+type LinkFilter struct {
+	LinkGroupID int
+	// IncludeSubgroups вместе со ссылками подгрупп LinkGroupID
+	IncludeSubgroups bool
+	// Name поиск по заголовку и URL, а также по заметкам и цитатам пользователя NotesUserID
+	Name        string
+	NotesUserID int
+	// Broken только ссылки, не открывшиеся при последней проверке
+	Broken bool
+	// State прочитанные или непрочитанные ссылки, пусто - все
+	State LinkReadState
 }
 
 type LinkResponse struct {
 	Link
 	Group struct {
-		ID          *int    `json:"id"`
-		Name        *string `json:"name"`
+		ID   *int    `json:"id"`
+		Name *string `json:"name"`
 	} `json:"link_group,omitempty"`
 	// Group *LinkGroup `json:"group,omitempty"`
 }
 
+// LinkGoURL путь редиректа на ссылку с подписанным токеном доступа
+type LinkGoURL struct {
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type LinkCreate struct {
 	LinkGroupID *int   `json:"link_group_id,omitempty"`
 	URL         string `json:"url"`
 	Title       string `json:"title,omitempty"`
 	Description string `json:"description,omitempty"`
 	IsFavorite  bool   `json:"is_favorite,omitempty"`
+	// TagIDs теги пространства ссылки
+	TagIDs []int `json:"tag_ids,omitempty"`
 }
 
 func (l *LinkCreate) Validate() error {
 	if l.URL == "" {
 		return app_errors.BadRequest("URL не может быть пустым", "LinkCreate.Validate")
 	}
+	slices.Sort(l.TagIDs)
+	l.TagIDs = slices.Compact(l.TagIDs)
+	return nil
+}
+
+// LinkUpdate редактируемые поля ссылки, заменяются целиком
+type LinkUpdate struct {
+	LinkGroupID *int   `json:"link_group_id"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	IsArchived  bool   `json:"is_archived"`
+	IsFavorite  bool   `json:"is_favorite"`
+}
+
+func (l *LinkUpdate) Validate() error {
+	if l.URL == "" {
+		return app_errors.BadRequest("URL не может быть пустым", "LinkUpdate.Validate")
+	}
 	return nil
 }
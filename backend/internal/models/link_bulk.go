@@ -0,0 +1,98 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"slices"
+	"time"
+)
+
+const maxBulkLinks = 5000
+
+// LinkBulkAction действие над набором ссылок
+type LinkBulkAction string
+
+const (
+	LinkBulkMove       LinkBulkAction = "move"
+	LinkBulkAddTags    LinkBulkAction = "add_tags"
+	LinkBulkRemoveTags LinkBulkAction = "remove_tags"
+	LinkBulkFavorite   LinkBulkAction = "favorite"
+	LinkBulkUnfavorite LinkBulkAction = "unfavorite"
+	LinkBulkArchive    LinkBulkAction = "archive"
+	LinkBulkUnarchive  LinkBulkAction = "unarchive"
+	LinkBulkDelete     LinkBulkAction = "delete"
+	LinkBulkRefresh    LinkBulkAction = "refresh"
+)
+
+// LinkBulk действие Action над ссылками LinkIDs
+type LinkBulk struct {
+	LinkIDs []int          `json:"link_ids"`
+	Action  LinkBulkAction `json:"action"`
+	// LinkGroupID группа для move, nil - без группы
+	LinkGroupID *int `json:"link_group_id,omitempty"`
+	// TagIDs теги для add_tags и remove_tags
+	TagIDs []int `json:"tag_ids,omitempty"`
+}
+
+func (lb *LinkBulk) Validate() error {
+	op := "LinkBulk.Validate"
+
+	slices.Sort(lb.LinkIDs)
+	lb.LinkIDs = slices.Compact(lb.LinkIDs)
+
+	if len(lb.LinkIDs) == 0 {
+		return app_errors.BadRequest("Не указаны ссылки", op)
+	}
+
+	if len(lb.LinkIDs) > maxBulkLinks {
+		return app_errors.BadRequest("Слишком много ссылок в одном запросе", op)
+	}
+
+	switch lb.Action {
+	case LinkBulkAddTags, LinkBulkRemoveTags:
+		slices.Sort(lb.TagIDs)
+		lb.TagIDs = slices.Compact(lb.TagIDs)
+		if len(lb.TagIDs) == 0 {
+			return app_errors.BadRequest("Не указаны теги", op)
+		}
+	case LinkBulkMove, LinkBulkFavorite, LinkBulkUnfavorite, LinkBulkArchive, LinkBulkUnarchive, LinkBulkDelete, LinkBulkRefresh:
+	default:
+		return app_errors.BadRequest("Неверное действие, допустимые значения: move, add_tags, remove_tags, favorite, unfavorite, archive, unarchive, delete, refresh", op)
+	}
+
+	return nil
+}
+
+// Результат действия над одной ссылкой
+const (
+	LinkBulkItemOK       = "ok"
+	LinkBulkItemNotFound = "not_found"
+	LinkBulkItemError    = "error"
+)
+
+type LinkBulkItemResult struct {
+	LinkID int    `json:"link_id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Состояние фоновой задачи
+const (
+	LinkBulkJobPending = "pending"
+	LinkBulkJobRunning = "running"
+	LinkBulkJobDone    = "done"
+)
+
+// LinkBulkJob выполнение массового действия. Небольшие наборы выполняются сразу и возвращаются
+// без ID, большие - в фоне, состояние запрашивается по ID
+type LinkBulkJob struct {
+	ID          string                `json:"id,omitempty"`
+	UserID      int                   `json:"-"`
+	WorkspaceID int                   `json:"-"`
+	Action      LinkBulkAction        `json:"action"`
+	Status      string                `json:"status"`
+	Total       int                   `json:"total"`
+	Processed   int                   `json:"processed"`
+	Results     []*LinkBulkItemResult `json:"results,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	FinishedAt  *time.Time            `json:"finished_at,omitempty"`
+}
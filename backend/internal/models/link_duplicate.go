@@ -0,0 +1,51 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"slices"
+)
+
+const maxMergeLinks = 100
+
+// Причины, по которым ссылки считаются дубликатами
+const (
+	DuplicateReasonCanonicalURL = "canonical_url"
+	DuplicateReasonTitle        = "title"
+)
+
+// LinkDuplicateGroup группа ссылок, похожих друг на друга
+type LinkDuplicateGroup struct {
+	Reasons []string `json:"reasons"`
+	Links   []*Link  `json:"links"`
+}
+
+// LinkMerge слияние ссылок LinkIDs в ссылку SurvivorID
+type LinkMerge struct {
+	SurvivorID int   `json:"survivor_id"`
+	LinkIDs    []int `json:"link_ids"`
+}
+
+func (lm *LinkMerge) Validate() error {
+	op := "LinkMerge.Validate"
+
+	if lm.SurvivorID <= 0 {
+		return app_errors.BadRequest("Не указана ссылка, в которую выполняется слияние", op)
+	}
+
+	// Убираем повторы и саму оставляемую ссылку
+	slices.Sort(lm.LinkIDs)
+	lm.LinkIDs = slices.Compact(lm.LinkIDs)
+	lm.LinkIDs = slices.DeleteFunc(lm.LinkIDs, func(id int) bool {
+		return id == lm.SurvivorID
+	})
+
+	if len(lm.LinkIDs) == 0 {
+		return app_errors.BadRequest("Не указаны ссылки для слияния", op)
+	}
+
+	if len(lm.LinkIDs) > maxMergeLinks {
+		return app_errors.BadRequest("Слишком много ссылок для слияния", op)
+	}
+
+	return nil
+}
@@ -8,12 +8,31 @@ import (
 type LinkGroup struct {
 	ID          int       `json:"id"`
 	UserID      int       `json:"user_id"`
+	WorkspaceID int       `json:"workspace_id"`
+	ParentID    *int      `json:"parent_id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Position    int       `json:"position"`
 	Color       string    `json:"color"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// Role роль текущего пользователя, заполняется там, где группа может быть чужой
+	Role LinkGroupRole `json:"role,omitempty"`
+}
+
+// LinkGroupNode узел дерева групп
+type LinkGroupNode struct {
+	LinkGroup
+	// LinkCount ссылок в самой группе
+	LinkCount int `json:"link_count"`
+	// TotalLinkCount ссылок в группе вместе с подгруппами
+	TotalLinkCount int              `json:"total_link_count"`
+	Children       []*LinkGroupNode `json:"children"`
+}
+
+// LinkGroupParentSet перенос группы, ParentID nil - в корень
+type LinkGroupParentSet struct {
+	ParentID *int `json:"parent_id"`
 }
 
 // type LinkGroupShortResponse struct {
@@ -28,6 +47,7 @@ type LinkGroup struct {
 // }
 
 type LinkGroupCreate struct {
+	ParentID    *int   `json:"parent_id,omitempty"`
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	Color       string `json:"color,omitempty"`
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"strconv"
+	"strings"
+)
+
+// LinkGroupDeleteLinks что делать со ссылками удаляемой группы
+type LinkGroupDeleteLinks string
+
+const (
+	// LinkGroupDeleteLinksOrphan оставить ссылки без группы
+	LinkGroupDeleteLinksOrphan LinkGroupDeleteLinks = "orphan"
+	// LinkGroupDeleteLinksMoveTo перенести ссылки в другую группу
+	LinkGroupDeleteLinksMoveTo LinkGroupDeleteLinks = "move_to"
+	// LinkGroupDeleteLinksDelete удалить ссылки
+	LinkGroupDeleteLinksDelete LinkGroupDeleteLinks = "delete"
+	// LinkGroupDeleteLinksArchive архивировать ссылки, оставив их без группы
+	LinkGroupDeleteLinksArchive LinkGroupDeleteLinks = "archive"
+)
+
+// LinkGroupDelete параметры удаления группы
+type LinkGroupDelete struct {
+	// LiftChildren поднять подгруппы на уровень выше вместо удаления вместе с группой
+	LiftChildren bool
+	Links        LinkGroupDeleteLinks
+	// MoveToID группа для переноса ссылок при Links = move_to
+	MoveToID int
+}
+
+// ParseLinkGroupDelete разбирает параметры children=delete|lift и links=orphan|move_to:{id}|delete|archive
+func ParseLinkGroupDelete(children, links string) (*LinkGroupDelete, error) {
+	op := "ParseLinkGroupDelete"
+
+	params := &LinkGroupDelete{
+		LiftChildren: true,
+		Links:        LinkGroupDeleteLinksOrphan,
+	}
+
+	switch children {
+	case "", "lift":
+	case "delete":
+		params.LiftChildren = false
+	default:
+		return nil, app_errors.BadRequest("Параметр children: delete или lift", op)
+	}
+
+	action, value, hasValue := strings.Cut(links, ":")
+	switch LinkGroupDeleteLinks(action) {
+	case "":
+	case LinkGroupDeleteLinksOrphan, LinkGroupDeleteLinksDelete, LinkGroupDeleteLinksArchive:
+		if hasValue {
+			return nil, app_errors.BadRequest("Параметр links: orphan, move_to:{id}, delete или archive", op)
+		}
+		params.Links = LinkGroupDeleteLinks(action)
+	case LinkGroupDeleteLinksMoveTo:
+		moveToID, err := strconv.Atoi(value)
+		if err != nil || moveToID <= 0 {
+			return nil, app_errors.BadRequest("Параметр links: укажите группу для переноса, move_to:{id}", op)
+		}
+		params.Links = LinkGroupDeleteLinksMoveTo
+		params.MoveToID = moveToID
+	default:
+		return nil, app_errors.BadRequest("Параметр links: orphan, move_to:{id}, delete или archive", op)
+	}
+
+	return params, nil
+}
+
+// LinkGroupDeletePreview что затронет удаление группы
+type LinkGroupDeletePreview struct {
+	Subgroups int `json:"subgroups"`
+	// Links ссылок в самой группе
+	Links int `json:"links"`
+	// SubgroupLinks ссылок в подгруппах
+	SubgroupLinks int `json:"subgroup_links"`
+	// AffectedLinks ссылок, к которым применится действие links с учетом children
+	AffectedLinks int `json:"affected_links"`
+}
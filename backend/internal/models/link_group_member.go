@@ -0,0 +1,86 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// LinkGroupRole роль пользователя в группе. Роль в группе действует и на ее подгруппы
+type LinkGroupRole string
+
+const (
+	LinkGroupRoleViewer LinkGroupRole = "viewer"
+	LinkGroupRoleEditor LinkGroupRole = "editor"
+	LinkGroupRoleOwner  LinkGroupRole = "owner"
+)
+
+var linkGroupRoleLevels = map[LinkGroupRole]int{
+	LinkGroupRoleViewer: 1,
+	LinkGroupRoleEditor: 2,
+	LinkGroupRoleOwner:  3,
+}
+
+// Allows дает ли роль права роли required
+func (r LinkGroupRole) Allows(required LinkGroupRole) bool {
+	return linkGroupRoleLevels[r] >= linkGroupRoleLevels[required]
+}
+
+func (r LinkGroupRole) Validate() error {
+	if _, ok := linkGroupRoleLevels[r]; !ok {
+		return app_errors.BadRequest("Неверная роль, допустимые значения: viewer, editor, owner", "LinkGroupRole.Validate")
+	}
+	return nil
+}
+
+// LinkGroupMember участник общей группы
+type LinkGroupMember struct {
+	LinkGroupID int           `json:"link_group_id"`
+	UserID      int           `json:"user_id"`
+	Name        string        `json:"name"`
+	Email       string        `json:"email"`
+	Role        LinkGroupRole `json:"role"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// LinkGroupMemberSet смена роли участника
+type LinkGroupMemberSet struct {
+	Role LinkGroupRole `json:"role"`
+}
+
+func (m *LinkGroupMemberSet) Validate() error {
+	return m.Role.Validate()
+}
+
+type LinkGroupInvite struct {
+	ID          int           `json:"id"`
+	LinkGroupID int           `json:"link_group_id"`
+	Email       string        `json:"email"`
+	Role        LinkGroupRole `json:"role"`
+	Token       string        `json:"-"`
+	InvitedBy   *int          `json:"invited_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	// EmailSent удалось ли отправить письмо с приглашением
+	EmailSent bool `json:"email_sent"`
+}
+
+type LinkGroupInviteCreate struct {
+	Email string        `json:"email"`
+	Role  LinkGroupRole `json:"role"`
+}
+
+func (i *LinkGroupInviteCreate) Validate() error {
+	op := "LinkGroupInviteCreate.Validate"
+
+	i.Email = strings.ToLower(strings.TrimSpace(i.Email))
+	if _, err := mail.ParseAddress(i.Email); err != nil {
+		return app_errors.BadRequest("Неверный email", op)
+	}
+
+	if i.Role == "" {
+		i.Role = LinkGroupRoleViewer
+	}
+	return i.Role.Validate()
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// LinkGroupShare публикация группы на публичной странице /p/{slug}
+type LinkGroupShare struct {
+	LinkGroupID  int        `json:"link_group_id"`
+	Slug         string     `json:"slug"`
+	PasswordHash *string    `json:"-"`
+	HasPassword  bool       `json:"has_password"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	CreatedBy    *int       `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	// Path путь публичной страницы
+	Path string `json:"path"`
+}
+
+// IsActive не истек ли срок публикации
+func (s *LinkGroupShare) IsActive(now time.Time) bool {
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// LinkGroupShareSave публикация группы или изменение настроек публикации
+type LinkGroupShareSave struct {
+	// Password nil - оставить текущий пароль, пустая строка - снять пароль
+	Password  *string    `json:"password,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// RegenerateSlug выпустить новое имя страницы, старая ссылка перестанет работать
+	RegenerateSlug bool `json:"regenerate_slug,omitempty"`
+}
+
+func (s *LinkGroupShareSave) Validate() error {
+	op := "LinkGroupShareSave.Validate"
+
+	// bcrypt учитывает только первые 72 байта пароля
+	if s.Password != nil && *s.Password != "" && (len(*s.Password) < 4 || len(*s.Password) > 72) {
+		return app_errors.BadRequest("Пароль должен быть от 4 до 72 символов", op)
+	}
+
+	if s.ExpiresAt != nil && s.ExpiresAt.Before(time.Now()) {
+		return app_errors.BadRequest("Срок действия должен быть в будущем", op)
+	}
+
+	return nil
+}
+
+// PublicLinkGroup группа на публичной странице: только то, что можно показать постороннему
+type PublicLinkGroup struct {
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Color       string             `json:"color"`
+	Links       []*PublicLink      `json:"links"`
+	Groups      []*PublicLinkGroup `json:"groups"`
+}
+
+type PublicLink struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// FaviconURL адрес иконки на публичной странице, пусто - иконки нет
+	FaviconURL string `json:"favicon_url,omitempty"`
+}
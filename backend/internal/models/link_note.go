@@ -0,0 +1,116 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxLinkNoteLength длина заметки в символах
+	maxLinkNoteLength = 20000
+	// maxHighlightLength длина цитаты и ее префикса с суффиксом в символах
+	maxHighlightLength       = 5000
+	maxHighlightAnchorLength = 500
+)
+
+// LinkNote личная заметка пользователя к ссылке. Body - Markdown как есть, HTML - очищенный HTML,
+// заполняется по запросу
+type LinkNote struct {
+	ID        int       `json:"id"`
+	LinkID    int       `json:"link_id"`
+	UserID    int       `json:"-"`
+	Body      string    `json:"body"`
+	HTML      string    `json:"html,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LinkNoteSave struct {
+	Body string `json:"body"`
+}
+
+func (ln *LinkNoteSave) Validate() error {
+	op := "LinkNoteSave.Validate"
+
+	ln.Body = strings.TrimSpace(ln.Body)
+	if ln.Body == "" {
+		return app_errors.BadRequest("Текст заметки не может быть пустым", op)
+	}
+
+	if utf8.RuneCountInString(ln.Body) > maxLinkNoteLength {
+		return app_errors.BadRequest("Заметка слишком длинная", op)
+	}
+
+	return nil
+}
+
+// HighlightAnchor положение цитаты в тексте страницы: текст до и после цитаты и смещения в символах
+// (как TextQuoteSelector и TextPositionSelector у W3C Web Annotation). Любое поле можно не указывать
+type HighlightAnchor struct {
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+	Start  *int   `json:"start,omitempty"`
+	End    *int   `json:"end,omitempty"`
+}
+
+func (ha *HighlightAnchor) Validate() error {
+	op := "HighlightAnchor.Validate"
+
+	if utf8.RuneCountInString(ha.Prefix) > maxHighlightAnchorLength || utf8.RuneCountInString(ha.Suffix) > maxHighlightAnchorLength {
+		return app_errors.BadRequest("Слишком длинный текст вокруг цитаты", op)
+	}
+
+	if (ha.Start == nil) != (ha.End == nil) {
+		return app_errors.BadRequest("Нужно указать начало и конец цитаты", op)
+	}
+
+	if ha.Start != nil && (*ha.Start < 0 || *ha.End <= *ha.Start) {
+		return app_errors.BadRequest("Неверное положение цитаты", op)
+	}
+
+	return nil
+}
+
+// LinkHighlight цитата со страницы ссылки
+type LinkHighlight struct {
+	ID        int              `json:"id"`
+	LinkID    int              `json:"link_id"`
+	UserID    int              `json:"-"`
+	Quote     string           `json:"quote"`
+	Anchor    *HighlightAnchor `json:"anchor"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type LinkHighlightCreate struct {
+	Quote  string           `json:"quote"`
+	Anchor *HighlightAnchor `json:"anchor,omitempty"`
+}
+
+func (lh *LinkHighlightCreate) Validate() error {
+	op := "LinkHighlightCreate.Validate"
+
+	lh.Quote = strings.TrimSpace(lh.Quote)
+	if lh.Quote == "" {
+		return app_errors.BadRequest("Цитата не может быть пустой", op)
+	}
+
+	if utf8.RuneCountInString(lh.Quote) > maxHighlightLength {
+		return app_errors.BadRequest("Цитата слишком длинная", op)
+	}
+
+	if lh.Anchor != nil {
+		return lh.Anchor.Validate()
+	}
+
+	return nil
+}
+
+// ExportLink ссылка в выгрузке: с группой и личными заметками и цитатами пользователя
+type ExportLink struct {
+	Link
+	GroupName  *string          `json:"link_group,omitempty"`
+	Notes      []*LinkNote      `json:"notes"`
+	Highlights []*LinkHighlight `json:"highlights"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// LinkReadState состояние ссылки в списке чтения
+type LinkReadState string
+
+const (
+	LinkStateUnread LinkReadState = "unread"
+	LinkStateRead   LinkReadState = "read"
+)
+
+func (s LinkReadState) IsValid() bool {
+	return s == LinkStateUnread || s == LinkStateRead
+}
+
+// maxReminderAhead на сколько вперед можно поставить напоминание
+const maxReminderAhead = 5 * 365 * 24 * time.Hour
+
+type LinkReminderSet struct {
+	RemindAt time.Time `json:"remind_at"`
+}
+
+func (lr *LinkReminderSet) Validate() error {
+	op := "LinkReminderSet.Validate"
+
+	now := time.Now()
+	if !lr.RemindAt.After(now) {
+		return app_errors.BadRequest("Время напоминания должно быть в будущем", op)
+	}
+
+	if lr.RemindAt.After(now.Add(maxReminderAhead)) {
+		return app_errors.BadRequest("Напоминание можно поставить не дальше чем на 5 лет", op)
+	}
+
+	return nil
+}
+
+// LinkReminder наступившее напоминание о ссылке для отправки пользователю
+type LinkReminder struct {
+	Link      *Link
+	RemindAt  time.Time
+	UserID    int
+	UserEmail string
+	UserName  string
+	// Attempts сколько предыдущих попыток отправки закончились ошибкой
+	Attempts int
+}
+
+// LinkReminderEvent данные события напоминания о ссылке
+type LinkReminderEvent struct {
+	UserID   int       `json:"user_id"`
+	RemindAt time.Time `json:"remind_at"`
+	Link     *Link     `json:"link"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var slugPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{2,63}$`)
+
+// reservedSlugs слова, которые нельзя занять коротким именем: пути приложения и служебные имена
+var reservedSlugs = map[string]bool{
+	"api": true, "go": true, "s": true, "p": true, "admin": true, "auth": true,
+	"login": true, "logout": true, "register": true, "activate": true, "profile": true,
+	"settings": true, "dashboard": true, "static": true, "media": true, "assets": true,
+	"public": true, "feeds": true, "save": true, "share": true, "help": true,
+	"about": true, "www": true, "null": true, "undefined": true,
+}
+
+// IsReservedSlug проверяет, зарезервировано ли имя. Имена из одних цифр тоже заняты:
+// /go/{key} сначала читает key как ID ссылки
+func IsReservedSlug(slug string) bool {
+	return reservedSlugs[strings.ToLower(slug)] || isNumericSlug(slug)
+}
+
+// isNumericSlug имя состоит только из цифр
+func isNumericSlug(slug string) bool {
+	return strings.Trim(slug, "0123456789") == ""
+}
+
+type LinkSlugSet struct {
+	// Slug пустой - сохранить текущий или сгенерировать новый
+	Slug      string     `json:"slug,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	IsPublic  bool       `json:"is_public"`
+}
+
+func (ls *LinkSlugSet) Validate() error {
+	op := "LinkSlugSet.Validate"
+
+	ls.Slug = strings.TrimSpace(ls.Slug)
+
+	if ls.Slug != "" {
+		if !slugPattern.MatchString(ls.Slug) {
+			return app_errors.BadRequest("Короткое имя: от 3 до 64 символов, латиница, цифры, '-' и '_'", op)
+		}
+
+		if isNumericSlug(ls.Slug) {
+			return app_errors.BadRequest("Короткое имя не может состоять только из цифр", op)
+		}
+
+		if IsReservedSlug(ls.Slug) {
+			return app_errors.BadRequest("Короткое имя зарезервировано", op)
+		}
+	}
+
+	if ls.ExpiresAt != nil && ls.ExpiresAt.Before(time.Now()) {
+		return app_errors.BadRequest("Срок действия должен быть в будущем", op)
+	}
+
+	return nil
+}
+
+type LinkSlugStats struct {
+	Slug       string          `json:"slug"`
+	ClickCount int             `json:"click_count"`
+	SlugClicks int             `json:"slug_clicks"`
+	Visits     []*VisitsPerDay `json:"visits"`
+}
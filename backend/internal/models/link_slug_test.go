@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkSlugSetValidate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		slugSet  LinkSlugSet
+		wantSlug string
+		wantErr  bool
+	}{
+		{name: "пустое имя", slugSet: LinkSlugSet{}},
+		{name: "обычное имя", slugSet: LinkSlugSet{Slug: " my-link_1 "}, wantSlug: "my-link_1"},
+		{name: "цифры с буквой", slugSet: LinkSlugSet{Slug: "2024a"}, wantSlug: "2024a"},
+		{name: "только цифры", slugSet: LinkSlugSet{Slug: "2024"}, wantErr: true},
+		{name: "только цифры, длинное", slugSet: LinkSlugSet{Slug: "0123456789"}, wantErr: true},
+		{name: "короткое", slugSet: LinkSlugSet{Slug: "ab"}, wantErr: true},
+		{name: "начинается с дефиса", slugSet: LinkSlugSet{Slug: "-abc"}, wantErr: true},
+		{name: "недопустимый символ", slugSet: LinkSlugSet{Slug: "abc.def"}, wantErr: true},
+		{name: "зарезервированное", slugSet: LinkSlugSet{Slug: "Admin"}, wantErr: true},
+		{name: "срок в прошлом", slugSet: LinkSlugSet{Slug: "abc", ExpiresAt: &past}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.slugSet.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, ошибка ожидалась: %v", err, tt.wantErr)
+			}
+			if err == nil && tt.slugSet.Slug != tt.wantSlug {
+				t.Errorf("Slug = %q, ожидалось %q", tt.slugSet.Slug, tt.wantSlug)
+			}
+		})
+	}
+}
+
+func TestIsReservedSlug(t *testing.T) {
+	for slug, want := range map[string]bool{"api": true, "API": true, "123": true, "a123": false, "links": false} {
+		if got := IsReservedSlug(slug); got != want {
+			t.Errorf("IsReservedSlug(%q) = %v, ожидалось %v", slug, got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+type LinkVisit struct {
+	ID        int64     `json:"id"`
+	LinkID    int       `json:"link_id"`
+	UserID    int       `json:"user_id"`
+	Referrer  string    `json:"referrer"`
+	Client    string    `json:"client"`
+	Slug      string    `json:"slug,omitempty"`
+	VisitedAt time.Time `json:"visited_at"`
+}
+
+// VisitPeriod период для аналитики посещений
+type VisitPeriod string
+
+const (
+	VisitPeriodWeek  VisitPeriod = "7d"
+	VisitPeriodMonth VisitPeriod = "30d"
+	VisitPeriodAll   VisitPeriod = "all"
+)
+
+func ParseVisitPeriod(value string, defaultPeriod VisitPeriod) (VisitPeriod, error) {
+	if value == "" {
+		return defaultPeriod, nil
+	}
+
+	period := VisitPeriod(value)
+	switch period {
+	case VisitPeriodWeek, VisitPeriodMonth, VisitPeriodAll:
+		return period, nil
+	default:
+		return "", app_errors.BadRequest("Неверный период, допустимые значения: 7d, 30d, all", "ParseVisitPeriod")
+	}
+}
+
+// Since возвращает начало периода, nil - за все время
+func (p VisitPeriod) Since(now time.Time) *time.Time {
+	var since time.Time
+	switch p {
+	case VisitPeriodWeek:
+		since = now.AddDate(0, 0, -7)
+	case VisitPeriodMonth:
+		since = now.AddDate(0, 0, -30)
+	default:
+		return nil
+	}
+	return &since
+}
+
+// VisitsFilter фильтр аналитики посещений
+type VisitsFilter struct {
+	LinkID      int
+	LinkGroupID int
+	Since       *time.Time
+	// ViaSlug учитывать только переходы по коротким ссылкам
+	ViaSlug bool
+}
+
+type VisitsPerDay struct {
+	Day    time.Time `json:"day"`
+	Visits int       `json:"visits"`
+}
+
+type LinkTopVisited struct {
+	Link
+	Visits int `json:"visits"`
+}
+
+type LinkRecentVisited struct {
+	Link
+	VisitedAt time.Time `json:"visited_at"`
+}
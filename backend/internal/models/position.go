@@ -0,0 +1,51 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"slices"
+)
+
+// PositionOrder изменение порядка: либо полный список IDs в нужном порядке,
+// либо перемещение элемента ID перед BeforeID или после AfterID
+type PositionOrder struct {
+	IDs      []int `json:"ids,omitempty"`
+	ID       int   `json:"id,omitempty"`
+	BeforeID int   `json:"before_id,omitempty"`
+	AfterID  int   `json:"after_id,omitempty"`
+}
+
+// IsMove перемещение одного элемента
+func (po *PositionOrder) IsMove() bool {
+	return len(po.IDs) == 0
+}
+
+func (po *PositionOrder) Validate() error {
+	op := "PositionOrder.Validate"
+
+	if len(po.IDs) > 0 {
+		if po.ID != 0 || po.BeforeID != 0 || po.AfterID != 0 {
+			return app_errors.BadRequest("Укажите либо список ids, либо перемещение id", op)
+		}
+
+		ids := slices.Clone(po.IDs)
+		slices.Sort(ids)
+		if len(slices.Compact(ids)) != len(po.IDs) {
+			return app_errors.BadRequest("Список ids содержит повторы", op)
+		}
+		return nil
+	}
+
+	if po.ID <= 0 {
+		return app_errors.BadRequest("Укажите список ids или перемещаемый id", op)
+	}
+
+	if (po.BeforeID > 0) == (po.AfterID > 0) {
+		return app_errors.BadRequest("Укажите одно из полей before_id или after_id", op)
+	}
+
+	if po.BeforeID == po.ID || po.AfterID == po.ID {
+		return app_errors.BadRequest("Элемент нельзя переместить относительно самого себя", op)
+	}
+
+	return nil
+}
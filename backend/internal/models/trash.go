@@ -0,0 +1,42 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// TrashItemType тип элемента корзины
+type TrashItemType string
+
+const (
+	TrashItemLink      TrashItemType = "link"
+	TrashItemLinkGroup TrashItemType = "link_group"
+	TrashItemTag       TrashItemType = "tag"
+)
+
+// ParseTrashItemType разбирает тип элемента корзины, allowEmpty - пустое значение означает все типы
+func ParseTrashItemType(value string, allowEmpty bool) (TrashItemType, error) {
+	itemType := TrashItemType(value)
+	switch itemType {
+	case TrashItemLink, TrashItemLinkGroup, TrashItemTag:
+		return itemType, nil
+	case "":
+		if allowEmpty {
+			return "", nil
+		}
+	}
+	return "", app_errors.BadRequest("Неверный тип, допустимые значения: link, link_group, tag", "ParseTrashItemType")
+}
+
+type TrashItem struct {
+	Type TrashItemType `json:"type"`
+	ID   int           `json:"id"`
+	// Name заголовок ссылки или имя группы, тега
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+	// LinkGroupID группа ссылки или родитель группы
+	LinkGroupID *int      `json:"link_group_id,omitempty"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	// PurgeAt когда элемент будет удален окончательно
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
@@ -20,4 +20,7 @@ type CurrentUser struct {
 	Email    string `json:"email"`
 	IsActive bool   `json:"is_active"`
 	IsAdmin  bool   `json:"is_admin"`
+	// WorkspaceID и WorkspaceRole активное пространство запроса, в JWT не попадают
+	WorkspaceID   int           `json:"-"`
+	WorkspaceRole WorkspaceRole `json:"-"`
 }
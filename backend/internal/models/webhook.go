@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"link-storage/pkg/types/app_errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxWebhookEvents сколько типов событий можно указать в одном webhook
+const maxWebhookEvents = 20
+
+// WebhookDeliveryStatus состояние доставки
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending ждет отправки или повтора
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed все попытки исчерпаны
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+type Webhook struct {
+	ID          int    `json:"id"`
+	WorkspaceID int    `json:"workspace_id"`
+	CreatedBy   *int   `json:"created_by"`
+	URL         string `json:"url"`
+	// Secret ключ подписи, отдается только при создании и смене ключа
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookSave создание и изменение webhook
+type WebhookSave struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// IsActive nil - при создании включен, при изменении не меняется
+	IsActive *bool `json:"is_active"`
+	// RotateSecret выпустить новый ключ подписи, прежний перестает действовать
+	RotateSecret bool `json:"rotate_secret"`
+}
+
+func (w *WebhookSave) Validate() error {
+	op := "WebhookSave.Validate"
+
+	w.URL = strings.TrimSpace(w.URL)
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return app_errors.BadRequest("Неверный адрес webhook, нужен http или https", op)
+	}
+
+	if len(w.Events) == 0 {
+		return app_errors.BadRequest("Укажите хотя бы один тип событий", op)
+	}
+
+	events := make([]string, 0, len(w.Events))
+	for _, event := range w.Events {
+		event = strings.TrimSpace(event)
+		if !IsEventPattern(event) {
+			return app_errors.BadRequest("Неизвестный тип события: "+event, op)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) > maxWebhookEvents {
+		return app_errors.BadRequest("Слишком много типов событий", op)
+	}
+	w.Events = events
+
+	return nil
+}
+
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      int                   `json:"webhook_id"`
+	EventID        string                `json:"event_id"`
+	EventType      string                `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	ResponseStatus *int                  `json:"response_status"`
+	ResponseBody   string                `json:"response_body"`
+	LastError      string                `json:"last_error"`
+	DurationMs     *int                  `json:"duration_ms"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	// URL и Secret webhook для отправки
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookDeliveryResult итог попытки доставки
+type WebhookDeliveryResult struct {
+	Status         WebhookDeliveryStatus
+	ResponseStatus *int
+	ResponseBody   string
+	Error          string
+	DurationMs     int
+	// NextAttemptAt время повтора, если Status - pending
+	NextAttemptAt time.Time
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"link-storage/pkg/types/app_errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// WorkspaceRole роль участника рабочего пространства
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+	WorkspaceRoleEditor WorkspaceRole = "editor"
+	WorkspaceRoleAdmin  WorkspaceRole = "admin"
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+)
+
+var workspaceRoleLevels = map[WorkspaceRole]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleAdmin:  3,
+	WorkspaceRoleOwner:  4,
+}
+
+// Allows дает ли роль права роли required
+func (r WorkspaceRole) Allows(required WorkspaceRole) bool {
+	return workspaceRoleLevels[r] >= workspaceRoleLevels[required]
+}
+
+// LinkGroupRole права роли пространства на его группы: admin и owner управляют группами как владельцы
+func (r WorkspaceRole) LinkGroupRole() LinkGroupRole {
+	switch r {
+	case WorkspaceRoleOwner, WorkspaceRoleAdmin:
+		return LinkGroupRoleOwner
+	case WorkspaceRoleEditor:
+		return LinkGroupRoleEditor
+	case WorkspaceRoleViewer:
+		return LinkGroupRoleViewer
+	}
+	return ""
+}
+
+// Validate роль, которую можно назначить участнику. Владелец меняется только передачей владения
+func (r WorkspaceRole) Validate() error {
+	switch r {
+	case WorkspaceRoleViewer, WorkspaceRoleEditor, WorkspaceRoleAdmin:
+		return nil
+	}
+	return app_errors.BadRequest("Неверная роль, допустимые значения: viewer, editor, admin", "WorkspaceRole.Validate")
+}
+
+type Workspace struct {
+	ID int `json:"id"`
+	// PersonalUserID владелец личного пространства, nil - командное пространство
+	PersonalUserID *int      `json:"personal_user_id"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Role роль текущего пользователя
+	Role WorkspaceRole `json:"role,omitempty"`
+}
+
+// IsPersonal личное пространство пользователя
+func (w *Workspace) IsPersonal() bool {
+	return w.PersonalUserID != nil
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int           `json:"workspace_id"`
+	UserID      int           `json:"user_id"`
+	Name        string        `json:"name"`
+	Email       string        `json:"email"`
+	Role        WorkspaceRole `json:"role"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// WorkspaceSave создание и переименование пространства
+type WorkspaceSave struct {
+	Name string `json:"name"`
+}
+
+func (w *WorkspaceSave) Validate() error {
+	op := "WorkspaceSave.Validate"
+
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return app_errors.BadRequest("Название пространства обязательно", op)
+	}
+	if utf8.RuneCountInString(w.Name) > 100 {
+		return app_errors.BadRequest("Название пространства не должно превышать 100 символов", op)
+	}
+	return nil
+}
+
+// WorkspaceMemberAdd добавление зарегистрированного пользователя по email
+type WorkspaceMemberAdd struct {
+	Email string        `json:"email"`
+	Role  WorkspaceRole `json:"role"`
+}
+
+func (m *WorkspaceMemberAdd) Validate() error {
+	m.Email = strings.ToLower(strings.TrimSpace(m.Email))
+	if m.Email == "" {
+		return app_errors.BadRequest("Email обязателен", "WorkspaceMemberAdd.Validate")
+	}
+
+	if m.Role == "" {
+		m.Role = WorkspaceRoleViewer
+	}
+	return m.Role.Validate()
+}
+
+type WorkspaceMemberSet struct {
+	Role WorkspaceRole `json:"role"`
+}
+
+func (m *WorkspaceMemberSet) Validate() error {
+	return m.Role.Validate()
+}
+
+// WorkspaceOwnerTransfer передача владения. Прежний владелец остается администратором
+type WorkspaceOwnerTransfer struct {
+	UserID int `json:"user_id"`
+}
+
+func (t *WorkspaceOwnerTransfer) Validate() error {
+	if t.UserID <= 0 {
+		return app_errors.BadRequest("Не указан новый владелец", "WorkspaceOwnerTransfer.Validate")
+	}
+	return nil
+}
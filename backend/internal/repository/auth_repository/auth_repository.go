@@ -26,6 +26,11 @@ type AuthRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByID(ctx context.Context, id int) (*models.User, error)
 	CreateSessionAndLogin(ctx context.Context, session *models.Session) error
+
+	GetAPITokens(ctx context.Context, userID int) ([]*models.APIToken, error)
+	CreateAPIToken(ctx context.Context, token *models.APIToken) error
+	DeleteAPIToken(ctx context.Context, id, userID int) error
+	GetUserByAPITokenHash(ctx context.Context, tokenHash string) (*models.User, error)
 }
 
 type authRepository struct {
@@ -165,6 +170,15 @@ func (r *authRepository) CreateUserFromRegistration(ctx context.Context, registr
         INSERT INTO users (name, email, password_hashed, is_active, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6)
         RETURNING id
+    `
+	queryCreateWorkspace := `
+        WITH w AS (
+            INSERT INTO workspaces (name, personal_user_id)
+            VALUES ($2, $1)
+            RETURNING id
+        )
+        INSERT INTO workspace_members (workspace_id, user_id, role)
+        SELECT id, $1, 'owner' FROM w
     `
 	queryDeactivateRegistration := `
         UPDATE registrations
@@ -209,13 +223,19 @@ func (r *authRepository) CreateUserFromRegistration(ctx context.Context, registr
 		return nil, app_errors.HandleDBError(err, "Создание пользователя на основании регистрации", op)
 	}
 
-	// 2. Деактивируем регистрацию
+	// 2. Создаем личное рабочее пространство
+	if _, err = tx.Exec(ctx, queryCreateWorkspace, user.ID, user.Name); err != nil {
+		r.logger.Error(err, op, "user_id", user.ID)
+		return nil, app_errors.HandleDBError(err, "Создание личного рабочего пространства", op)
+	}
+
+	// 3. Деактивируем регистрацию
 	if _, err = tx.Exec(ctx, queryDeactivateRegistration, registration.ID); err != nil {
 		r.logger.Error(err, op, "registration_id", registration.ID)
 		return nil, app_errors.HandleDBError(err, "Деактивация регистрации", op)
 	}
 
-	// 3. Коммитим транзакцию
+	// 4. Коммитим транзакцию
 	if err := tx.Commit(ctx); err != nil {
 		r.logger.Error(err, op, "user_id", user.ID)
 		return nil, app_errors.Internal(err, op)
@@ -507,3 +527,124 @@ func (r *authRepository) CreateSessionAndLogin(ctx context.Context, session *mod
 
 	return nil
 }
+
+// API TOKENS
+func (r *authRepository) GetAPITokens(ctx context.Context, userID int) ([]*models.APIToken, error) {
+	op := "auth_repository.GetAPITokens"
+
+	query := `
+        SELECT id, user_id, name, hint, last_used_at, created_at
+        FROM api_tokens
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		r.logger.Error(err, op, "user_id", userID)
+		return nil, app_errors.HandleDBError(err, "Получение токенов доступа", op)
+	}
+	defer rows.Close()
+
+	tokens := []*models.APIToken{}
+	for rows.Next() {
+		var token models.APIToken
+		if err := rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.Name,
+			&token.Hint,
+			&token.LastUsedAt,
+			&token.CreatedAt,
+		); err != nil {
+			r.logger.Error(err, op, "user_id", userID)
+			return nil, app_errors.HandleDBError(err, "Получение токенов доступа", op)
+		}
+		tokens = append(tokens, &token)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(err, op, "user_id", userID)
+		return nil, app_errors.HandleDBError(err, "Получение токенов доступа", op)
+	}
+
+	return tokens, nil
+}
+
+func (r *authRepository) CreateAPIToken(ctx context.Context, token *models.APIToken) error {
+	op := "auth_repository.CreateAPIToken"
+
+	query := `
+        INSERT INTO api_tokens (user_id, name, token_hash, hint)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `
+
+	if err := r.db.Pool.QueryRow(ctx, query,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.Hint,
+	).Scan(&token.ID, &token.CreatedAt); err != nil {
+		r.logger.Error(err, op, "user_id", token.UserID)
+		return app_errors.HandleDBError(err, "Создание токена доступа", op)
+	}
+
+	return nil
+}
+
+func (r *authRepository) DeleteAPIToken(ctx context.Context, id, userID int) error {
+	op := "auth_repository.DeleteAPIToken"
+
+	query := `DELETE FROM api_tokens WHERE id = $1 AND user_id = $2`
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		r.logger.Error(err, op, "id", id, "user_id", userID)
+		return app_errors.HandleDBError(err, "Удаление токена доступа", op)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return app_errors.NotFound("Токен не найден", op)
+	}
+
+	return nil
+}
+
+// GetUserByAPITokenHash владелец токена по его хешу, заодно отмечает использование токена
+func (r *authRepository) GetUserByAPITokenHash(ctx context.Context, tokenHash string) (*models.User, error) {
+	op := "auth_repository.GetUserByAPITokenHash"
+
+	query := `
+        WITH token AS (
+            UPDATE api_tokens
+                SET last_used_at = CURRENT_TIMESTAMP
+            WHERE token_hash = $1
+            RETURNING user_id
+        )
+        SELECT u.id, u.name, u.email, u.password_hashed, u.is_active, u.is_admin, u.last_login_at, u.created_at, u.updated_at
+        FROM users u
+        JOIN token t ON t.user_id = u.id
+    `
+
+	var user models.User
+	err := r.db.Pool.QueryRow(ctx, query, tokenHash).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.PasswordHashed,
+		&user.IsActive,
+		&user.IsAdmin,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "Получение пользователя по токену доступа", op)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,297 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// digestSettingsColumns колонки расписания дайджеста (алиас d), порядок совпадает со scanDigestSettings
+const digestSettingsColumns = `d.user_id, d.is_enabled, d.frequency, d.hour, d.weekday, d.timezone, d.unsubscribe_token,
+		       d.last_sent_at, d.next_send_at`
+
+// digestWorkspacesSQL пространства, в которых состоит пользователь $1
+const digestWorkspacesSQL = `SELECT workspace_id FROM workspace_members WHERE user_id = $1`
+
+func scanDigestSettings(row pgx.Row, settings *models.DigestSettings, extra ...any) error {
+	dest := []any{
+		&settings.UserID,
+		&settings.IsEnabled,
+		&settings.Frequency,
+		&settings.Hour,
+		&settings.Weekday,
+		&settings.Timezone,
+		&settings.UnsubscribeToken,
+		&settings.LastSentAt,
+		&settings.NextSendAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func (r *linkRepository) GetDigestSettings(ctx context.Context, userID int) (*models.DigestSettings, error) {
+	op := "link_repository.GetDigestSettings"
+
+	query := `
+		SELECT ` + digestSettingsColumns + `
+		FROM digest_settings d
+		WHERE d.user_id = $1
+	`
+
+	var settings models.DigestSettings
+	if err := scanDigestSettings(r.pool.QueryRow(ctx, query, userID), &settings); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение настроек дайджеста", op)
+	}
+	return &settings, nil
+}
+
+// SaveDigestSettings создает или обновляет расписание. Токен отписки задается только при создании
+func (r *linkRepository) SaveDigestSettings(ctx context.Context, settings *models.DigestSettings) error {
+	op := "link_repository.SaveDigestSettings"
+
+	query := `
+		INSERT INTO digest_settings (user_id, is_enabled, frequency, hour, weekday, timezone, unsubscribe_token, next_send_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE
+			SET is_enabled = EXCLUDED.is_enabled,
+			    frequency = EXCLUDED.frequency,
+			    hour = EXCLUDED.hour,
+			    weekday = EXCLUDED.weekday,
+			    timezone = EXCLUDED.timezone,
+			    next_send_at = EXCLUDED.next_send_at,
+			    updated_at = CURRENT_TIMESTAMP
+		RETURNING unsubscribe_token, last_sent_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		settings.UserID,
+		settings.IsEnabled,
+		settings.Frequency,
+		settings.Hour,
+		settings.Weekday,
+		settings.Timezone,
+		settings.UnsubscribeToken,
+		settings.NextSendAt).Scan(&settings.UnsubscribeToken, &settings.LastSentAt); err != nil {
+		return app_errors.HandleDBError(err, "сохранение настроек дайджеста", op)
+	}
+	return nil
+}
+
+// GetDueDigests до limit включенных дайджестов, время отправки которых наступило к now, с адресом получателя
+func (r *linkRepository) GetDueDigests(ctx context.Context, now time.Time, limit int) ([]*models.DigestSettings, error) {
+	op := "link_repository.GetDueDigests"
+
+	query := `
+		SELECT ` + digestSettingsColumns + `, u.email, u.name
+		FROM digest_settings d
+		JOIN users u ON u.id = d.user_id AND u.is_active = true
+		WHERE d.is_enabled = true AND
+		      d.next_send_at <= $1
+		ORDER BY d.next_send_at
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "получение дайджестов для отправки", op)
+	}
+	defer rows.Close()
+
+	digests := []*models.DigestSettings{}
+	for rows.Next() {
+		var settings models.DigestSettings
+		if err := scanDigestSettings(rows, &settings, &settings.UserEmail, &settings.UserName); err != nil {
+			return nil, app_errors.HandleDBError(err, "получение дайджестов для отправки", op)
+		}
+		digests = append(digests, &settings)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение дайджестов для отправки", op)
+	}
+	return digests, nil
+}
+
+// ClaimDigest переносит отправку с sendAt на nextSendAt. false - дайджест уже забрал другой экземпляр
+// приложения или пользователь изменил расписание
+func (r *linkRepository) ClaimDigest(ctx context.Context, userID int, sendAt, nextSendAt time.Time) (bool, error) {
+	op := "link_repository.ClaimDigest"
+
+	query := `
+		UPDATE digest_settings
+			SET next_send_at = $3
+		WHERE user_id = $1 AND
+		      is_enabled = true AND
+		      next_send_at = $2
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, sendAt, nextSendAt)
+	if err != nil {
+		return false, app_errors.HandleDBError(err, "перенос отправки дайджеста", op)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// SetDigestSent отмечает отправку: следующий дайджест соберется с sentAt
+func (r *linkRepository) SetDigestSent(ctx context.Context, userID int, sentAt time.Time) error {
+	op := "link_repository.SetDigestSent"
+
+	query := `
+		UPDATE digest_settings
+			SET last_sent_at = $2
+		WHERE user_id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, userID, sentAt); err != nil {
+		return app_errors.HandleDBError(err, "отметка отправки дайджеста", op)
+	}
+	return nil
+}
+
+// UnsubscribeDigest выключает дайджест по токену отписки из письма
+func (r *linkRepository) UnsubscribeDigest(ctx context.Context, token string) error {
+	op := "link_repository.UnsubscribeDigest"
+
+	query := `
+		UPDATE digest_settings
+			SET is_enabled = false,
+			    next_send_at = NULL,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE unsubscribe_token = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, token)
+	if err != nil {
+		return app_errors.HandleDBError(err, "отписка от дайджеста", op)
+	}
+
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Подписка на дайджест не найдена", op)
+	}
+	return nil
+}
+
+// GetDigestSharedLinks ссылки, которые другие участники добавили с since в группы, общие с пользователем
+func (r *linkRepository) GetDigestSharedLinks(ctx context.Context, userID int, since time.Time, limit int) ([]*models.DigestLink, error) {
+	query := `
+		SELECT l.id, l.url, l.title, g.name, l.reading_minutes, 0
+		FROM links l
+		JOIN link_group_members m ON m.link_group_id = l.link_group_id AND m.user_id = $1
+		JOIN link_groups g ON g.id = l.link_group_id AND g.deleted_at IS NULL
+		WHERE l.user_id <> $1 AND
+		      l.created_at >= $2 AND
+		      l.deleted_at IS NULL
+		ORDER BY l.created_at DESC
+		LIMIT $3
+	`
+
+	return r.getDigestLinks(ctx, "link_repository.GetDigestSharedLinks", query, userID, since, limit)
+}
+
+// GetDigestUnreadLinks последние непрочитанные ссылки, сохраненные пользователем, и их общее количество
+func (r *linkRepository) GetDigestUnreadLinks(ctx context.Context, userID, limit int) ([]*models.DigestLink, int, error) {
+	op := "link_repository.GetDigestUnreadLinks"
+
+	where := `
+		WHERE l.user_id = $1 AND
+		      l.workspace_id IN (` + digestWorkspacesSQL + `) AND
+		      l.read_at IS NULL AND
+		      l.is_archived = false AND
+		      l.deleted_at IS NULL
+	`
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM links l`+where, userID).Scan(&total); err != nil {
+		return nil, 0, app_errors.HandleDBError(err, "получение непрочитанных ссылок", op)
+	}
+
+	query := `
+		SELECT l.id, l.url, l.title, g.name, l.reading_minutes, 0
+		FROM links l
+		LEFT JOIN link_groups g ON g.id = l.link_group_id AND g.deleted_at IS NULL
+	` + where + `
+		ORDER BY l.created_at DESC
+		LIMIT $2
+	`
+
+	links, err := r.getDigestLinks(ctx, op, query, userID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
+
+// GetDigestBrokenLinks ссылки пространств пользователя, которые перестали открываться с since
+func (r *linkRepository) GetDigestBrokenLinks(ctx context.Context, userID int, since time.Time, limit int) ([]*models.DigestLink, error) {
+	query := `
+		SELECT l.id, l.url, l.title, g.name, l.reading_minutes, 0
+		FROM links l
+		LEFT JOIN link_groups g ON g.id = l.link_group_id AND g.deleted_at IS NULL
+		WHERE l.workspace_id IN (` + digestWorkspacesSQL + `) AND
+		      l.broken_at >= $2 AND
+		      l.deleted_at IS NULL
+		ORDER BY l.broken_at DESC
+		LIMIT $3
+	`
+
+	return r.getDigestLinks(ctx, "link_repository.GetDigestBrokenLinks", query, userID, since, limit)
+}
+
+// GetDigestTopVisited самые посещаемые с since ссылки пространств пользователя
+func (r *linkRepository) GetDigestTopVisited(ctx context.Context, userID int, since time.Time, limit int) ([]*models.DigestLink, error) {
+	// Сырые события за период плюс дневные агрегаты уже свернутых событий, как в GetLinksTopVisited
+	query := `
+		WITH workspace_links AS (
+			SELECT id FROM links WHERE workspace_id IN (` + digestWorkspacesSQL + `)
+		)
+		SELECT l.id, l.url, l.title, g.name, l.reading_minutes, v.visits
+		FROM links l
+		JOIN (
+			SELECT link_id, SUM(visits) AS visits
+			FROM (
+				SELECT link_id, COUNT(*) AS visits
+				FROM link_visits
+				WHERE link_id IN (SELECT id FROM workspace_links) AND visited_at >= $2
+				GROUP BY link_id
+				UNION ALL
+				SELECT link_id, SUM(visits) AS visits
+				FROM link_visits_daily
+				WHERE link_id IN (SELECT id FROM workspace_links) AND day >= $2::date
+				GROUP BY link_id
+			) s
+			GROUP BY link_id
+		) v ON v.link_id = l.id
+		LEFT JOIN link_groups g ON g.id = l.link_group_id AND g.deleted_at IS NULL
+		WHERE l.is_archived = false AND
+		      l.deleted_at IS NULL
+		ORDER BY v.visits DESC, l.title ASC
+		LIMIT $3
+	`
+
+	return r.getDigestLinks(ctx, "link_repository.GetDigestTopVisited", query, userID, since, limit)
+}
+
+func (r *linkRepository) getDigestLinks(ctx context.Context, op, query string, args ...any) ([]*models.DigestLink, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение ссылок дайджеста", op)
+	}
+	defer rows.Close()
+
+	links := []*models.DigestLink{}
+	for rows.Next() {
+		var link models.DigestLink
+		if err := rows.Scan(&link.ID, &link.URL, &link.Title, &link.LinkGroupName, &link.ReadingMinutes, &link.Visits); err != nil {
+			return nil, app_errors.HandleDBError(err, "получение ссылок дайджеста", op)
+		}
+		links = append(links, &link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение ссылок дайджеста", op)
+	}
+	return links, nil
+}
@@ -0,0 +1,232 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const feedTokenColumns = `f.id, f.user_id, f.link_group_id, f.tag_id, f.token, f.created_at`
+
+func scanFeedToken(row pgx.Row, feedToken *models.FeedToken) error {
+	return row.Scan(
+		&feedToken.ID,
+		&feedToken.UserID,
+		&feedToken.LinkGroupID,
+		&feedToken.TagID,
+		&feedToken.Token,
+		&feedToken.CreatedAt,
+	)
+}
+
+// GetTagByID тег пространства без удаленных
+func (r *linkRepository) GetTagByID(ctx context.Context, id, workspaceID int) (*models.Tag, error) {
+	op := "link_repository.GetTagByID"
+
+	query := `
+		SELECT id, user_id, workspace_id, name, COALESCE(color, ''), created_at, updated_at
+		FROM tags
+		WHERE id = $1 AND
+		      workspace_id = $2 AND
+		      deleted_at IS NULL
+	`
+
+	var tag models.Tag
+	if err := r.pool.QueryRow(ctx, query, id, workspaceID).Scan(
+		&tag.ID,
+		&tag.UserID,
+		&tag.WorkspaceID,
+		&tag.Name,
+		&tag.Color,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение тега", op)
+	}
+	return &tag, nil
+}
+
+// GetTagForUser тег, доступный пользователю как участнику пространства тега
+func (r *linkRepository) GetTagForUser(ctx context.Context, id, userID int) (*models.Tag, error) {
+	op := "link_repository.GetTagForUser"
+
+	query := `
+		SELECT t.workspace_id
+		FROM tags t
+		JOIN workspace_members wm ON wm.workspace_id = t.workspace_id AND wm.user_id = $2
+		WHERE t.id = $1 AND
+		      t.deleted_at IS NULL
+	`
+
+	var workspaceID int
+	if err := r.pool.QueryRow(ctx, query, id, userID).Scan(&workspaceID); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение тега", op)
+	}
+	return r.GetTagByID(ctx, id, workspaceID)
+}
+
+// feedSourceCondition условие на ссылку l из источника ленты и его параметр
+func feedSourceCondition(source models.FeedSource, param string) (string, any) {
+	if source.LinkGroupID != nil {
+		return `l.link_group_id IN (` + subgroupIDsQuery(param) + `)`, *source.LinkGroupID
+	}
+	return `EXISTS (SELECT 1 FROM link_tags lt WHERE lt.link_id = l.id AND lt.tag_id = ` + param + `)`, *source.TagID
+}
+
+// feedTokenSourceCondition условие на токен f по источнику ленты и его параметр
+func feedTokenSourceCondition(source models.FeedSource, param string) (string, any) {
+	if source.LinkGroupID != nil {
+		return `f.link_group_id = ` + param, *source.LinkGroupID
+	}
+	return `f.tag_id = ` + param, *source.TagID
+}
+
+func (r *linkRepository) GetFeedToken(ctx context.Context, userID int, source models.FeedSource) (*models.FeedToken, error) {
+	op := "link_repository.GetFeedToken"
+
+	condition, sourceID := feedTokenSourceCondition(source, "$2")
+	query := `
+		SELECT ` + feedTokenColumns + `
+		FROM feed_tokens f
+		WHERE f.user_id = $1 AND
+		      ` + condition
+
+	var feedToken models.FeedToken
+	if err := scanFeedToken(r.pool.QueryRow(ctx, query, userID, sourceID), &feedToken); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение токена ленты", op)
+	}
+	return &feedToken, nil
+}
+
+func (r *linkRepository) GetFeedTokenByToken(ctx context.Context, token string) (*models.FeedToken, error) {
+	op := "link_repository.GetFeedTokenByToken"
+
+	query := `
+		SELECT ` + feedTokenColumns + `
+		FROM feed_tokens f
+		JOIN users u ON u.id = f.user_id
+		WHERE f.token = $1 AND
+		      u.is_active
+	`
+
+	var feedToken models.FeedToken
+	if err := scanFeedToken(r.pool.QueryRow(ctx, query, token), &feedToken); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение токена ленты", op)
+	}
+	return &feedToken, nil
+}
+
+// CreateFeedToken выпускает токен ленты. Прежний токен пользователя на тот же источник перестает действовать
+func (r *linkRepository) CreateFeedToken(ctx context.Context, feedToken *models.FeedToken) error {
+	op := "link_repository.CreateFeedToken"
+
+	condition, sourceID := feedTokenSourceCondition(models.FeedSource{LinkGroupID: feedToken.LinkGroupID, TagID: feedToken.TagID}, "$2")
+	queryDelete := `
+		DELETE FROM feed_tokens f
+		WHERE f.user_id = $1 AND
+		      ` + condition
+
+	query := `
+		INSERT INTO feed_tokens (user_id, link_group_id, tag_id, token)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание токена ленты", op)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, queryDelete, feedToken.UserID, sourceID); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание токена ленты", op)
+	}
+
+	if err := tx.QueryRow(ctx, query,
+		feedToken.UserID,
+		feedToken.LinkGroupID,
+		feedToken.TagID,
+		feedToken.Token).Scan(&feedToken.ID, &feedToken.CreatedAt); err != nil {
+		return app_errors.HandleDBError(err, "создание токена ленты", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "создание токена ленты", op)
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteFeedToken(ctx context.Context, userID int, source models.FeedSource) error {
+	op := "link_repository.DeleteFeedToken"
+
+	condition, sourceID := feedTokenSourceCondition(source, "$2")
+	query := `
+		DELETE FROM feed_tokens f
+		WHERE f.user_id = $1 AND
+		      ` + condition
+
+	result, err := r.pool.Exec(ctx, query, userID, sourceID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление токена ленты", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Лента не найдена", op)
+	}
+	return nil
+}
+
+// GetFeedItems последние limit ссылок источника по дате добавления вместе с именами тегов
+func (r *linkRepository) GetFeedItems(ctx context.Context, source models.FeedSource, limit int) ([]*models.FeedItem, error) {
+	op := "link_repository.GetFeedItems"
+
+	condition, sourceID := feedSourceCondition(source, "$1")
+	query := `
+		SELECT l.id, l.url, l.title, l.description, COALESCE(l.preview_image, ''),
+		       ARRAY(
+		           SELECT t.name
+		           FROM link_tags lt
+		           JOIN tags t ON t.id = lt.tag_id
+		           WHERE lt.link_id = l.id AND
+		                 t.deleted_at IS NULL
+		           ORDER BY t.name
+		       ),
+		       l.created_at, l.updated_at
+		FROM links l
+		WHERE ` + condition + ` AND
+		      l.deleted_at IS NULL
+		ORDER BY l.created_at DESC, l.id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, sourceID, limit)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение ленты", op)
+	}
+	defer rows.Close()
+
+	items := []*models.FeedItem{}
+
+	for rows.Next() {
+		var item models.FeedItem
+		if err := rows.Scan(
+			&item.LinkID,
+			&item.URL,
+			&item.Title,
+			&item.Description,
+			&item.PreviewImage,
+			&item.Tags,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение ленты", op)
+		}
+		items = append(items, &item)
+	}
+	return items, nil
+}
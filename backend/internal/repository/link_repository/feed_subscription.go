@@ -0,0 +1,198 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const feedSubscriptionColumns = `s.id, s.link_group_id, s.user_id, s.url, s.title, s.etag, s.last_modified,
+		       s.last_fetched_at, s.last_success_at, s.last_status, s.last_error, s.error_count, s.next_fetch_at,
+		       s.created_at, s.updated_at, g.workspace_id`
+
+func scanFeedSubscription(row pgx.Row, subscription *models.FeedSubscription) error {
+	return row.Scan(
+		&subscription.ID,
+		&subscription.LinkGroupID,
+		&subscription.UserID,
+		&subscription.URL,
+		&subscription.Title,
+		&subscription.ETag,
+		&subscription.LastModified,
+		&subscription.LastFetchedAt,
+		&subscription.LastSuccessAt,
+		&subscription.LastStatus,
+		&subscription.LastError,
+		&subscription.ErrorCount,
+		&subscription.NextFetchAt,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+		&subscription.WorkspaceID,
+	)
+}
+
+func (r *linkRepository) queryFeedSubscriptions(ctx context.Context, op, query string, args ...any) ([]*models.FeedSubscription, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение подписок на ленты", op)
+	}
+	defer rows.Close()
+
+	subscriptions := []*models.FeedSubscription{}
+
+	for rows.Next() {
+		var subscription models.FeedSubscription
+		if err := scanFeedSubscription(rows, &subscription); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение подписок на ленты", op)
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+	return subscriptions, nil
+}
+
+func (r *linkRepository) GetFeedSubscriptions(ctx context.Context, linkGroupID int) ([]*models.FeedSubscription, error) {
+	op := "link_repository.GetFeedSubscriptions"
+
+	query := `
+		SELECT ` + feedSubscriptionColumns + `
+		FROM feed_subscriptions s
+		JOIN link_groups g ON g.id = s.link_group_id
+		WHERE s.link_group_id = $1
+		ORDER BY s.created_at, s.id
+	`
+
+	return r.queryFeedSubscriptions(ctx, op, query, linkGroupID)
+}
+
+func (r *linkRepository) GetFeedSubscription(ctx context.Context, id, linkGroupID int) (*models.FeedSubscription, error) {
+	op := "link_repository.GetFeedSubscription"
+
+	query := `
+		SELECT ` + feedSubscriptionColumns + `
+		FROM feed_subscriptions s
+		JOIN link_groups g ON g.id = s.link_group_id
+		WHERE s.id = $1 AND
+		      s.link_group_id = $2
+	`
+
+	var subscription models.FeedSubscription
+	if err := scanFeedSubscription(r.pool.QueryRow(ctx, query, id, linkGroupID), &subscription); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение подписки на ленту", op)
+	}
+	return &subscription, nil
+}
+
+// GetFeedSubscriptionsForPoll подписки, которым пора загружать ленту, кроме подписок групп из корзины
+func (r *linkRepository) GetFeedSubscriptionsForPoll(ctx context.Context, now time.Time, limit int) ([]*models.FeedSubscription, error) {
+	op := "link_repository.GetFeedSubscriptionsForPoll"
+
+	query := `
+		SELECT ` + feedSubscriptionColumns + `
+		FROM feed_subscriptions s
+		JOIN link_groups g ON g.id = s.link_group_id
+		WHERE s.next_fetch_at <= $1 AND
+		      g.deleted_at IS NULL
+		ORDER BY s.next_fetch_at
+		LIMIT $2
+	`
+
+	return r.queryFeedSubscriptions(ctx, op, query, now, limit)
+}
+
+func (r *linkRepository) CreateFeedSubscription(ctx context.Context, subscription *models.FeedSubscription) error {
+	op := "link_repository.CreateFeedSubscription"
+
+	query := `
+		INSERT INTO feed_subscriptions (link_group_id, user_id, url)
+		VALUES ($1, $2, $3)
+		RETURNING id, next_fetch_at, created_at, updated_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		subscription.LinkGroupID,
+		subscription.UserID,
+		subscription.URL).Scan(&subscription.ID, &subscription.NextFetchAt, &subscription.CreatedAt, &subscription.UpdatedAt); err != nil {
+		return app_errors.HandleDBError(err, "создание подписки на ленту", op)
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteFeedSubscription(ctx context.Context, id, linkGroupID int) error {
+	op := "link_repository.DeleteFeedSubscription"
+
+	result, err := r.pool.Exec(ctx, `DELETE FROM feed_subscriptions WHERE id = $1 AND link_group_id = $2`, id, linkGroupID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление подписки на ленту", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Подписка не найдена", op)
+	}
+	return nil
+}
+
+// SetFeedFetchResult сохраняет результат загрузки. Ошибка увеличивает счетчик ошибок подряд, успех сбрасывает его
+func (r *linkRepository) SetFeedFetchResult(ctx context.Context, id int, result *models.FeedFetchResult) error {
+	op := "link_repository.SetFeedFetchResult"
+
+	query := `
+		UPDATE feed_subscriptions
+			SET title = CASE WHEN $2 = '' THEN title ELSE $2 END,
+			    etag = CASE WHEN $6 = '' THEN $3 ELSE etag END,
+			    last_modified = CASE WHEN $6 = '' THEN $4 ELSE last_modified END,
+			    last_status = $5,
+			    last_error = $6,
+			    last_fetched_at = CURRENT_TIMESTAMP,
+			    last_success_at = CASE WHEN $6 = '' THEN CURRENT_TIMESTAMP ELSE last_success_at END,
+			    error_count = CASE WHEN $6 = '' THEN 0 ELSE error_count + 1 END,
+			    next_fetch_at = $7,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query,
+		id,
+		result.Title,
+		result.ETag,
+		result.LastModified,
+		result.Status,
+		result.Error,
+		result.NextFetchAt); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "сохранение результата загрузки ленты", op)
+	}
+	return nil
+}
+
+// HasFeedSubscriptionItem обработана ли уже запись ленты с таким GUID
+func (r *linkRepository) HasFeedSubscriptionItem(ctx context.Context, subscriptionID int, guid string) (bool, error) {
+	op := "link_repository.HasFeedSubscriptionItem"
+
+	query := `SELECT EXISTS (SELECT 1 FROM feed_subscription_items WHERE subscription_id = $1 AND guid = $2)`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, subscriptionID, guid).Scan(&exists); err != nil {
+		return false, app_errors.HandleDBError(err, "проверка записи ленты", op)
+	}
+	return exists, nil
+}
+
+// AddFeedSubscriptionItem отмечает запись ленты обработанной, linkID - созданная или найденная ссылка
+func (r *linkRepository) AddFeedSubscriptionItem(ctx context.Context, subscriptionID int, guid string, linkID *int) error {
+	op := "link_repository.AddFeedSubscriptionItem"
+
+	query := `
+		INSERT INTO feed_subscription_items (subscription_id, guid, link_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.pool.Exec(ctx, query, subscriptionID, guid, linkID); err != nil {
+		return app_errors.HandleDBError(err, "сохранение записи ленты", op)
+	}
+	return nil
+}
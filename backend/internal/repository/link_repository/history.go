@@ -0,0 +1,205 @@
+package link_repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryInsertHistory запись истории, changes - JSON с изменениями по полям
+const queryInsertHistory = `
+	INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+	VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+// linkHistoryFields поля ссылки, изменения которых попадают в историю. Ключи совпадают с json-тегами моделей
+func linkHistoryFields(link *models.Link) map[string]any {
+	return map[string]any{
+		"url":             link.URL,
+		"title":           link.Title,
+		"description":     link.Description,
+		"link_group_id":   link.LinkGroupID,
+		"is_archived":     link.IsArchived,
+		"is_favorite":     link.IsFavorite,
+		"slug":            link.Slug,
+		"slug_expires_at": link.SlugExpiresAt,
+		"slug_is_public":  link.SlugIsPublic,
+	}
+}
+
+// linkGroupHistoryFields поля группы, изменения которых попадают в историю
+func linkGroupHistoryFields(linkGroup *models.LinkGroup) map[string]any {
+	return map[string]any{
+		"name":        linkGroup.Name,
+		"description": linkGroup.Description,
+		"color":       linkGroup.Color,
+		"parent_id":   linkGroup.ParentID,
+	}
+}
+
+// tagHistoryFields поля тега, изменения которых попадают в историю
+func tagHistoryFields(tag *models.Tag) map[string]any {
+	return map[string]any{
+		"name":  tag.Name,
+		"color": tag.Color,
+	}
+}
+
+// diffHistoryFields изменившиеся поля. before или after nil - создание или удаление всех полей
+func diffHistoryFields(before, after map[string]any) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+
+	keys := before
+	if keys == nil {
+		keys = after
+	}
+
+	for key := range keys {
+		change := models.FieldChange{Old: before[key], New: after[key]}
+
+		// Сравниваем в JSON-представлении: так же значения лягут в историю
+		oldJSON, _ := json.Marshal(change.Old)
+		newJSON, _ := json.Marshal(change.New)
+		if !bytes.Equal(oldJSON, newJSON) {
+			changes[key] = change
+		}
+	}
+	return changes
+}
+
+// deletedAtChange изменение отметки корзины для удаления и восстановления
+func deletedAtChange(old, new *time.Time) map[string]models.FieldChange {
+	return map[string]models.FieldChange{"deleted_at": {Old: old, New: new}}
+}
+
+// writeHistory записывает изменение в той же транзакции, что и само изменение. Пустое обновление не пишется
+func writeHistory(ctx context.Context, tx pgx.Tx, entityType models.EntityType, entityID, userID int, action models.HistoryAction, changes map[string]models.FieldChange) error {
+	if len(changes) == 0 && action == models.HistoryUpdate {
+		return nil
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, queryInsertHistory, entityType, entityID, userID, models.HistoryActorFromContext(ctx), action, changesJSON)
+	return err
+}
+
+// GetEntityHistory история сущности пользователя по возрастанию
+func (r *linkRepository) GetEntityHistory(ctx context.Context, entityType models.EntityType, entityID, userID int) ([]*models.HistoryEntry, error) {
+	op := "link_repository.GetEntityHistory"
+
+	query := `
+		SELECT h.id, h.entity_type, h.entity_id, h.user_id, h.actor_id, u.name, h.action, h.changes, h.created_at
+		FROM entity_history h
+		LEFT JOIN users u ON u.id = h.actor_id
+		WHERE h.entity_type = $1 AND
+		      h.entity_id = $2 AND
+		      h.user_id = $3
+		ORDER BY h.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, entityType, entityID, userID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение истории изменений", op)
+	}
+	defer rows.Close()
+
+	var entries []*models.HistoryEntry
+
+	for rows.Next() {
+		var entry models.HistoryEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.UserID,
+			&entry.ActorID,
+			&entry.ActorName,
+			&entry.Action,
+			&entry.Changes,
+			&entry.CreatedAt); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение истории изменений", op)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// lockLink блокирует ссылку до конца транзакции и возвращает ее текущее состояние
+func lockLink(ctx context.Context, tx pgx.Tx, linkID int) (*models.Link, error) {
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.id = $1 AND
+		      l.deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var link models.Link
+	if err := scanLink(tx.QueryRow(ctx, query, linkID), &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// updateLinkWithHistory выполняет обновление ссылки query и записывает изменившиеся поля в историю
+func (r *linkRepository) updateLinkWithHistory(ctx context.Context, op, what string, action models.HistoryAction, linkID int, query string, args ...any) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, what, op)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockLink(ctx, tx, linkID)
+	if err != nil {
+		return app_errors.HandleDBError(err, what, op)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return app_errors.HandleDBError(err, what, op)
+	}
+
+	after, err := lockLink(ctx, tx, linkID)
+	if err != nil {
+		return app_errors.HandleDBError(err, what, op)
+	}
+
+	changes := diffHistoryFields(linkHistoryFields(before), linkHistoryFields(after))
+	if err := writeHistory(ctx, tx, models.EntityLink, linkID, before.UserID, action, changes); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, what, op)
+	}
+	return nil
+}
+
+// lockLinkGroup блокирует группу до конца транзакции и возвращает ее текущее состояние
+func lockLinkGroup(ctx context.Context, tx pgx.Tx, id int) (*models.LinkGroup, error) {
+	query := `
+		SELECT ` + linkGroupColumns + `
+		FROM link_groups
+		WHERE id = $1 AND
+		      deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var linkGroup models.LinkGroup
+	if err := scanLinkGroup(tx.QueryRow(ctx, query, id), &linkGroup); err != nil {
+		return nil, err
+	}
+	return &linkGroup, nil
+}
@@ -0,0 +1,100 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+)
+
+func (r *linkRepository) GetInboundEmail(ctx context.Context, userID, workspaceID int) (*models.InboundEmail, error) {
+	op := "link_repository.GetInboundEmail"
+
+	query := `
+		SELECT id, user_id, workspace_id, token, created_at
+		FROM inbound_email_tokens
+		WHERE user_id = $1 AND
+		      workspace_id = $2
+	`
+
+	var inbox models.InboundEmail
+	if err := r.pool.QueryRow(ctx, query, userID, workspaceID).Scan(
+		&inbox.ID,
+		&inbox.UserID,
+		&inbox.WorkspaceID,
+		&inbox.Token,
+		&inbox.CreatedAt,
+	); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение адреса для писем", op)
+	}
+	return &inbox, nil
+}
+
+// GetInboundEmailByToken адрес по токену с ролью пользователя в пространстве. Пользователь вышел
+// из пространства или заблокирован - NotFound
+func (r *linkRepository) GetInboundEmailByToken(ctx context.Context, token string) (*models.InboundEmail, error) {
+	op := "link_repository.GetInboundEmailByToken"
+
+	query := `
+		SELECT t.id, t.user_id, t.workspace_id, t.token, t.created_at, wm.role
+		FROM inbound_email_tokens t
+		JOIN users u ON u.id = t.user_id
+		JOIN workspace_members wm ON wm.workspace_id = t.workspace_id AND wm.user_id = t.user_id
+		WHERE t.token = $1 AND
+		      u.is_active
+	`
+
+	var inbox models.InboundEmail
+	if err := r.pool.QueryRow(ctx, query, token).Scan(
+		&inbox.ID,
+		&inbox.UserID,
+		&inbox.WorkspaceID,
+		&inbox.Token,
+		&inbox.CreatedAt,
+		&inbox.Role,
+	); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение адреса для писем", op)
+	}
+	return &inbox, nil
+}
+
+// SaveInboundEmail выпускает адрес, прежний адрес пользователя в пространстве перестает действовать
+func (r *linkRepository) SaveInboundEmail(ctx context.Context, inbox *models.InboundEmail) error {
+	op := "link_repository.SaveInboundEmail"
+
+	query := `
+		INSERT INTO inbound_email_tokens (user_id, workspace_id, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, workspace_id) DO UPDATE
+			SET token = EXCLUDED.token,
+			    created_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		inbox.UserID,
+		inbox.WorkspaceID,
+		inbox.Token).Scan(&inbox.ID, &inbox.CreatedAt); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание адреса для писем", op)
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteInboundEmail(ctx context.Context, userID, workspaceID int) error {
+	op := "link_repository.DeleteInboundEmail"
+
+	query := `
+		DELETE FROM inbound_email_tokens
+		WHERE user_id = $1 AND
+		      workspace_id = $2
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, workspaceID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление адреса для писем", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Адрес для писем не найден", op)
+	}
+	return nil
+}
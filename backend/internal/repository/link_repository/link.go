@@ -7,10 +7,58 @@ import (
 	"link-storage/internal/models"
 	"link-storage/pkg/response"
 	"link-storage/pkg/types/app_errors"
+	"slices"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
-func (r *linkRepository) CreateLink(ctx context.Context, link *models.Link) error {
+// linkColumns колонки ссылки (алиас l), порядок совпадает со scanLink
+const linkColumns = `l.id, l.user_id, l.workspace_id, l.link_group_id, l.url, l.canonical_url, l.title, l.description, l.favicon_url, l.preview_image,
+		       l.is_archived, l.is_favorite, l.click_count, l.last_visited, l.frecency,
+		       l.slug, l.slug_expires_at, l.slug_is_public, l.http_status, l.last_checked_at, l.final_url, l.check_failures,
+		       l.read_at, l.reading_minutes, l.remind_at, l.remind_user_id,
+		       l.position, l.created_at, l.updated_at`
+
+// scanLink сканирует колонки linkColumns в link, extra - дополнительные колонки после них
+func scanLink(row pgx.Row, link *models.Link, extra ...any) error {
+	dest := []any{
+		&link.ID,
+		&link.UserID,
+		&link.WorkspaceID,
+		&link.LinkGroupID,
+		&link.URL,
+		&link.CanonicalURL,
+		&link.Title,
+		&link.Description,
+		&link.FaviconURL,
+		&link.PreviewImage,
+		&link.IsArchived,
+		&link.IsFavorite,
+		&link.ClickCount,
+		&link.LastVisited,
+		&link.Frecency,
+		&link.Slug,
+		&link.SlugExpiresAt,
+		&link.SlugIsPublic,
+		&link.HTTPStatus,
+		&link.LastCheckedAt,
+		&link.FinalURL,
+		&link.CheckFailures,
+		&link.ReadAt,
+		&link.ReadingMinutes,
+		&link.RemindAt,
+		&link.RemindUserID,
+		&link.Position,
+		&link.CreatedAt,
+		&link.UpdatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// CreateLink создает ссылку в конце группы и сразу добавляет ей теги tagIDs той же транзакцией.
+// Тег другого пространства или удаленный - NotFound, ссылка не создается
+func (r *linkRepository) CreateLink(ctx context.Context, link *models.Link, tagIDs []int) error {
 	op := "link_repository.CreateLink"
 
 	now := time.Now()
@@ -18,52 +66,103 @@ func (r *linkRepository) CreateLink(ctx context.Context, link *models.Link) erro
 	link.UpdatedAt = now
 
 	query := `
-		INSERT INTO links (user_id, link_group_id, url, title, description, is_archived, is_favorite, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id
+		INSERT INTO links (user_id, workspace_id, link_group_id, url, canonical_url, title, description, is_archived, is_favorite, position, created_at, updated_at)
+		VALUES ($1, $12, $2, $3, $4, $5, $6, $7, $8,
+		        (SELECT COALESCE(MAX(position), 0) + $11 FROM links WHERE workspace_id = $12 AND link_group_id IS NOT DISTINCT FROM $2 AND deleted_at IS NULL),
+		        $9, $10)
+		RETURNING id, position
 	`
 
-	if err := r.pool.QueryRow(ctx, query, link.UserID,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return app_errors.HandleDBError(err, "Создание ссылки", op)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, query, link.UserID,
 		link.LinkGroupID,
 		link.URL,
+		link.CanonicalURL,
 		link.Title,
 		link.Description,
 		link.IsArchived,
 		link.IsFavorite,
 		link.CreatedAt,
-		link.UpdatedAt).Scan(&link.ID); err != nil {
+		link.UpdatedAt,
+		positionGap,
+		link.WorkspaceID).Scan(&link.ID, &link.Position); err != nil {
+		return app_errors.HandleDBError(err, "Создание ссылки", op)
+	}
+
+	changes := diffHistoryFields(nil, linkHistoryFields(link))
+	if err := writeHistory(ctx, tx, models.EntityLink, link.ID, link.UserID, models.HistoryCreate, changes); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if len(tagIDs) > 0 {
+		if err := bulkAddTags(ctx, tx, []int{link.ID}, link.WorkspaceID, tagIDs, op); err != nil {
+			if app_errors.IsNotFound(err) {
+				return err
+			}
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "добавление тегов ссылки", op)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return app_errors.HandleDBError(err, "Создание ссылки", op)
 	}
 	return nil
 }
 
+// UpdateLink обновляет редактируемые поля ссылки. При смене группы ссылка встает в конец новой группы
+func (r *linkRepository) UpdateLink(ctx context.Context, link *models.Link, action models.HistoryAction) error {
+	op := "link_repository.UpdateLink"
+
+	query := `
+		UPDATE links l
+			SET url = $2,
+			    canonical_url = $3,
+			    title = $4,
+			    description = $5,
+			    link_group_id = $6,
+			    is_archived = $7,
+			    is_favorite = $8,
+			    position = CASE
+			        WHEN l.link_group_id IS NOT DISTINCT FROM $6 THEN l.position
+			        ELSE (SELECT COALESCE(MAX(position), 0) + $9 FROM links WHERE workspace_id = l.workspace_id AND link_group_id IS NOT DISTINCT FROM $6 AND deleted_at IS NULL)
+			    END,
+			    orphaned_from_group_id = CASE WHEN l.link_group_id IS NOT DISTINCT FROM $6 THEN l.orphaned_from_group_id END,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE l.id = $1
+	`
+
+	return r.updateLinkWithHistory(ctx, op, "Обновление ссылки", action, link.ID, query,
+		link.ID,
+		link.URL,
+		link.CanonicalURL,
+		link.Title,
+		link.Description,
+		link.LinkGroupID,
+		link.IsArchived,
+		link.IsFavorite,
+		positionGap)
+}
+
 func (r *linkRepository) GetLinkByID(ctx context.Context, linkID int) (*models.Link, error) {
 	op := "link_repository.GetLinkByID"
 
 	query := `
-		SELECT id, user_id, link_group_id, url, title, description, favicon_url, preview_image, is_archived, is_favorite,
-		       click_count, last_visited, created_at, updated_at
-		FROM links
-		WHERE id = $1
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.id = $1 AND
+		      l.deleted_at IS NULL
 	`
 
 	var link models.Link
 
-	if err := r.pool.QueryRow(ctx, query, linkID).Scan(
-		&link.ID,
-		&link.UserID,
-		&link.LinkGroupID,
-		&link.URL,
-		&link.Title,
-		&link.Description,
-		&link.FaviconURL,
-		&link.PreviewImage,
-		&link.IsArchived,
-		&link.IsFavorite,
-		&link.ClickCount,
-		&link.LastVisited,
-		&link.CreatedAt,
-		&link.UpdatedAt); err != nil {
+	if err := scanLink(r.pool.QueryRow(ctx, query, linkID), &link); err != nil {
 		return nil, app_errors.HandleDBError(err, "Получение ссылки по ID", op)
 	}
 
@@ -78,52 +177,69 @@ func (r *linkRepository) SetLinkFavIconAndTitle(ctx context.Context, linkID int,
 			SET favicon_url = $1,
 			    title = $2,
 			    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
+		WHERE id = $3 AND
+		      deleted_at IS NULL
 	`
 
-	result, err := r.pool.Exec(ctx, query, favIconPath, title, linkID)
-	if err != nil {
-		return app_errors.HandleDBError(err, "Установка favicon для ссылки", op)
-	}
-	if result.RowsAffected() == 0 {
-		return app_errors.NotFound("Ссылка не найдена", op)
-	}
-	return nil
+	return r.updateLinkWithHistory(ctx, op, "Установка favicon для ссылки", models.HistoryUpdate, linkID, query, favIconPath, title, linkID)
 }
 
-func (r *linkRepository) GetLinksByUserIDWithPagination(ctx context.Context, userID, linkGroupID, limit, offset int, name string) (*response.ListResponse[models.LinkResponse], error) {
-	op := "link_repository.GetLinksByUserIDWithPagination"
+func (r *linkRepository) GetLinksByWorkspaceIDWithPagination(ctx context.Context, workspaceID int, filter models.LinkFilter, limit, offset int) (*response.ListResponse[models.LinkResponse], error) {
+	op := "link_repository.GetLinksByWorkspaceIDWithPagination"
 
-	query := `
-		SELECT l.id, l.user_id, l.link_group_id, l.url, l.title, l.description, l.favicon_url, l.preview_image, l.is_archived, l.is_favorite,
-			   l.click_count, l.last_visited, l.created_at, l.updated_at, g.id, g.name
-		FROM links l LEFT JOIN link_groups g ON l.link_group_id = g.id
-		WHERE l.user_id = $1
-	`
-
-	queryCount := `SELECT COUNT(id) FROM links WHERE user_id = $1`
+	where := ` WHERE l.workspace_id = $1 AND l.deleted_at IS NULL`
+	args := []any{workspaceID}
 
-	args := []any{userID}
-	argsCount := []any{userID}
-
-	if linkGroupID > 0 {
-		query += fmt.Sprintf(" AND l.link_group_id = $%d", len(args)+1)
-		args = append(args, linkGroupID)
-
-		queryCount += fmt.Sprintf(" AND link_group_id = $%d", len(argsCount)+1)
-		argsCount = append(argsCount, linkGroupID)
+	if filter.LinkGroupID > 0 {
+		if filter.IncludeSubgroups {
+			where += ` AND l.link_group_id IN (` + subgroupIDsQuery(fmt.Sprintf("$%d", len(args)+1)) + `)`
+		} else {
+			where += fmt.Sprintf(" AND l.link_group_id = $%d", len(args)+1)
+		}
+		args = append(args, filter.LinkGroupID)
 	}
 
-	if name != "" {
-		search := "%" + name + "%"
-		query += fmt.Sprintf(` AND ((l.title ILIKE $%d) OR (l.url ILIKE $%d))`, len(args)+1, len(args)+2)
+	if filter.Name != "" {
+		search := "%" + filter.Name + "%"
+		where += fmt.Sprintf(` AND ((l.title ILIKE $%d) OR (l.url ILIKE $%d)`, len(args)+1, len(args)+2)
 		args = append(args, search, search)
+		if filter.NotesUserID > 0 {
+			where += fmt.Sprintf(`
+				OR EXISTS (SELECT 1 FROM link_notes n WHERE n.link_id = l.id AND n.user_id = $%d AND n.body ILIKE $%d)
+				OR EXISTS (SELECT 1 FROM link_highlights h WHERE h.link_id = l.id AND h.user_id = $%d AND h.quote ILIKE $%d)`,
+				len(args)+1, len(args)+2, len(args)+1, len(args)+2)
+			args = append(args, filter.NotesUserID, search)
+		}
+		where += `)`
+	}
+
+	if filter.Broken {
+		where += ` AND l.check_failures > 0`
+	}
 
-		queryCount += fmt.Sprintf(` AND ((title ILIKE $%d) OR (url ILIKE $%d))`, len(argsCount)+1, len(argsCount)+2)
-		argsCount = append(argsCount, search, search)
+	switch filter.State {
+	case models.LinkStateUnread:
+		where += ` AND l.read_at IS NULL`
+	case models.LinkStateRead:
+		where += ` AND l.read_at IS NOT NULL`
 	}
 
-	query += ` ORDER BY l.title ASC`
+	query := `
+		SELECT ` + linkColumns + `, g.id, g.name
+		FROM links l LEFT JOIN link_groups g ON l.link_group_id = g.id AND g.deleted_at IS NULL
+	` + where
+
+	queryCount := `SELECT COUNT(l.id) FROM links l` + where
+	argsCount := slices.Clone(args)
+
+	// Внутри группы ссылки идут в заданном пользователем порядке, список чтения - от новых к старым
+	if filter.LinkGroupID > 0 {
+		query += ` ORDER BY l.position ASC, l.title ASC`
+	} else if filter.State != "" {
+		query += ` ORDER BY l.created_at DESC, l.id DESC`
+	} else {
+		query += ` ORDER BY l.title ASC`
+	}
 	if limit > 0 && offset >= 0 {
 		query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
 		args = append(args, limit, offset)
@@ -157,23 +273,7 @@ func (r *linkRepository) GetLinksByUserIDWithPagination(ctx context.Context, use
 
 	for rows.Next() {
 		var link models.LinkResponse
-		if err := rows.Scan(
-			&link.ID,
-			&link.UserID,
-			&link.LinkGroupID,
-			&link.URL,
-			&link.Title,
-			&link.Description,
-			&link.FaviconURL,
-			&link.PreviewImage,
-			&link.IsArchived,
-			&link.IsFavorite,
-			&link.ClickCount,
-			&link.LastVisited,
-			&link.CreatedAt,
-			&link.UpdatedAt,
-			&link.Group.ID,
-			&link.Group.Name); err != nil {
+		if err := scanLink(rows, &link.Link, &link.Group.ID, &link.Group.Name); err != nil {
 			return nil, app_errors.HandleDBError(err, "получение ссылок", op)
 		}
 
@@ -199,71 +299,3 @@ func (r *linkRepository) GetLinksByUserIDWithPagination(ctx context.Context, use
 		TotalPages: totalPages,
 	}, nil
 }
-
-func (r *linkRepository) LinkVisitedPlus(ctx context.Context, linkID int) error {
-	op := "link_repository.LinkVisitedPlus"
-
-	query := `
-		UPDATE links
-			SET click_count = COALESCE(click_count, 0) + 1, 
-			last_visited = NOW()
-		WHERE id = $1
-	`
-
-	result, err := r.pool.Exec(ctx, query, linkID)
-	if err != nil {
-		r.logger.Error(err, op)
-		return app_errors.HandleDBError(err, "увеличение счетчика посещений ссылки", op)
-	}
-
-	if result.RowsAffected() == 0 {
-		return app_errors.NotFound("ссылка не найдена", op)
-	}
-
-	return nil
-}
-
-func (r *linkRepository) GetLinksTopVisited(ctx context.Context, userID, limit int) ([]*models.Link, error) {
-	op := "link_repository.GetLinksTopVisited"
-
-	query := `
-		SELECT id, user_id, link_group_id, url, title, description, favicon_url, preview_image, is_archived, is_favorite, click_count, last_visited, created_at, updated_at
-		FROM links
-		WHERE user_id = $1 AND
-			  click_count > 0 AND
-			  is_archived = false
-		ORDER BY click_count DESC
-		LIMIT $2
-	`
-	var links []*models.Link
-
-	result, err := r.pool.Query(ctx, query, userID, limit)
-	if err != nil {
-		r.logger.Error(err, op)
-		return nil, app_errors.HandleDBError(err, "получение топа посещаемых ссылок", op)
-	}
-
-	for result.Next() {
-		var link models.Link
-		if err := result.Scan(
-					&link.ID,
-					&link.UserID,
-					&link.LinkGroupID,
-					&link.URL,
-					&link.Title,
-					&link.Description,
-					&link.FaviconURL,
-					&link.PreviewImage,
-					&link.IsArchived,
-					&link.IsFavorite,
-					&link.ClickCount,
-					&link.LastVisited,
-					&link.CreatedAt,
-					&link.UpdatedAt,); err != nil {
-						r.logger.Error(err, op)
-			return nil, app_errors.HandleDBError(err, "получение топа посещаемых ссылок", op)
-		}
-		links = append(links, &link)
-	}
-	return links, nil
-}
\ No newline at end of file
@@ -0,0 +1,198 @@
+package link_repository
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BulkUpdateLinks применяет действие bulk к ссылкам пространства одной транзакцией и возвращает ID
+// обработанных ссылок. Ссылки других пространств, удаленные и несуществующие пропускаются. refresh здесь не выполняется
+func (r *linkRepository) BulkUpdateLinks(ctx context.Context, workspaceID int, bulk *models.LinkBulk) ([]int, error) {
+	op := "link_repository.BulkUpdateLinks"
+
+	queryLock := `
+		SELECT id
+		FROM links
+		WHERE id = ANY($1) AND
+		      workspace_id = $2 AND
+		      deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "массовое изменение ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, queryLock, bulk.LinkIDs, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "массовое изменение ссылок", op)
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "массовое изменение ссылок", op)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "массовое изменение ссылок", op)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	actorID := models.HistoryActorFromContext(ctx)
+
+	switch bulk.Action {
+	case models.LinkBulkMove:
+		err = bulkMoveLinks(ctx, tx, ids, workspaceID, bulk.LinkGroupID, actorID)
+	case models.LinkBulkAddTags:
+		err = bulkAddTags(ctx, tx, ids, workspaceID, bulk.TagIDs, op)
+	case models.LinkBulkRemoveTags:
+		_, err = tx.Exec(ctx, `DELETE FROM link_tags WHERE link_id = ANY($1) AND tag_id = ANY($2)`, ids, bulk.TagIDs)
+	case models.LinkBulkFavorite, models.LinkBulkUnfavorite:
+		err = bulkSetFlag(ctx, tx, ids, "is_favorite", bulk.Action == models.LinkBulkFavorite, actorID)
+	case models.LinkBulkArchive, models.LinkBulkUnarchive:
+		err = bulkSetFlag(ctx, tx, ids, "is_archived", bulk.Action == models.LinkBulkArchive, actorID)
+	case models.LinkBulkDelete:
+		err = bulkDeleteLinks(ctx, tx, ids, actorID)
+	default:
+		return nil, app_errors.BadRequest("Действие не выполняется в транзакции", op)
+	}
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, err
+		}
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "массовое изменение ссылок", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, app_errors.HandleDBError(err, "массовое изменение ссылок", op)
+	}
+
+	return ids, nil
+}
+
+// bulkMoveLinks переносит ссылки в конец группы linkGroupID, сохраняя их порядок
+func bulkMoveLinks(ctx context.Context, tx pgx.Tx, ids []int, workspaceID int, linkGroupID, actorID *int) error {
+	query := `
+		WITH moved AS (
+			UPDATE links l
+				SET link_group_id = $2,
+				    orphaned_from_group_id = NULL,
+				    position = m.max_position + o.n * $4,
+				    updated_at = CURRENT_TIMESTAMP
+			FROM (
+				SELECT id, link_group_id,
+				       ROW_NUMBER() OVER (ORDER BY link_group_id, position, id) AS n
+				FROM links
+				WHERE id = ANY($1) AND
+				      link_group_id IS DISTINCT FROM $2
+			) o, (
+				SELECT COALESCE(MAX(position), 0) AS max_position
+				FROM links
+				WHERE workspace_id = $3 AND
+				      link_group_id IS NOT DISTINCT FROM $2 AND
+				      deleted_at IS NULL
+			) m
+			WHERE l.id = o.id
+			RETURNING l.id, l.user_id, o.link_group_id AS old_group_id, l.link_group_id
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $5, 'update',
+		       jsonb_build_object('link_group_id', jsonb_build_object('old', old_group_id, 'new', link_group_id))
+		FROM moved
+	`
+
+	_, err := tx.Exec(ctx, query, ids, linkGroupID, workspaceID, positionGap, actorID)
+	return err
+}
+
+// bulkAddTags добавляет ссылкам теги пространства. Тег другого пространства или удаленный - NotFound
+func bulkAddTags(ctx context.Context, tx pgx.Tx, ids []int, workspaceID int, tagIDs []int, op string) error {
+	queryTags := `
+		SELECT COUNT(*)
+		FROM tags
+		WHERE id = ANY($1) AND
+		      workspace_id = $2 AND
+		      deleted_at IS NULL
+	`
+
+	query := `
+		INSERT INTO link_tags (link_id, tag_id)
+		SELECT l.id, t.id
+		FROM unnest($1::int[]) AS l(id), unnest($2::int[]) AS t(id)
+		ON CONFLICT DO NOTHING
+	`
+
+	var count int
+	if err := tx.QueryRow(ctx, queryTags, tagIDs, workspaceID).Scan(&count); err != nil {
+		return err
+	}
+	if count != len(tagIDs) {
+		return app_errors.NotFound("Тег не найден", op)
+	}
+
+	_, err := tx.Exec(ctx, query, ids, tagIDs)
+	return err
+}
+
+// bulkSetFlag устанавливает логическое поле column, в историю попадают только изменившиеся ссылки
+func bulkSetFlag(ctx context.Context, tx pgx.Tx, ids []int, column string, value bool, actorID *int) error {
+	query := fmt.Sprintf(`
+		WITH changed AS (
+			UPDATE links l
+				SET %[1]s = $2,
+				    updated_at = CURRENT_TIMESTAMP
+			FROM links old
+			WHERE l.id = ANY($1) AND
+			      old.id = l.id AND
+			      l.%[1]s IS DISTINCT FROM $2
+			RETURNING l.id, l.user_id, old.%[1]s AS old_value
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $3, 'update',
+		       jsonb_build_object('%[1]s', jsonb_build_object('old', old_value, 'new', $2::boolean))
+		FROM changed
+	`, column)
+
+	_, err := tx.Exec(ctx, query, ids, value, actorID)
+	return err
+}
+
+// bulkDeleteLinks перемещает ссылки в корзину с общим временем удаления
+func bulkDeleteLinks(ctx context.Context, tx pgx.Tx, ids []int, actorID *int) error {
+	query := `
+		WITH deleted AS (
+			UPDATE links
+				SET deleted_at = $2
+			WHERE id = ANY($1)
+			RETURNING id, user_id
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $3, 'delete',
+		       jsonb_build_object('deleted_at', jsonb_build_object('old', NULL, 'new', $2::timestamptz))
+		FROM deleted
+	`
+
+	_, err := tx.Exec(ctx, query, ids, time.Now(), actorID)
+	return err
+}
@@ -0,0 +1,80 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+)
+
+func (r *linkRepository) GetLinkByCanonicalURL(ctx context.Context, workspaceID int, canonicalURL string) (*models.Link, error) {
+	op := "link_repository.GetLinkByCanonicalURL"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.workspace_id = $1 AND
+		      l.canonical_url = $2 AND
+		      l.deleted_at IS NULL
+	`
+
+	var link models.Link
+
+	if err := scanLink(r.pool.QueryRow(ctx, query, workspaceID, canonicalURL), &link); err != nil {
+		return nil, app_errors.HandleDBError(err, "Получение ссылки по каноническому URL", op)
+	}
+
+	return &link, nil
+}
+
+func (r *linkRepository) SetLinkCanonicalURL(ctx context.Context, linkID int, canonicalURL string) error {
+	op := "link_repository.SetLinkCanonicalURL"
+
+	query := `
+		UPDATE links
+			SET canonical_url = $1
+		WHERE id = $2
+	`
+
+	result, err := r.pool.Exec(ctx, query, canonicalURL, linkID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "Установка канонического URL", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Ссылка не найдена", op)
+	}
+	return nil
+}
+
+// GetLinksWithoutCanonicalURL ссылки без канонического URL с ID больше afterID, по возрастанию ID
+func (r *linkRepository) GetLinksWithoutCanonicalURL(ctx context.Context, afterID, limit int) ([]*models.Link, error) {
+	op := "link_repository.GetLinksWithoutCanonicalURL"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.canonical_url IS NULL AND
+		      l.deleted_at IS NULL AND
+		      l.id > $1
+		ORDER BY l.id
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, afterID, limit)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение ссылок без канонического URL", op)
+	}
+	defer rows.Close()
+
+	var links []*models.Link
+
+	for rows.Next() {
+		var link models.Link
+		if err := scanLink(rows, &link); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение ссылок без канонического URL", op)
+		}
+		links = append(links, &link)
+	}
+	return links, nil
+}
@@ -0,0 +1,63 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// GetLinksForCheck ссылки, которые не проверялись с checkedBefore, сначала никогда не проверенные
+func (r *linkRepository) GetLinksForCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*models.Link, error) {
+	op := "link_repository.GetLinksForCheck"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.is_archived = false AND
+		      l.deleted_at IS NULL AND
+		      (l.last_checked_at IS NULL OR l.last_checked_at < $1)
+		ORDER BY l.last_checked_at NULLS FIRST, l.id
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, checkedBefore, limit)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение ссылок для проверки", op)
+	}
+	defer rows.Close()
+
+	var links []*models.Link
+
+	for rows.Next() {
+		var link models.Link
+		if err := scanLink(rows, &link); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение ссылок для проверки", op)
+		}
+		links = append(links, &link)
+	}
+	return links, nil
+}
+
+// SetLinkCheckResult сохраняет результат проверки. Счетчик неудач растет при broken и сбрасывается
+// при успехе; при archiveAfter > 0 ссылка архивируется, когда неудач подряд набралось archiveAfter
+func (r *linkRepository) SetLinkCheckResult(ctx context.Context, linkID int, httpStatus *int, finalURL string, broken bool, archiveAfter int) error {
+	op := "link_repository.SetLinkCheckResult"
+
+	query := `
+		UPDATE links
+			SET http_status = $2,
+			    final_url = $3,
+			    last_checked_at = CURRENT_TIMESTAMP,
+			    check_failures = CASE WHEN $4 THEN check_failures + 1 ELSE 0 END,
+			    broken_at = CASE WHEN $4 THEN COALESCE(broken_at, CURRENT_TIMESTAMP) END,
+			    is_archived = is_archived OR ($4 AND $5 > 0 AND check_failures + 1 >= $5)
+		WHERE id = $1
+	`
+
+	// Автоматическая архивация попадает в историю ссылки
+	return r.updateLinkWithHistory(ctx, op, "сохранение результата проверки ссылки", models.HistoryUpdate, linkID, query,
+		linkID, httpStatus, finalURL, broken, archiveAfter)
+}
@@ -0,0 +1,301 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// GetLinksByWorkspaceID все ссылки пространства по возрастанию ID
+func (r *linkRepository) GetLinksByWorkspaceID(ctx context.Context, workspaceID int) ([]*models.Link, error) {
+	op := "link_repository.GetLinksByWorkspaceID"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.workspace_id = $1 AND
+		      l.deleted_at IS NULL
+		ORDER BY l.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение ссылок пространства", op)
+	}
+	defer rows.Close()
+
+	var links []*models.Link
+
+	for rows.Next() {
+		var link models.Link
+		if err := scanLink(rows, &link); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение ссылок пространства", op)
+		}
+		links = append(links, &link)
+	}
+	return links, nil
+}
+
+// MergeLinks сливает ссылки linkIDs в ссылку survivorID и перемещает их в корзину: объединяет теги,
+// суммирует счетчики, берет самую раннюю дату создания, последнее посещение и отметку избранного.
+// Оставшейся ссылке достаются короткое имя, описание, отметка о прочтении и напоминание слитых,
+// если своих нет. Заметки и цитаты слитых ссылок переходят к оставшейся.
+// Короткое имя может быть только у одной из ссылок, иначе - Conflict
+func (r *linkRepository) MergeLinks(ctx context.Context, survivorID int, linkIDs []int) error {
+	op := "link_repository.MergeLinks"
+
+	queryLock := `
+		SELECT id
+		FROM links
+		WHERE (id = $1 OR id = ANY($2)) AND
+		      deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	querySlugs := `
+		SELECT COUNT(*)
+		FROM links
+		WHERE (id = $1 OR id = ANY($2)) AND
+		      slug IS NOT NULL
+	`
+
+	queryTags := `
+		INSERT INTO link_tags (link_id, tag_id)
+		SELECT DISTINCT $1::int, tag_id
+		FROM link_tags
+		WHERE link_id = ANY($2)
+		ON CONFLICT DO NOTHING
+	`
+
+	queryVisits := `
+		UPDATE link_visits
+			SET link_id = $1
+		WHERE link_id = ANY($2)
+	`
+
+	queryVisitsDaily := `
+		INSERT INTO link_visits_daily (link_id, user_id, day, visits, slug_visits)
+		SELECT $1::int, MIN(user_id), day, SUM(visits), SUM(slug_visits)
+		FROM link_visits_daily
+		WHERE link_id = ANY($2)
+		GROUP BY day
+		ON CONFLICT (link_id, day) DO UPDATE
+			SET visits = link_visits_daily.visits + EXCLUDED.visits,
+			    slug_visits = link_visits_daily.slug_visits + EXCLUDED.slug_visits
+	`
+
+	queryNotes := `
+		UPDATE link_notes
+			SET link_id = $1
+		WHERE link_id = ANY($2)
+	`
+
+	queryHighlights := `
+		UPDATE link_highlights
+			SET link_id = $1
+		WHERE link_id = ANY($2)
+	`
+
+	// Перемещение в корзину и перенос полей одним запросом: агрегаты считаются по прежним значениям
+	// слитых строк. Короткое имя слитой ссылки освобождается, чтобы перейти к оставшейся.
+	// Ссылка прочитана, если прочитана любая из копий; напоминание берется ближайшее
+	queryMerge := `
+		WITH merged AS (
+			UPDATE links d
+				SET deleted_at = CURRENT_TIMESTAMP,
+				    slug = NULL,
+				    slug_expires_at = NULL,
+				    slug_is_public = false
+			FROM links old
+			WHERE d.id = ANY($2) AND
+			      old.id = d.id
+			RETURNING old.id, old.click_count, old.created_at, old.last_visited, old.is_favorite, old.canonical_url,
+			          old.description, old.read_at, old.reading_minutes, old.remind_at, old.remind_user_id,
+			          old.slug, old.slug_expires_at, old.slug_is_public
+		)
+		UPDATE links l
+			SET click_count = COALESCE(l.click_count, 0) + m.click_count,
+			    created_at = LEAST(l.created_at, m.created_at),
+			    last_visited = GREATEST(l.last_visited, m.last_visited),
+			    is_favorite = COALESCE(l.is_favorite, false) OR m.is_favorite,
+			    description = COALESCE(NULLIF(l.description, ''), m.description, ''),
+			    read_at = COALESCE(l.read_at, m.read_at),
+			    reading_minutes = COALESCE(l.reading_minutes, m.reading_minutes),
+			    remind_at = CASE WHEN l.remind_at IS NULL THEN m.remind_at ELSE l.remind_at END,
+			    remind_user_id = CASE WHEN l.remind_at IS NULL THEN m.remind_user_id ELSE l.remind_user_id END,
+			    updated_at = CURRENT_TIMESTAMP
+		FROM (
+			SELECT COALESCE(SUM(click_count), 0) AS click_count,
+			       MIN(created_at) AS created_at,
+			       MAX(last_visited) AS last_visited,
+			       COALESCE(BOOL_OR(is_favorite), false) AS is_favorite,
+			       MIN(canonical_url) AS canonical_url,
+			       (ARRAY_AGG(description ORDER BY id) FILTER (WHERE description <> ''))[1] AS description,
+			       MAX(read_at) AS read_at,
+			       MAX(reading_minutes) AS reading_minutes,
+			       MIN(remind_at) AS remind_at,
+			       (ARRAY_AGG(remind_user_id ORDER BY remind_at) FILTER (WHERE remind_at IS NOT NULL))[1] AS remind_user_id,
+			       (ARRAY_AGG(slug) FILTER (WHERE slug IS NOT NULL))[1] AS slug,
+			       (ARRAY_AGG(slug_expires_at) FILTER (WHERE slug IS NOT NULL))[1] AS slug_expires_at,
+			       COALESCE((ARRAY_AGG(slug_is_public) FILTER (WHERE slug IS NOT NULL))[1], false) AS slug_is_public
+			FROM merged
+		) m
+		WHERE l.id = $1
+		RETURNING m.canonical_url, m.slug, m.slug_expires_at, m.slug_is_public
+	`
+
+	// Слитые ссылки попадают в корзину, в истории остается ссылка, в которую они слиты
+	queryHistory := `
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $3, 'delete',
+		       jsonb_build_object('deleted_at', jsonb_build_object('old', NULL, 'new', deleted_at),
+		                          'merged_into', jsonb_build_object('old', NULL, 'new', $1::int))
+		FROM links
+		WHERE id = ANY($2)
+	`
+
+	// Канонический URL ссылки в корзине уже свободен: уникальность проверяется только у действующих
+	queryCanonical := `
+		UPDATE links
+			SET canonical_url = $2
+		WHERE id = $1 AND
+		      canonical_url IS NULL
+	`
+
+	// Короткое имя переносится отдельным запросом, после того как слитая ссылка его освободила
+	querySlug := `
+		UPDATE links
+			SET slug = $2,
+			    slug_expires_at = $3,
+			    slug_is_public = $4
+		WHERE id = $1 AND
+		      slug IS NULL
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Блокируем ссылки, чтобы параллельные посещения не потерялись
+	rows, err := tx.Query(ctx, queryLock, survivorID, linkIDs)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+
+	if locked != len(linkIDs)+1 {
+		return app_errors.NotFound("ссылка не найдена", op)
+	}
+
+	// Короткая ссылка уже может быть опубликована: молча выбрать одно из имен нельзя
+	var slugs int
+	if err := tx.QueryRow(ctx, querySlugs, survivorID, linkIDs).Scan(&slugs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+	if slugs > 1 {
+		return app_errors.Conflict("Короткое имя есть у нескольких объединяемых ссылок: оставьте его только у одной", op)
+	}
+
+	// 2. Объединяем теги
+	if _, err := tx.Exec(ctx, queryTags, survivorID, linkIDs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "объединение тегов ссылок", op)
+	}
+
+	// 3. Переносим историю посещений
+	if _, err := tx.Exec(ctx, queryVisits, survivorID, linkIDs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "перенос посещений ссылок", op)
+	}
+
+	if _, err := tx.Exec(ctx, queryVisitsDaily, survivorID, linkIDs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "перенос посещений ссылок", op)
+	}
+
+	// 4. Переносим заметки и цитаты: у слитой ссылки в корзине их уже не найти
+	if _, err := tx.Exec(ctx, queryNotes, survivorID, linkIDs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "перенос заметок ссылок", op)
+	}
+
+	if _, err := tx.Exec(ctx, queryHighlights, survivorID, linkIDs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "перенос цитат ссылок", op)
+	}
+
+	// 5. Перемещаем дубликаты в корзину и переносим их поля
+	before, err := lockLink(ctx, tx, survivorID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+
+	var canonicalURL, slug *string
+	var slugExpiresAt *time.Time
+	var slugIsPublic bool
+	if err := tx.QueryRow(ctx, queryMerge, survivorID, linkIDs).Scan(&canonicalURL, &slug, &slugExpiresAt, &slugIsPublic); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+
+	if _, err := tx.Exec(ctx, queryHistory, survivorID, linkIDs, models.HistoryActorFromContext(ctx)); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if slug != nil {
+		if _, err := tx.Exec(ctx, querySlug, survivorID, *slug, slugExpiresAt, slugIsPublic); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "перенос короткого имени", op)
+		}
+	}
+
+	if canonicalURL != nil {
+		if _, err := tx.Exec(ctx, queryCanonical, survivorID, *canonicalURL); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "установка канонического URL", op)
+		}
+	}
+
+	// 6. Пересчитываем frecency по объединенной истории
+	if _, err := tx.Exec(ctx, queryRecalculateFrecency, survivorID); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "пересчет frecency ссылки", op)
+	}
+
+	after, err := lockLink(ctx, tx, survivorID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+
+	changes := diffHistoryFields(linkHistoryFields(before), linkHistoryFields(after))
+	if err := writeHistory(ctx, tx, models.EntityLink, survivorID, before.UserID, models.HistoryUpdate, changes); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "слияние ссылок", op)
+	}
+
+	return nil
+}
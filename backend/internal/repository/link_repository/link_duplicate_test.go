@@ -0,0 +1,151 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// seedMergeLinks ссылки пространства с URL urls, в порядке создания
+func seedMergeLinks(t *testing.T, r *linkRepository, userID, workspaceID int, urls ...string) []*models.Link {
+	t.Helper()
+
+	links := make([]*models.Link, 0, len(urls))
+	for _, rawURL := range urls {
+		link := &models.Link{UserID: userID, WorkspaceID: workspaceID, URL: rawURL, Title: rawURL}
+		if err := r.CreateLink(context.Background(), link, nil); err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+func TestMergeLinksCarriesFields(t *testing.T) {
+	r := newTestRepository(t)
+	userID, workspaceID := seedTestWorkspace(t, r, "merge")
+	ctx := context.Background()
+
+	links := seedMergeLinks(t, r, userID, workspaceID, "https://a.test/1", "https://a.test/2", "https://a.test/3")
+	survivor, withSlug, read := links[0], links[1], links[2]
+
+	remindAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE links SET slug = 'merge-' || id, slug_is_public = true, description = 'описание', remind_at = $2, remind_user_id = user_id
+		WHERE id = $1`, withSlug.ID, remindAt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.pool.Exec(ctx, `UPDATE links SET read_at = CURRENT_TIMESTAMP, reading_minutes = 7 WHERE id = $1`, read.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.MergeLinks(ctx, survivor.ID, []int{withSlug.ID, read.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := r.GetLinkByID(ctx, survivor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Slug == nil || *merged.Slug != "merge-"+strconv.Itoa(withSlug.ID) || !merged.SlugIsPublic {
+		t.Errorf("короткое имя %v, публичное %v", merged.Slug, merged.SlugIsPublic)
+	}
+	if merged.Description != "описание" {
+		t.Errorf("описание %q", merged.Description)
+	}
+	if merged.ReadAt == nil || merged.ReadingMinutes == nil || *merged.ReadingMinutes != 7 {
+		t.Errorf("отметка о прочтении %v, %v", merged.ReadAt, merged.ReadingMinutes)
+	}
+	if merged.RemindAt == nil || !merged.RemindAt.Equal(remindAt) || merged.RemindUserID == nil || *merged.RemindUserID != userID {
+		t.Errorf("напоминание %v для %v", merged.RemindAt, merged.RemindUserID)
+	}
+
+	// Слитые ссылки в корзине, а не удалены
+	var inTrash int
+	if err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM links
+		WHERE id = ANY($1) AND deleted_at IS NOT NULL AND slug IS NULL`, []int{withSlug.ID, read.ID}).Scan(&inTrash); err != nil {
+		t.Fatal(err)
+	}
+	if inTrash != 2 {
+		t.Errorf("в корзине %d слитых ссылок, ожидалось 2", inTrash)
+	}
+}
+
+func TestMergeLinksSlugConflict(t *testing.T) {
+	r := newTestRepository(t)
+	userID, workspaceID := seedTestWorkspace(t, r, "merge-slug")
+	ctx := context.Background()
+
+	links := seedMergeLinks(t, r, userID, workspaceID, "https://b.test/1", "https://b.test/2")
+	if _, err := r.pool.Exec(ctx, `UPDATE links SET slug = 'merge-slug-' || id WHERE id = ANY($1)`, []int{links[0].ID, links[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := r.MergeLinks(ctx, links[0].ID, []int{links[1].ID})
+	if !app_errors.IsConflict(err) {
+		t.Fatalf("ошибка %v, ожидался Conflict", err)
+	}
+
+	// Слияние не выполнено: вторая ссылка на месте со своим именем
+	link, err := r.GetLinkByID(ctx, links[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Slug == nil {
+		t.Error("короткое имя потеряно при отказе в слиянии")
+	}
+}
+
+func TestMergeLinksMovesNotes(t *testing.T) {
+	r := newTestRepository(t)
+	userID, workspaceID := seedTestWorkspace(t, r, "merge-notes")
+	ctx := context.Background()
+
+	links := seedMergeLinks(t, r, userID, workspaceID, "https://c.test/1", "https://c.test/2")
+	survivor, duplicate := links[0], links[1]
+
+	for _, note := range []*models.LinkNote{
+		{LinkID: survivor.ID, UserID: userID, Body: "своя заметка"},
+		{LinkID: duplicate.ID, UserID: userID, Body: "заметка дубликата"},
+	} {
+		if err := r.CreateLinkNote(ctx, note); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.CreateLinkHighlight(ctx, &models.LinkHighlight{LinkID: duplicate.ID, UserID: userID, Quote: "цитата"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.MergeLinks(ctx, survivor.ID, []int{duplicate.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := r.GetLinkNotes(ctx, survivor.ID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[1].Body != "заметка дубликата" {
+		t.Errorf("заметки оставшейся ссылки %+v", notes)
+	}
+
+	highlights, err := r.GetLinkHighlights(ctx, survivor.ID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(highlights) != 1 || highlights[0].Quote != "цитата" {
+		t.Errorf("цитаты оставшейся ссылки %+v", highlights)
+	}
+
+	// У слитой ссылки в корзине ничего не осталось
+	left, err := r.GetLinkNotes(ctx, duplicate.ID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("заметки остались у слитой ссылки: %+v", left)
+	}
+}
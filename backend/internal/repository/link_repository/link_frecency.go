@@ -0,0 +1,114 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"strings"
+)
+
+// queryRecalculateFrecency пересчитывает frecency по истории посещений ($1 = 0 - для всех ссылок).
+// Каждое посещение весит по корзине давности (как в Firefox): до 4 дней - 100, до 14 - 70,
+// до 31 - 50, до 90 - 30, старше - 10. Клики без событий в истории (до ее появления или
+// удаленные по сроку хранения) учитываются с минимальным весом
+const queryRecalculateFrecency = `
+	UPDATE links l
+		SET frecency = f.score
+	FROM (
+		SELECT lf.id AS link_id,
+		       COALESCE(SUM(v.visits * CASE
+		           WHEN CURRENT_DATE - v.day <= 4 THEN 100
+		           WHEN CURRENT_DATE - v.day <= 14 THEN 70
+		           WHEN CURRENT_DATE - v.day <= 31 THEN 50
+		           WHEN CURRENT_DATE - v.day <= 90 THEN 30
+		           ELSE 10
+		       END), 0) + GREATEST(lf.click_count - COALESCE(SUM(v.visits), 0), 0) * 10 AS score
+		FROM links lf
+		LEFT JOIN (
+			SELECT link_id, visited_at::date AS day, COUNT(*) AS visits
+			FROM link_visits
+			WHERE $1 = 0 OR link_id = $1
+			GROUP BY link_id, visited_at::date
+			UNION ALL
+			SELECT link_id, day, visits
+			FROM link_visits_daily
+			WHERE $1 = 0 OR link_id = $1
+		) v ON v.link_id = lf.id
+		WHERE $1 = 0 OR lf.id = $1
+		GROUP BY lf.id, lf.click_count
+	) f
+	WHERE l.id = f.link_id AND
+	      l.frecency <> f.score
+`
+
+// RecalculateFrecency пересчитывает frecency ссылки, linkID = 0 - всех ссылок
+func (r *linkRepository) RecalculateFrecency(ctx context.Context, linkID int) (int64, error) {
+	op := "link_repository.RecalculateFrecency"
+
+	result, err := r.pool.Exec(ctx, queryRecalculateFrecency, linkID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return 0, app_errors.HandleDBError(err, "пересчет frecency ссылок", op)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// GetLinksForLauncher ссылки, у которых заголовок (или слово в нем) либо домен начинается с prefix,
+// в порядке убывания frecency
+func (r *linkRepository) GetLinksForLauncher(ctx context.Context, workspaceID int, prefix string, limit int) ([]*models.Link, error) {
+	op := "link_repository.GetLinksForLauncher"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.workspace_id = $1 AND
+		      l.is_archived = false AND
+		      l.deleted_at IS NULL
+		ORDER BY l.frecency DESC, l.click_count DESC, l.title ASC
+		LIMIT $2
+	`
+	args := []any{workspaceID, limit}
+
+	if prefix != "" {
+		// Совпадение с началом заголовка ставим выше совпадений внутри заголовка и по домену
+		query = `
+			SELECT ` + linkColumns + `
+			FROM links l
+			WHERE l.workspace_id = $1 AND
+			      l.is_archived = false AND
+			      l.deleted_at IS NULL AND
+			      (l.title ILIKE $3 || '%' OR
+			       l.title ILIKE '% ' || $3 || '%' OR
+			       l.url ILIKE '%://' || $3 || '%' OR
+			       l.url ILIKE '%://www.' || $3 || '%')
+			ORDER BY (l.title ILIKE $3 || '%') DESC, l.frecency DESC, l.click_count DESC, l.title ASC
+			LIMIT $2
+		`
+		args = append(args, escapeLike(prefix))
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "поиск ссылок для лаунчера", op)
+	}
+	defer rows.Close()
+
+	var links []*models.Link
+
+	for rows.Next() {
+		var link models.Link
+		if err := scanLink(rows, &link); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "поиск ссылок для лаунчера", op)
+		}
+		links = append(links, &link)
+	}
+	return links, nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
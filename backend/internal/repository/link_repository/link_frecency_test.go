@@ -0,0 +1,83 @@
+package link_repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// launcherLinks ссылок в пространстве для проверки скорости лаунчера
+const launcherLinks = 10000
+
+// seedLauncherWorkspace пользователь с личным пространством и count ссылками, удаляется после теста
+func seedLauncherWorkspace(tb testing.TB, r *linkRepository, count int) int {
+	tb.Helper()
+	ctx := context.Background()
+
+	userID, workspaceID := seedTestWorkspace(tb, r, "launcher")
+
+	// Заголовки из нескольких слов и домены с повторяющимися частями, как в живых закладках
+	if _, err := r.pool.Exec(ctx, `
+		INSERT INTO links (user_id, workspace_id, url, title, frecency, click_count)
+		SELECT $1, $2,
+		       'https://' || (ARRAY['github.com', 'docs.example.org', 'news.site.io', 'www.blog.dev'])[1 + i % 4] || '/page/' || i,
+		       (ARRAY['Go', 'Postgres', 'Kubernetes', 'React', 'Rust'])[1 + i % 5] || ' notes ' || md5(i::text),
+		       i % 1000, i % 50
+		FROM generate_series(1, $3) AS i`, userID, workspaceID, count); err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := r.pool.Exec(ctx, `ANALYZE links`); err != nil {
+		tb.Fatal(err)
+	}
+
+	return workspaceID
+}
+
+func TestGetLinksForLauncher10k(t *testing.T) {
+	r := newTestRepository(t)
+	workspaceID := seedLauncherWorkspace(t, r, launcherLinks)
+	ctx := context.Background()
+
+	links, err := r.GetLinksForLauncher(ctx, workspaceID, "postg", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 10 {
+		t.Fatalf("найдено %d ссылок, ожидалось 10", len(links))
+	}
+	for _, link := range links {
+		if !strings.HasPrefix(link.Title, "Postgres") {
+			t.Errorf("лишняя ссылка %q", link.Title)
+		}
+	}
+
+	// Медиана по нескольким запросам: первый запрос прогревает кеш
+	var durations []time.Duration
+	for _, prefix := range []string{"go", "postg", "kube", "github", "docs", "blog", "rea", "rust", "news", "notes"} {
+		for range 5 {
+			start := time.Now()
+			if _, err := r.GetLinksForLauncher(ctx, workspaceID, prefix, 10); err != nil {
+				t.Fatal(err)
+			}
+			durations = append(durations, time.Since(start))
+		}
+	}
+	slices.Sort(durations)
+	if median := durations[len(durations)/2]; median > 20*time.Millisecond {
+		t.Errorf("медиана поиска лаунчера %s, ожидалось не больше 20ms", median)
+	}
+}
+
+func BenchmarkGetLinksForLauncher(b *testing.B) {
+	r := newTestRepository(b)
+	workspaceID := seedLauncherWorkspace(b, r, launcherLinks)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, err := r.GetLinksForLauncher(ctx, workspaceID, "kube", 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
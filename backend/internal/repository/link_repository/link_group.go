@@ -6,9 +6,32 @@ import (
 	"link-storage/internal/models"
 	"link-storage/pkg/response"
 	"link-storage/pkg/types/app_errors"
+	"slices"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// linkGroupColumns колонки группы ссылок, порядок совпадает со scanLinkGroup
+const linkGroupColumns = `id, user_id, workspace_id, parent_id, name, description, position, color, created_at, updated_at`
+
+// scanLinkGroup сканирует колонки linkGroupColumns в linkGroup, extra - дополнительные колонки после них
+func scanLinkGroup(row pgx.Row, linkGroup *models.LinkGroup, extra ...any) error {
+	dest := []any{
+		&linkGroup.ID,
+		&linkGroup.UserID,
+		&linkGroup.WorkspaceID,
+		&linkGroup.ParentID,
+		&linkGroup.Name,
+		&linkGroup.Description,
+		&linkGroup.Position,
+		&linkGroup.Color,
+		&linkGroup.CreatedAt,
+		&linkGroup.UpdatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
 func (r *linkRepository) CreateLinkGroup(ctx context.Context, linkGroup *models.LinkGroup) error {
 	op := "link_repository.CreateLinkGroup"
 
@@ -18,66 +41,76 @@ func (r *linkRepository) CreateLinkGroup(ctx context.Context, linkGroup *models.
 	linkGroup.UpdatedAt = currentTime
 
 	query := `
-		INSERT INTO link_groups (user_id, name, description, position, color, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO link_groups (user_id, workspace_id, parent_id, name, description, position, color, created_at, updated_at)
+		VALUES ($1, $9, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
 
 	queryMaxPosition := `
-		SELECT coalesce(MAX(position), 0) + 1 AS position
+		SELECT coalesce(MAX(position), 0) + $2 AS position
 		FROM link_groups
-		WHERE user_id = $1
+		WHERE workspace_id = $1
 	`
 
-	if err := r.pool.QueryRow(ctx, queryMaxPosition, linkGroup.UserID).Scan(&linkGroup.Position); err != nil {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "добавление группы ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, queryMaxPosition, linkGroup.WorkspaceID, positionGap).Scan(&linkGroup.Position); err != nil {
 		r.logger.Error(err, op)
 		return app_errors.HandleDBError(err, "получение максимальной позиции группы ссылок", op)
 	}
 
-	if err := r.pool.QueryRow(ctx, query, linkGroup.UserID, linkGroup.Name, linkGroup.Description, linkGroup.Position, linkGroup.Color, linkGroup.CreatedAt, linkGroup.UpdatedAt).Scan(&linkGroup.ID); err != nil {
+	if err := tx.QueryRow(ctx, query, linkGroup.UserID, linkGroup.ParentID, linkGroup.Name, linkGroup.Description, linkGroup.Position, linkGroup.Color, linkGroup.CreatedAt, linkGroup.UpdatedAt, linkGroup.WorkspaceID).Scan(&linkGroup.ID); err != nil {
+		return app_errors.HandleDBError(err, "добавление группы ссылок", op)
+	}
+
+	changes := diffHistoryFields(nil, linkGroupHistoryFields(linkGroup))
+	if err := writeHistory(ctx, tx, models.EntityLinkGroup, linkGroup.ID, linkGroup.UserID, models.HistoryCreate, changes); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return app_errors.HandleDBError(err, "добавление группы ссылок", op)
 	}
 
 	return nil
 }
 
-func (r *linkRepository) GetLinkGroupByID(ctc context.Context, id, userID int) (*models.LinkGroup, error) {
+func (r *linkRepository) GetLinkGroupByID(ctc context.Context, id, workspaceID int) (*models.LinkGroup, error) {
 	op := "link_repository.GetLinkGroupByID"
 
 	query := `
-		SELECT id, user_id, name, description, position, color, created_at, updated_at
+		SELECT ` + linkGroupColumns + `
 		FROM link_groups
-		WHERE id = $1 AND user_id = $2
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL
 	`
 
 	var linkGroup models.LinkGroup
 
-	if err := r.pool.QueryRow(ctc, query, id, userID).Scan(
-		&linkGroup.ID,
-		&linkGroup.UserID,
-		&linkGroup.Name,
-		&linkGroup.Description,
-		&linkGroup.Position,
-		&linkGroup.Color,
-		&linkGroup.CreatedAt,
-		&linkGroup.UpdatedAt); err != nil {
+	if err := scanLinkGroup(r.pool.QueryRow(ctc, query, id, workspaceID), &linkGroup); err != nil {
 		return nil, app_errors.HandleDBError(err, "получение группы ссылок", op)
 	}
 
 	return &linkGroup, nil
 }
 
-func (r *linkRepository) HasLinkGroupWithNameByUserID(ctx context.Context, name string, userID int) (bool, error) {
-	op := "link_repository.HasLinkGroupWithNameByUserID"
+func (r *linkRepository) HasLinkGroupWithNameByWorkspaceID(ctx context.Context, name string, workspaceID int) (bool, error) {
+	op := "link_repository.HasLinkGroupWithNameByWorkspaceID"
 
 	query := `
 		SELECT COUNT(*)
 		FROM link_groups
-		WHERE user_id = $1 AND
-		      name ILIKE $2
+		WHERE workspace_id = $1 AND
+		      name ILIKE $2 AND
+		      deleted_at IS NULL
 	`
 	var count int
-	if err := r.pool.QueryRow(ctx, query, userID, name).Scan(&count); err != nil {
+	if err := r.pool.QueryRow(ctx, query, workspaceID, name).Scan(&count); err != nil {
 		return false, app_errors.HandleDBError(err, "проверка наличия группы ссылок с таким именем", op)
 	}
 	return count > 0, nil
@@ -91,29 +124,180 @@ func (r *linkRepository) UpdateLinkGroup(ctx context.Context, linkGroup *models.
 	query := `
 		UPDATE link_groups
 		SET name = $1, description = $2, color = $3, updated_at = $4
-		WHERE id = $5		
+		WHERE id = $5 AND deleted_at IS NULL
 	`
-	result, err := r.pool.Exec(ctx, query, linkGroup.Name, linkGroup.Description, linkGroup.Color, linkGroup.UpdatedAt, linkGroup.ID)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "обновление группы ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockLinkGroup(ctx, tx, linkGroup.ID)
 	if err != nil {
 		return app_errors.HandleDBError(err, "обновление группы ссылок", op)
 	}
 
-	if result.RowsAffected() == 0 {
-		return app_errors.NotFound("группа ссылок не найдена", op)
+	if _, err := tx.Exec(ctx, query, linkGroup.Name, linkGroup.Description, linkGroup.Color, linkGroup.UpdatedAt, linkGroup.ID); err != nil {
+		return app_errors.HandleDBError(err, "обновление группы ссылок", op)
+	}
+
+	after := *before
+	after.Name = linkGroup.Name
+	after.Description = linkGroup.Description
+	after.Color = linkGroup.Color
+
+	changes := diffHistoryFields(linkGroupHistoryFields(before), linkGroupHistoryFields(&after))
+	if err := writeHistory(ctx, tx, models.EntityLinkGroup, before.ID, before.UserID, models.HistoryUpdate, changes); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "обновление группы ссылок", op)
 	}
 
 	return nil
 }
 
-func (r *linkRepository) DeleteLinkGroup(ctx context.Context, id int) error {
+// DeleteLinkGroup перемещает группу в корзину и применяет к ее ссылкам действие params.Links. Подгруппы при LiftChildren
+// переходят к родителю удаляемой группы, иначе уходят в корзину вместе с ней, а действие применяется и к их ссылкам.
+// Все, что ушло в корзину одной операцией, получает одинаковое deleted_at - по нему группа восстанавливается целиком
+func (r *linkRepository) DeleteLinkGroup(ctx context.Context, id, workspaceID int, params *models.LinkGroupDelete) error {
 	op := "link_repository.DeleteLinkGroup"
 
+	queryGroupIDs := `SELECT ARRAY(` + subgroupIDsQuery("$1") + `)`
+
+	// Переносим ссылки в конец целевого списка (nil - без группы), сохраняя их порядок.
+	// Ссылки, оставшиеся без группы, запоминают ее, чтобы вернуться при восстановлении
+	queryMoveLinks := `
+		WITH moved AS (
+			UPDATE links l
+				SET link_group_id = $2,
+				    orphaned_from_group_id = CASE WHEN $2::int IS NULL THEN l.link_group_id END,
+				    position = m.max_position + o.n * $4,
+				    is_archived = COALESCE(l.is_archived, false) OR $5,
+				    updated_at = CURRENT_TIMESTAMP
+			FROM (
+				SELECT id, link_group_id, is_archived,
+				       ROW_NUMBER() OVER (ORDER BY link_group_id, position, id) AS n
+				FROM links
+				WHERE link_group_id = ANY($1) AND
+				      deleted_at IS NULL
+			) o, (
+				SELECT COALESCE(MAX(position), 0) AS max_position
+				FROM links
+				WHERE workspace_id = $3 AND
+				      link_group_id IS NOT DISTINCT FROM $2 AND
+				      deleted_at IS NULL
+			) m
+			WHERE l.id = o.id
+			RETURNING l.id, l.user_id, o.link_group_id AS old_group_id, l.link_group_id,
+			          o.is_archived AS old_is_archived, l.is_archived
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $6, 'update',
+		       jsonb_build_object('link_group_id', jsonb_build_object('old', old_group_id, 'new', link_group_id)) ||
+		       CASE WHEN old_is_archived IS DISTINCT FROM is_archived
+		            THEN jsonb_build_object('is_archived', jsonb_build_object('old', old_is_archived, 'new', is_archived))
+		            ELSE '{}'::jsonb
+		       END
+		FROM moved
+	`
+
+	queryDeleteLinks := `
+		WITH deleted AS (
+			UPDATE links
+				SET deleted_at = $2
+			WHERE link_group_id = ANY($1) AND
+			      deleted_at IS NULL
+			RETURNING id, user_id
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $3, 'delete',
+		       jsonb_build_object('deleted_at', jsonb_build_object('old', NULL, 'new', $2::timestamptz))
+		FROM deleted
+	`
+
+	queryLift := `
+		WITH lifted AS (
+			UPDATE link_groups
+				SET parent_id = (SELECT parent_id FROM link_groups WHERE id = $1),
+				    updated_at = CURRENT_TIMESTAMP
+			WHERE parent_id = $1 AND
+			      deleted_at IS NULL
+			RETURNING id, user_id, parent_id
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link_group', id, user_id, $2, 'update',
+		       jsonb_build_object('parent_id', jsonb_build_object('old', $1::int, 'new', parent_id))
+		FROM lifted
+	`
+
 	query := `
-		DELETE FROM link_groups
-		WHERE id = $1
+		WITH deleted AS (
+			UPDATE link_groups
+				SET deleted_at = $3
+			WHERE id = ANY($1) AND
+			      workspace_id = $2 AND
+			      deleted_at IS NULL
+			RETURNING id, user_id
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link_group', id, user_id, $4, 'delete',
+		       jsonb_build_object('deleted_at', jsonb_build_object('old', NULL, 'new', $3::timestamptz))
+		FROM deleted
 	`
 
-	result, err := r.pool.Exec(ctx, query, id)
+	deletedAt := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление группы ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Группы, которые будут удалены
+	groupIDs := []int{id}
+	if !params.LiftChildren {
+		if err := tx.QueryRow(ctx, queryGroupIDs, id).Scan(&groupIDs); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "удаление группы ссылок", op)
+		}
+	}
+
+	// 2. Ссылки удаляемых групп
+	switch params.Links {
+	case models.LinkGroupDeleteLinksDelete:
+		if _, err := tx.Exec(ctx, queryDeleteLinks, groupIDs, deletedAt, models.HistoryActorFromContext(ctx)); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "удаление ссылок группы", op)
+		}
+	default:
+		var moveToID *int
+		if params.Links == models.LinkGroupDeleteLinksMoveTo {
+			if slices.Contains(groupIDs, params.MoveToID) {
+				return app_errors.BadRequest("Нельзя перенести ссылки в удаляемую группу", op)
+			}
+			moveToID = &params.MoveToID
+		}
+
+		archive := params.Links == models.LinkGroupDeleteLinksArchive
+		if _, err := tx.Exec(ctx, queryMoveLinks, groupIDs, moveToID, workspaceID, positionGap, archive, models.HistoryActorFromContext(ctx)); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "перенос ссылок группы", op)
+		}
+	}
+
+	// 3. Подгруппы
+	if params.LiftChildren {
+		if _, err := tx.Exec(ctx, queryLift, id, models.HistoryActorFromContext(ctx)); err != nil {
+			return app_errors.HandleDBError(err, "перенос подгрупп", op)
+		}
+	}
+
+	result, err := tx.Exec(ctx, query, groupIDs, workspaceID, deletedAt, models.HistoryActorFromContext(ctx))
 	if err != nil {
 		return app_errors.HandleDBError(err, "удаление группы ссылок", op)
 	}
@@ -122,25 +306,55 @@ func (r *linkRepository) DeleteLinkGroup(ctx context.Context, id int) error {
 		return app_errors.NotFound("группа ссылок не найдена", op)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "удаление группы ссылок", op)
+	}
+
 	return nil
 }
 
-func (r *linkRepository) GetLinkGroupsByUserIDWithPagination(ctx context.Context, name string, userID int, limit, offset int) (*response.ListResponse[models.LinkGroup], error) {
-	op := "link_repository.GetLinkGroupsByUserIDWithPagination"
+// GetLinkGroupDeletePreview количество подгрупп и ссылок, которые затронет удаление группы
+func (r *linkRepository) GetLinkGroupDeletePreview(ctx context.Context, id int) (*models.LinkGroupDeletePreview, error) {
+	op := "link_repository.GetLinkGroupDeletePreview"
 
 	query := `
-		SELECT id, user_id, name, description, position, color, created_at, updated_at
-		FROM link_groups
-		WHERE user_id = $1
+		WITH tree AS (` + subgroupIDsQuery("$1") + `)
+		SELECT (SELECT COUNT(*) FROM tree) - 1,
+		       (SELECT COUNT(*) FROM links WHERE link_group_id = $1 AND deleted_at IS NULL),
+		       (SELECT COUNT(*) FROM links WHERE link_group_id IN (SELECT id FROM tree) AND link_group_id <> $1 AND deleted_at IS NULL)
 	`
 
+	var preview models.LinkGroupDeletePreview
+
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&preview.Subgroups, &preview.Links, &preview.SubgroupLinks); err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "подсчет ссылок удаляемой группы", op)
+	}
+
+	return &preview, nil
+}
+
+// GetLinkGroupsByWorkspaceIDWithPagination группы пространства и общие группы других пространств, в которые пригласили
+// пользователя userID. Общие группы идут после групп пространства
+func (r *linkRepository) GetLinkGroupsByWorkspaceIDWithPagination(ctx context.Context, name string, workspaceID, userID int, limit, offset int) (*response.ListResponse[models.LinkGroup], error) {
+	op := "link_repository.GetLinkGroupsByWorkspaceIDWithPagination"
+
+	where := `
+		WHERE (g.workspace_id = $1 OR g.id IN (SELECT link_group_id FROM link_group_members WHERE user_id = $2)) AND
+		      g.deleted_at IS NULL
+	`
+
+	query := `
+		SELECT ` + linkGroupColumns + `, ` + linkGroupRoleSQL("$2") + `
+		FROM link_groups g
+	` + where
+
 	queryCount := `
 		SELECT COUNT(*)
-		FROM link_groups
-		WHERE user_id = $1
-	`
-	args := []any{userID}
-	argsCount := []any{userID}
+		FROM link_groups g
+	` + where
+	args := []any{workspaceID, userID}
+	argsCount := []any{workspaceID, userID}
 
 	if name != "" {
 		searchName := "%" + name + "%"
@@ -150,7 +364,7 @@ func (r *linkRepository) GetLinkGroupsByUserIDWithPagination(ctx context.Context
 		argsCount = append(argsCount, searchName)
 	}
 
-	query += ` ORDER BY name`
+	query += ` ORDER BY g.workspace_id <> $1, position, name`
 
 	if limit > 0 && offset >= 0 {
 		query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
@@ -183,15 +397,7 @@ func (r *linkRepository) GetLinkGroupsByUserIDWithPagination(ctx context.Context
 
 	for rows.Next() {
 		var linkGroup models.LinkGroup
-		if err := rows.Scan(
-			&linkGroup.ID,
-			&linkGroup.UserID,
-			&linkGroup.Name,
-			&linkGroup.Description,
-			&linkGroup.Position,
-			&linkGroup.Color,
-			&linkGroup.CreatedAt,
-			&linkGroup.UpdatedAt); err != nil {
+		if err := scanLinkGroup(rows, &linkGroup, &linkGroup.Role); err != nil {
 			return nil, app_errors.HandleDBError(err, "получение групп ссылок", op)
 		}
 		linkGroups = append(linkGroups, &linkGroup)
@@ -0,0 +1,313 @@
+package link_repository
+
+import (
+	"context"
+	"errors"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// linkGroupRoleSQL роль пользователя из параметра param в группе g: роль в пространстве группы
+// (admin и owner управляют группами как владельцы) или роль участника в самой группе либо в ближайшем
+// ее предке - из двух берется старшая. NULL - у пользователя нет доступа к группе
+func linkGroupRoleSQL(param string) string {
+	return `
+		(
+			SELECT r.role
+			FROM (
+				SELECT CASE wm.role WHEN 'admin' THEN 'owner' ELSE wm.role END AS role
+				FROM workspace_members wm
+				WHERE wm.workspace_id = g.workspace_id AND
+				      wm.user_id = ` + param + `
+				UNION ALL
+				(
+					WITH RECURSIVE ancestors AS (
+						SELECT g.id, g.parent_id, 0 AS depth
+						UNION ALL
+						SELECT p.id, p.parent_id, a.depth + 1
+						FROM link_groups p
+						JOIN ancestors a ON p.id = a.parent_id
+						WHERE p.deleted_at IS NULL
+					)
+					SELECT m.role
+					FROM ancestors a
+					JOIN link_group_members m ON m.link_group_id = a.id
+					WHERE m.user_id = ` + param + `
+					ORDER BY a.depth
+					LIMIT 1
+				)
+			) r
+			ORDER BY array_position(ARRAY['viewer', 'editor', 'owner'], r.role::text) DESC
+			LIMIT 1
+		)`
+}
+
+// GetLinkGroupForUser группа, доступная пользователю как участнику ее пространства или самой группы, с его ролью
+func (r *linkRepository) GetLinkGroupForUser(ctx context.Context, id, userID int) (*models.LinkGroup, error) {
+	op := "link_repository.GetLinkGroupForUser"
+
+	query := `
+		SELECT ` + linkGroupColumns + `, ` + linkGroupRoleSQL("$2") + `
+		FROM link_groups g
+		WHERE g.id = $1 AND
+		      g.deleted_at IS NULL
+	`
+
+	var linkGroup models.LinkGroup
+	var role *models.LinkGroupRole
+
+	if err := scanLinkGroup(r.pool.QueryRow(ctx, query, id, userID), &linkGroup, &role); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение группы ссылок", op)
+	}
+
+	if role == nil {
+		return nil, app_errors.NotFound("Группа не найдена", op)
+	}
+	linkGroup.Role = *role
+
+	return &linkGroup, nil
+}
+
+// GetLinkGroupMembers участники группы по дате добавления
+func (r *linkRepository) GetLinkGroupMembers(ctx context.Context, linkGroupID int) ([]*models.LinkGroupMember, error) {
+	op := "link_repository.GetLinkGroupMembers"
+
+	query := `
+		SELECT m.link_group_id, m.user_id, u.name, u.email, m.role, m.created_at
+		FROM link_group_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.link_group_id = $1
+		ORDER BY m.created_at, m.user_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, linkGroupID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение участников группы", op)
+	}
+	defer rows.Close()
+
+	members := []*models.LinkGroupMember{}
+
+	for rows.Next() {
+		var member models.LinkGroupMember
+		if err := rows.Scan(&member.LinkGroupID, &member.UserID, &member.Name, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение участников группы", op)
+		}
+		members = append(members, &member)
+	}
+	return members, nil
+}
+
+func (r *linkRepository) SetLinkGroupMemberRole(ctx context.Context, linkGroupID, userID int, role models.LinkGroupRole) error {
+	op := "link_repository.SetLinkGroupMemberRole"
+
+	query := `
+		UPDATE link_group_members
+			SET role = $3,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE link_group_id = $1 AND
+		      user_id = $2
+	`
+
+	result, err := r.pool.Exec(ctx, query, linkGroupID, userID, role)
+	if err != nil {
+		return app_errors.HandleDBError(err, "изменение роли участника", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Участник не найден", op)
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteLinkGroupMember(ctx context.Context, linkGroupID, userID int) error {
+	op := "link_repository.DeleteLinkGroupMember"
+
+	query := `
+		DELETE FROM link_group_members
+		WHERE link_group_id = $1 AND
+		      user_id = $2
+	`
+
+	result, err := r.pool.Exec(ctx, query, linkGroupID, userID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление участника группы", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Участник не найден", op)
+	}
+	return nil
+}
+
+// CreateLinkGroupInvite создает приглашение. Прежнее неиспользованное приглашение на тот же email заменяется
+func (r *linkRepository) CreateLinkGroupInvite(ctx context.Context, invite *models.LinkGroupInvite) error {
+	op := "link_repository.CreateLinkGroupInvite"
+
+	queryDeletePending := `
+		DELETE FROM link_group_invites
+		WHERE link_group_id = $1 AND
+		      lower(email) = lower($2) AND
+		      accepted_at IS NULL
+	`
+
+	query := `
+		INSERT INTO link_group_invites (link_group_id, email, role, token, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание приглашения", op)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, queryDeletePending, invite.LinkGroupID, invite.Email); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание приглашения", op)
+	}
+
+	if err := tx.QueryRow(ctx, query,
+		invite.LinkGroupID,
+		invite.Email,
+		invite.Role,
+		invite.Token,
+		invite.InvitedBy,
+		invite.ExpiresAt).Scan(&invite.ID, &invite.CreatedAt); err != nil {
+		return app_errors.HandleDBError(err, "создание приглашения", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "создание приглашения", op)
+	}
+	return nil
+}
+
+// GetLinkGroupInvites действующие приглашения группы
+func (r *linkRepository) GetLinkGroupInvites(ctx context.Context, linkGroupID int) ([]*models.LinkGroupInvite, error) {
+	op := "link_repository.GetLinkGroupInvites"
+
+	query := `
+		SELECT id, link_group_id, email, role, invited_by, created_at, expires_at
+		FROM link_group_invites
+		WHERE link_group_id = $1 AND
+		      accepted_at IS NULL AND
+		      expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, linkGroupID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение приглашений", op)
+	}
+	defer rows.Close()
+
+	invites := []*models.LinkGroupInvite{}
+
+	for rows.Next() {
+		var invite models.LinkGroupInvite
+		if err := rows.Scan(&invite.ID, &invite.LinkGroupID, &invite.Email, &invite.Role, &invite.InvitedBy, &invite.CreatedAt, &invite.ExpiresAt); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение приглашений", op)
+		}
+		invites = append(invites, &invite)
+	}
+	return invites, nil
+}
+
+func (r *linkRepository) DeleteLinkGroupInvite(ctx context.Context, id, linkGroupID int) error {
+	op := "link_repository.DeleteLinkGroupInvite"
+
+	query := `
+		DELETE FROM link_group_invites
+		WHERE id = $1 AND
+		      link_group_id = $2 AND
+		      accepted_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, linkGroupID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "отзыв приглашения", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Приглашение не найдено", op)
+	}
+	return nil
+}
+
+// AcceptLinkGroupInvite принимает приглашение token пользователем с адресом email и возвращает ID группы.
+// Если пользователь уже участник, его роль заменяется ролью из приглашения
+func (r *linkRepository) AcceptLinkGroupInvite(ctx context.Context, token string, userID int, email string) (int, error) {
+	op := "link_repository.AcceptLinkGroupInvite"
+
+	queryInvite := `
+		SELECT i.id, i.link_group_id, i.role, i.invited_by,
+		       EXISTS (SELECT 1 FROM workspace_members wm WHERE wm.workspace_id = g.workspace_id AND wm.user_id = $3)
+		FROM link_group_invites i
+		JOIN link_groups g ON g.id = i.link_group_id
+		WHERE i.token = $1 AND
+		      lower(i.email) = lower($2) AND
+		      i.accepted_at IS NULL AND
+		      i.expires_at > CURRENT_TIMESTAMP AND
+		      g.deleted_at IS NULL
+		FOR UPDATE OF i
+	`
+
+	queryMember := `
+		INSERT INTO link_group_members (link_group_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (link_group_id, user_id) DO UPDATE
+			SET role = EXCLUDED.role,
+			    updated_at = CURRENT_TIMESTAMP
+	`
+
+	queryAccept := `
+		UPDATE link_group_invites
+			SET accepted_at = CURRENT_TIMESTAMP,
+			    accepted_by = $2
+		WHERE id = $1
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return 0, app_errors.HandleDBError(err, "принятие приглашения", op)
+	}
+	defer tx.Rollback(ctx)
+
+	var inviteID, linkGroupID int
+	var role models.LinkGroupRole
+	var invitedBy *int
+	var inWorkspace bool
+	if err := tx.QueryRow(ctx, queryInvite, token, email, userID).Scan(&inviteID, &linkGroupID, &role, &invitedBy, &inWorkspace); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, app_errors.NotFound("Приглашение не найдено или истекло", op)
+		}
+		r.logger.Error(err, op)
+		return 0, app_errors.HandleDBError(err, "принятие приглашения", op)
+	}
+
+	if inWorkspace {
+		return 0, app_errors.BadRequest("Вы уже участник пространства этой группы", op)
+	}
+
+	if _, err := tx.Exec(ctx, queryMember, linkGroupID, userID, role, invitedBy); err != nil {
+		r.logger.Error(err, op)
+		return 0, app_errors.HandleDBError(err, "принятие приглашения", op)
+	}
+
+	if _, err := tx.Exec(ctx, queryAccept, inviteID, userID); err != nil {
+		r.logger.Error(err, op)
+		return 0, app_errors.HandleDBError(err, "принятие приглашения", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, app_errors.HandleDBError(err, "принятие приглашения", op)
+	}
+	return linkGroupID, nil
+}
@@ -0,0 +1,160 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const linkGroupShareColumns = `s.link_group_id, s.slug, s.password_hash, s.expires_at, s.created_by, s.created_at, s.updated_at`
+
+func scanLinkGroupShare(row pgx.Row, share *models.LinkGroupShare) error {
+	if err := row.Scan(
+		&share.LinkGroupID,
+		&share.Slug,
+		&share.PasswordHash,
+		&share.ExpiresAt,
+		&share.CreatedBy,
+		&share.CreatedAt,
+		&share.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	share.HasPassword = share.PasswordHash != nil
+	return nil
+}
+
+func (r *linkRepository) GetLinkGroupShare(ctx context.Context, linkGroupID int) (*models.LinkGroupShare, error) {
+	op := "link_repository.GetLinkGroupShare"
+
+	query := `
+		SELECT ` + linkGroupShareColumns + `
+		FROM link_group_shares s
+		WHERE s.link_group_id = $1
+	`
+
+	var share models.LinkGroupShare
+	if err := scanLinkGroupShare(r.pool.QueryRow(ctx, query, linkGroupID), &share); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение публикации группы", op)
+	}
+	return &share, nil
+}
+
+// GetLinkGroupShareBySlug публикация по имени страницы. Публикация группы из корзины не находится
+func (r *linkRepository) GetLinkGroupShareBySlug(ctx context.Context, slug string) (*models.LinkGroupShare, error) {
+	op := "link_repository.GetLinkGroupShareBySlug"
+
+	query := `
+		SELECT ` + linkGroupShareColumns + `
+		FROM link_group_shares s
+		JOIN link_groups g ON g.id = s.link_group_id
+		WHERE s.slug = $1 AND
+		      g.deleted_at IS NULL
+	`
+
+	var share models.LinkGroupShare
+	if err := scanLinkGroupShare(r.pool.QueryRow(ctx, query, slug), &share); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение публикации группы", op)
+	}
+	return &share, nil
+}
+
+// SaveLinkGroupShare публикует группу или заменяет настройки существующей публикации
+func (r *linkRepository) SaveLinkGroupShare(ctx context.Context, share *models.LinkGroupShare) error {
+	op := "link_repository.SaveLinkGroupShare"
+
+	query := `
+		INSERT INTO link_group_shares (link_group_id, slug, password_hash, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (link_group_id) DO UPDATE
+			SET slug = EXCLUDED.slug,
+			    password_hash = EXCLUDED.password_hash,
+			    expires_at = EXCLUDED.expires_at,
+			    updated_at = CURRENT_TIMESTAMP
+		RETURNING created_by, created_at, updated_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		share.LinkGroupID,
+		share.Slug,
+		share.PasswordHash,
+		share.ExpiresAt,
+		share.CreatedBy).Scan(&share.CreatedBy, &share.CreatedAt, &share.UpdatedAt); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "публикация группы", op)
+	}
+
+	share.HasPassword = share.PasswordHash != nil
+	return nil
+}
+
+func (r *linkRepository) DeleteLinkGroupShare(ctx context.Context, linkGroupID int) error {
+	op := "link_repository.DeleteLinkGroupShare"
+
+	result, err := r.pool.Exec(ctx, `DELETE FROM link_group_shares WHERE link_group_id = $1`, linkGroupID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "отмена публикации группы", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Группа не опубликована", op)
+	}
+	return nil
+}
+
+// GetPublicLinkGroupContent группа linkGroupID с подгруппами и их ссылки без удаленных
+func (r *linkRepository) GetPublicLinkGroupContent(ctx context.Context, linkGroupID int) ([]*models.LinkGroup, []*models.Link, error) {
+	op := "link_repository.GetPublicLinkGroupContent"
+
+	queryGroups := `
+		SELECT ` + linkGroupColumns + `
+		FROM link_groups
+		WHERE id IN (` + subgroupIDsQuery("$1") + `)
+		ORDER BY position, name
+	`
+
+	queryLinks := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE l.link_group_id IN (` + subgroupIDsQuery("$1") + `) AND
+		      l.deleted_at IS NULL
+		ORDER BY l.position, l.id
+	`
+
+	rows, err := r.pool.Query(ctx, queryGroups, linkGroupID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, nil, app_errors.HandleDBError(err, "получение публичной группы", op)
+	}
+	defer rows.Close()
+
+	var linkGroups []*models.LinkGroup
+	for rows.Next() {
+		var linkGroup models.LinkGroup
+		if err := scanLinkGroup(rows, &linkGroup); err != nil {
+			r.logger.Error(err, op)
+			return nil, nil, app_errors.HandleDBError(err, "получение публичной группы", op)
+		}
+		linkGroups = append(linkGroups, &linkGroup)
+	}
+	rows.Close()
+
+	rows, err = r.pool.Query(ctx, queryLinks, linkGroupID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, nil, app_errors.HandleDBError(err, "получение публичной группы", op)
+	}
+	defer rows.Close()
+
+	var links []*models.Link
+	for rows.Next() {
+		var link models.Link
+		if err := scanLink(rows, &link); err != nil {
+			r.logger.Error(err, op)
+			return nil, nil, app_errors.HandleDBError(err, "получение публичной группы", op)
+		}
+		links = append(links, &link)
+	}
+	return linkGroups, links, nil
+}
@@ -0,0 +1,136 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+)
+
+// subgroupIDsQuery запрос ID группы из параметра param и всех ее подгрупп
+func subgroupIDsQuery(param string) string {
+	return `
+		WITH RECURSIVE subgroups AS (
+			SELECT id FROM link_groups WHERE id = ` + param + ` AND deleted_at IS NULL
+			UNION
+			SELECT g.id FROM link_groups g JOIN subgroups s ON g.parent_id = s.id WHERE g.deleted_at IS NULL
+		)
+		SELECT id FROM subgroups
+	`
+}
+
+// GetLinkGroupsWithLinkCount все группы пространства с количеством ссылок в каждой (без подгрупп)
+func (r *linkRepository) GetLinkGroupsWithLinkCount(ctx context.Context, workspaceID int) ([]*models.LinkGroupNode, error) {
+	op := "link_repository.GetLinkGroupsWithLinkCount"
+
+	query := `
+		SELECT ` + linkGroupColumns + `, COALESCE(c.link_count, 0)
+		FROM link_groups g
+		LEFT JOIN (
+			SELECT link_group_id, COUNT(*) AS link_count
+			FROM links
+			WHERE workspace_id = $1 AND
+			      link_group_id IS NOT NULL AND
+			      deleted_at IS NULL
+			GROUP BY link_group_id
+		) c ON c.link_group_id = g.id
+		WHERE g.workspace_id = $1 AND
+		      g.deleted_at IS NULL
+		ORDER BY g.position, g.name
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение дерева групп ссылок", op)
+	}
+	defer rows.Close()
+
+	var nodes []*models.LinkGroupNode
+
+	for rows.Next() {
+		var node models.LinkGroupNode
+		if err := scanLinkGroup(rows, &node.LinkGroup, &node.LinkCount); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение дерева групп ссылок", op)
+		}
+		nodes = append(nodes, &node)
+	}
+	return nodes, nil
+}
+
+// SetLinkGroupParent переносит группу вместе с подгруппами в parentID (nil - в корень).
+// Перенос в собственную подгруппу отклоняется
+func (r *linkRepository) SetLinkGroupParent(ctx context.Context, id, workspaceID int, parentID *int) error {
+	op := "link_repository.SetLinkGroupParent"
+
+	// Блокируем группы пространства, чтобы параллельные переносы не замкнули цикл
+	queryLock := `
+		SELECT id
+		FROM link_groups
+		WHERE workspace_id = $1 AND
+		      deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	queryCycle := `
+		SELECT EXISTS (
+			SELECT 1 FROM (` + subgroupIDsQuery("$1") + `) s WHERE s.id = $2
+		)
+	`
+
+	query := `
+		UPDATE link_groups
+			SET parent_id = $1,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND workspace_id = $3 AND deleted_at IS NULL
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "перенос группы ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, queryLock, workspaceID); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "перенос группы ссылок", op)
+	}
+
+	if parentID != nil {
+		var cycle bool
+		if err := tx.QueryRow(ctx, queryCycle, id, *parentID).Scan(&cycle); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "перенос группы ссылок", op)
+		}
+
+		if cycle {
+			return app_errors.BadRequest("Группу нельзя перенести в саму себя или в свою подгруппу", op)
+		}
+	}
+
+	before, err := lockLinkGroup(ctx, tx, id)
+	if err != nil {
+		return app_errors.HandleDBError(err, "перенос группы ссылок", op)
+	}
+	if before.WorkspaceID != workspaceID {
+		return app_errors.NotFound("группа ссылок не найдена", op)
+	}
+
+	if _, err := tx.Exec(ctx, query, parentID, id, workspaceID); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "перенос группы ссылок", op)
+	}
+
+	changes := diffHistoryFields(map[string]any{"parent_id": before.ParentID}, map[string]any{"parent_id": parentID})
+	if err := writeHistory(ctx, tx, models.EntityLinkGroup, id, before.UserID, models.HistoryUpdate, changes); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "перенос группы ссылок", op)
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const linkNoteColumns = `n.id, n.link_id, n.user_id, n.body, n.created_at, n.updated_at`
+
+const linkHighlightColumns = `h.id, h.link_id, h.user_id, h.quote, h.anchor, h.created_at`
+
+func scanLinkNote(row pgx.Row, note *models.LinkNote) error {
+	return row.Scan(&note.ID, &note.LinkID, &note.UserID, &note.Body, &note.CreatedAt, &note.UpdatedAt)
+}
+
+func scanLinkHighlight(row pgx.Row, highlight *models.LinkHighlight) error {
+	return row.Scan(&highlight.ID, &highlight.LinkID, &highlight.UserID, &highlight.Quote, &highlight.Anchor, &highlight.CreatedAt)
+}
+
+// GetLinkNotes заметки пользователя к ссылке от старых к новым
+func (r *linkRepository) GetLinkNotes(ctx context.Context, linkID, userID int) ([]*models.LinkNote, error) {
+	op := "link_repository.GetLinkNotes"
+
+	query := `
+		SELECT ` + linkNoteColumns + `
+		FROM link_notes n
+		WHERE n.link_id = $1 AND
+		      n.user_id = $2
+		ORDER BY n.created_at, n.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, linkID, userID)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "получение заметок", op)
+	}
+	defer rows.Close()
+
+	notes := []*models.LinkNote{}
+	for rows.Next() {
+		var note models.LinkNote
+		if err := scanLinkNote(rows, &note); err != nil {
+			return nil, app_errors.HandleDBError(err, "получение заметок", op)
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение заметок", op)
+	}
+	return notes, nil
+}
+
+func (r *linkRepository) CreateLinkNote(ctx context.Context, note *models.LinkNote) error {
+	op := "link_repository.CreateLinkNote"
+
+	query := `
+		INSERT INTO link_notes (link_id, user_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query, note.LinkID, note.UserID, note.Body).Scan(&note.ID, &note.CreatedAt, &note.UpdatedAt); err != nil {
+		return app_errors.HandleDBError(err, "создание заметки", op)
+	}
+	return nil
+}
+
+// UpdateLinkNote меняет текст заметки. Чужую заметку найти нельзя
+func (r *linkRepository) UpdateLinkNote(ctx context.Context, note *models.LinkNote) error {
+	op := "link_repository.UpdateLinkNote"
+
+	query := `
+		UPDATE link_notes n
+			SET body = $4,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE n.id = $1 AND
+		      n.link_id = $2 AND
+		      n.user_id = $3
+		RETURNING ` + linkNoteColumns
+
+	if err := scanLinkNote(r.pool.QueryRow(ctx, query, note.ID, note.LinkID, note.UserID, note.Body), note); err != nil {
+		return app_errors.HandleDBError(err, "изменение заметки", op)
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteLinkNote(ctx context.Context, id, linkID, userID int) error {
+	op := "link_repository.DeleteLinkNote"
+
+	query := `
+		DELETE FROM link_notes
+		WHERE id = $1 AND
+		      link_id = $2 AND
+		      user_id = $3
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, linkID, userID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление заметки", op)
+	}
+
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Заметка не найдена", op)
+	}
+	return nil
+}
+
+// GetLinkHighlights цитаты пользователя со страницы ссылки: по положению на странице, без него - в конце
+func (r *linkRepository) GetLinkHighlights(ctx context.Context, linkID, userID int) ([]*models.LinkHighlight, error) {
+	op := "link_repository.GetLinkHighlights"
+
+	query := `
+		SELECT ` + linkHighlightColumns + `
+		FROM link_highlights h
+		WHERE h.link_id = $1 AND
+		      h.user_id = $2
+		ORDER BY (h.anchor->>'start')::int NULLS LAST, h.created_at, h.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, linkID, userID)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "получение цитат", op)
+	}
+	defer rows.Close()
+
+	highlights := []*models.LinkHighlight{}
+	for rows.Next() {
+		var highlight models.LinkHighlight
+		if err := scanLinkHighlight(rows, &highlight); err != nil {
+			return nil, app_errors.HandleDBError(err, "получение цитат", op)
+		}
+		highlights = append(highlights, &highlight)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение цитат", op)
+	}
+	return highlights, nil
+}
+
+func (r *linkRepository) CreateLinkHighlight(ctx context.Context, highlight *models.LinkHighlight) error {
+	op := "link_repository.CreateLinkHighlight"
+
+	query := `
+		INSERT INTO link_highlights (link_id, user_id, quote, anchor)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query, highlight.LinkID, highlight.UserID, highlight.Quote, highlight.Anchor).Scan(&highlight.ID, &highlight.CreatedAt); err != nil {
+		return app_errors.HandleDBError(err, "создание цитаты", op)
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteLinkHighlight(ctx context.Context, id, linkID, userID int) error {
+	op := "link_repository.DeleteLinkHighlight"
+
+	query := `
+		DELETE FROM link_highlights
+		WHERE id = $1 AND
+		      link_id = $2 AND
+		      user_id = $3
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, linkID, userID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление цитаты", op)
+	}
+
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Цитата не найдена", op)
+	}
+	return nil
+}
+
+// GetExportLinks ссылки пространства без удаленных с группой, заметками и цитатами пользователя userID
+func (r *linkRepository) GetExportLinks(ctx context.Context, workspaceID, userID int) ([]*models.ExportLink, error) {
+	op := "link_repository.GetExportLinks"
+
+	queryLinks := `
+		SELECT ` + linkColumns + `, g.name
+		FROM links l
+		LEFT JOIN link_groups g ON g.id = l.link_group_id AND g.deleted_at IS NULL
+		WHERE l.workspace_id = $1 AND
+		      l.deleted_at IS NULL
+		ORDER BY g.name NULLS FIRST, l.position, l.title, l.id
+	`
+
+	queryNotes := `
+		SELECT ` + linkNoteColumns + `
+		FROM link_notes n
+		JOIN links l ON l.id = n.link_id
+		WHERE l.workspace_id = $1 AND
+		      l.deleted_at IS NULL AND
+		      n.user_id = $2
+		ORDER BY n.created_at, n.id
+	`
+
+	queryHighlights := `
+		SELECT ` + linkHighlightColumns + `
+		FROM link_highlights h
+		JOIN links l ON l.id = h.link_id
+		WHERE l.workspace_id = $1 AND
+		      l.deleted_at IS NULL AND
+		      h.user_id = $2
+		ORDER BY (h.anchor->>'start')::int NULLS LAST, h.created_at, h.id
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "выгрузка ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, queryLinks, workspaceID)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "выгрузка ссылок", op)
+	}
+
+	links := []*models.ExportLink{}
+	byID := make(map[int]*models.ExportLink)
+	for rows.Next() {
+		link := &models.ExportLink{Notes: []*models.LinkNote{}, Highlights: []*models.LinkHighlight{}}
+		if err := scanLink(rows, &link.Link, &link.GroupName); err != nil {
+			rows.Close()
+			return nil, app_errors.HandleDBError(err, "выгрузка ссылок", op)
+		}
+		links = append(links, link)
+		byID[link.ID] = link
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "выгрузка ссылок", op)
+	}
+
+	rows, err = tx.Query(ctx, queryNotes, workspaceID, userID)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "выгрузка заметок", op)
+	}
+	for rows.Next() {
+		var note models.LinkNote
+		if err := scanLinkNote(rows, &note); err != nil {
+			rows.Close()
+			return nil, app_errors.HandleDBError(err, "выгрузка заметок", op)
+		}
+		if link := byID[note.LinkID]; link != nil {
+			link.Notes = append(link.Notes, &note)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "выгрузка заметок", op)
+	}
+
+	rows, err = tx.Query(ctx, queryHighlights, workspaceID, userID)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "выгрузка цитат", op)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var highlight models.LinkHighlight
+		if err := scanLinkHighlight(rows, &highlight); err != nil {
+			return nil, app_errors.HandleDBError(err, "выгрузка цитат", op)
+		}
+		if link := byID[highlight.LinkID]; link != nil {
+			link.Highlights = append(link.Highlights, &highlight)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "выгрузка цитат", op)
+	}
+
+	return links, nil
+}
@@ -0,0 +1,150 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// SetLinkReadAt отмечает ссылку прочитанной (readAt) или возвращает в список чтения (nil).
+// Прочитанной ссылке напоминание больше не нужно. В историю не попадает, как и проверка ссылки
+func (r *linkRepository) SetLinkReadAt(ctx context.Context, linkID int, readAt *time.Time) error {
+	op := "link_repository.SetLinkReadAt"
+
+	query := `
+		UPDATE links
+			SET read_at = $2,
+			    remind_at = CASE WHEN $2::timestamptz IS NULL THEN remind_at END,
+			    remind_user_id = CASE WHEN $2::timestamptz IS NULL THEN remind_user_id END,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND
+		      deleted_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, linkID, readAt)
+	if err != nil {
+		return app_errors.HandleDBError(err, "отметка прочтения ссылки", op)
+	}
+
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Ссылка не найдена", op)
+	}
+	return nil
+}
+
+// SetLinkReadingMinutes сохраняет оценку времени чтения страницы
+func (r *linkRepository) SetLinkReadingMinutes(ctx context.Context, linkID, minutes int) error {
+	op := "link_repository.SetLinkReadingMinutes"
+
+	query := `
+		UPDATE links
+			SET reading_minutes = $2
+		WHERE id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, linkID, minutes); err != nil {
+		return app_errors.HandleDBError(err, "сохранение времени чтения ссылки", op)
+	}
+	return nil
+}
+
+// SetLinkReminder ставит напоминание пользователю userID на remindAt, nil - снимает напоминание.
+// У ссылки одно напоминание: новое заменяет прежнее
+func (r *linkRepository) SetLinkReminder(ctx context.Context, linkID int, userID *int, remindAt *time.Time) error {
+	op := "link_repository.SetLinkReminder"
+
+	query := `
+		UPDATE links
+			SET remind_at = $2,
+			    remind_user_id = $3,
+			    remind_attempts = 0,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND
+		      deleted_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, linkID, remindAt, userID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "установка напоминания", op)
+	}
+
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Ссылка не найдена", op)
+	}
+	return nil
+}
+
+// ClaimDueLinkReminders забирает до limit наступивших к now напоминаний и снимает их со ссылок.
+// Неотправленное напоминание возвращается на ссылку через RetryLinkReminder.
+// Напоминания пользователей, которые заблокированы или вышли из пространства, снимаются без отправки
+func (r *linkRepository) ClaimDueLinkReminders(ctx context.Context, now time.Time, limit int) ([]*models.LinkReminder, error) {
+	op := "link_repository.ClaimDueLinkReminders"
+
+	query := `
+		WITH due AS (
+			SELECT id, remind_at, remind_user_id, remind_attempts
+			FROM links
+			WHERE remind_at <= $1 AND
+			      deleted_at IS NULL
+			ORDER BY remind_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE links l
+				SET remind_at = NULL,
+				    remind_user_id = NULL,
+				    remind_attempts = 0
+			FROM due
+			WHERE l.id = due.id
+			RETURNING l.*, due.remind_at AS due_remind_at, due.remind_user_id AS due_user_id,
+			          due.remind_attempts AS due_attempts
+		)
+		SELECT ` + linkColumns + `, l.due_remind_at, u.id, u.email, u.name, l.due_attempts
+		FROM claimed l
+		JOIN users u ON u.id = l.due_user_id AND u.is_active = true
+		JOIN workspace_members wm ON wm.workspace_id = l.workspace_id AND wm.user_id = u.id
+		ORDER BY l.due_remind_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, app_errors.HandleDBError(err, "получение напоминаний", op)
+	}
+	defer rows.Close()
+
+	reminders := []*models.LinkReminder{}
+	for rows.Next() {
+		reminder := &models.LinkReminder{Link: &models.Link{}}
+		if err := scanLink(rows, reminder.Link, &reminder.RemindAt, &reminder.UserID, &reminder.UserEmail, &reminder.UserName, &reminder.Attempts); err != nil {
+			return nil, app_errors.HandleDBError(err, "получение напоминаний", op)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение напоминаний", op)
+	}
+	return reminders, nil
+}
+
+// RetryLinkReminder возвращает на ссылку неотправленное напоминание с новым временем remindAt и числом
+// неудачных попыток attempts. Если пользователь уже поставил новое напоминание, оно не заменяется
+func (r *linkRepository) RetryLinkReminder(ctx context.Context, reminder *models.LinkReminder, remindAt time.Time, attempts int) error {
+	op := "link_repository.RetryLinkReminder"
+
+	query := `
+		UPDATE links
+			SET remind_at = $2,
+			    remind_user_id = $3,
+			    remind_attempts = $4
+		WHERE id = $1 AND
+		      remind_at IS NULL AND
+		      deleted_at IS NULL
+	`
+
+	if _, err := r.pool.Exec(ctx, query, reminder.Link.ID, remindAt, reminder.UserID, attempts); err != nil {
+		return app_errors.HandleDBError(err, "повтор напоминания", op)
+	}
+	return nil
+}
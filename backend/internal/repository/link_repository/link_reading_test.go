@@ -0,0 +1,83 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"testing"
+	"time"
+)
+
+// claimTestReminder напоминание о ссылке linkID из наступивших к now, nil - не найдено
+func claimTestReminder(t *testing.T, r *linkRepository, now time.Time, linkID int) *models.LinkReminder {
+	t.Helper()
+
+	reminders, err := r.ClaimDueLinkReminders(context.Background(), now, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, reminder := range reminders {
+		if reminder.Link.ID == linkID {
+			return reminder
+		}
+	}
+	return nil
+}
+
+func TestRetryLinkReminder(t *testing.T) {
+	r := newTestRepository(t)
+	userID, workspaceID := seedTestWorkspace(t, r, "reminder")
+	ctx := context.Background()
+
+	if _, err := r.pool.Exec(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, 'owner')`, workspaceID, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	link := &models.Link{UserID: userID, WorkspaceID: workspaceID, URL: "https://reminder.test/a"}
+	if err := r.CreateLink(ctx, link, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	remindAt := now.Add(-time.Minute)
+	if err := r.SetLinkReminder(ctx, link.ID, &userID, &remindAt); err != nil {
+		t.Fatal(err)
+	}
+
+	reminder := claimTestReminder(t, r, now, link.ID)
+	if reminder == nil || reminder.Attempts != 0 || reminder.UserID != userID {
+		t.Fatalf("забрано напоминание %+v", reminder)
+	}
+	if again := claimTestReminder(t, r, now, link.ID); again != nil {
+		t.Fatal("напоминание забрано дважды")
+	}
+
+	// Неотправленное напоминание возвращается на ссылку и снова наступает в новое время
+	retryAt := now.Add(time.Hour)
+	if err := r.RetryLinkReminder(ctx, reminder, retryAt, 1); err != nil {
+		t.Fatal(err)
+	}
+	if early := claimTestReminder(t, r, now, link.ID); early != nil {
+		t.Fatal("повтор наступил раньше срока")
+	}
+
+	reminder = claimTestReminder(t, r, retryAt, link.ID)
+	if reminder == nil || reminder.Attempts != 1 || !reminder.RemindAt.Equal(retryAt) {
+		t.Fatalf("повтор напоминания %+v", reminder)
+	}
+
+	// Новое напоминание пользователя повтор не заменяет, счетчик попыток у нового - с нуля
+	userRemindAt := now.Add(3 * time.Hour)
+	if err := r.SetLinkReminder(ctx, link.ID, &userID, &userRemindAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RetryLinkReminder(ctx, reminder, retryAt, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	reminder = claimTestReminder(t, r, userRemindAt, link.ID)
+	if reminder == nil || reminder.Attempts != 0 || !reminder.RemindAt.Equal(userRemindAt) {
+		t.Fatalf("напоминание пользователя %+v", reminder)
+	}
+}
@@ -0,0 +1,57 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+func (r *linkRepository) GetLinkBySlug(ctx context.Context, slug string) (*models.Link, error) {
+	op := "link_repository.GetLinkBySlug"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links l
+		WHERE lower(l.slug) = lower($1) AND
+		      l.deleted_at IS NULL
+	`
+
+	var link models.Link
+
+	if err := scanLink(r.pool.QueryRow(ctx, query, slug), &link); err != nil {
+		return nil, app_errors.HandleDBError(err, "Получение ссылки по короткому имени", op)
+	}
+
+	return &link, nil
+}
+
+func (r *linkRepository) HasLinkWithSlug(ctx context.Context, slug string) (bool, error) {
+	op := "link_repository.HasLinkWithSlug"
+
+	query := `
+		SELECT EXISTS(SELECT 1 FROM links WHERE lower(slug) = lower($1))
+	`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, slug).Scan(&exists); err != nil {
+		return false, app_errors.HandleDBError(err, "проверка занятости короткого имени", op)
+	}
+	return exists, nil
+}
+
+// SetLinkSlug устанавливает короткую ссылку, slug == nil - удаляет ее
+func (r *linkRepository) SetLinkSlug(ctx context.Context, linkID int, slug *string, expiresAt *time.Time, isPublic bool) error {
+	op := "link_repository.SetLinkSlug"
+
+	query := `
+		UPDATE links
+			SET slug = $1,
+			    slug_expires_at = $2,
+			    slug_is_public = $3,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+
+	return r.updateLinkWithHistory(ctx, op, "Установка короткой ссылки", models.HistoryUpdate, linkID, query, slug, expiresAt, isPublic, linkID)
+}
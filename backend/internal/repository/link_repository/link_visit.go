@@ -0,0 +1,287 @@
+package link_repository
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+func (r *linkRepository) LinkVisitedPlus(ctx context.Context, visit *models.LinkVisit) error {
+	op := "link_repository.LinkVisitedPlus"
+
+	if visit.VisitedAt.IsZero() {
+		visit.VisitedAt = time.Now()
+	}
+
+	query := `
+		UPDATE links
+			SET click_count = COALESCE(click_count, 0) + 1,
+			last_visited = $2
+		WHERE id = $1 AND
+		      deleted_at IS NULL
+	`
+
+	queryVisit := `
+		INSERT INTO link_visits (link_id, user_id, referrer, client, slug, visited_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "увеличение счетчика посещений ссылки", op)
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Двигаем счетчик посещений
+	result, err := tx.Exec(ctx, query, visit.LinkID, visit.VisitedAt)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "увеличение счетчика посещений ссылки", op)
+	}
+
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("ссылка не найдена", op)
+	}
+
+	// 2. Записываем событие посещения
+	if err := tx.QueryRow(ctx, queryVisit,
+		visit.LinkID,
+		visit.UserID,
+		visit.Referrer,
+		visit.Client,
+		visit.Slug,
+		visit.VisitedAt).Scan(&visit.ID); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись посещения ссылки", op)
+	}
+
+	// 3. Пересчитываем frecency ссылки
+	if _, err := tx.Exec(ctx, queryRecalculateFrecency, visit.LinkID); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "пересчет frecency ссылки", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "увеличение счетчика посещений ссылки", op)
+	}
+
+	return nil
+}
+
+// GetLinksTopVisited топ посещаемых ссылок, since == nil - по общему счетчику за все время
+func (r *linkRepository) GetLinksTopVisited(ctx context.Context, workspaceID, limit int, since *time.Time) ([]*models.LinkTopVisited, error) {
+	op := "link_repository.GetLinksTopVisited"
+
+	query := `
+		SELECT ` + linkColumns + `, l.click_count
+		FROM links l
+		WHERE l.workspace_id = $1 AND
+			  l.click_count > 0 AND
+			  l.is_archived = false AND
+			  l.deleted_at IS NULL
+		ORDER BY l.click_count DESC
+		LIMIT $2
+	`
+	args := []any{workspaceID, limit}
+
+	if since != nil {
+		// Сырые события за период плюс дневные агрегаты уже свернутых событий
+		query = `
+			SELECT ` + linkColumns + `, v.visits
+			FROM links l
+			JOIN (
+				SELECT link_id, SUM(visits) AS visits
+				FROM (
+					SELECT link_id, COUNT(*) AS visits
+					FROM link_visits
+					WHERE link_id IN (SELECT id FROM links WHERE workspace_id = $1) AND visited_at >= $3
+					GROUP BY link_id
+					UNION ALL
+					SELECT link_id, SUM(visits) AS visits
+					FROM link_visits_daily
+					WHERE link_id IN (SELECT id FROM links WHERE workspace_id = $1) AND day >= $3::date
+					GROUP BY link_id
+				) s
+				GROUP BY link_id
+			) v ON v.link_id = l.id
+			WHERE l.workspace_id = $1 AND
+				  l.is_archived = false AND
+				  l.deleted_at IS NULL
+			ORDER BY v.visits DESC, l.title ASC
+			LIMIT $2
+		`
+		args = append(args, *since)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение топа посещаемых ссылок", op)
+	}
+	defer rows.Close()
+
+	var links []*models.LinkTopVisited
+
+	for rows.Next() {
+		var link models.LinkTopVisited
+		if err := scanLink(rows, &link.Link, &link.Visits); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение топа посещаемых ссылок", op)
+		}
+		links = append(links, &link)
+	}
+	return links, nil
+}
+
+func (r *linkRepository) GetLinksRecentVisited(ctx context.Context, workspaceID, limit int) ([]*models.LinkRecentVisited, error) {
+	op := "link_repository.GetLinksRecentVisited"
+
+	query := `
+		SELECT ` + linkColumns + `, v.visited_at
+		FROM links l
+		JOIN (
+			SELECT link_id, MAX(visited_at) AS visited_at
+			FROM link_visits
+			WHERE link_id IN (SELECT id FROM links WHERE workspace_id = $1)
+			GROUP BY link_id
+		) v ON v.link_id = l.id
+		WHERE l.workspace_id = $1 AND
+		      l.deleted_at IS NULL
+		ORDER BY v.visited_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID, limit)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение недавно посещенных ссылок", op)
+	}
+	defer rows.Close()
+
+	var links []*models.LinkRecentVisited
+
+	for rows.Next() {
+		var link models.LinkRecentVisited
+		if err := scanLink(rows, &link.Link, &link.VisitedAt); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение недавно посещенных ссылок", op)
+		}
+		links = append(links, &link)
+	}
+	return links, nil
+}
+
+// GetVisitsPerDay посещения по дням для ссылки или группы ссылок пространства из фильтра
+func (r *linkRepository) GetVisitsPerDay(ctx context.Context, workspaceID int, filter models.VisitsFilter) ([]*models.VisitsPerDay, error) {
+	op := "link_repository.GetVisitsPerDay"
+
+	args := []any{workspaceID}
+	where := ""
+
+	if filter.LinkID > 0 {
+		where += fmt.Sprintf(` AND link_id = $%d`, len(args)+1)
+		args = append(args, filter.LinkID)
+	}
+
+	if filter.LinkGroupID > 0 {
+		where += fmt.Sprintf(` AND link_id IN (SELECT id FROM links WHERE link_group_id = $%d)`, len(args)+1)
+		args = append(args, filter.LinkGroupID)
+	}
+
+	whereRaw := where
+	whereDaily := where
+	if filter.Since != nil {
+		whereRaw += fmt.Sprintf(` AND visited_at >= $%d`, len(args)+1)
+		whereDaily += fmt.Sprintf(` AND day >= $%d::date`, len(args)+1)
+		args = append(args, *filter.Since)
+	}
+
+	dailyVisits := "visits"
+	if filter.ViaSlug {
+		whereRaw += ` AND slug <> ''`
+		dailyVisits = "slug_visits"
+	}
+
+	query := `
+		SELECT day, SUM(visits)::int
+		FROM (
+			SELECT visited_at::date AS day, COUNT(*) AS visits
+			FROM link_visits
+			WHERE link_id IN (SELECT id FROM links WHERE workspace_id = $1)` + whereRaw + `
+			GROUP BY 1
+			UNION ALL
+			SELECT day, SUM(` + dailyVisits + `) AS visits
+			FROM link_visits_daily
+			WHERE link_id IN (SELECT id FROM links WHERE workspace_id = $1)` + whereDaily + `
+			GROUP BY 1
+		) s
+		GROUP BY day
+		HAVING SUM(visits) > 0
+		ORDER BY day
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение посещений по дням", op)
+	}
+	defer rows.Close()
+
+	var visits []*models.VisitsPerDay
+
+	for rows.Next() {
+		var visit models.VisitsPerDay
+		if err := rows.Scan(&visit.Day, &visit.Visits); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение посещений по дням", op)
+		}
+		visits = append(visits, &visit)
+	}
+	return visits, nil
+}
+
+// RollupLinkVisits сворачивает события посещений до rawBefore в дневные агрегаты
+// и удаляет агрегаты старше dailyBefore (если задан). Возвращает количество обновленных агрегатов
+func (r *linkRepository) RollupLinkVisits(ctx context.Context, rawBefore time.Time, dailyBefore *time.Time) (int64, error) {
+	op := "link_repository.RollupLinkVisits"
+
+	// Перенос и удаление выполняются одним запросом, чтобы события не посчитались дважды
+	query := `
+		WITH moved AS (
+			DELETE FROM link_visits
+			WHERE visited_at < $1
+			RETURNING link_id, user_id, slug, visited_at
+		)
+		INSERT INTO link_visits_daily (link_id, user_id, day, visits, slug_visits)
+		SELECT link_id, user_id, visited_at::date, COUNT(*), COUNT(*) FILTER (WHERE slug <> '')
+		FROM moved
+		GROUP BY link_id, user_id, visited_at::date
+		ON CONFLICT (link_id, day) DO UPDATE
+			SET visits = link_visits_daily.visits + EXCLUDED.visits,
+			    slug_visits = link_visits_daily.slug_visits + EXCLUDED.slug_visits
+	`
+
+	queryDeleteDaily := `
+		DELETE FROM link_visits_daily
+		WHERE day < $1::date
+	`
+
+	result, err := r.pool.Exec(ctx, query, rawBefore)
+	if err != nil {
+		r.logger.Error(err, op)
+		return 0, app_errors.HandleDBError(err, "свертка посещений ссылок", op)
+	}
+
+	if dailyBefore != nil {
+		if _, err := r.pool.Exec(ctx, queryDeleteDaily, *dailyBefore); err != nil {
+			r.logger.Error(err, op)
+			return 0, app_errors.HandleDBError(err, "удаление устаревших агрегатов посещений", op)
+		}
+	}
+
+	return result.RowsAffected(), nil
+}
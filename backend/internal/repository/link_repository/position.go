@@ -0,0 +1,201 @@
+package link_repository
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// positionGap шаг между соседними позициями. Перемещение ставит элемент в середину промежутка
+// между соседями, и только когда промежуток исчерпан, позиции всего списка раздаются заново
+const positionGap = 1024
+
+// positionScope список, внутри которого упорядочиваются строки
+type positionScope struct {
+	table string
+	// where условие отбора строк списка, параметры начинаются с $1
+	where string
+	args  []any
+}
+
+func linkGroupsScope(workspaceID int) positionScope {
+	return positionScope{
+		table: "link_groups",
+		where: "workspace_id = $1 AND deleted_at IS NULL",
+		args:  []any{workspaceID},
+	}
+}
+
+func groupLinksScope(workspaceID, linkGroupID int) positionScope {
+	return positionScope{
+		table: "links",
+		where: "workspace_id = $1 AND link_group_id = $2 AND deleted_at IS NULL",
+		args:  []any{workspaceID, linkGroupID},
+	}
+}
+
+type positionRow struct {
+	id       int
+	position int
+}
+
+func (r *linkRepository) SetLinkGroupsOrder(ctx context.Context, workspaceID int, order *models.PositionOrder) error {
+	return r.setOrder(ctx, linkGroupsScope(workspaceID), order, "link_repository.SetLinkGroupsOrder")
+}
+
+func (r *linkRepository) SetLinksOrder(ctx context.Context, workspaceID, linkGroupID int, order *models.PositionOrder) error {
+	return r.setOrder(ctx, groupLinksScope(workspaceID, linkGroupID), order, "link_repository.SetLinksOrder")
+}
+
+func (r *linkRepository) setOrder(ctx context.Context, scope positionScope, order *models.PositionOrder, op string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "изменение порядка", op)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := lockPositions(ctx, tx, scope)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "изменение порядка", op)
+	}
+
+	var positions map[int]int
+	if order.IsMove() {
+		positions, err = movePositions(rows, order, op)
+	} else {
+		positions, err = listPositions(rows, order.IDs, op)
+	}
+	if err != nil {
+		return err
+	}
+
+	for id, position := range positions {
+		if err := updatePosition(ctx, tx, scope.table, id, position); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "изменение порядка", op)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "изменение порядка", op)
+	}
+	return nil
+}
+
+// lockPositions блокирует строки списка и возвращает их в текущем порядке
+func lockPositions(ctx context.Context, tx pgx.Tx, scope positionScope) ([]positionRow, error) {
+	query := fmt.Sprintf(`
+		SELECT id, position
+		FROM %s
+		WHERE %s
+		ORDER BY position, id
+		FOR UPDATE
+	`, scope.table, scope.where)
+
+	rows, err := tx.Query(ctx, query, scope.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []positionRow
+	for rows.Next() {
+		var row positionRow
+		if err := rows.Scan(&row.id, &row.position); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func updatePosition(ctx context.Context, tx pgx.Tx, table string, id, position int) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+			SET position = $1
+		WHERE id = $2
+	`, table)
+
+	_, err := tx.Exec(ctx, query, position, id)
+	return err
+}
+
+// listPositions новые позиции для полного порядка ids. ids должны совпадать со списком
+func listPositions(rows []positionRow, ids []int, op string) (map[int]int, error) {
+	if len(ids) != len(rows) {
+		return nil, app_errors.BadRequest("Список ids должен содержать все элементы", op)
+	}
+
+	current := make(map[int]int, len(rows))
+	for _, row := range rows {
+		current[row.id] = row.position
+	}
+
+	positions := map[int]int{}
+	for i, id := range ids {
+		position, ok := current[id]
+		if !ok {
+			return nil, app_errors.BadRequest(fmt.Sprintf("Элемент %d не найден в списке", id), op)
+		}
+		if newPosition := (i + 1) * positionGap; newPosition != position {
+			positions[id] = newPosition
+		}
+	}
+	return positions, nil
+}
+
+// movePositions новые позиции при перемещении элемента: обычно меняется одна строка
+func movePositions(rows []positionRow, order *models.PositionOrder, op string) (map[int]int, error) {
+	index := slices.IndexFunc(rows, func(row positionRow) bool { return row.id == order.ID })
+	if index < 0 {
+		return nil, app_errors.NotFound("Перемещаемый элемент не найден", op)
+	}
+	moved := rows[index]
+	rest := slices.Delete(slices.Clone(rows), index, index+1)
+
+	anchorID := order.BeforeID
+	if anchorID == 0 {
+		anchorID = order.AfterID
+	}
+
+	target := slices.IndexFunc(rest, func(row positionRow) bool { return row.id == anchorID })
+	if target < 0 {
+		return nil, app_errors.NotFound("Элемент, относительно которого выполняется перемещение, не найден", op)
+	}
+	if order.AfterID > 0 {
+		target++
+	}
+
+	prev := 0
+	if target > 0 {
+		prev = rest[target-1].position
+	}
+	next := prev + 2*positionGap
+	if target < len(rest) {
+		next = rest[target].position
+	}
+
+	if next-prev >= 2 {
+		position := prev + (next-prev)/2
+		if position == moved.position {
+			return nil, nil
+		}
+		return map[int]int{moved.id: position}, nil
+	}
+
+	// Промежуток исчерпан - раздаем позиции заново
+	ordered := slices.Insert(rest, target, moved)
+	positions := map[int]int{}
+	for i, row := range ordered {
+		if position := (i + 1) * positionGap; position != row.position {
+			positions[row.id] = position
+		}
+	}
+	return positions, nil
+}
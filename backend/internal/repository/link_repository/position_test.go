@@ -0,0 +1,141 @@
+package link_repository
+
+import (
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"slices"
+	"testing"
+)
+
+// applyPositions порядок ID после применения новых позиций, как в ORDER BY position, id
+func applyPositions(rows []positionRow, positions map[int]int) []int {
+	result := slices.Clone(rows)
+	for i, row := range result {
+		if position, ok := positions[row.id]; ok {
+			result[i].position = position
+		}
+	}
+	slices.SortFunc(result, func(a, b positionRow) int {
+		if a.position != b.position {
+			return a.position - b.position
+		}
+		return a.id - b.id
+	})
+
+	ids := make([]int, len(result))
+	for i, row := range result {
+		ids[i] = row.id
+	}
+	return ids
+}
+
+func gapRows(ids ...int) []positionRow {
+	rows := make([]positionRow, len(ids))
+	for i, id := range ids {
+		rows[i] = positionRow{id: id, position: (i + 1) * positionGap}
+	}
+	return rows
+}
+
+func TestMovePositions(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []positionRow
+		order   models.PositionOrder
+		want    []int
+		changed int
+	}{
+		{name: "в начало", rows: gapRows(1, 2, 3), order: models.PositionOrder{ID: 3, BeforeID: 1}, want: []int{3, 1, 2}, changed: 1},
+		{name: "в конец", rows: gapRows(1, 2, 3), order: models.PositionOrder{ID: 1, AfterID: 3}, want: []int{2, 3, 1}, changed: 1},
+		{name: "в середину", rows: gapRows(1, 2, 3, 4), order: models.PositionOrder{ID: 4, AfterID: 1}, want: []int{1, 4, 2, 3}, changed: 1},
+		{name: "на свое место", rows: gapRows(1, 2, 3), order: models.PositionOrder{ID: 2, AfterID: 1}, want: []int{1, 2, 3}, changed: 0},
+		{
+			name:    "промежуток исчерпан",
+			rows:    []positionRow{{id: 1, position: 10}, {id: 2, position: 11}, {id: 3, position: 12}},
+			order:   models.PositionOrder{ID: 3, AfterID: 1},
+			want:    []int{1, 3, 2},
+			changed: 3,
+		},
+		{
+			name:    "перед первым с позицией 1",
+			rows:    []positionRow{{id: 1, position: 1}, {id: 2, position: 2}},
+			order:   models.PositionOrder{ID: 2, BeforeID: 1},
+			want:    []int{2, 1},
+			changed: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions, err := movePositions(tt.rows, &tt.order, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(positions) != tt.changed {
+				t.Errorf("изменено позиций %d, ожидалось %d: %v", len(positions), tt.changed, positions)
+			}
+			if got := applyPositions(tt.rows, positions); !slices.Equal(got, tt.want) {
+				t.Errorf("порядок %v, ожидался %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Повторные перемещения в один промежуток делят его пополам, пока он не исчерпается,
+// после чего позиции раздаются заново, а порядок остается верным
+func TestMovePositionsRepeated(t *testing.T) {
+	rows := gapRows(1, 2, 3)
+	want := []int{1, 2, 3}
+
+	for i := range 20 {
+		// Последний элемент каждый раз ставится сразу после первого
+		last := want[len(want)-1]
+		positions, err := movePositions(rows, &models.PositionOrder{ID: last, AfterID: 1}, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, row := range rows {
+			if position, ok := positions[row.id]; ok {
+				rows[j].position = position
+			}
+		}
+		slices.SortFunc(rows, func(a, b positionRow) int { return a.position - b.position })
+
+		want = append([]int{1, last}, want[1:len(want)-1]...)
+		if got := applyPositions(rows, nil); !slices.Equal(got, want) {
+			t.Fatalf("шаг %d: порядок %v, ожидался %v", i, got, want)
+		}
+	}
+}
+
+func TestMovePositionsNotFound(t *testing.T) {
+	rows := gapRows(1, 2)
+	for _, order := range []models.PositionOrder{{ID: 5, AfterID: 1}, {ID: 1, BeforeID: 5}} {
+		if _, err := movePositions(rows, &order, "test"); !app_errors.IsNotFound(err) {
+			t.Errorf("%+v: ошибка %v, ожидалось NotFound", order, err)
+		}
+	}
+}
+
+func TestListPositions(t *testing.T) {
+	rows := gapRows(1, 2, 3)
+
+	positions, err := listPositions(rows, []int{3, 1, 2}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := applyPositions(rows, positions); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Errorf("порядок %v, ожидался [3 1 2]", got)
+	}
+
+	// Неизменные позиции не обновляются
+	if positions, _ := listPositions(rows, []int{1, 2, 3}, "test"); len(positions) != 0 {
+		t.Errorf("для прежнего порядка изменены позиции %v", positions)
+	}
+
+	for _, ids := range [][]int{{1, 2}, {1, 2, 4}} {
+		if _, err := listPositions(rows, ids, "test"); err == nil {
+			t.Errorf("%v: ожидалась ошибка", ids)
+		}
+	}
+}
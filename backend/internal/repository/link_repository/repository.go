@@ -5,6 +5,7 @@ import (
 	"link-storage/internal/models"
 	"link-storage/pkg/logger"
 	"link-storage/pkg/response"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -13,19 +14,135 @@ type LinkRepository interface {
 	//LinkGroup
 
 	CreateLinkGroup(ctx context.Context, linkGroup *models.LinkGroup) error
-	GetLinkGroupByID(ctc context.Context, id, userID int) (*models.LinkGroup, error)
-	HasLinkGroupWithNameByUserID(ctx context.Context, name string, userID int) (bool, error)
+	GetLinkGroupByID(ctc context.Context, id, workspaceID int) (*models.LinkGroup, error)
+	HasLinkGroupWithNameByWorkspaceID(ctx context.Context, name string, workspaceID int) (bool, error)
 	UpdateLinkGroup(ctx context.Context, linkGroup *models.LinkGroup) error
-	DeleteLinkGroup(ctx context.Context, id int) error
-	GetLinkGroupsByUserIDWithPagination(ctx context.Context, name string, userID int, limit, offset int) (*response.ListResponse[models.LinkGroup], error)
+	DeleteLinkGroup(ctx context.Context, id, workspaceID int, params *models.LinkGroupDelete) error
+	GetLinkGroupDeletePreview(ctx context.Context, id int) (*models.LinkGroupDeletePreview, error)
+	GetLinkGroupsByWorkspaceIDWithPagination(ctx context.Context, name string, workspaceID, userID int, limit, offset int) (*response.ListResponse[models.LinkGroup], error)
+	SetLinkGroupsOrder(ctx context.Context, workspaceID int, order *models.PositionOrder) error
+	GetLinkGroupsWithLinkCount(ctx context.Context, workspaceID int) ([]*models.LinkGroupNode, error)
+	SetLinkGroupParent(ctx context.Context, id, workspaceID int, parentID *int) error
+
+	// LinkGroupMember
+	GetLinkGroupForUser(ctx context.Context, id, userID int) (*models.LinkGroup, error)
+	GetLinkGroupMembers(ctx context.Context, linkGroupID int) ([]*models.LinkGroupMember, error)
+	SetLinkGroupMemberRole(ctx context.Context, linkGroupID, userID int, role models.LinkGroupRole) error
+	DeleteLinkGroupMember(ctx context.Context, linkGroupID, userID int) error
+	CreateLinkGroupInvite(ctx context.Context, invite *models.LinkGroupInvite) error
+	GetLinkGroupInvites(ctx context.Context, linkGroupID int) ([]*models.LinkGroupInvite, error)
+	DeleteLinkGroupInvite(ctx context.Context, id, linkGroupID int) error
+	AcceptLinkGroupInvite(ctx context.Context, token string, userID int, email string) (int, error)
+
+	// LinkGroupShare
+	GetLinkGroupShare(ctx context.Context, linkGroupID int) (*models.LinkGroupShare, error)
+	GetLinkGroupShareBySlug(ctx context.Context, slug string) (*models.LinkGroupShare, error)
+	SaveLinkGroupShare(ctx context.Context, share *models.LinkGroupShare) error
+	DeleteLinkGroupShare(ctx context.Context, linkGroupID int) error
+	GetPublicLinkGroupContent(ctx context.Context, linkGroupID int) ([]*models.LinkGroup, []*models.Link, error)
+
+	// Feed
+	GetTagByID(ctx context.Context, id, workspaceID int) (*models.Tag, error)
+	GetTagForUser(ctx context.Context, id, userID int) (*models.Tag, error)
+	GetTags(ctx context.Context, workspaceID int) ([]*models.Tag, error)
+	GetOrCreateTags(ctx context.Context, workspaceID, userID int, names []string) ([]int, error)
+	GetFeedToken(ctx context.Context, userID int, source models.FeedSource) (*models.FeedToken, error)
+	GetFeedTokenByToken(ctx context.Context, token string) (*models.FeedToken, error)
+	CreateFeedToken(ctx context.Context, feedToken *models.FeedToken) error
+	DeleteFeedToken(ctx context.Context, userID int, source models.FeedSource) error
+	GetFeedItems(ctx context.Context, source models.FeedSource, limit int) ([]*models.FeedItem, error)
+
+	// FeedSubscription
+	GetFeedSubscriptions(ctx context.Context, linkGroupID int) ([]*models.FeedSubscription, error)
+	GetFeedSubscription(ctx context.Context, id, linkGroupID int) (*models.FeedSubscription, error)
+	GetFeedSubscriptionsForPoll(ctx context.Context, now time.Time, limit int) ([]*models.FeedSubscription, error)
+	CreateFeedSubscription(ctx context.Context, subscription *models.FeedSubscription) error
+	DeleteFeedSubscription(ctx context.Context, id, linkGroupID int) error
+	SetFeedFetchResult(ctx context.Context, id int, result *models.FeedFetchResult) error
+	HasFeedSubscriptionItem(ctx context.Context, subscriptionID int, guid string) (bool, error)
+	AddFeedSubscriptionItem(ctx context.Context, subscriptionID int, guid string, linkID *int) error
+
+	// InboundEmail
+	GetInboundEmail(ctx context.Context, userID, workspaceID int) (*models.InboundEmail, error)
+	GetInboundEmailByToken(ctx context.Context, token string) (*models.InboundEmail, error)
+	SaveInboundEmail(ctx context.Context, inbox *models.InboundEmail) error
+	DeleteInboundEmail(ctx context.Context, userID, workspaceID int) error
 
 	// Link
-	CreateLink(ctx context.Context, link *models.Link) error
+	CreateLink(ctx context.Context, link *models.Link, tagIDs []int) error
+	UpdateLink(ctx context.Context, link *models.Link, action models.HistoryAction) error
 	GetLinkByID(ctx context.Context, id int) (*models.Link, error)
 	SetLinkFavIconAndTitle(ctx context.Context, linkID int, favIconPath, title string) error
-	GetLinksByUserIDWithPagination(ctx context.Context, userID, linkGroupID, limit, offset int, name string) (*response.ListResponse[models.LinkResponse], error)
-	LinkVisitedPlus(ctx context.Context, linkID int) error
-	GetLinksTopVisited(ctx context.Context, userID, limit int) ([]*models.Link, error)
+	GetLinksByWorkspaceIDWithPagination(ctx context.Context, workspaceID int, filter models.LinkFilter, limit, offset int) (*response.ListResponse[models.LinkResponse], error)
+	SetLinksOrder(ctx context.Context, workspaceID, linkGroupID int, order *models.PositionOrder) error
+	BulkUpdateLinks(ctx context.Context, workspaceID int, bulk *models.LinkBulk) ([]int, error)
+
+	// LinkVisit
+	LinkVisitedPlus(ctx context.Context, visit *models.LinkVisit) error
+	GetLinksTopVisited(ctx context.Context, workspaceID, limit int, since *time.Time) ([]*models.LinkTopVisited, error)
+	GetLinksRecentVisited(ctx context.Context, workspaceID, limit int) ([]*models.LinkRecentVisited, error)
+	GetVisitsPerDay(ctx context.Context, workspaceID int, filter models.VisitsFilter) ([]*models.VisitsPerDay, error)
+	RollupLinkVisits(ctx context.Context, rawBefore time.Time, dailyBefore *time.Time) (int64, error)
+	RecalculateFrecency(ctx context.Context, linkID int) (int64, error)
+	GetLinksForLauncher(ctx context.Context, workspaceID int, prefix string, limit int) ([]*models.Link, error)
+
+	// LinkSlug
+	GetLinkBySlug(ctx context.Context, slug string) (*models.Link, error)
+	HasLinkWithSlug(ctx context.Context, slug string) (bool, error)
+	SetLinkSlug(ctx context.Context, linkID int, slug *string, expiresAt *time.Time, isPublic bool) error
+
+	// LinkReading
+	SetLinkReadAt(ctx context.Context, linkID int, readAt *time.Time) error
+	SetLinkReadingMinutes(ctx context.Context, linkID, minutes int) error
+	SetLinkReminder(ctx context.Context, linkID int, userID *int, remindAt *time.Time) error
+	ClaimDueLinkReminders(ctx context.Context, now time.Time, limit int) ([]*models.LinkReminder, error)
+	RetryLinkReminder(ctx context.Context, reminder *models.LinkReminder, remindAt time.Time, attempts int) error
+
+	// LinkNote
+	GetLinkNotes(ctx context.Context, linkID, userID int) ([]*models.LinkNote, error)
+	CreateLinkNote(ctx context.Context, note *models.LinkNote) error
+	UpdateLinkNote(ctx context.Context, note *models.LinkNote) error
+	DeleteLinkNote(ctx context.Context, id, linkID, userID int) error
+	GetLinkHighlights(ctx context.Context, linkID, userID int) ([]*models.LinkHighlight, error)
+	CreateLinkHighlight(ctx context.Context, highlight *models.LinkHighlight) error
+	DeleteLinkHighlight(ctx context.Context, id, linkID, userID int) error
+	GetExportLinks(ctx context.Context, workspaceID, userID int) ([]*models.ExportLink, error)
+
+	// Digest
+	GetDigestSettings(ctx context.Context, userID int) (*models.DigestSettings, error)
+	SaveDigestSettings(ctx context.Context, settings *models.DigestSettings) error
+	GetDueDigests(ctx context.Context, now time.Time, limit int) ([]*models.DigestSettings, error)
+	ClaimDigest(ctx context.Context, userID int, sendAt, nextSendAt time.Time) (bool, error)
+	SetDigestSent(ctx context.Context, userID int, sentAt time.Time) error
+	UnsubscribeDigest(ctx context.Context, token string) error
+	GetDigestSharedLinks(ctx context.Context, userID int, since time.Time, limit int) ([]*models.DigestLink, error)
+	GetDigestUnreadLinks(ctx context.Context, userID, limit int) ([]*models.DigestLink, int, error)
+	GetDigestBrokenLinks(ctx context.Context, userID int, since time.Time, limit int) ([]*models.DigestLink, error)
+	GetDigestTopVisited(ctx context.Context, userID int, since time.Time, limit int) ([]*models.DigestLink, error)
+
+	// LinkCanonical
+	GetLinkByCanonicalURL(ctx context.Context, workspaceID int, canonicalURL string) (*models.Link, error)
+	SetLinkCanonicalURL(ctx context.Context, linkID int, canonicalURL string) error
+	GetLinksWithoutCanonicalURL(ctx context.Context, afterID, limit int) ([]*models.Link, error)
+
+	// LinkDuplicate
+	GetLinksByWorkspaceID(ctx context.Context, workspaceID int) ([]*models.Link, error)
+	MergeLinks(ctx context.Context, survivorID int, linkIDs []int) error
+
+	// Trash
+	DeleteLink(ctx context.Context, id int) error
+	GetTrashItems(ctx context.Context, workspaceID int, itemType models.TrashItemType, limit, offset int) (*response.ListResponse[models.TrashItem], error)
+	RestoreLink(ctx context.Context, id, workspaceID int) error
+	RestoreLinkGroup(ctx context.Context, id, workspaceID int) error
+	RestoreTag(ctx context.Context, id, workspaceID int) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+
+	// History
+	GetEntityHistory(ctx context.Context, entityType models.EntityType, entityID, userID int) ([]*models.HistoryEntry, error)
+
+	// LinkCheck
+	GetLinksForCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*models.Link, error)
+	SetLinkCheckResult(ctx context.Context, linkID int, httpStatus *int, finalURL string, broken bool, archiveAfter int) error
 }
 
 type linkRepository struct {
@@ -0,0 +1,56 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/pkg/database"
+	"link-storage/pkg/logger"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestRepository репозиторий на тестовой базе из TEST_DB_DSN с примененными миграциями.
+// Без TEST_DB_DSN тест пропускается
+func newTestRepository(tb testing.TB) *linkRepository {
+	tb.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		tb.Skip("TEST_DB_DSN не задан")
+	}
+
+	db, err := database.New(database.Config{DSN: dsn, MigrationPath: "../../../migrations"}, logger.New("error"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(db.Pool.Close)
+
+	return &linkRepository{pool: db.Pool, logger: logger.New("error")}
+}
+
+// seedTestWorkspace пользователь с личным пространством, удаляется после теста вместе с данными
+func seedTestWorkspace(tb testing.TB, r *linkRepository, name string) (int, int) {
+	tb.Helper()
+	ctx := context.Background()
+
+	var userID, workspaceID int
+	email := name + "-" + time.Now().Format("20060102150405.000000000") + "@test.local"
+	if err := r.pool.QueryRow(ctx, `
+		INSERT INTO users (name, email, password_hashed, is_active)
+		VALUES ($1, $2, '-', true)
+		RETURNING id`, name, email).Scan(&userID); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		_, _ = r.pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	if err := r.pool.QueryRow(ctx, `
+		INSERT INTO workspaces (name, personal_user_id)
+		VALUES ($1, $2)
+		RETURNING id`, name, userID).Scan(&workspaceID); err != nil {
+		tb.Fatal(err)
+	}
+
+	return userID, workspaceID
+}
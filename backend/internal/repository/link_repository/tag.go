@@ -0,0 +1,146 @@
+package link_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"strings"
+)
+
+// GetTags теги пространства без удаленных, по имени
+func (r *linkRepository) GetTags(ctx context.Context, workspaceID int) ([]*models.Tag, error) {
+	op := "link_repository.GetTags"
+
+	query := `
+		SELECT id, user_id, workspace_id, name, COALESCE(color, ''), created_at, updated_at
+		FROM tags
+		WHERE workspace_id = $1 AND
+		      deleted_at IS NULL
+		ORDER BY name, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение тегов", op)
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(
+			&tag.ID,
+			&tag.UserID,
+			&tag.WorkspaceID,
+			&tag.Name,
+			&tag.Color,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение тегов", op)
+		}
+		tags = append(tags, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение тегов", op)
+	}
+
+	return tags, nil
+}
+
+// GetOrCreateTags ID тегов пространства по именам без учета регистра, недостающие теги создаются
+// с записью в историю изменений той же транзакцией
+func (r *linkRepository) GetOrCreateTags(ctx context.Context, workspaceID, userID int, names []string) ([]int, error) {
+	op := "link_repository.GetOrCreateTags"
+
+	queryLock := `SELECT pg_advisory_xact_lock(hashtext('tags'), $1)`
+
+	query := `
+		WITH input AS (
+			SELECT DISTINCT ON (lower(name)) name
+			FROM unnest($3::text[]) AS name
+		),
+		existing AS (
+			SELECT DISTINCT ON (lower(t.name)) t.id, lower(t.name) AS key
+			FROM tags t
+			WHERE t.workspace_id = $1 AND
+			      t.deleted_at IS NULL AND
+			      lower(t.name) IN (SELECT lower(name) FROM input)
+			ORDER BY lower(t.name), t.id
+		),
+		created AS (
+			INSERT INTO tags (user_id, workspace_id, name)
+			SELECT $2, $1, i.name
+			FROM input i
+			WHERE lower(i.name) NOT IN (SELECT key FROM existing)
+			RETURNING id, name
+		)
+		SELECT id, NULL::text, false FROM existing
+		UNION ALL
+		SELECT id, name, true FROM created
+	`
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение тегов", op)
+	}
+	defer tx.Rollback(ctx)
+
+	// Теги без уникального индекса по имени: параллельное создание одного тега сериализуем
+	if _, err := tx.Exec(ctx, queryLock, workspaceID); err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение тегов", op)
+	}
+
+	rows, err := tx.Query(ctx, query, workspaceID, userID, names)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение тегов", op)
+	}
+
+	var ids []int
+	var created []*models.Tag
+	for rows.Next() {
+		var id int
+		var name *string
+		var isCreated bool
+		if err := rows.Scan(&id, &name, &isCreated); err != nil {
+			rows.Close()
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение тегов", op)
+		}
+		ids = append(ids, id)
+		if isCreated {
+			created = append(created, &models.Tag{ID: id, UserID: userID, WorkspaceID: workspaceID, Name: *name})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение тегов", op)
+	}
+
+	for _, tag := range created {
+		changes := diffHistoryFields(nil, tagHistoryFields(tag))
+		if err := writeHistory(ctx, tx, models.EntityTag, tag.ID, userID, models.HistoryCreate, changes); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "запись истории изменений", op)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение тегов", op)
+	}
+	return ids, nil
+}
@@ -0,0 +1,455 @@
+package link_repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DeleteLink перемещает ссылку в корзину. Группа и теги ссылки сохраняются для восстановления
+func (r *linkRepository) DeleteLink(ctx context.Context, id int) error {
+	op := "link_repository.DeleteLink"
+
+	query := `
+		UPDATE links
+			SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND
+		      deleted_at IS NULL
+		RETURNING user_id, deleted_at
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление ссылки", op)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	var deletedAt time.Time
+	if err := tx.QueryRow(ctx, query, id).Scan(&userID, &deletedAt); err != nil {
+		return app_errors.HandleDBError(err, "удаление ссылки", op)
+	}
+
+	if err := writeHistory(ctx, tx, models.EntityLink, id, userID, models.HistoryDelete, deletedAtChange(nil, &deletedAt)); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "удаление ссылки", op)
+	}
+	return nil
+}
+
+// GetTrashItems содержимое корзины пространства, itemType пустой - все типы, новые сверху
+func (r *linkRepository) GetTrashItems(ctx context.Context, workspaceID int, itemType models.TrashItemType, limit, offset int) (*response.ListResponse[models.TrashItem], error) {
+	op := "link_repository.GetTrashItems"
+
+	query := `
+		SELECT type, id, name, url, link_group_id, deleted_at
+		FROM (
+			SELECT 'link' AS type, id, title AS name, url, link_group_id, deleted_at
+			FROM links
+			WHERE workspace_id = $1 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT 'link_group', id, name, '', parent_id, deleted_at
+			FROM link_groups
+			WHERE workspace_id = $1 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT 'tag', id, name, '', NULL, deleted_at
+			FROM tags
+			WHERE workspace_id = $1 AND deleted_at IS NOT NULL
+		) t
+		WHERE $2 = '' OR type = $2
+	`
+
+	queryCount := `SELECT COUNT(*) FROM (` + query + `) c`
+	argsCount := []any{workspaceID, string(itemType)}
+
+	query += ` ORDER BY deleted_at DESC, type, id`
+	args := []any{workspaceID, string(itemType)}
+
+	if limit > 0 && offset >= 0 {
+		query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+		args = append(args, limit, offset)
+	}
+
+	var total int
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение корзины", op)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, queryCount, argsCount...).Scan(&total); err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение корзины", op)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение корзины", op)
+	}
+	defer rows.Close()
+
+	var items []*models.TrashItem
+
+	for rows.Next() {
+		var item models.TrashItem
+		var name *string
+		if err := rows.Scan(&item.Type, &item.ID, &name, &item.URL, &item.LinkGroupID, &item.DeletedAt); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение корзины", op)
+		}
+		if name != nil {
+			item.Name = *name
+		}
+		items = append(items, &item)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, app_errors.HandleDBError(err, "получение корзины", op)
+	}
+
+	page := 1
+	totalPages := 1
+	if limit > 0 {
+		page = offset/limit + 1
+		totalPages = (total + limit - 1) / limit
+	}
+
+	return &response.ListResponse[models.TrashItem]{
+		Data:       items,
+		Total:      total,
+		Page:       page,
+		PageSize:   limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// RestoreLink восстанавливает ссылку из корзины вместе с ее группой, если та тоже в корзине
+func (r *linkRepository) RestoreLink(ctx context.Context, id, workspaceID int) error {
+	op := "link_repository.RestoreLink"
+
+	queryLink := `
+		SELECT user_id, link_group_id, deleted_at
+		FROM links
+		WHERE id = $1 AND
+		      workspace_id = $2 AND
+		      deleted_at IS NOT NULL
+		FOR UPDATE
+	`
+
+	query := `
+		UPDATE links
+			SET deleted_at = NULL,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "восстановление ссылки", op)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	var linkGroupID *int
+	var deletedAt time.Time
+	if err := tx.QueryRow(ctx, queryLink, id, workspaceID).Scan(&userID, &linkGroupID, &deletedAt); err != nil {
+		return app_errors.HandleDBError(err, "восстановление ссылки", op)
+	}
+
+	// Возвращаем ссылку в ее группу: группу из корзины восстанавливаем без остальных ссылок
+	if linkGroupID != nil {
+		if err := r.restoreLinkGroups(ctx, tx, []int{*linkGroupID}, workspaceID, op); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return app_errors.HandleDBError(err, "восстановление ссылки", op)
+	}
+
+	if err := writeHistory(ctx, tx, models.EntityLink, id, userID, models.HistoryRestore, deletedAtChange(&deletedAt, nil)); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "восстановление ссылки", op)
+	}
+	return nil
+}
+
+// RestoreLinkGroup восстанавливает группу из корзины вместе с подгруппами и ссылками, удаленными с ней
+// одной операцией, и возвращает в группу ссылки, оставшиеся без нее при удалении
+func (r *linkRepository) RestoreLinkGroup(ctx context.Context, id, workspaceID int) error {
+	op := "link_repository.RestoreLinkGroup"
+
+	queryGroup := `
+		SELECT deleted_at
+		FROM link_groups
+		WHERE id = $1 AND
+		      workspace_id = $2 AND
+		      deleted_at IS NOT NULL
+		FOR UPDATE
+	`
+
+	queryTree := `
+		SELECT ARRAY(
+			WITH RECURSIVE tree AS (
+				SELECT id FROM link_groups WHERE id = $1
+				UNION
+				SELECT g.id FROM link_groups g JOIN tree t ON g.parent_id = t.id WHERE g.deleted_at = $2
+			)
+			SELECT id FROM tree
+		)
+	`
+
+	queryLinks := `
+		WITH restored AS (
+			UPDATE links
+				SET deleted_at = NULL,
+				    updated_at = CURRENT_TIMESTAMP
+			WHERE link_group_id = ANY($1) AND
+			      deleted_at = $2
+			RETURNING id, user_id
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $3, 'restore',
+		       jsonb_build_object('deleted_at', jsonb_build_object('old', $2::timestamptz, 'new', NULL))
+		FROM restored
+	`
+
+	queryOrphans := `
+		WITH returned AS (
+		UPDATE links l
+			SET link_group_id = o.group_id,
+			    orphaned_from_group_id = NULL,
+			    position = COALESCE(m.max_position, 0) + o.n * $2,
+			    updated_at = CURRENT_TIMESTAMP
+		FROM (
+			SELECT id, orphaned_from_group_id AS group_id,
+			       ROW_NUMBER() OVER (PARTITION BY orphaned_from_group_id ORDER BY position, id) AS n
+			FROM links
+			WHERE orphaned_from_group_id = ANY($1) AND
+			      link_group_id IS NULL AND
+			      deleted_at IS NULL
+		) o
+		LEFT JOIN (
+			SELECT link_group_id, MAX(position) AS max_position
+			FROM links
+			WHERE link_group_id = ANY($1) AND
+			      deleted_at IS NULL
+			GROUP BY link_group_id
+		) m ON m.link_group_id = o.group_id
+		WHERE l.id = o.id
+		RETURNING l.id, l.user_id, l.link_group_id
+		)
+		INSERT INTO entity_history (entity_type, entity_id, user_id, actor_id, action, changes)
+		SELECT 'link', id, user_id, $3, 'update',
+		       jsonb_build_object('link_group_id', jsonb_build_object('old', NULL, 'new', link_group_id))
+		FROM returned
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt time.Time
+	if err := tx.QueryRow(ctx, queryGroup, id, workspaceID).Scan(&deletedAt); err != nil {
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+
+	var groupIDs []int
+	if err := tx.QueryRow(ctx, queryTree, id, deletedAt).Scan(&groupIDs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+
+	if err := r.restoreLinkGroups(ctx, tx, groupIDs, workspaceID, op); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, queryLinks, groupIDs, deletedAt, models.HistoryActorFromContext(ctx)); err != nil {
+		return app_errors.HandleDBError(err, "восстановление ссылок группы", op)
+	}
+
+	if _, err := tx.Exec(ctx, queryOrphans, groupIDs, positionGap, models.HistoryActorFromContext(ctx)); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "возврат ссылок в группу", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+	return nil
+}
+
+// restoreLinkGroups снимает пометку удаления с групп groupIDs. Группа, родитель которой остается
+// в корзине, переходит в корень. Занятое имя среди действующих групп - Conflict
+func (r *linkRepository) restoreLinkGroups(ctx context.Context, tx pgx.Tx, groupIDs []int, workspaceID int, op string) error {
+	queryNameTaken := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM link_groups a
+			JOIN link_groups t ON lower(t.name) = lower(a.name)
+			WHERE t.id = ANY($1) AND
+			      t.deleted_at IS NOT NULL AND
+			      a.workspace_id = $2 AND
+			      a.deleted_at IS NULL
+		)
+	`
+
+	query := `
+		UPDATE link_groups g
+			SET deleted_at = NULL,
+			    updated_at = CURRENT_TIMESTAMP
+		FROM link_groups old
+		WHERE g.id = ANY($1) AND
+		      g.workspace_id = $2 AND
+		      g.deleted_at IS NOT NULL AND
+		      old.id = g.id
+		RETURNING g.id, g.user_id, old.deleted_at
+	`
+
+	queryDetach := `
+		UPDATE link_groups g
+			SET parent_id = NULL
+		FROM link_groups p
+		WHERE g.id = ANY($1) AND
+		      p.id = g.parent_id AND
+		      p.deleted_at IS NOT NULL
+	`
+
+	var nameTaken bool
+	if err := tx.QueryRow(ctx, queryNameTaken, groupIDs, workspaceID).Scan(&nameTaken); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+
+	if nameTaken {
+		return app_errors.Conflict("Группа с таким именем уже существует", op)
+	}
+
+	rows, err := tx.Query(ctx, query, groupIDs, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+
+	type restoredGroup struct {
+		userID    int
+		deletedAt time.Time
+	}
+
+	restored := map[int]restoredGroup{}
+	for rows.Next() {
+		var id int
+		var group restoredGroup
+		if err := rows.Scan(&id, &group.userID, &group.deletedAt); err != nil {
+			rows.Close()
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+		}
+		restored[id] = group
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+
+	for id, group := range restored {
+		if err := writeHistory(ctx, tx, models.EntityLinkGroup, id, group.userID, models.HistoryRestore, deletedAtChange(&group.deletedAt, nil)); err != nil {
+			r.logger.Error(err, op)
+			return app_errors.HandleDBError(err, "запись истории изменений", op)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, queryDetach, groupIDs); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "восстановление группы ссылок", op)
+	}
+
+	return nil
+}
+
+func (r *linkRepository) RestoreTag(ctx context.Context, id, workspaceID int) error {
+	op := "link_repository.RestoreTag"
+
+	query := `
+		UPDATE tags t
+			SET deleted_at = NULL,
+			    updated_at = CURRENT_TIMESTAMP
+		FROM tags old
+		WHERE t.id = $1 AND
+		      t.workspace_id = $2 AND
+		      t.deleted_at IS NOT NULL AND
+		      old.id = t.id
+		RETURNING t.user_id, old.deleted_at
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return app_errors.HandleDBError(err, "восстановление тега", op)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	var deletedAt time.Time
+	if err := tx.QueryRow(ctx, query, id, workspaceID).Scan(&userID, &deletedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return app_errors.NotFound("Тег не найден в корзине", op)
+		}
+		return app_errors.HandleDBError(err, "восстановление тега", op)
+	}
+
+	if err := writeHistory(ctx, tx, models.EntityTag, id, userID, models.HistoryRestore, deletedAtChange(&deletedAt, nil)); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "запись истории изменений", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "восстановление тега", op)
+	}
+	return nil
+}
+
+// PurgeTrash окончательно удаляет элементы, попавшие в корзину до before. Возвращает количество удаленных строк
+func (r *linkRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	op := "link_repository.PurgeTrash"
+
+	// Сначала ссылки, затем группы: каскад по parent_id удалит подгруппы, ушедшие в корзину вместе с группой
+	queries := []string{
+		`DELETE FROM links WHERE deleted_at < $1`,
+		`DELETE FROM link_groups WHERE deleted_at < $1`,
+		`DELETE FROM tags WHERE deleted_at < $1`,
+	}
+
+	var purged int64
+	for _, query := range queries {
+		result, err := r.pool.Exec(ctx, query, before)
+		if err != nil {
+			r.logger.Error(err, op)
+			return purged, app_errors.HandleDBError(err, "очистка корзины", op)
+		}
+		purged += result.RowsAffected()
+	}
+
+	return purged, nil
+}
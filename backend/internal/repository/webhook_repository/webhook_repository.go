@@ -0,0 +1,379 @@
+package webhook_repository
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookRepository interface {
+	GetWebhooks(ctx context.Context, workspaceID int) ([]*models.Webhook, error)
+	GetWebhook(ctx context.Context, id, workspaceID int) (*models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id, workspaceID int) error
+
+	// WebhookDelivery
+	CreateEventDeliveries(ctx context.Context, workspaceID int, eventID, eventType string, payload []byte) (int64, error)
+	CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, webhookID, limit, offset int) (*response.ListResponse[models.WebhookDelivery], error)
+	GetWebhookDelivery(ctx context.Context, id int64, webhookID int) (*models.WebhookDelivery, error)
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.WebhookDelivery, error)
+	SetWebhookDeliveryResult(ctx context.Context, id int64, result *models.WebhookDeliveryResult) error
+	DeleteWebhookDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type webhookRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.AppLogger
+}
+
+func New(pool *pgxpool.Pool, logger logger.AppLogger) WebhookRepository {
+	return &webhookRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+const webhookColumns = `id, workspace_id, created_by, url, events, is_active, created_at, updated_at`
+
+func scanWebhook(row pgx.Row, webhook *models.Webhook) error {
+	return row.Scan(
+		&webhook.ID,
+		&webhook.WorkspaceID,
+		&webhook.CreatedBy,
+		&webhook.URL,
+		&webhook.Events,
+		&webhook.IsActive,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+}
+
+func (r *webhookRepository) GetWebhooks(ctx context.Context, workspaceID int) ([]*models.Webhook, error) {
+	op := "webhook_repository.GetWebhooks"
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE workspace_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение webhooks", op)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+
+	for rows.Next() {
+		var webhook models.Webhook
+		if err := scanWebhook(rows, &webhook); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение webhooks", op)
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) GetWebhook(ctx context.Context, id, workspaceID int) (*models.Webhook, error) {
+	op := "webhook_repository.GetWebhook"
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE id = $1 AND
+		      workspace_id = $2
+	`
+
+	var webhook models.Webhook
+	if err := scanWebhook(r.pool.QueryRow(ctx, query, id, workspaceID), &webhook); err != nil {
+		return nil, app_errors.HandleDBError(err, "webhook не найден", op)
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	op := "webhook_repository.CreateWebhook"
+
+	query := `
+		INSERT INTO webhooks (workspace_id, created_by, url, secret, events, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		webhook.WorkspaceID,
+		webhook.CreatedBy,
+		webhook.URL,
+		webhook.Secret,
+		webhook.Events,
+		webhook.IsActive).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание webhook", op)
+	}
+	return nil
+}
+
+// UpdateWebhook сохраняет адрес, события и активность. Пустой Secret - ключ подписи не меняется
+func (r *webhookRepository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	op := "webhook_repository.UpdateWebhook"
+
+	query := `
+		UPDATE webhooks
+			SET url = $3,
+			    events = $4,
+			    is_active = $5,
+			    secret = CASE WHEN $6 = '' THEN secret ELSE $6 END,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND
+		      workspace_id = $2
+		RETURNING updated_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		webhook.ID,
+		webhook.WorkspaceID,
+		webhook.URL,
+		webhook.Events,
+		webhook.IsActive,
+		webhook.Secret).Scan(&webhook.UpdatedAt); err != nil {
+		return app_errors.HandleDBError(err, "webhook не найден", op)
+	}
+	return nil
+}
+
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, id, workspaceID int) error {
+	op := "webhook_repository.DeleteWebhook"
+
+	result, err := r.pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND workspace_id = $2`, id, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "удаление webhook", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("webhook не найден", op)
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+		       d.response_status, d.response_body, d.last_error, d.duration_ms, d.delivered_at, d.created_at, d.updated_at`
+
+func scanWebhookDelivery(row pgx.Row, delivery *models.WebhookDelivery, extra ...any) error {
+	return row.Scan(append([]any{
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.ResponseBody,
+		&delivery.LastError,
+		&delivery.DurationMs,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	}, extra...)...)
+}
+
+// CreateEventDeliveries ставит событие в очередь всех активных webhooks пространства, подписанных
+// на его тип, категорию или все события. Возвращает число созданных доставок
+func (r *webhookRepository) CreateEventDeliveries(ctx context.Context, workspaceID int, eventID, eventType string, payload []byte) (int64, error) {
+	op := "webhook_repository.CreateEventDeliveries"
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT id, $2::uuid, $3::varchar, $4::jsonb
+		FROM webhooks
+		WHERE workspace_id = $1 AND
+		      is_active AND
+		      ($3::varchar = ANY(events) OR split_part($3::varchar, '.', 1) || '.*' = ANY(events) OR '*' = ANY(events))
+	`
+
+	result, err := r.pool.Exec(ctx, query, workspaceID, eventID, eventType, string(payload))
+	if err != nil {
+		r.logger.Error(err, op, "event", eventType)
+		return 0, app_errors.HandleDBError(err, "создание доставок webhooks", op)
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *webhookRepository) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	op := "webhook_repository.CreateWebhookDelivery"
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4::jsonb)
+		RETURNING id, status, attempts, next_attempt_at, created_at, updated_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		string(delivery.Payload)).Scan(&delivery.ID, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание доставки webhook", op)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries журнал доставок webhook, новые первыми
+func (r *webhookRepository) GetWebhookDeliveries(ctx context.Context, webhookID, limit, offset int) (*response.ListResponse[models.WebhookDelivery], error) {
+	op := "webhook_repository.GetWebhookDeliveries"
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = $1`, webhookID).Scan(&total); err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение доставок webhook", op)
+	}
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение доставок webhook", op)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &delivery); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение доставок webhook", op)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return response.NewListResponse(deliveries, total, offset/limit+1, limit), nil
+}
+
+func (r *webhookRepository) GetWebhookDelivery(ctx context.Context, id int64, webhookID int) (*models.WebhookDelivery, error) {
+	op := "webhook_repository.GetWebhookDelivery"
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `, w.url, w.secret
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id = $1 AND
+		      d.webhook_id = $2
+	`
+
+	var delivery models.WebhookDelivery
+	if err := scanWebhookDelivery(r.pool.QueryRow(ctx, query, id, webhookID), &delivery, &delivery.URL, &delivery.Secret); err != nil {
+		return nil, app_errors.HandleDBError(err, "доставка не найдена", op)
+	}
+	return &delivery, nil
+}
+
+// ClaimWebhookDeliveries забирает доставки, которым пора уйти, и откладывает их до leaseUntil:
+// параллельный запуск их не возьмет, а при падении процесса они уйдут повторно после leaseUntil
+func (r *webhookRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	op := "webhook_repository.ClaimWebhookDeliveries"
+
+	query := `
+		UPDATE webhook_deliveries d
+			SET next_attempt_at = $2,
+			    updated_at = CURRENT_TIMESTAMP
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND
+		      d.id IN (
+		          SELECT pd.id
+		          FROM webhook_deliveries pd
+		          JOIN webhooks pw ON pw.id = pd.webhook_id
+		          WHERE pd.status = 'pending' AND
+		                pd.next_attempt_at <= $1 AND
+		                pw.is_active
+		          ORDER BY pd.next_attempt_at
+		          LIMIT $3
+		          FOR UPDATE OF pd SKIP LOCKED
+		      )
+		RETURNING ` + webhookDeliveryColumns + `, w.url, w.secret
+	`
+
+	rows, err := r.pool.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение доставок webhooks", op)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &delivery, &delivery.URL, &delivery.Secret); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение доставок webhooks", op)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+// SetWebhookDeliveryResult сохраняет итог попытки доставки и увеличивает счетчик попыток
+func (r *webhookRepository) SetWebhookDeliveryResult(ctx context.Context, id int64, result *models.WebhookDeliveryResult) error {
+	op := "webhook_repository.SetWebhookDeliveryResult"
+
+	query := `
+		UPDATE webhook_deliveries
+			SET status = $2,
+			    attempts = attempts + 1,
+			    response_status = $3,
+			    response_body = $4,
+			    last_error = $5,
+			    duration_ms = $6,
+			    next_attempt_at = $7,
+			    delivered_at = CASE WHEN $2 = 'succeeded' THEN CURRENT_TIMESTAMP ELSE delivered_at END,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query,
+		id,
+		string(result.Status),
+		result.ResponseStatus,
+		result.ResponseBody,
+		result.Error,
+		result.DurationMs,
+		result.NextAttemptAt); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "сохранение доставки webhook", op)
+	}
+	return nil
+}
+
+// DeleteWebhookDeliveriesBefore удаляет завершенные доставки старше before
+func (r *webhookRepository) DeleteWebhookDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	op := "webhook_repository.DeleteWebhookDeliveriesBefore"
+
+	result, err := r.pool.Exec(ctx, `DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1`, before)
+	if err != nil {
+		r.logger.Error(err, op)
+		return 0, app_errors.HandleDBError(err, "удаление старых доставок webhooks", op)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,380 @@
+package workspace_repository
+
+import (
+	"context"
+	"errors"
+	"link-storage/internal/models"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/types/app_errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WorkspaceRepository interface {
+	GetActiveWorkspace(ctx context.Context, userID, workspaceID int) (*models.Workspace, error)
+	GetWorkspacesByUserID(ctx context.Context, userID int) ([]*models.Workspace, error)
+	CreateWorkspace(ctx context.Context, workspace *models.Workspace, ownerID int) error
+	UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error
+	DeleteWorkspace(ctx context.Context, id int) error
+
+	// WorkspaceMember
+	GetWorkspaceMembers(ctx context.Context, workspaceID int) ([]*models.WorkspaceMember, error)
+	GetWorkspaceMember(ctx context.Context, workspaceID, userID int) (*models.WorkspaceMember, error)
+	AddWorkspaceMember(ctx context.Context, workspaceID int, email string, role models.WorkspaceRole) (*models.WorkspaceMember, error)
+	SetWorkspaceMemberRole(ctx context.Context, workspaceID, userID int, role models.WorkspaceRole) error
+	DeleteWorkspaceMember(ctx context.Context, workspaceID, userID int) error
+	TransferWorkspaceOwnership(ctx context.Context, workspaceID, ownerID, newOwnerID int) error
+}
+
+type workspaceRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.AppLogger
+}
+
+func New(pool *pgxpool.Pool, logger logger.AppLogger) WorkspaceRepository {
+	return &workspaceRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// GetActiveWorkspace пространство, в котором пользователь состоит, с его ролью. workspaceID 0 - личное пространство
+func (r *workspaceRepository) GetActiveWorkspace(ctx context.Context, userID, workspaceID int) (*models.Workspace, error) {
+	op := "workspace_repository.GetActiveWorkspace"
+
+	query := `
+		SELECT w.id, w.personal_user_id, w.name, w.created_at, w.updated_at, m.role
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1
+		WHERE ($2 = 0 AND w.personal_user_id = $1) OR
+		      w.id = $2
+	`
+
+	var workspace models.Workspace
+	if err := r.pool.QueryRow(ctx, query, userID, workspaceID).Scan(
+		&workspace.ID,
+		&workspace.PersonalUserID,
+		&workspace.Name,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+		&workspace.Role,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, app_errors.NotFound("Рабочее пространство не найдено", op)
+		}
+		r.logger.Error(err, op, "user_id", userID, "workspace_id", workspaceID)
+		return nil, app_errors.HandleDBError(err, "получение рабочего пространства", op)
+	}
+
+	return &workspace, nil
+}
+
+// GetWorkspacesByUserID пространства пользователя: личное первым, затем командные по названию
+func (r *workspaceRepository) GetWorkspacesByUserID(ctx context.Context, userID int) ([]*models.Workspace, error) {
+	op := "workspace_repository.GetWorkspacesByUserID"
+
+	query := `
+		SELECT w.id, w.personal_user_id, w.name, w.created_at, w.updated_at, m.role
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.personal_user_id IS NULL, w.name, w.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение рабочих пространств", op)
+	}
+	defer rows.Close()
+
+	workspaces := []*models.Workspace{}
+
+	for rows.Next() {
+		var workspace models.Workspace
+		if err := rows.Scan(&workspace.ID, &workspace.PersonalUserID, &workspace.Name, &workspace.CreatedAt, &workspace.UpdatedAt, &workspace.Role); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение рабочих пространств", op)
+		}
+		workspaces = append(workspaces, &workspace)
+	}
+	return workspaces, nil
+}
+
+// CreateWorkspace создает командное пространство, ownerID становится его владельцем
+func (r *workspaceRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace, ownerID int) error {
+	op := "workspace_repository.CreateWorkspace"
+
+	query := `
+		INSERT INTO workspaces (name)
+		VALUES ($1)
+		RETURNING id, created_at, updated_at
+	`
+
+	queryOwner := `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, 'owner')
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание рабочего пространства", op)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, query, workspace.Name).Scan(&workspace.ID, &workspace.CreatedAt, &workspace.UpdatedAt); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание рабочего пространства", op)
+	}
+
+	if _, err := tx.Exec(ctx, queryOwner, workspace.ID, ownerID); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "создание рабочего пространства", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "создание рабочего пространства", op)
+	}
+
+	workspace.Role = models.WorkspaceRoleOwner
+	return nil
+}
+
+func (r *workspaceRepository) UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	op := "workspace_repository.UpdateWorkspace"
+
+	query := `
+		UPDATE workspaces
+			SET name = $2,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query, workspace.ID, workspace.Name).Scan(&workspace.UpdatedAt); err != nil {
+		return app_errors.HandleDBError(err, "изменение рабочего пространства", op)
+	}
+	return nil
+}
+
+// DeleteWorkspace удаляет командное пространство вместе с содержимым
+func (r *workspaceRepository) DeleteWorkspace(ctx context.Context, id int) error {
+	op := "workspace_repository.DeleteWorkspace"
+
+	query := `
+		DELETE FROM workspaces
+		WHERE id = $1 AND
+		      personal_user_id IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "удаление рабочего пространства", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Рабочее пространство не найдено", op)
+	}
+	return nil
+}
+
+// GetWorkspaceMembers участники пространства: владелец первым, затем по дате добавления
+func (r *workspaceRepository) GetWorkspaceMembers(ctx context.Context, workspaceID int) ([]*models.WorkspaceMember, error) {
+	op := "workspace_repository.GetWorkspaceMembers"
+
+	query := `
+		SELECT m.workspace_id, m.user_id, u.name, u.email, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.role <> 'owner', m.created_at, m.user_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение участников пространства", op)
+	}
+	defer rows.Close()
+
+	members := []*models.WorkspaceMember{}
+
+	for rows.Next() {
+		var member models.WorkspaceMember
+		if err := rows.Scan(&member.WorkspaceID, &member.UserID, &member.Name, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+			r.logger.Error(err, op)
+			return nil, app_errors.HandleDBError(err, "получение участников пространства", op)
+		}
+		members = append(members, &member)
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) GetWorkspaceMember(ctx context.Context, workspaceID, userID int) (*models.WorkspaceMember, error) {
+	op := "workspace_repository.GetWorkspaceMember"
+
+	query := `
+		SELECT m.workspace_id, m.user_id, u.name, u.email, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1 AND
+		      m.user_id = $2
+	`
+
+	var member models.WorkspaceMember
+	if err := r.pool.QueryRow(ctx, query, workspaceID, userID).Scan(&member.WorkspaceID, &member.UserID, &member.Name, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, app_errors.NotFound("Участник не найден", op)
+		}
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "получение участника пространства", op)
+	}
+	return &member, nil
+}
+
+// AddWorkspaceMember добавляет зарегистрированного пользователя по email
+func (r *workspaceRepository) AddWorkspaceMember(ctx context.Context, workspaceID int, email string, role models.WorkspaceRole) (*models.WorkspaceMember, error) {
+	op := "workspace_repository.AddWorkspaceMember"
+
+	queryUser := `
+		SELECT id, name, email
+		FROM users
+		WHERE lower(email) = lower($1) AND
+		      is_active
+	`
+
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+		RETURNING created_at
+	`
+
+	member := models.WorkspaceMember{WorkspaceID: workspaceID, Role: role}
+	if err := r.pool.QueryRow(ctx, queryUser, email).Scan(&member.UserID, &member.Name, &member.Email); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, app_errors.NotFound("Пользователь с таким email не найден", op)
+		}
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "добавление участника пространства", op)
+	}
+
+	if err := r.pool.QueryRow(ctx, query, workspaceID, member.UserID, role).Scan(&member.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, app_errors.Conflict("Пользователь уже состоит в пространстве", op)
+		}
+		r.logger.Error(err, op)
+		return nil, app_errors.HandleDBError(err, "добавление участника пространства", op)
+	}
+
+	return &member, nil
+}
+
+// SetWorkspaceMemberRole меняет роль участника. Роль владельца так не меняется
+func (r *workspaceRepository) SetWorkspaceMemberRole(ctx context.Context, workspaceID, userID int, role models.WorkspaceRole) error {
+	op := "workspace_repository.SetWorkspaceMemberRole"
+
+	query := `
+		UPDATE workspace_members
+			SET role = $3,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE workspace_id = $1 AND
+		      user_id = $2 AND
+		      role <> 'owner'
+	`
+
+	result, err := r.pool.Exec(ctx, query, workspaceID, userID, role)
+	if err != nil {
+		return app_errors.HandleDBError(err, "изменение роли участника", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Участник не найден", op)
+	}
+	return nil
+}
+
+// DeleteWorkspaceMember исключает участника. Владельца исключить нельзя. Созданное участником остается в пространстве
+func (r *workspaceRepository) DeleteWorkspaceMember(ctx context.Context, workspaceID, userID int) error {
+	op := "workspace_repository.DeleteWorkspaceMember"
+
+	query := `
+		DELETE FROM workspace_members
+		WHERE workspace_id = $1 AND
+		      user_id = $2 AND
+		      role <> 'owner'
+	`
+
+	result, err := r.pool.Exec(ctx, query, workspaceID, userID)
+	if err != nil {
+		return app_errors.HandleDBError(err, "удаление участника пространства", op)
+	}
+	if result.RowsAffected() == 0 {
+		return app_errors.NotFound("Участник не найден", op)
+	}
+	return nil
+}
+
+// TransferWorkspaceOwnership передает владение участнику newOwnerID, прежний владелец становится администратором
+func (r *workspaceRepository) TransferWorkspaceOwnership(ctx context.Context, workspaceID, ownerID, newOwnerID int) error {
+	op := "workspace_repository.TransferWorkspaceOwnership"
+
+	queryLock := `
+		SELECT user_id
+		FROM workspace_members
+		WHERE workspace_id = $1 AND
+		      user_id IN ($2, $3)
+		FOR UPDATE
+	`
+
+	querySetRole := `
+		UPDATE workspace_members
+			SET role = $3,
+			    updated_at = CURRENT_TIMESTAMP
+		WHERE workspace_id = $1 AND
+		      user_id = $2
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "передача владения", op)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, queryLock, workspaceID, ownerID, newOwnerID)
+	if err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "передача владения", op)
+	}
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "передача владения", op)
+	}
+
+	if locked != 2 {
+		return app_errors.NotFound("Участник не найден", op)
+	}
+
+	// Сначала понижаем прежнего владельца: владелец в пространстве один
+	if _, err := tx.Exec(ctx, querySetRole, workspaceID, ownerID, models.WorkspaceRoleAdmin); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "передача владения", op)
+	}
+
+	if _, err := tx.Exec(ctx, querySetRole, workspaceID, newOwnerID, models.WorkspaceRoleOwner); err != nil {
+		r.logger.Error(err, op)
+		return app_errors.HandleDBError(err, "передача владения", op)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return app_errors.HandleDBError(err, "передача владения", op)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package auth_service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/slug"
+	"strings"
+)
+
+const (
+	// apiTokenLength длина случайной части персонального токена в base62
+	apiTokenLength = 40
+	// apiTokenHintLength сколько символов токена видно в списке
+	apiTokenHintLength = 8
+)
+
+// hashAPIToken токен хранится как SHA-256: он случайный и длинный, медленный хеш не нужен
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *authService) GetAPITokens(ctx context.Context, userID int) ([]*models.APIToken, error) {
+	return s.repo.GetAPITokens(ctx, userID)
+}
+
+// CreateAPIToken выпускает персональный токен. Токен в ответе показывается только один раз
+func (s *authService) CreateAPIToken(ctx context.Context, userID int, tokenCreate *models.APITokenCreate) (*models.APIToken, error) {
+	op := "AuthService.CreateAPIToken"
+
+	random, err := slug.Base62(apiTokenLength)
+	if err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+	token := models.APITokenPrefix + random
+
+	apiToken := &models.APIToken{
+		UserID:    userID,
+		Name:      tokenCreate.Name,
+		TokenHash: hashAPIToken(token),
+		Hint:      token[:len(models.APITokenPrefix)+apiTokenHintLength],
+	}
+	if err := s.repo.CreateAPIToken(ctx, apiToken); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Создан токен доступа", op, "user_id", userID, "token_id", apiToken.ID)
+
+	apiToken.Token = token
+	return apiToken, nil
+}
+
+func (s *authService) DeleteAPIToken(ctx context.Context, userID, id int) error {
+	return s.repo.DeleteAPIToken(ctx, id, userID)
+}
+
+// VerifyAPIToken пользователь по персональному токену
+func (s *authService) VerifyAPIToken(ctx context.Context, token string) (*models.CurrentUser, error) {
+	op := "AuthService.VerifyAPIToken"
+
+	if !strings.HasPrefix(token, models.APITokenPrefix) {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	user, err := s.repo.GetUserByAPITokenHash(ctx, hashAPIToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		s.logger.Warn("Неизвестный токен доступа", op)
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	return &models.CurrentUser{
+		ID:       user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		IsActive: user.IsActive,
+		IsAdmin:  user.IsAdmin,
+	}, nil
+}
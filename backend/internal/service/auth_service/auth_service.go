@@ -20,6 +20,12 @@ type AuthService interface {
 	VerifyJwt(tokenString string) (*models.CurrentUser, error)
 	Login(ctx context.Context, email, password, ipAddress, userAgent string) (*models.SessionResponse, error)
 	RefreshToken(ctx context.Context, refreshToken, ipAddress, userAgent string) (*models.SessionResponse, error)
+
+	// APIToken
+	GetAPITokens(ctx context.Context, userID int) ([]*models.APIToken, error)
+	CreateAPIToken(ctx context.Context, userID int, tokenCreate *models.APITokenCreate) (*models.APIToken, error)
+	DeleteAPIToken(ctx context.Context, userID, id int) error
+	VerifyAPIToken(ctx context.Context, token string) (*models.CurrentUser, error)
 }
 
 type authService struct {
@@ -0,0 +1,239 @@
+package link_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/mailer"
+	"link-storage/pkg/types/app_errors"
+	"strings"
+	"time"
+)
+
+const (
+	// digestSectionLimit сколько ссылок в каждом разделе дайджеста
+	digestSectionLimit = 10
+	digestTokenBytes   = 16
+	// digestDefaultHour час отправки по умолчанию, пока пользователь не настроил расписание
+	digestDefaultHour = 8
+)
+
+// DigestOptions настройки отправки дайджестов
+type DigestOptions struct {
+	// BatchSize сколько дайджестов отправляется за один запуск
+	BatchSize int
+}
+
+// GetDigestSettings расписание дайджеста текущего пользователя, без настроек - выключенный ежедневный
+func (s *linkService) GetDigestSettings(ctx context.Context) (*models.DigestSettings, error) {
+	op := "link_service.GetDigestSettings"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	settings, err := s.repo.GetDigestSettings(ctx, user.ID)
+	if app_errors.IsNotFound(err) {
+		return &models.DigestSettings{
+			UserID:    user.ID,
+			Frequency: models.DigestDaily,
+			Hour:      digestDefaultHour,
+			Weekday:   int(time.Monday),
+			Timezone:  "UTC",
+		}, nil
+	}
+	return settings, err
+}
+
+func (s *linkService) SaveDigestSettings(ctx context.Context, settingsSave *models.DigestSettingsSave) (*models.DigestSettings, error) {
+	op := "link_service.SaveDigestSettings"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	settings := &models.DigestSettings{
+		UserID:    user.ID,
+		IsEnabled: settingsSave.IsEnabled,
+		Frequency: settingsSave.Frequency,
+		Hour:      settingsSave.Hour,
+		Weekday:   settingsSave.Weekday,
+		Timezone:  settingsSave.Timezone,
+	}
+
+	if settings.IsEnabled {
+		next, err := settings.NextSend(time.Now())
+		if err != nil {
+			return nil, app_errors.BadRequestWithError(err, "Неизвестный часовой пояс", op)
+		}
+		settings.NextSendAt = &next
+	}
+
+	// Токен нужен только новой записи, у существующей он сохранится
+	token := make([]byte, digestTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+	settings.UnsubscribeToken = hex.EncodeToString(token)
+
+	if err := s.repo.SaveDigestSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// PreviewDigest дайджест текущего пользователя, каким он был бы отправлен сейчас
+func (s *linkService) PreviewDigest(ctx context.Context) (*models.DigestMessage, error) {
+	settings, err := s.GetDigestSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	settings.UserName = user.Name
+
+	digest, err := s.buildDigest(ctx, settings, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.renderDigest(settings, digest)
+}
+
+// UnsubscribeDigest выключает дайджест по токену из письма, вход в приложение не нужен
+func (s *linkService) UnsubscribeDigest(ctx context.Context, token string) error {
+	op := "link_service.UnsubscribeDigest"
+
+	if len(token) != hex.EncodedLen(digestTokenBytes) {
+		return app_errors.NotFound("Подписка на дайджест не найдена", op)
+	}
+
+	if err := s.repo.UnsubscribeDigest(ctx, strings.ToLower(token)); err != nil {
+		return err
+	}
+
+	s.logger.Info("Отписка от дайджеста", op)
+	return nil
+}
+
+// SendDigests отправляет дайджесты, время которых наступило. Перед отправкой дайджест переносится
+// на следующий период, поэтому при ошибке письма он не повторяется, а его ссылки войдут в следующий
+func (s *linkService) SendDigests(ctx context.Context) error {
+	op := "link_service.SendDigests"
+
+	now := time.Now()
+	digests, err := s.repo.GetDueDigests(ctx, now, s.options.Digest.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(digests) == 0 {
+		return nil
+	}
+
+	sent, skipped, failed := 0, 0, 0
+	for _, settings := range digests {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		next, err := settings.NextSend(now)
+		if err != nil {
+			s.logger.Error(err, op, "user_id", settings.UserID)
+			failed++
+			continue
+		}
+
+		claimed, err := s.repo.ClaimDigest(ctx, settings.UserID, *settings.NextSendAt, next)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		ok, err := s.sendDigest(ctx, settings, now)
+		switch {
+		case err != nil:
+			s.logger.Error(err, op, "user_id", settings.UserID)
+			failed++
+		case ok:
+			sent++
+		default:
+			skipped++
+		}
+	}
+
+	s.logger.Info("Дайджесты отправлены", op,
+		"sent", sent,
+		"skipped", skipped,
+		"failed", failed)
+
+	return nil
+}
+
+// sendDigest собирает и отправляет дайджест, false - за период нечего отправлять
+func (s *linkService) sendDigest(ctx context.Context, settings *models.DigestSettings, now time.Time) (bool, error) {
+	digest, err := s.buildDigest(ctx, settings, now)
+	if err != nil {
+		return false, err
+	}
+
+	if !digest.IsEmpty() {
+		message, err := s.renderDigest(settings, digest)
+		if err != nil {
+			return false, err
+		}
+
+		if err := s.mailer.Send(ctx, &mailer.Message{
+			To:          settings.UserEmail,
+			Subject:     message.Subject,
+			Text:        message.Text,
+			HTML:        message.HTML,
+			Unsubscribe: s.digestUnsubscribeURL(settings.UnsubscribeToken),
+		}); err != nil {
+			return false, err
+		}
+	}
+
+	return !digest.IsEmpty(), s.repo.SetDigestSent(ctx, settings.UserID, now)
+}
+
+// buildDigest собирает разделы дайджеста с прошлой отправки, но не больше чем за два периода
+func (s *linkService) buildDigest(ctx context.Context, settings *models.DigestSettings, now time.Time) (*models.Digest, error) {
+	period := settings.Frequency.Period()
+	since := now.Add(-period)
+	if settings.LastSentAt != nil && settings.LastSentAt.After(now.Add(-2*period)) {
+		since = *settings.LastSentAt
+	}
+
+	digest := &models.Digest{
+		Frequency: settings.Frequency,
+		Since:     since,
+		Until:     now,
+	}
+
+	var err error
+	if digest.SharedLinks, err = s.repo.GetDigestSharedLinks(ctx, settings.UserID, since, digestSectionLimit); err != nil {
+		return nil, err
+	}
+	if digest.UnreadLinks, digest.UnreadTotal, err = s.repo.GetDigestUnreadLinks(ctx, settings.UserID, digestSectionLimit); err != nil {
+		return nil, err
+	}
+	if digest.BrokenLinks, err = s.repo.GetDigestBrokenLinks(ctx, settings.UserID, since, digestSectionLimit); err != nil {
+		return nil, err
+	}
+	if digest.TopVisited, err = s.repo.GetDigestTopVisited(ctx, settings.UserID, since, digestSectionLimit); err != nil {
+		return nil, err
+	}
+
+	return digest, nil
+}
+
+// digestUnsubscribeURL адрес отписки из письма, открывается без входа в приложение
+func (s *linkService) digestUnsubscribeURL(token string) string {
+	return strings.TrimRight(s.options.AppURL, "/") + "/api/v1/digest/unsubscribe/" + token
+}
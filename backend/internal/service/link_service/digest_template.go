@@ -0,0 +1,160 @@
+package link_service
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// digestTemplateData данные шаблонов дайджеста. Даты - в часовом поясе пользователя
+type digestTemplateData struct {
+	*models.Digest
+	Name           string
+	Period         string
+	AppURL         string
+	UnsubscribeURL string
+}
+
+var digestTemplateFuncs = map[string]any{
+	"linkTitle": func(link *models.DigestLink) string {
+		if link.Title != "" {
+			return link.Title
+		}
+		return link.URL
+	},
+	// section передает в шаблон раздела заголовок вместе со ссылками
+	"section": func(title string, links []*models.DigestLink) any {
+		return struct {
+			Title string
+			Links []*models.DigestLink
+		}{title, links}
+	},
+}
+
+var digestTextTemplate = texttemplate.Must(texttemplate.New("digest").Funcs(digestTemplateFuncs).Parse(
+	`{{if .Name}}{{.Name}}, в{{else}}В{{end}}аши ссылки {{.Period}}.
+{{- if .SharedLinks}}
+
+Новое в общих группах:
+{{- range .SharedLinks}}
+- {{linkTitle .}}{{with .LinkGroupName}} [{{.}}]{{end}}
+  {{.URL}}
+{{- end}}
+{{- end}}
+{{- if .UnreadLinks}}
+
+Непрочитанные ({{.UnreadTotal}}):
+{{- range .UnreadLinks}}
+- {{linkTitle .}}{{with .ReadingMinutes}} ({{.}} мин.){{end}}
+  {{.URL}}
+{{- end}}
+{{- end}}
+{{- if .BrokenLinks}}
+
+Перестали открываться:
+{{- range .BrokenLinks}}
+- {{linkTitle .}}
+  {{.URL}}
+{{- end}}
+{{- end}}
+{{- if .TopVisited}}
+
+Популярное:
+{{- range .TopVisited}}
+- {{linkTitle .}} - переходов: {{.Visits}}
+  {{.URL}}
+{{- end}}
+{{- end}}
+
+Открыть приложение: {{.AppURL}}
+{{- if .UnsubscribeURL}}
+Отписаться от дайджеста: {{.UnsubscribeURL}}
+{{- end}}
+`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest").Funcs(digestTemplateFuncs).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body style="margin:0;padding:16px;background:#f6f8fa;font-family:system-ui,-apple-system,'Segoe UI',Roboto,sans-serif;color:#1f2328">
+<div style="max-width:600px;margin:0 auto;padding:24px;background:#fff;border:1px solid #d1d9e0;border-radius:8px">
+<p style="margin:0 0 16px;font-size:16px">{{if .Name}}{{.Name}}, в{{else}}В{{end}}аши ссылки {{.Period}}.</p>
+{{- if .SharedLinks}}
+{{template "section" (section "Новое в общих группах" .SharedLinks)}}
+{{- end}}
+{{- if .UnreadLinks}}
+{{template "section" (section (printf "Непрочитанные (%d)" .UnreadTotal) .UnreadLinks)}}
+{{- end}}
+{{- if .BrokenLinks}}
+{{template "section" (section "Перестали открываться" .BrokenLinks)}}
+{{- end}}
+{{- if .TopVisited}}
+{{template "section" (section "Популярное" .TopVisited)}}
+{{- end}}
+<p style="margin:24px 0 0;font-size:14px"><a href="{{.AppURL}}" style="color:#0969da">Открыть приложение</a></p>
+{{- if .UnsubscribeURL}}
+<p style="margin:8px 0 0;font-size:12px;color:#59636e"><a href="{{.UnsubscribeURL}}" style="color:#59636e">Отписаться от дайджеста</a></p>
+{{- end}}
+</div>
+</body>
+</html>
+{{define "section"}}
+<h2 style="margin:24px 0 8px;font-size:18px">{{.Title}}</h2>
+<ul style="margin:0;padding:0 0 0 20px">
+{{- range .Links}}
+<li style="margin:0 0 8px"><a href="{{.URL}}" style="color:#0969da;text-decoration:none">{{linkTitle .}}</a>
+{{- with .LinkGroupName}} <span style="color:#59636e">· {{.}}</span>{{end}}
+{{- with .ReadingMinutes}} <span style="color:#59636e">· {{.}} мин.</span>{{end}}
+{{- if .Visits}} <span style="color:#59636e">· переходов: {{.Visits}}</span>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}`))
+
+// renderDigest письмо дайджеста в текстовом и HTML-виде
+func (s *linkService) renderDigest(settings *models.DigestSettings, digest *models.Digest) (*models.DigestMessage, error) {
+	op := "link_service.renderDigest"
+
+	location, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	since := digest.Since.In(location).Format("02.01.2006")
+	until := digest.Until.In(location).Format("02.01.2006")
+
+	data := &digestTemplateData{
+		Digest: digest,
+		Name:   settings.UserName,
+		AppURL: strings.TrimRight(s.options.AppURL, "/"),
+	}
+	if settings.UnsubscribeToken != "" {
+		data.UnsubscribeURL = s.digestUnsubscribeURL(settings.UnsubscribeToken)
+	}
+
+	subject := "Дайджест ссылок за " + until
+	data.Period = "за день"
+	if digest.Frequency == models.DigestWeekly {
+		subject = fmt.Sprintf("Дайджест ссылок за %s - %s", since, until)
+		data.Period = fmt.Sprintf("с %s по %s", since, until)
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, data); err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+	if err := digestHTMLTemplate.Execute(&html, data); err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	return &models.DigestMessage{
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
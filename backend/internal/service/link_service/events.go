@@ -0,0 +1,101 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/events"
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// publish отправляет событие в шину. Автор события - текущий пользователь, в фоновых задачах его нет
+func (s *linkService) publish(ctx context.Context, eventType string, workspaceID int, linkGroupID *int, data any) {
+	event := events.Event{
+		Type:        eventType,
+		WorkspaceID: workspaceID,
+		LinkGroupID: linkGroupID,
+		Data:        data,
+	}
+	if user := middleware.GetCurrentUserFromContext(ctx); user != nil {
+		event.ActorID = user.ID
+	}
+
+	s.events.Publish(ctx, event)
+}
+
+func (s *linkService) publishLink(ctx context.Context, eventType string, link *models.Link) {
+	s.publish(ctx, eventType, link.WorkspaceID, link.LinkGroupID, link)
+}
+
+func (s *linkService) publishLinkGroup(ctx context.Context, eventType string, linkGroup *models.LinkGroup) {
+	s.publish(ctx, eventType, linkGroup.WorkspaceID, &linkGroup.ID, linkGroup)
+}
+
+const (
+	// eventAccessTTL сколько поток помнит доступ к группе из чужого пространства
+	eventAccessTTL = time.Minute
+	// eventSubscriberBuffer сколько событий ждут отправки клиенту, пока поток не закрыт как медленный
+	eventSubscriberBuffer = 64
+)
+
+// EventStreamOptions настройки потока событий SSE
+type EventStreamOptions struct {
+	// BufferSize сколько последних событий хранить для продолжения потока по Last-Event-ID
+	BufferSize int
+	// Heartbeat интервал комментариев, которые держат соединение открытым
+	Heartbeat time.Duration
+}
+
+// EventStream подписка текущего пользователя на события для SSE
+type EventStream struct {
+	*events.Subscription
+	// Visible видно ли событие пользователю. Не потокобезопасна: вызывается из горутины потока
+	Visible func(event events.Event) bool
+	// Heartbeat интервал комментариев, которые держат соединение открытым через прокси
+	Heartbeat time.Duration
+}
+
+type eventAccess struct {
+	allowed   bool
+	checkedAt time.Time
+}
+
+// SubscribeEvents подписка на события активного пространства и общих групп пользователя.
+// lastEventID - последнее полученное клиентом событие, с него поток продолжается из буфера
+func (s *linkService) SubscribeEvents(ctx context.Context, lastEventID string) (*EventStream, error) {
+	op := "link_service.SubscribeEvents"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[int]eventAccess)
+	visible := func(event events.Event) bool {
+		if event.WorkspaceID == user.WorkspaceID {
+			return true
+		}
+		if event.LinkGroupID == nil {
+			return false
+		}
+
+		// Группа из чужого пространства видна участникам группы
+		access, ok := groups[*event.LinkGroupID]
+		if !ok || time.Since(access.checkedAt) > eventAccessTTL {
+			_, err := s.repo.GetLinkGroupForUser(ctx, *event.LinkGroupID, user.ID)
+			if err != nil && !app_errors.IsNotFound(err) {
+				s.logger.Error(err, op, "link_group_id", *event.LinkGroupID)
+			}
+			access = eventAccess{allowed: err == nil, checkedAt: time.Now()}
+			groups[*event.LinkGroupID] = access
+		}
+		return access.allowed
+	}
+
+	return &EventStream{
+		Subscription: s.broadcaster.Subscribe(lastEventID, eventSubscriberBuffer),
+		Visible:      visible,
+		Heartbeat:    s.options.Events.Heartbeat,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package link_service
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/slug"
+)
+
+const (
+	// feedItemsCount сколько последних ссылок отдает лента
+	feedItemsCount = 50
+	// feedTokenLength длина секретного токена ленты в base62
+	feedTokenLength = 32
+)
+
+// feedSourceAccess проверяет, что текущий пользователь видит источник ленты
+func (s *linkService) feedSourceAccess(ctx context.Context, source models.FeedSource, op string) (*models.CurrentUser, error) {
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.LinkGroupID != nil {
+		if _, err := s.linkGroupAccess(ctx, *source.LinkGroupID, models.LinkGroupRoleViewer, op); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	if _, err := s.repo.GetTagByID(ctx, *source.TagID, user.WorkspaceID); err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Тег не найден", op)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func setFeedTokenPaths(feedToken *models.FeedToken) {
+	feedToken.AtomPath = fmt.Sprintf("/feeds/%s/%s", feedToken.Token, models.FeedFormatAtom)
+	feedToken.RSSPath = fmt.Sprintf("/feeds/%s/%s", feedToken.Token, models.FeedFormatRSS)
+}
+
+// GetFeedToken токен ленты источника, выпущенный текущим пользователем
+func (s *linkService) GetFeedToken(ctx context.Context, source models.FeedSource) (*models.FeedToken, error) {
+	op := "link_service.GetFeedToken"
+
+	user, err := s.feedSourceAccess(ctx, source, op)
+	if err != nil {
+		return nil, err
+	}
+
+	feedToken, err := s.repo.GetFeedToken(ctx, user.ID, source)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Лента не создана", op)
+		}
+		return nil, err
+	}
+
+	setFeedTokenPaths(feedToken)
+	return feedToken, nil
+}
+
+// CreateFeedToken выпускает новый токен ленты источника, прежний токен пользователя перестает действовать
+func (s *linkService) CreateFeedToken(ctx context.Context, source models.FeedSource) (*models.FeedToken, error) {
+	op := "link_service.CreateFeedToken"
+
+	user, err := s.feedSourceAccess(ctx, source, op)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := slug.Base62(feedTokenLength)
+	if err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	feedToken := &models.FeedToken{
+		UserID:      user.ID,
+		LinkGroupID: source.LinkGroupID,
+		TagID:       source.TagID,
+		Token:       token,
+	}
+	if err := s.repo.CreateFeedToken(ctx, feedToken); err != nil {
+		return nil, err
+	}
+
+	setFeedTokenPaths(feedToken)
+	return feedToken, nil
+}
+
+func (s *linkService) DeleteFeedToken(ctx context.Context, source models.FeedSource) error {
+	op := "link_service.DeleteFeedToken"
+
+	user, err := s.feedSourceAccess(ctx, source, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteFeedToken(ctx, user.ID, source)
+}
+
+// GetFeed лента по секретному токену, без авторизации. Лента действует, пока у владельца токена
+// есть доступ к источнику: после исключения из группы или пространства токен отдает NotFound
+func (s *linkService) GetFeed(ctx context.Context, token string) (*models.Feed, error) {
+	op := "link_service.GetFeed"
+
+	feedToken, err := s.repo.GetFeedTokenByToken(ctx, token)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Лента не найдена", op)
+		}
+		return nil, err
+	}
+
+	source := models.FeedSource{LinkGroupID: feedToken.LinkGroupID, TagID: feedToken.TagID}
+	feed := &models.Feed{ID: feedToken.ID, Link: s.options.AppURL, UpdatedAt: feedToken.CreatedAt}
+
+	if source.LinkGroupID != nil {
+		linkGroup, err := s.repo.GetLinkGroupForUser(ctx, *source.LinkGroupID, feedToken.UserID)
+		if err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.NotFound("Лента не найдена", op)
+			}
+			return nil, err
+		}
+		feed.Title = linkGroup.Name
+		feed.Description = linkGroup.Description
+	} else {
+		tag, err := s.repo.GetTagForUser(ctx, *source.TagID, feedToken.UserID)
+		if err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.NotFound("Лента не найдена", op)
+			}
+			return nil, err
+		}
+		feed.Title = "#" + tag.Name
+	}
+
+	feed.Items, err = s.repo.GetFeedItems(ctx, source, feedItemsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range feed.Items {
+		if item.UpdatedAt.After(feed.UpdatedAt) {
+			feed.UpdatedAt = item.UpdatedAt
+		}
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,308 @@
+package link_service
+
+import (
+	"context"
+	"errors"
+	"link-storage/internal/models"
+	"link-storage/pkg/feed"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// feedSubscriptionMaxBackoff предел отсрочки загрузки ленты после ошибок подряд
+	feedSubscriptionMaxBackoff = 24 * time.Hour
+	// feedItemTitleLength предел заголовка ссылки из записи ленты, как у колонки links.title
+	feedItemTitleLength = 500
+)
+
+// FeedPollOptions настройки фоновой загрузки внешних лент
+type FeedPollOptions struct {
+	// Refresh как часто загружать одну ленту
+	Refresh time.Duration
+	// BatchSize сколько лент загружать за один запуск
+	BatchSize int
+	// MaxItems сколько новых записей ленты превращать в ссылки за одну загрузку
+	MaxItems int
+	Client   parseurl.ClientOptions
+}
+
+func (s *linkService) GetFeedSubscriptions(ctx context.Context, linkGroupID int) ([]*models.FeedSubscription, error) {
+	op := "link_service.GetFeedSubscriptions"
+
+	if _, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleViewer, op); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetFeedSubscriptions(ctx, linkGroupID)
+}
+
+// CreateFeedSubscription подключает внешнюю ленту к группе, первая загрузка - при ближайшем запуске опроса
+func (s *linkService) CreateFeedSubscription(ctx context.Context, linkGroupID int, subscriptionCreate *models.FeedSubscriptionCreate) (*models.FeedSubscription, error) {
+	op := "link_service.CreateFeedSubscription"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.FeedSubscription{
+		LinkGroupID: linkGroupID,
+		UserID:      user.ID,
+		URL:         subscriptionCreate.URL,
+		WorkspaceID: linkGroup.WorkspaceID,
+	}
+	if err := s.repo.CreateFeedSubscription(ctx, subscription); err != nil {
+		if app_errors.IsConflict(err) {
+			return nil, app_errors.Conflict("Лента уже подключена к группе", op)
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *linkService) DeleteFeedSubscription(ctx context.Context, linkGroupID, id int) error {
+	op := "link_service.DeleteFeedSubscription"
+
+	if _, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleEditor, op); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteFeedSubscription(ctx, id, linkGroupID)
+}
+
+// RefreshFeedSubscription загружает ленту сразу, не дожидаясь планового опроса
+func (s *linkService) RefreshFeedSubscription(ctx context.Context, linkGroupID, id int) (*models.FeedSubscription, error) {
+	op := "link_service.RefreshFeedSubscription"
+
+	if _, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleEditor, op); err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.repo.GetFeedSubscription(ctx, id, linkGroupID)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Подписка не найдена", op)
+		}
+		return nil, err
+	}
+
+	if err := s.pollFeedSubscription(ctx, parseurl.NewClient(s.options.FeedPoll.Client), subscription); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetFeedSubscription(ctx, id, linkGroupID)
+}
+
+// PollFeedSubscriptions фоновая задача: загружает ленты, которым подошел срок, и создает ссылки из новых записей
+func (s *linkService) PollFeedSubscriptions(ctx context.Context) error {
+	op := "link_service.PollFeedSubscriptions"
+
+	opts := s.options.FeedPoll
+
+	subscriptions, err := s.repo.GetFeedSubscriptionsForPoll(ctx, time.Now(), opts.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	client := parseurl.NewClient(opts.Client)
+
+	failed := 0
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.pollFeedSubscription(ctx, client, subscription); err != nil {
+			s.logger.Error(err, op, "subscription_id", subscription.ID)
+			failed++
+		}
+	}
+
+	s.logger.Info("Загрузка лент завершена", op,
+		"polled", len(subscriptions),
+		"failed", failed)
+
+	return nil
+}
+
+// pollFeedSubscription загружает одну ленту и сохраняет статус загрузки. Ошибки самой ленты
+// (сеть, HTTP, разбор) записываются в подписку, наружу возвращаются только ошибки БД
+func (s *linkService) pollFeedSubscription(ctx context.Context, client *http.Client, subscription *models.FeedSubscription) error {
+	op := "link_service.pollFeedSubscription"
+
+	opts := s.options.FeedPoll
+	now := time.Now()
+
+	result := &models.FeedFetchResult{
+		ETag:         subscription.ETag,
+		LastModified: subscription.LastModified,
+		NextFetchAt:  now.Add(opts.Refresh),
+	}
+
+	fetchErr := s.fetchFeedSubscription(ctx, client, subscription, result)
+
+	// Прерванную остановкой приложения загрузку не засчитываем
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if fetchErr != nil {
+		result.Error = fetchErr.Error()
+		result.NextFetchAt = now.Add(feedSubscriptionBackoff(opts.Refresh, subscription.ErrorCount+1))
+		s.logger.Warn("Не удалось загрузить ленту", op,
+			"subscription_id", subscription.ID,
+			"url", subscription.URL,
+			"error", result.Error)
+	}
+
+	return s.repo.SetFeedFetchResult(ctx, subscription.ID, result)
+}
+
+// fetchFeedSubscription загружает ленту условным запросом и создает ссылки из новых записей
+func (s *linkService) fetchFeedSubscription(ctx context.Context, client *http.Client, subscription *models.FeedSubscription, result *models.FeedFetchResult) error {
+	op := "link_service.fetchFeedSubscription"
+
+	// Ссылки создаются от имени автора подписки, пока он может редактировать группу
+	linkGroup, err := s.repo.GetLinkGroupForUser(ctx, subscription.LinkGroupID, subscription.UserID)
+	if err != nil && !app_errors.IsNotFound(err) {
+		return err
+	}
+	if linkGroup == nil || !linkGroup.Role.Allows(models.LinkGroupRoleEditor) {
+		return errors.New("у автора подписки нет прав на изменение группы")
+	}
+
+	fetched, err := feed.Fetch(ctx, client, subscription.URL, subscription.ETag, subscription.LastModified)
+	if fetched != nil {
+		result.Status = &fetched.StatusCode
+	}
+	if err != nil {
+		return err
+	}
+
+	result.ETag = fetched.ETag
+	result.LastModified = fetched.LastModified
+
+	if fetched.NotModified {
+		return nil
+	}
+
+	result.Title = truncateRunes(fetched.Feed.Title, feedItemTitleLength)
+
+	// Ленты идут от новых записей к старым: берем MaxItems новых и создаем ссылки от старых к новым
+	items := fetched.Feed.Items
+	if opts := s.options.FeedPoll; len(items) > opts.MaxItems {
+		items = items[:opts.MaxItems]
+	}
+
+	var linkIDs []int
+	for i := len(items) - 1; i >= 0; i-- {
+		linkID, err := s.addFeedItem(ctx, subscription, linkGroup.WorkspaceID, items[i])
+		if err != nil {
+			return err
+		}
+		if linkID != 0 {
+			linkIDs = append(linkIDs, linkID)
+		}
+	}
+
+	if len(linkIDs) > 0 {
+		s.logger.Info("Созданы ссылки из ленты", op,
+			"subscription_id", subscription.ID,
+			"created", len(linkIDs))
+
+		// Иконку и заголовок страниц получаем в фоне, по одной: загрузка не должна задерживать
+		// опрос остальных лент, неудача не мешает импорту
+		go func() {
+			ctx := context.WithoutCancel(ctx)
+			for _, linkID := range linkIDs {
+				_, _ = s.setLinkFavIconAndTitle(ctx, client, linkID)
+			}
+		}()
+	}
+	return nil
+}
+
+// addFeedItem создает ссылку из записи ленты. Запись с известным GUID или URL, уже сохраненным
+// в пространстве группы, только отмечается обработанной. Возвращает ID созданной ссылки, 0 - ссылка не создана
+func (s *linkService) addFeedItem(ctx context.Context, subscription *models.FeedSubscription, workspaceID int, item *feed.Item) (int, error) {
+	if item.ID == "" || item.URL == "" {
+		return 0, nil
+	}
+
+	exists, err := s.repo.HasFeedSubscriptionItem(ctx, subscription.ID, item.ID)
+	if err != nil || exists {
+		return 0, err
+	}
+
+	// Запись без корректного http(s) адреса пропускаем навсегда
+	canonicalURL, err := parseurl.Canonicalize(item.URL)
+	if err != nil {
+		return 0, s.repo.AddFeedSubscriptionItem(ctx, subscription.ID, item.ID, nil)
+	}
+
+	existing, err := s.repo.GetLinkByCanonicalURL(ctx, workspaceID, canonicalURL)
+	if err != nil && !app_errors.IsNotFound(err) {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, s.repo.AddFeedSubscriptionItem(ctx, subscription.ID, item.ID, &existing.ID)
+	}
+
+	link := &models.Link{
+		UserID:       subscription.UserID,
+		WorkspaceID:  workspaceID,
+		LinkGroupID:  &subscription.LinkGroupID,
+		URL:          item.URL,
+		CanonicalURL: &canonicalURL,
+		Title:        truncateRunes(item.Title, feedItemTitleLength),
+		Description:  item.Description,
+	}
+
+	if err := s.repo.CreateLink(ctx, link, nil); err != nil {
+		// Ту же ссылку успели сохранить параллельно
+		if app_errors.IsConflict(err) {
+			return 0, s.repo.AddFeedSubscriptionItem(ctx, subscription.ID, item.ID, nil)
+		}
+		return 0, err
+	}
+
+	if err := s.repo.AddFeedSubscriptionItem(ctx, subscription.ID, item.ID, &link.ID); err != nil {
+		return 0, err
+	}
+
+	s.publishLink(ctx, models.EventLinkCreated, link)
+
+	return link.ID, nil
+}
+
+// feedSubscriptionBackoff отсрочка после errorCount ошибок подряд: интервал удваивается, но не больше суток
+func feedSubscriptionBackoff(refresh time.Duration, errorCount int) time.Duration {
+	backoff := refresh
+	for range min(errorCount-1, 10) {
+		backoff *= 2
+		if backoff >= feedSubscriptionMaxBackoff {
+			return feedSubscriptionMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func truncateRunes(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	return string([]rune(value)[:limit])
+}
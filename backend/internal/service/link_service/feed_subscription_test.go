@@ -0,0 +1,296 @@
+package link_service
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/link_repository"
+	"link-storage/pkg/events"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// feedPollRepo репозиторий в памяти с методами, которые нужны опросу лент. Остальные методы
+// не реализованы: их вызов в тесте - ошибка (паника на nil интерфейсе)
+type feedPollRepo struct {
+	link_repository.LinkRepository
+
+	mu            sync.Mutex
+	subscriptions []*models.FeedSubscription
+	items         map[string]bool
+	links         []*models.Link
+	results       map[int]*models.FeedFetchResult
+}
+
+func newFeedPollRepo(subscriptions ...*models.FeedSubscription) *feedPollRepo {
+	return &feedPollRepo{
+		subscriptions: subscriptions,
+		items:         map[string]bool{},
+		results:       map[int]*models.FeedFetchResult{},
+	}
+}
+
+func (r *feedPollRepo) GetFeedSubscriptionsForPoll(ctx context.Context, now time.Time, limit int) ([]*models.FeedSubscription, error) {
+	return r.subscriptions, nil
+}
+
+func (r *feedPollRepo) GetLinkGroupForUser(ctx context.Context, id, userID int) (*models.LinkGroup, error) {
+	return &models.LinkGroup{ID: id, WorkspaceID: 1, Role: models.LinkGroupRoleEditor}, nil
+}
+
+func (r *feedPollRepo) HasFeedSubscriptionItem(ctx context.Context, subscriptionID int, guid string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.items[fmt.Sprintf("%d:%s", subscriptionID, guid)], nil
+}
+
+func (r *feedPollRepo) AddFeedSubscriptionItem(ctx context.Context, subscriptionID int, guid string, linkID *int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items[fmt.Sprintf("%d:%s", subscriptionID, guid)] = true
+	return nil
+}
+
+func (r *feedPollRepo) GetLinkByCanonicalURL(ctx context.Context, workspaceID int, canonicalURL string) (*models.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, link := range r.links {
+		if link.WorkspaceID == workspaceID && *link.CanonicalURL == canonicalURL {
+			return link, nil
+		}
+	}
+	return nil, app_errors.NotFound("Ссылка не найдена", "feedPollRepo.GetLinkByCanonicalURL")
+}
+
+func (r *feedPollRepo) CreateLink(ctx context.Context, link *models.Link, tagIDs []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link.ID = len(r.links) + 1
+	r.links = append(r.links, link)
+	return nil
+}
+
+// GetLinkByID после создания ссылки сервис загружает ее страницу: в тесте ссылок "нет", сети не будет
+func (r *feedPollRepo) GetLinkByID(ctx context.Context, id int) (*models.Link, error) {
+	return nil, app_errors.NotFound("Ссылка не найдена", "feedPollRepo.GetLinkByID")
+}
+
+// SetFeedFetchResult сохраняет валидаторы только при успешной загрузке, как запрос в БД
+func (r *feedPollRepo) SetFeedFetchResult(ctx context.Context, id int, result *models.FeedFetchResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[id] = result
+	for _, subscription := range r.subscriptions {
+		if subscription.ID != id {
+			continue
+		}
+		if result.Error == "" {
+			subscription.ETag = result.ETag
+			subscription.LastModified = result.LastModified
+			subscription.ErrorCount = 0
+		} else {
+			subscription.ErrorCount++
+		}
+		subscription.LastError = result.Error
+	}
+	return nil
+}
+
+func (r *feedPollRepo) linkTitles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	titles := make([]string, len(r.links))
+	for i, link := range r.links {
+		titles[i] = link.Title
+	}
+	return titles
+}
+
+// feedServer лента на локальном сервере: записи от новых к старым, ETag меняется с каждым изменением
+type feedServer struct {
+	mu       sync.Mutex
+	atom     bool
+	entries  [][2]string // guid, путь
+	version  int
+	requests int
+	notMod   int
+}
+
+func (f *feedServer) add(guid, path string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append([][2]string{{guid, path}}, f.entries...)
+	f.version++
+}
+
+func (f *feedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	etag := fmt.Sprintf(`"v%d"`, f.version)
+	if r.Header.Get("If-None-Match") == etag {
+		f.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+
+	base := "http://" + r.Host
+	var body strings.Builder
+	if f.atom {
+		body.WriteString(`<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title>`)
+		for _, entry := range f.entries {
+			fmt.Fprintf(&body, `<entry><id>%s</id><title>%s</title><link href="%s%s"/></entry>`, entry[0], entry[0], base, entry[1])
+		}
+		body.WriteString(`</feed>`)
+	} else {
+		body.WriteString(`<rss version="2.0"><channel><title>RSS</title>`)
+		for _, entry := range f.entries {
+			fmt.Fprintf(&body, `<item><guid>%s</guid><title>%s</title><link>%s%s</link></item>`, entry[0], entry[0], base, entry[1])
+		}
+		body.WriteString(`</channel></rss>`)
+	}
+	_, _ = w.Write([]byte(body.String()))
+}
+
+func newFeedPollService(repo *feedPollRepo, maxItems int, allowPrivate bool) *linkService {
+	appLogger := logger.New("error")
+	return &linkService{
+		repo:   repo,
+		logger: appLogger,
+		events: events.NewBus(appLogger),
+		options: Options{
+			FeedPoll: FeedPollOptions{
+				Refresh:   time.Hour,
+				BatchSize: 10,
+				MaxItems:  maxItems,
+				Client:    parseurl.ClientOptions{Timeout: 5 * time.Second, AllowPrivateNetworks: allowPrivate},
+			},
+		},
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	return strings.Join(a, "|") == strings.Join(b, "|")
+}
+
+func TestPollFeedSubscriptions(t *testing.T) {
+	for _, format := range []string{"rss", "atom"} {
+		t.Run(format, func(t *testing.T) {
+			source := &feedServer{atom: format == "atom"}
+			source.add("c", "/c")
+			source.add("b", "/b")
+			source.add("a", "/a")
+			server := httptest.NewServer(source)
+			defer server.Close()
+
+			subscription := &models.FeedSubscription{ID: 1, LinkGroupID: 10, UserID: 5, URL: server.URL}
+			repo := newFeedPollRepo(subscription)
+			s := newFeedPollService(repo, 2, true)
+			ctx := context.Background()
+
+			// Берутся MaxItems новых записей, ссылки создаются от старых к новым
+			if err := s.PollFeedSubscriptions(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := repo.linkTitles(); !equalStrings(got, []string{"b", "a"}) {
+				t.Fatalf("после первой загрузки ссылки %v, ожидались [b a]", got)
+			}
+			if result := repo.results[1]; result.Error != "" || *result.Status != http.StatusOK || result.ETag != `"v3"` {
+				t.Errorf("результат загрузки %+v", result)
+			}
+			link := repo.links[0]
+			if *link.LinkGroupID != 10 || link.UserID != 5 || link.WorkspaceID != 1 || link.URL != server.URL+"/b" {
+				t.Errorf("ссылка %+v", link)
+			}
+
+			// Лента не изменилась: условный запрос, сервер отвечает 304
+			if err := s.PollFeedSubscriptions(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if source.notMod != 1 || len(repo.links) != 2 {
+				t.Errorf("304 ответов %d, ссылок %d, ожидалось 1 и 2", source.notMod, len(repo.links))
+			}
+			if result := repo.results[1]; result.Error != "" || *result.Status != http.StatusNotModified || result.ETag != `"v3"` {
+				t.Errorf("результат после 304 %+v", result)
+			}
+
+			// Новая запись: создается только она, известные записи пропускаются
+			source.add("d", "/d")
+			if err := s.PollFeedSubscriptions(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := repo.linkTitles(); !equalStrings(got, []string{"b", "a", "d"}) {
+				t.Errorf("после новой записи ссылки %v, ожидались [b a d]", got)
+			}
+
+			// Сервер без условных запросов отдает ленту целиком: дубликатов нет
+			subscription.ETag = ""
+			if err := s.PollFeedSubscriptions(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.links) != 3 {
+				t.Errorf("после повторной полной загрузки ссылок %d, ожидалось 3", len(repo.links))
+			}
+
+			// Запись с новым GUID, но уже сохраненным адресом, ссылку не дублирует
+			source.add("a-copy", "/a")
+			if err := s.PollFeedSubscriptions(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.links) != 3 || !repo.items["1:a-copy"] {
+				t.Errorf("запись с известным адресом: ссылок %d, отмечена %v", len(repo.links), repo.items["1:a-copy"])
+			}
+		})
+	}
+}
+
+func TestPollFeedSubscriptionsPrivateNetwork(t *testing.T) {
+	source := &feedServer{}
+	source.add("a", "/a")
+	server := httptest.NewServer(source)
+	defer server.Close()
+
+	subscription := &models.FeedSubscription{ID: 1, LinkGroupID: 10, UserID: 5, URL: server.URL}
+	repo := newFeedPollRepo(subscription)
+
+	// По умолчанию адреса локальной сети запрещены
+	if err := newFeedPollService(repo, 10, false).PollFeedSubscriptions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if source.requests != 0 || len(repo.links) != 0 {
+		t.Errorf("запросов %d, ссылок %d, ожидалось 0", source.requests, len(repo.links))
+	}
+	if result := repo.results[1]; !strings.Contains(result.Error, parseurl.ErrPrivateNetwork.Error()) {
+		t.Errorf("ошибка загрузки %q", result.Error)
+	}
+	if subscription.ErrorCount != 1 {
+		t.Errorf("ошибок подряд %d, ожидалась 1", subscription.ErrorCount)
+	}
+}
+
+func TestFeedSubscriptionBackoff(t *testing.T) {
+	tests := []struct {
+		errors int
+		want   time.Duration
+	}{
+		{errors: 1, want: time.Hour},
+		{errors: 2, want: 2 * time.Hour},
+		{errors: 4, want: 8 * time.Hour},
+		{errors: 6, want: feedSubscriptionMaxBackoff},
+		{errors: 100, want: feedSubscriptionMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := feedSubscriptionBackoff(time.Hour, tt.errors); got != tt.want {
+			t.Errorf("feedSubscriptionBackoff(1h, %d) = %s, ожидалось %s", tt.errors, got, tt.want)
+		}
+	}
+}
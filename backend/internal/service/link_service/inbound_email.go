@@ -0,0 +1,232 @@
+package link_service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"link-storage/internal/models"
+	"link-storage/pkg/mailparse"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"strings"
+)
+
+// inboundEmailTokenBytes случайных байт в токене адреса. Токен в hex: часть почтовых серверов
+// приводит адрес к нижнему регистру
+const inboundEmailTokenBytes = 16
+
+// InboundEmailOptions настройки сохранения ссылок письмом
+type InboundEmailOptions struct {
+	// Domain домен адресов LocalPart+<token>@Domain, пусто - прием писем выключен
+	Domain    string
+	LocalPart string
+	// MaxLinks сколько ссылок сохраняется из одного письма
+	MaxLinks int
+}
+
+func (s *linkService) setInboundEmailAddress(inbox *models.InboundEmail) {
+	inbox.Address = s.options.InboundEmail.LocalPart + "+" + inbox.Token + "@" + s.options.InboundEmail.Domain
+}
+
+// inboundEmailToken токен из адреса LocalPart+<token>@Domain, пустая строка - адрес не для сохранения ссылок
+func (s *linkService) inboundEmailToken(address string) string {
+	options := s.options.InboundEmail
+	if options.Domain == "" {
+		return ""
+	}
+
+	local, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(address)), "@")
+	if !ok || domain != strings.ToLower(options.Domain) {
+		return ""
+	}
+	token, ok := strings.CutPrefix(local, strings.ToLower(options.LocalPart)+"+")
+	if !ok || len(token) != hex.EncodedLen(inboundEmailTokenBytes) {
+		return ""
+	}
+	return token
+}
+
+// IsInboundEmailAddress адрес вида LocalPart+<token>@Domain. Токен здесь не проверяется
+func (s *linkService) IsInboundEmailAddress(address string) bool {
+	return s.inboundEmailToken(address) != ""
+}
+
+// GetInboundEmail адрес текущего пользователя для писем в активное пространство
+func (s *linkService) GetInboundEmail(ctx context.Context) (*models.InboundEmail, error) {
+	op := "link_service.GetInboundEmail"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox, err := s.repo.GetInboundEmail(ctx, user.ID, user.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.setInboundEmailAddress(inbox)
+	return inbox, nil
+}
+
+// CreateInboundEmail выпускает адрес для писем в активное пространство, прежний адрес перестает работать
+func (s *linkService) CreateInboundEmail(ctx context.Context) (*models.InboundEmail, error) {
+	op := "link_service.CreateInboundEmail"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.options.InboundEmail.Domain == "" {
+		return nil, app_errors.BadRequest("Прием писем не настроен", op)
+	}
+
+	random := make([]byte, inboundEmailTokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	inbox := &models.InboundEmail{
+		UserID:      user.ID,
+		WorkspaceID: user.WorkspaceID,
+		Token:       hex.EncodeToString(random),
+	}
+	if err := s.repo.SaveInboundEmail(ctx, inbox); err != nil {
+		return nil, err
+	}
+
+	s.setInboundEmailAddress(inbox)
+	return inbox, nil
+}
+
+func (s *linkService) DeleteInboundEmail(ctx context.Context) error {
+	op := "link_service.DeleteInboundEmail"
+
+	// Удалить свой адрес можно и после понижения роли
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteInboundEmail(ctx, user.ID, user.WorkspaceID)
+}
+
+// SaveInboundEmail сохраняет ссылки из письма в пространства адресатов. recipients - адреса из конверта SMTP,
+// пусто - адреса берутся из заголовков письма. Ни одного известного адреса - NotFound
+func (s *linkService) SaveInboundEmail(ctx context.Context, recipients []string, data []byte) (*models.InboundEmailResult, error) {
+	op := "link_service.SaveInboundEmail"
+
+	message, err := mailparse.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, app_errors.BadRequestWithError(err, "Неверный формат письма", op)
+	}
+
+	if len(recipients) == 0 {
+		recipients = message.Recipients
+	}
+
+	tokens := make(map[string]bool)
+	result := &models.InboundEmailResult{LinkIDs: []int{}}
+	found := false
+
+	for _, recipient := range recipients {
+		token := s.inboundEmailToken(recipient)
+		if token == "" || tokens[token] {
+			continue
+		}
+		tokens[token] = true
+
+		inbox, err := s.repo.GetInboundEmailByToken(ctx, token)
+		if err != nil {
+			if app_errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+
+		if !inbox.Role.LinkGroupRole().Allows(models.LinkGroupRoleEditor) {
+			s.logger.Warn("Письмо в пространство без прав на запись", op, "user_id", inbox.UserID, "workspace_id", inbox.WorkspaceID)
+			continue
+		}
+
+		if err := s.saveInboundEmailLinks(ctx, inbox, message, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if !found {
+		return nil, app_errors.NotFound("Адрес для писем не найден", op)
+	}
+
+	s.logger.Info("Письмо обработано", op, "saved", len(result.LinkIDs), "skipped", result.Skipped)
+	return result, nil
+}
+
+// saveInboundEmailLinks сохраняет ссылки письма от имени владельца адреса. Тема становится названием,
+// только если ссылка одна, иначе названия берутся со страниц. Уже сохраненные ссылки пропускаются
+func (s *linkService) saveInboundEmailLinks(ctx context.Context, inbox *models.InboundEmail, message *mailparse.Message, result *models.InboundEmailResult) error {
+	urls := message.URLs(s.options.InboundEmail.MaxLinks)
+	if len(urls) == 0 {
+		return nil
+	}
+
+	title := ""
+	if len(urls) == 1 {
+		title = truncateRunes(message.Title(), feedItemTitleLength)
+	}
+
+	// Теги создаются, только когда есть что сохранить
+	var tagIDs []int
+	hashtags := message.Hashtags()
+
+	for _, rawURL := range urls {
+		canonicalURL, err := parseurl.Canonicalize(rawURL)
+		if err != nil {
+			result.Skipped++
+			continue
+		}
+
+		if err := s.checkDuplicateLink(ctx, inbox.WorkspaceID, canonicalURL, ""); err != nil {
+			if app_errors.IsConflict(err) {
+				result.Skipped++
+				continue
+			}
+			return err
+		}
+
+		if len(hashtags) > 0 && tagIDs == nil {
+			if tagIDs, err = s.repo.GetOrCreateTags(ctx, inbox.WorkspaceID, inbox.UserID, hashtags); err != nil {
+				return err
+			}
+		}
+
+		link := &models.Link{
+			UserID:       inbox.UserID,
+			WorkspaceID:  inbox.WorkspaceID,
+			URL:          rawURL,
+			CanonicalURL: &canonicalURL,
+			Title:        title,
+		}
+		if err := s.repo.CreateLink(ctx, link, tagIDs); err != nil {
+			// Ту же ссылку успели сохранить параллельно
+			if app_errors.IsConflict(err) {
+				result.Skipped++
+				continue
+			}
+			return err
+		}
+		result.LinkIDs = append(result.LinkIDs, link.ID)
+
+		s.publishLink(ctx, models.EventLinkCreated, link)
+
+		// Иконку и заголовок получаем в фоне: отправитель письма не должен ждать загрузки страниц
+		go func(linkID int) {
+			_, _ = s.setLinkFavIconAndTitle(context.WithoutCancel(ctx), s.metadataClient, linkID)
+		}(link.ID)
+	}
+
+	return nil
+}
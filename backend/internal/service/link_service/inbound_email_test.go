@@ -0,0 +1,128 @@
+package link_service
+
+import (
+	"context"
+	"encoding/hex"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/link_repository"
+	"link-storage/pkg/events"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// inboundEmailRepo репозиторий для приема письма: один адрес, созданные ссылки хранятся в памяти,
+// сохраненные после загрузки страницы заголовки уходят в канал titles
+type inboundEmailRepo struct {
+	link_repository.LinkRepository
+
+	mu     sync.Mutex
+	links  map[int]*models.Link
+	titles chan string
+}
+
+func (r *inboundEmailRepo) GetInboundEmailByToken(ctx context.Context, token string) (*models.InboundEmail, error) {
+	return &models.InboundEmail{UserID: 1, WorkspaceID: 1, Token: token, Role: models.WorkspaceRoleOwner}, nil
+}
+
+func (r *inboundEmailRepo) GetLinkByCanonicalURL(ctx context.Context, workspaceID int, canonicalURL string) (*models.Link, error) {
+	return nil, app_errors.NotFound("Ссылка не найдена", "inboundEmailRepo.GetLinkByCanonicalURL")
+}
+
+func (r *inboundEmailRepo) CreateLink(ctx context.Context, link *models.Link, tagIDs []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link.ID = len(r.links) + 1
+	saved := *link
+	r.links[link.ID] = &saved
+	return nil
+}
+
+func (r *inboundEmailRepo) GetLinkByID(ctx context.Context, id int) (*models.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link := *r.links[id]
+	return &link, nil
+}
+
+func (r *inboundEmailRepo) SetLinkFavIconAndTitle(ctx context.Context, linkID int, favIconPath, title string) error {
+	r.titles <- title
+	return nil
+}
+
+func (r *inboundEmailRepo) SetLinkReadingMinutes(ctx context.Context, linkID int, minutes int) error {
+	return nil
+}
+
+func TestSaveInboundEmailPrivateNetwork(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/page" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>Внутренняя страница</title></head></html>`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		metadata  parseurl.ClientOptions
+		wantTitle string
+		wantFetch bool
+	}{
+		{name: "по умолчанию страница во внутренней сети не загружается"},
+		{
+			name:      "внутренняя сеть разрешена настройкой",
+			metadata:  parseurl.ClientOptions{AllowPrivateNetworks: true},
+			wantTitle: "Внутренняя страница",
+			wantFetch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			appLogger := logger.New("error")
+			repo := &inboundEmailRepo{links: map[int]*models.Link{}, titles: make(chan string, 1)}
+			s := New(repo, appLogger, nil, events.NewBus(appLogger), Options{
+				Metadata:     tt.metadata,
+				InboundEmail: InboundEmailOptions{Domain: "links.test", LocalPart: "save", MaxLinks: 10},
+			})
+
+			address := "save+" + strings.Repeat("a", hex.EncodedLen(inboundEmailTokenBytes)) + "@links.test"
+			message := "From: sender@example.com\r\nTo: " + address + "\r\nSubject: \r\n\r\n" + server.URL + "/page\r\n"
+
+			result, err := s.SaveInboundEmail(context.Background(), []string{address}, []byte(message))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.LinkIDs) != 1 {
+				t.Fatalf("сохранено ссылок %v, ожидалась одна", result.LinkIDs)
+			}
+
+			// Страница загружается в фоне, дожидаемся сохранения результата
+			select {
+			case title := <-repo.titles:
+				if title != tt.wantTitle {
+					t.Errorf("заголовок %q, ожидался %q", title, tt.wantTitle)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("страница ссылки не обработана")
+			}
+
+			if fetched := requests.Load() > 0; fetched != tt.wantFetch {
+				t.Errorf("запросов к странице %d", requests.Load())
+			}
+		})
+	}
+}
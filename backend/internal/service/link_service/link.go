@@ -8,10 +8,7 @@ import (
 	"link-storage/pkg/response"
 	"link-storage/pkg/types/app_errors"
 	"link-storage/pkg/utils/parseurl"
-)
-
-const (
-	defaultTopVisitedCount = 10
+	"net/http"
 )
 
 func (s *linkService) CreateLink(ctx context.Context, linkCreate *models.LinkCreate) (*models.Link, error) {
@@ -22,31 +19,79 @@ func (s *linkService) CreateLink(ctx context.Context, linkCreate *models.LinkCre
 		return nil, app_errors.Unauthorized(op)
 	}
 
-	link := &models.Link{
-		UserID:      user.ID,
-		LinkGroupID: linkCreate.LinkGroupID,
-		URL:         linkCreate.URL,
-		Title:       linkCreate.Title,
-		Description: linkCreate.Description,
-		IsFavorite:  linkCreate.IsFavorite,
+	// Ссылка попадает в пространство группы, без группы - в активное пространство
+	workspaceID := user.WorkspaceID
+	if linkCreate.LinkGroupID != nil {
+		linkGroup, err := s.linkGroupAccess(ctx, *linkCreate.LinkGroupID, models.LinkGroupRoleEditor, op)
+		if err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.BadRequest("Группа ссылок не найдена", op)
+			}
+			return nil, err
+		}
+		workspaceID = linkGroup.WorkspaceID
+	} else if !user.WorkspaceRole.LinkGroupRole().Allows(models.LinkGroupRoleEditor) {
+		return nil, app_errors.Forbidden(op)
+	}
+
+	// Дубликаты ищем по каноническому URL
+	canonicalURL, err := parseurl.Canonicalize(linkCreate.URL)
+	if err != nil {
+		return nil, app_errors.BadRequest("Неверный URL", op)
 	}
 
-	// 1. Создадим запись ссылки и получим ее ID
-	if err := s.repo.CreateLink(ctx, link); err != nil {
+	if err := s.checkDuplicateLink(ctx, workspaceID, canonicalURL, op); err != nil {
 		return nil, err
 	}
 
-	// 2. После создания ссылки и получения ID получим favicon, сохраним его на диск и запишем в БД
-	linkUpdated, err := s.setLinkFavIconAndTitle(ctx, link.ID)
-	if err != nil {
-		// Даже если не удалось получить favicon, возвращаем ссылку
-		return link, nil
+	for _, tagID := range linkCreate.TagIDs {
+		if _, err := s.repo.GetTagByID(ctx, tagID, workspaceID); err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.BadRequest("Тег не найден", op)
+			}
+			return nil, err
+		}
+	}
+
+	link := &models.Link{
+		UserID:       user.ID,
+		WorkspaceID:  workspaceID,
+		LinkGroupID:  linkCreate.LinkGroupID,
+		URL:          linkCreate.URL,
+		CanonicalURL: &canonicalURL,
+		Title:        linkCreate.Title,
+		Description:  linkCreate.Description,
+		IsFavorite:   linkCreate.IsFavorite,
+	}
+
+	// 1. Создадим запись ссылки вместе с тегами и получим ее ID
+	if err := s.repo.CreateLink(ctx, link, linkCreate.TagIDs); err != nil {
+		// Параллельное создание той же ссылки упрется в уникальный индекс
+		if app_errors.IsConflict(err) {
+			if dupErr := s.checkDuplicateLink(ctx, workspaceID, canonicalURL, op); dupErr != nil {
+				return nil, dupErr
+			}
+		}
+		// Тег удалили между проверкой и созданием
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.BadRequest("Тег не найден", op)
+		}
+		return nil, err
+	}
+
+	s.publishLink(ctx, models.EventLinkCreated, link)
+
+	// 2. После создания ссылки и получения ID получим favicon, сохраним его на диск и запишем в БД.
+	// Даже если не удалось получить favicon, возвращаем ссылку
+	if linkUpdated, err := s.setLinkFavIconAndTitle(ctx, s.metadataClient, link.ID); err == nil {
+		link = linkUpdated
 	}
 
-	return linkUpdated, nil
+	return link, nil
 }
 
-func (s *linkService) setLinkFavIconAndTitle(ctx context.Context, linkID int) (*models.Link, error) {
+// setLinkFavIconAndTitle загружает страницу ссылки клиентом client: заголовок, favicon, канонический адрес и время чтения
+func (s *linkService) setLinkFavIconAndTitle(ctx context.Context, client *http.Client, linkID int) (*models.Link, error) {
 	op := "link_service.setLinkFavIconAndTitle"
 
 	link, err := s.repo.GetLinkByID(ctx, linkID)
@@ -58,7 +103,7 @@ func (s *linkService) setLinkFavIconAndTitle(ctx context.Context, linkID int) (*
 		return nil, app_errors.NotFound("Ссылка не найдена", op)
 	}
 
-	urlInfo := parseurl.New(link.URL)
+	urlInfo := parseurl.New(ctx, client, link.URL)
 	
 	// Обновляем заголовок если он пустой
 	if link.Title == "" {
@@ -72,13 +117,18 @@ func (s *linkService) setLinkFavIconAndTitle(ctx context.Context, linkID int) (*
 	favIconUrl := urlInfo.GetFaviconPath()
 	if favIconUrl != "" {
 		// Загрузим фактически себе на диск иконку
-		localFaviconPath, err = urlInfo.DownloadFavicon(s.favIconsPath, link.UserID, link.ID)
+		localFaviconPath, err = urlInfo.DownloadFavicon(ctx, client, s.options.FavIconsPath, link.UserID, link.ID)
 		if err != nil {
 			// Логируем ошибку, но продолжаем
 			s.logger.Warn(fmt.Sprintf("Не удалось скачать favicon для ссылки %d: %v", link.ID, err), op)
 		}
 	}
 
+	// Канонический адрес, указанный самой страницей, точнее нашего
+	if s.options.UsePageCanonical {
+		s.setLinkPageCanonicalURL(ctx, link, urlInfo.GetCanonicalURL())
+	}
+
 	// Если удалось скачать, сохраняем локальный путь, иначе оставляем пустым
 	if localFaviconPath != "" {
 		link.FaviconURL = localFaviconPath // ЛОКАЛЬНЫЙ ПУТЬ!
@@ -90,34 +140,30 @@ func (s *linkService) setLinkFavIconAndTitle(ctx context.Context, linkID int) (*
 	if err := s.repo.SetLinkFavIconAndTitle(ctx, link.ID, link.FaviconURL, link.Title); err != nil {
 		return nil, err
 	}
+
+	// Время чтения по тексту страницы, страница без текста оценку не меняет
+	if minutes := readingMinutes(urlInfo.GetWordCount()); minutes > 0 {
+		if err := s.repo.SetLinkReadingMinutes(ctx, link.ID, minutes); err != nil {
+			return nil, err
+		}
+		link.ReadingMinutes = &minutes
+	}
+
+	s.publishLink(ctx, models.EventLinkMetadataReady, link)
 	return link, nil
 }
 
 func (s *linkService) LinkRefreshIcon(ctx context.Context, linkID int) (*models.Link, error) {
 	op := "link_service.LinkRefreshIcon"
 
-	user := middleware.GetCurrentUserFromContext(ctx)
-	if user == nil {
-		return nil, app_errors.Unauthorized(op)
-	}
-
-	link, err := s.repo.GetLinkByID(ctx, linkID)
-	if err != nil {
+	if _, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op); err != nil {
 		return nil, err
 	}
 
-	if link == nil {
-		return nil, app_errors.NotFound("ссылка не найдена", op)
-	}
-
-	if link.UserID != user.ID {
-		return nil, app_errors.NotFound("ссылка не найдена", op)
-	}
-
-	return s.setLinkFavIconAndTitle(ctx, linkID)
+	return s.setLinkFavIconAndTitle(ctx, s.metadataClient, linkID)
 }
 
-func (s *linkService) GetLinksByUserIDWithPagination(ctx context.Context, linkGroupID, page, pageSize int, name string) (*response.ListResponse[models.LinkResponse], error) {
+func (s *linkService) GetLinksByUserIDWithPagination(ctx context.Context, filter models.LinkFilter, page, pageSize int) (*response.ListResponse[models.LinkResponse], error) {
 	op := "link_service.GetLinksByUserIDWithPagination"
 
 	user := middleware.GetCurrentUserFromContext(ctx)
@@ -128,39 +174,18 @@ func (s *linkService) GetLinksByUserIDWithPagination(ctx context.Context, linkGr
 
 	offset := pageSize * (page - 1)
 
-	return s.repo.GetLinksByUserIDWithPagination(ctx, user.ID, linkGroupID, pageSize, offset, name)
-}
-
-func (s *linkService) LinkVisitedPlus(ctx context.Context, linkID int) error {
-	op := "link_service.LinkVisitedPlus"
-
-	user := middleware.GetCurrentUserFromContext(ctx)
-	if user == nil {
-		return app_errors.Unauthorized(op)
-	}
-
-	link, err := s.repo.GetLinkByID(ctx, linkID)
-	if err != nil {
-		return err
-	}
-
-	if link == nil {
-		return app_errors.NotFound("ссылка не найдена", op)
+	// Ссылки общей группы хранятся в пространстве группы
+	workspaceID := user.WorkspaceID
+	if filter.LinkGroupID > 0 {
+		linkGroup, err := s.linkGroupAccess(ctx, filter.LinkGroupID, models.LinkGroupRoleViewer, op)
+		if err != nil {
+			return nil, err
+		}
+		workspaceID = linkGroup.WorkspaceID
 	}
 
-	if link.UserID != user.ID {
-		return app_errors.NotFound("ссылка не найдена", op)
-	}
+	// Заметки и цитаты личные: поиск идет только по своим
+	filter.NotesUserID = user.ID
 
-	return s.repo.LinkVisitedPlus(ctx, linkID)
+	return s.repo.GetLinksByWorkspaceIDWithPagination(ctx, workspaceID, filter, pageSize, offset)
 }
-
-func (s *linkService) GetLinksTopVisited(ctx context.Context) ([]*models.Link, error) {
-	op := "link_service.GetLinksTopVisited"
-	
-	user := middleware.GetCurrentUserFromContext(ctx)
-	if user == nil {
-		return nil, app_errors.Unauthorized(op)
-	}
-	return s.repo.GetLinksTopVisited(ctx, user.ID, defaultTopVisitedCount)
-}
\ No newline at end of file
@@ -0,0 +1,99 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+)
+
+// workspaceUser текущий пользователь, если его роль в активном пространстве дает права required на содержимое
+func (s *linkService) workspaceUser(ctx context.Context, required models.LinkGroupRole, op string) (*models.CurrentUser, error) {
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	if !user.WorkspaceRole.LinkGroupRole().Allows(required) {
+		return nil, app_errors.Forbidden(op)
+	}
+
+	return user, nil
+}
+
+// linkGroupAccess группа, в которой у текущего пользователя роль не ниже required.
+// Группа недоступна - NotFound, роль ниже нужной - Forbidden
+func (s *linkService) linkGroupAccess(ctx context.Context, linkGroupID int, required models.LinkGroupRole, op string) (*models.LinkGroup, error) {
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	linkGroup, err := s.repo.GetLinkGroupForUser(ctx, linkGroupID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !linkGroup.Role.Allows(required) {
+		return nil, app_errors.Forbidden(op)
+	}
+
+	return linkGroup, nil
+}
+
+// linkAccess ссылка, доступная текущему пользователю с ролью не ниже required: ссылка активного пространства
+// или ссылка группы, в которой у пользователя есть роль
+func (s *linkService) linkAccess(ctx context.Context, linkID int, required models.LinkGroupRole, op string) (*models.Link, error) {
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	link, err := s.repo.GetLinkByID(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	if link == nil {
+		return nil, app_errors.NotFound("ссылка не найдена", op)
+	}
+
+	inWorkspace := link.WorkspaceID == user.WorkspaceID
+	if inWorkspace && user.WorkspaceRole.LinkGroupRole().Allows(required) {
+		return link, nil
+	}
+
+	if link.LinkGroupID == nil {
+		if inWorkspace {
+			return nil, app_errors.Forbidden(op)
+		}
+		return nil, app_errors.NotFound("ссылка не найдена", op)
+	}
+
+	if _, err := s.linkGroupAccess(ctx, *link.LinkGroupID, required, op); err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("ссылка не найдена", op)
+		}
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// targetLinkGroup группа, в которую переносится или создается содержимое пространства workspaceID.
+// Нужна роль editor, а группа должна быть из того же пространства
+func (s *linkService) targetLinkGroup(ctx context.Context, linkGroupID, workspaceID int, op string) (*models.LinkGroup, error) {
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Группа ссылок не найдена", op)
+		}
+		return nil, err
+	}
+
+	if linkGroup.WorkspaceID != workspaceID {
+		return nil, app_errors.BadRequest("Группа принадлежит другому пространству", op)
+	}
+
+	return linkGroup, nil
+}
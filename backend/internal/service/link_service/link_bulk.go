@@ -0,0 +1,247 @@
+package link_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/hash"
+	"link-storage/pkg/types/app_errors"
+	"sync"
+	"time"
+)
+
+// linkBulkChunkSize сколько ссылок фоновая задача обрабатывает одной транзакцией
+const linkBulkChunkSize = 500
+
+// linkBulkJobs фоновые массовые действия в памяти процесса. Завершенные задачи хранятся
+// options.BulkJobRetention и удаляются при запуске следующих
+type linkBulkJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*models.LinkBulkJob
+}
+
+func newLinkBulkJobs() *linkBulkJobs {
+	return &linkBulkJobs{jobs: map[string]*models.LinkBulkJob{}}
+}
+
+func (j *linkBulkJobs) add(job *models.LinkBulkJob, retention time.Duration) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	for id, old := range j.jobs {
+		if old.FinishedAt != nil && now.Sub(*old.FinishedAt) > retention {
+			delete(j.jobs, id)
+		}
+	}
+	j.jobs[job.ID] = job
+}
+
+// get копия задачи пользователя userID, nil - не найдена
+func (j *linkBulkJobs) get(id string, userID int) *models.LinkBulkJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job, ok := j.jobs[id]
+	if !ok || job.UserID != userID {
+		return nil
+	}
+
+	result := *job
+	result.Results = append([]*models.LinkBulkItemResult(nil), job.Results...)
+	return &result
+}
+
+// update изменяет задачу под блокировкой, чтобы чтение состояния не видело ее наполовину
+func (j *linkBulkJobs) update(job *models.LinkBulkJob, fn func(job *models.LinkBulkJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(job)
+}
+
+// BulkLinks выполняет действие над набором ссылок. До options.BulkJobThreshold ссылок действие
+// выполняется сразу одной транзакцией, больше - в фоне частями, состояние доступно через GetLinkBulkJob
+func (s *linkService) BulkLinks(ctx context.Context, bulk *models.LinkBulk) (*models.LinkBulkJob, error) {
+	op := "link_service.BulkLinks"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if bulk.Action == models.LinkBulkMove && bulk.LinkGroupID != nil {
+		if _, err := s.repo.GetLinkGroupByID(ctx, *bulk.LinkGroupID, user.WorkspaceID); err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.BadRequest("Группа ссылок не найдена", op)
+			}
+			return nil, err
+		}
+	}
+
+	job := &models.LinkBulkJob{
+		UserID:      user.ID,
+		WorkspaceID: user.WorkspaceID,
+		Action:      bulk.Action,
+		Status:      models.LinkBulkJobRunning,
+		Total:       len(bulk.LinkIDs),
+		CreatedAt:   time.Now(),
+	}
+
+	if len(bulk.LinkIDs) <= s.options.BulkJobThreshold {
+		s.runLinkBulk(ctx, job, bulk, len(bulk.LinkIDs))
+		return job, nil
+	}
+
+	job.ID = hash.GetRandomString()
+	job.Status = models.LinkBulkJobPending
+	s.bulkJobs.add(job, s.options.BulkJobRetention)
+
+	// Задача переживает запрос, но сохраняет пользователя для истории изменений
+	jobCtx := context.WithoutCancel(ctx)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				s.logger.Error(fmt.Errorf("PANIC: %v", p), op, "job_id", job.ID)
+			}
+		}()
+		s.runLinkBulk(jobCtx, job, bulk, linkBulkChunkSize)
+	}()
+
+	return s.bulkJobs.get(job.ID, user.ID), nil
+}
+
+func (s *linkService) GetLinkBulkJob(ctx context.Context, id string) (*models.LinkBulkJob, error) {
+	op := "link_service.GetLinkBulkJob"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	job := s.bulkJobs.get(id, user.ID)
+	if job == nil {
+		return nil, app_errors.NotFound("Задача не найдена", op)
+	}
+	return job, nil
+}
+
+// runLinkBulk обрабатывает ссылки частями по chunkSize и копит результаты в job
+func (s *linkService) runLinkBulk(ctx context.Context, job *models.LinkBulkJob, bulk *models.LinkBulk, chunkSize int) {
+	s.bulkJobs.update(job, func(job *models.LinkBulkJob) {
+		job.Status = models.LinkBulkJobRunning
+	})
+
+	for start := 0; start < len(bulk.LinkIDs); start += chunkSize {
+		ids := bulk.LinkIDs[start:min(start+chunkSize, len(bulk.LinkIDs))]
+
+		var results []*models.LinkBulkItemResult
+		if bulk.Action == models.LinkBulkRefresh {
+			results = s.refreshLinks(ctx, job.WorkspaceID, ids)
+		} else {
+			results = s.bulkUpdateLinks(ctx, job.WorkspaceID, bulk, ids)
+		}
+
+		s.bulkJobs.update(job, func(job *models.LinkBulkJob) {
+			job.Results = append(job.Results, results...)
+			job.Processed += len(ids)
+		})
+	}
+
+	s.bulkJobs.update(job, func(job *models.LinkBulkJob) {
+		finishedAt := time.Now()
+		job.Status = models.LinkBulkJobDone
+		job.FinishedAt = &finishedAt
+	})
+}
+
+// bulkUpdateLinks применяет действие к ids одной транзакцией: при ошибке не изменяется ни одна ссылка
+func (s *linkService) bulkUpdateLinks(ctx context.Context, workspaceID int, bulk *models.LinkBulk, ids []int) []*models.LinkBulkItemResult {
+	op := "link_service.bulkUpdateLinks"
+
+	part := *bulk
+	part.LinkIDs = ids
+
+	processed, err := s.repo.BulkUpdateLinks(ctx, workspaceID, &part)
+	if err != nil {
+		s.logger.Error(err, op, "action", bulk.Action)
+	}
+
+	done := make(map[int]bool, len(processed))
+	for _, id := range processed {
+		done[id] = true
+	}
+
+	if err == nil {
+		s.publishBulkLinks(ctx, workspaceID, bulk.Action, processed)
+	}
+
+	results := make([]*models.LinkBulkItemResult, 0, len(ids))
+	for _, id := range ids {
+		result := &models.LinkBulkItemResult{LinkID: id, Status: models.LinkBulkItemOK}
+		switch {
+		case err != nil:
+			result.Status = models.LinkBulkItemError
+			result.Error = bulkErrorMessage(err)
+		case !done[id]:
+			result.Status = models.LinkBulkItemNotFound
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// refreshLinks заново получает заголовок и favicon каждой ссылки, ошибка одной не прерывает остальные
+func (s *linkService) refreshLinks(ctx context.Context, workspaceID int, ids []int) []*models.LinkBulkItemResult {
+	results := make([]*models.LinkBulkItemResult, 0, len(ids))
+
+	for _, id := range ids {
+		result := &models.LinkBulkItemResult{LinkID: id, Status: models.LinkBulkItemOK}
+
+		link, err := s.repo.GetLinkByID(ctx, id)
+		switch {
+		case err != nil && !app_errors.IsNotFound(err):
+			result.Status = models.LinkBulkItemError
+			result.Error = bulkErrorMessage(err)
+		case err != nil || link.WorkspaceID != workspaceID:
+			result.Status = models.LinkBulkItemNotFound
+		default:
+			if _, err := s.setLinkFavIconAndTitle(ctx, s.metadataClient, id); err != nil {
+				result.Status = models.LinkBulkItemError
+				result.Error = bulkErrorMessage(err)
+			}
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
+
+// publishBulkLinks события об измененных массовым действием ссылках
+func (s *linkService) publishBulkLinks(ctx context.Context, workspaceID int, action models.LinkBulkAction, ids []int) {
+	op := "link_service.publishBulkLinks"
+
+	for _, id := range ids {
+		if action == models.LinkBulkDelete {
+			s.publish(ctx, models.EventLinkDeleted, workspaceID, nil, models.EventRef{ID: id})
+			continue
+		}
+
+		link, err := s.repo.GetLinkByID(ctx, id)
+		if err != nil {
+			s.logger.Error(err, op, "link_id", id)
+			continue
+		}
+		s.publishLink(ctx, models.EventLinkUpdated, link)
+	}
+}
+
+// bulkErrorMessage текст ошибки для клиента без внутренних подробностей
+func bulkErrorMessage(err error) string {
+	var appErr *app_errors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return "внутренняя ошибка"
+}
@@ -0,0 +1,90 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+)
+
+const canonicalBackfillBatch = 500
+
+// checkDuplicateLink возвращает Conflict с существующей ссылкой, если в пространстве уже есть такой URL
+func (s *linkService) checkDuplicateLink(ctx context.Context, workspaceID int, canonicalURL, op string) error {
+	existing, err := s.repo.GetLinkByCanonicalURL(ctx, workspaceID, canonicalURL)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	return app_errors.ConflictWithDetails("Такая ссылка уже сохранена", op, existing)
+}
+
+// setLinkPageCanonicalURL сохраняет канонический адрес из <link rel="canonical">, если он не занят другой ссылкой
+func (s *linkService) setLinkPageCanonicalURL(ctx context.Context, link *models.Link, pageCanonicalURL string) {
+	op := "link_service.setLinkPageCanonicalURL"
+
+	if pageCanonicalURL == "" {
+		return
+	}
+
+	canonicalURL, err := parseurl.Canonicalize(pageCanonicalURL)
+	if err != nil || (link.CanonicalURL != nil && *link.CanonicalURL == canonicalURL) {
+		return
+	}
+
+	if err := s.repo.SetLinkCanonicalURL(ctx, link.ID, canonicalURL); err != nil {
+		// Занят другой ссылкой - это дубликат, оставляем канонический URL по адресу
+		if !app_errors.IsConflict(err) {
+			s.logger.Error(err, op, "link_id", link.ID)
+		}
+		return
+	}
+
+	link.CanonicalURL = &canonicalURL
+}
+
+// BackfillCanonicalURLs заполняет канонический URL ссылкам, созданным до его появления.
+// Дубликаты упираются в уникальный индекс и остаются без канонического URL
+func (s *linkService) BackfillCanonicalURLs(ctx context.Context) error {
+	op := "link_service.BackfillCanonicalURLs"
+
+	var filled, duplicates int
+
+	afterID := 0
+	for {
+		links, err := s.repo.GetLinksWithoutCanonicalURL(ctx, afterID, canonicalBackfillBatch)
+		if err != nil {
+			return err
+		}
+
+		if len(links) == 0 {
+			break
+		}
+
+		for _, link := range links {
+			afterID = link.ID
+
+			canonicalURL, err := parseurl.Canonicalize(link.URL)
+			if err != nil {
+				continue
+			}
+
+			if err := s.repo.SetLinkCanonicalURL(ctx, link.ID, canonicalURL); err != nil {
+				if app_errors.IsConflict(err) {
+					duplicates++
+					continue
+				}
+				return err
+			}
+			filled++
+		}
+	}
+
+	if filled > 0 || duplicates > 0 {
+		s.logger.Info("Канонические URL заполнены", op, "filled", filled, "duplicates", duplicates)
+	}
+	return nil
+}
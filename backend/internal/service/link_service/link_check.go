@@ -0,0 +1,154 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LinkCheckOptions настройки фоновой проверки битых ссылок
+type LinkCheckOptions struct {
+	// Recheck как часто перепроверять одну и ту же ссылку
+	Recheck time.Duration
+	// BatchSize сколько ссылок проверять за один запуск
+	BatchSize int
+	// Workers общее число одновременных запросов
+	Workers int
+	// HostConcurrency одновременных запросов к одному хосту
+	HostConcurrency int
+	// HostDelay пауза между запросами к одному хосту
+	HostDelay time.Duration
+	// ArchiveAfter архивировать ссылку после стольких неудач подряд, 0 - не архивировать
+	ArchiveAfter int
+	Client       parseurl.ClientOptions
+}
+
+// CheckLinks фоновая задача: проверяет доступность очередной порции ссылок.
+// Запросы к одному хосту ограничены по параллельности и разнесены по времени
+func (s *linkService) CheckLinks(ctx context.Context) error {
+	op := "link_service.CheckLinks"
+
+	opts := s.options.LinkCheck
+
+	links, err := s.repo.GetLinksForCheck(ctx, time.Now().Add(-opts.Recheck), opts.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	client := parseurl.NewClient(opts.Client)
+	workers := make(chan struct{}, max(opts.Workers, 1))
+
+	var (
+		wg            sync.WaitGroup
+		mu            sync.Mutex
+		brokenCount   int
+		archivedCount int
+	)
+
+	for _, hostLinks := range groupLinksByHost(links) {
+		queue := make(chan *models.Link, len(hostLinks))
+		for _, link := range hostLinks {
+			queue <- link
+		}
+		close(queue)
+
+		for range min(max(opts.HostConcurrency, 1), len(hostLinks)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for link := range queue {
+					select {
+					case <-ctx.Done():
+						return
+					case workers <- struct{}{}:
+					}
+
+					broken, archived := s.checkLink(ctx, client, link)
+					<-workers
+
+					mu.Lock()
+					if broken {
+						brokenCount++
+					}
+					if archived {
+						archivedCount++
+					}
+					mu.Unlock()
+
+					// Вежливая пауза перед следующим запросом к этому хосту
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(opts.HostDelay):
+					}
+				}
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	s.logger.Info("Проверка ссылок завершена", op,
+		"checked", len(links),
+		"broken", brokenCount,
+		"archived", archivedCount)
+
+	return nil
+}
+
+// checkLink проверяет одну ссылку и сохраняет результат. Возвращает, битая ли ссылка и архивирована ли она
+func (s *linkService) checkLink(ctx context.Context, client *http.Client, link *models.Link) (broken, archived bool) {
+	op := "link_service.checkLink"
+
+	opts := s.options.LinkCheck
+
+	result := parseurl.Check(ctx, client, parseurl.NormalizeURL(link.URL))
+
+	// Прерванную остановкой приложения проверку не засчитываем
+	if ctx.Err() != nil {
+		return false, false
+	}
+
+	var httpStatus *int
+	if result.StatusCode > 0 {
+		httpStatus = &result.StatusCode
+	}
+
+	broken = result.IsBroken()
+	if result.Err != nil {
+		s.logger.Debug("Ссылка не открылась", "op", op, "link_id", link.ID, "error", result.Err.Error())
+	}
+
+	if err := s.repo.SetLinkCheckResult(ctx, link.ID, httpStatus, result.FinalURL, broken, opts.ArchiveAfter); err != nil {
+		s.logger.Error(err, op, "link_id", link.ID)
+		return false, false
+	}
+
+	archived = broken && opts.ArchiveAfter > 0 && link.CheckFailures+1 >= opts.ArchiveAfter
+	return broken, archived
+}
+
+// groupLinksByHost группирует ссылки по хосту для ограничения нагрузки на каждый сайт
+func groupLinksByHost(links []*models.Link) map[string][]*models.Link {
+	byHost := make(map[string][]*models.Link)
+
+	for _, link := range links {
+		host := ""
+		if parsedURL, err := url.Parse(parseurl.NormalizeURL(link.URL)); err == nil {
+			host = strings.ToLower(parsedURL.Hostname())
+		}
+		byHost[host] = append(byHost[host], link)
+	}
+
+	return byHost
+}
@@ -0,0 +1,182 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// minDuplicateTitleLength заголовки короче не сравниваются: слишком много случайных совпадений
+const minDuplicateTitleLength = 4
+
+// GetLinkDuplicates группы ссылок активного пространства с одинаковым каноническим URL
+// или почти одинаковым заголовком на одном домене
+func (s *linkService) GetLinkDuplicates(ctx context.Context) ([]*models.LinkDuplicateGroup, error) {
+	op := "link_service.GetLinkDuplicates"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.GetLinksByWorkspaceID(ctx, user.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupDuplicateLinks(links), nil
+}
+
+// groupDuplicateLinks объединяет ссылки в группы: связанные через любой общий ключ попадают в одну группу
+func groupDuplicateLinks(links []*models.Link) []*models.LinkDuplicateGroup {
+	parent := make([]int, len(links))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// reasons причины, по которым ссылка совпала с другой
+	reasons := make([]map[string]bool, len(links))
+
+	unite := func(keys map[string][]int, reason string) {
+		for _, indexes := range keys {
+			if len(indexes) < 2 {
+				continue
+			}
+			for _, i := range indexes {
+				if reasons[i] == nil {
+					reasons[i] = map[string]bool{}
+				}
+				reasons[i][reason] = true
+				parent[find(i)] = find(indexes[0])
+			}
+		}
+	}
+
+	byCanonical := map[string][]int{}
+	byTitle := map[string][]int{}
+
+	for i, link := range links {
+		// У ссылок, сохраненных до канонизации, канонический URL может быть пустым
+		canonicalURL := ""
+		if link.CanonicalURL != nil {
+			canonicalURL = *link.CanonicalURL
+		} else if value, err := parseurl.Canonicalize(link.URL); err == nil {
+			canonicalURL = value
+		}
+		if canonicalURL != "" {
+			byCanonical[canonicalURL] = append(byCanonical[canonicalURL], i)
+		}
+
+		title := normalizeDuplicateTitle(link.Title)
+		host := duplicateHost(link.URL)
+		if len([]rune(title)) >= minDuplicateTitleLength && host != "" {
+			key := host + " " + title
+			byTitle[key] = append(byTitle[key], i)
+		}
+	}
+
+	unite(byCanonical, models.DuplicateReasonCanonicalURL)
+	unite(byTitle, models.DuplicateReasonTitle)
+
+	groupsByRoot := map[int]*models.LinkDuplicateGroup{}
+	var groups []*models.LinkDuplicateGroup
+
+	for i, link := range links {
+		if reasons[i] == nil {
+			continue
+		}
+
+		root := find(i)
+		group, ok := groupsByRoot[root]
+		if !ok {
+			group = &models.LinkDuplicateGroup{}
+			groupsByRoot[root] = group
+			groups = append(groups, group)
+		}
+
+		group.Links = append(group.Links, link)
+		for reason := range reasons[i] {
+			if !slices.Contains(group.Reasons, reason) {
+				group.Reasons = append(group.Reasons, reason)
+			}
+		}
+	}
+
+	for _, group := range groups {
+		slices.Sort(group.Reasons)
+	}
+
+	return groups
+}
+
+// normalizeDuplicateTitle приводит заголовок к нижнему регистру и оставляет только слова из букв и цифр
+func normalizeDuplicateTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// duplicateHost домен ссылки без www
+func duplicateHost(rawURL string) string {
+	u, err := url.Parse(parseurl.NormalizeURL(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// MergeLinks сливает ссылки в одну и возвращает оставшуюся ссылку. Слитые ссылки попадают в корзину
+func (s *linkService) MergeLinks(ctx context.Context, linkMerge *models.LinkMerge) (*models.Link, error) {
+	op := "link_service.MergeLinks"
+
+	survivor, err := s.linkAccess(ctx, linkMerge.SurvivorID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сливать можно только ссылки одного пространства
+	links := make([]*models.Link, 0, len(linkMerge.LinkIDs))
+	for _, linkID := range linkMerge.LinkIDs {
+		link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+		if err != nil {
+			return nil, err
+		}
+		if link.WorkspaceID != survivor.WorkspaceID {
+			return nil, app_errors.BadRequest("Нельзя объединить ссылки разных пространств", op)
+		}
+		links = append(links, link)
+	}
+
+	if err := s.repo.MergeLinks(ctx, survivor.ID, linkMerge.LinkIDs); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Ссылки объединены", op, "survivor_id", survivor.ID, "merged", len(linkMerge.LinkIDs))
+
+	merged, err := s.repo.GetLinkByID(ctx, survivor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		if link.ID != survivor.ID {
+			s.publish(ctx, models.EventLinkDeleted, link.WorkspaceID, link.LinkGroupID, models.EventRef{ID: link.ID})
+		}
+	}
+	s.publishLink(ctx, models.EventLinkUpdated, merged)
+	return merged, nil
+}
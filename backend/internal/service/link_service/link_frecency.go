@@ -0,0 +1,46 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"strings"
+)
+
+const (
+	defaultLauncherCount = 10
+	maxLauncherCount     = 50
+)
+
+func (s *linkService) GetLinksForLauncher(ctx context.Context, prefix string, limit int) ([]*models.Link, error) {
+	op := "link_service.GetLinksForLauncher"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 {
+		limit = defaultLauncherCount
+	}
+	if limit > maxLauncherCount {
+		limit = maxLauncherCount
+	}
+
+	return s.repo.GetLinksForLauncher(ctx, user.WorkspaceID, strings.TrimSpace(prefix), limit)
+}
+
+// RecalculateFrecency фоновая задача: frecency зависит от давности посещений,
+// поэтому периодически пересчитываем ее для всех ссылок
+func (s *linkService) RecalculateFrecency(ctx context.Context) error {
+	op := "link_service.RecalculateFrecency"
+
+	updated, err := s.repo.RecalculateFrecency(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	if updated > 0 {
+		s.logger.Info("Frecency ссылок пересчитана", op, "links", updated)
+	}
+	return nil
+}
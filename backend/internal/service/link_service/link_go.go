@@ -0,0 +1,133 @@
+package link_service
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/hash"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoToLink регистрирует переход по ссылке (по ID или короткому имени) и возвращает URL для редиректа.
+// Доступ есть у владельца и участников общей группы ссылки (JWT из заголовка или cookie),
+// по подписанному токену из GetLinkGoURL и у всех для публичной короткой ссылки
+func (s *linkService) GoToLink(ctx context.Context, key, token, referrer, client string) (string, error) {
+	linkID, err := strconv.Atoi(key)
+	if err != nil {
+		return s.GoToShortLink(ctx, key, token, referrer, client)
+	}
+
+	link, err := s.repo.GetLinkByID(ctx, linkID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.goTo(ctx, link, "", token, referrer, client)
+}
+
+// GoToShortLink переход по короткой ссылке /s/{slug}
+func (s *linkService) GoToShortLink(ctx context.Context, slug, token, referrer, client string) (string, error) {
+	op := "link_service.GoToShortLink"
+
+	link, err := s.repo.GetLinkBySlug(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+
+	if link == nil || !link.IsSlugActive(time.Now()) {
+		return "", app_errors.NotFound("ссылка не найдена", op)
+	}
+
+	return s.goTo(ctx, link, *link.Slug, token, referrer, client)
+}
+
+func (s *linkService) goTo(ctx context.Context, link *models.Link, slug, token, referrer, client string) (string, error) {
+	op := "link_service.goTo"
+
+	if link == nil {
+		return "", app_errors.NotFound("ссылка не найдена", op)
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+
+	switch {
+	case slug != "" && link.SlugIsPublic:
+	case user != nil && user.WorkspaceID == link.WorkspaceID:
+	case token != "" && checkLinkGoToken(s.options.LinkSignSecret, link, token, time.Now()):
+	case user == nil && token == "":
+		return "", app_errors.Unauthorized(op)
+	case user != nil && link.LinkGroupID != nil:
+		if _, err := s.linkGroupAccess(ctx, *link.LinkGroupID, models.LinkGroupRoleViewer, op); err != nil {
+			return "", app_errors.NotFound("ссылка не найдена", op)
+		}
+	default:
+		return "", app_errors.NotFound("ссылка не найдена", op)
+	}
+
+	// Переход важнее статистики: ошибку записи посещения только логируем
+	if err := s.repo.LinkVisitedPlus(ctx, &models.LinkVisit{
+		LinkID:   link.ID,
+		UserID:   link.UserID,
+		Referrer: referrer,
+		Client:   client,
+		Slug:     slug,
+	}); err != nil {
+		s.logger.Error(err, op, "link_id", link.ID)
+	} else {
+		s.publish(ctx, models.EventLinkVisited, link.WorkspaceID, link.LinkGroupID, models.LinkVisitedEvent{LinkID: link.ID, Slug: slug, Client: client})
+	}
+
+	return parseurl.NormalizeURL(link.URL), nil
+}
+
+// GetLinkGoURL путь редиректа с подписанным токеном, по которому ссылку можно открыть без авторизации
+func (s *linkService) GetLinkGoURL(ctx context.Context, linkID int) (*models.LinkGoURL, error) {
+	op := "link_service.GetLinkGoURL"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.options.LinkGoTokenTTL).Truncate(time.Second)
+
+	query := url.Values{}
+	query.Set("token", signLinkGoToken(s.options.LinkSignSecret, link, expiresAt))
+
+	return &models.LinkGoURL{
+		Path:      fmt.Sprintf("/go/%d?%s", link.ID, query.Encode()),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// linkGoMessage подписываемое сообщение для токена перехода: привязано к ссылке, ее владельцу и сроку действия
+func linkGoMessage(link *models.Link, expiresAt int64) string {
+	return fmt.Sprintf("go:%d:%d:%d", link.ID, link.UserID, expiresAt)
+}
+
+// signLinkGoToken токен перехода вида <unix-время окончания>.<подпись>
+func signLinkGoToken(secret string, link *models.Link, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	return strconv.FormatInt(expires, 10) + "." + hash.Sign(secret, linkGoMessage(link, expires))
+}
+
+// checkLinkGoToken токен перехода подписан для этой ссылки и не истек к now
+func checkLinkGoToken(secret string, link *models.Link, token string, now time.Time) bool {
+	expiresValue, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(expiresValue, 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+
+	return hash.CheckSignature(secret, linkGoMessage(link, expires), signature)
+}
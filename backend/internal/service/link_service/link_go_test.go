@@ -0,0 +1,55 @@
+package link_service
+
+import (
+	"link-storage/internal/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkGoToken(t *testing.T) {
+	const secret = "secret"
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+
+	link := &models.Link{ID: 7, UserID: 3}
+	token := signLinkGoToken(secret, link, expiresAt)
+	_, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name   string
+		secret string
+		link   *models.Link
+		token  string
+		now    time.Time
+		want   bool
+	}{
+		{name: "действующий токен", secret: secret, link: link, token: token, now: now, want: true},
+		{name: "за секунду до окончания", secret: secret, link: link, token: token, now: expiresAt.Add(-time.Second), want: true},
+		{name: "истекший токен", secret: secret, link: link, token: token, now: expiresAt, want: false},
+		{name: "другая ссылка", secret: secret, link: &models.Link{ID: 8, UserID: 3}, token: token, now: now, want: false},
+		{name: "другой владелец", secret: secret, link: &models.Link{ID: 7, UserID: 4}, token: token, now: now, want: false},
+		{name: "другой секрет", secret: "other", link: link, token: token, now: now, want: false},
+		{
+			name:   "продленный срок со старой подписью",
+			secret: secret,
+			link:   link,
+			token:  strconv.FormatInt(expiresAt.Add(24*time.Hour).Unix(), 10) + "." + signature,
+			now:    now,
+			want:   false,
+		},
+		{name: "подпись без срока", secret: secret, link: link, token: signature, now: now, want: false},
+		{name: "нечисловой срок", secret: secret, link: link, token: "soon." + signature, now: now, want: false},
+		{name: "испорченная подпись", secret: secret, link: link, token: token + "x", now: now, want: false},
+		{name: "пустой токен", secret: secret, link: link, token: "", now: now, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkLinkGoToken(tt.secret, tt.link, tt.token, tt.now); got != tt.want {
+				t.Errorf("checkLinkGoToken() = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
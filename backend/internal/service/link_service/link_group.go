@@ -16,8 +16,23 @@ func (s *linkService) CreateLinkGroup(ctx context.Context, linkGroupCreate *mode
 		return nil, app_errors.Unauthorized(op)
 	}
 
-	// Перед созданием проверим, что нет группы с таким именем по пользователю
-	exists, err := s.repo.HasLinkGroupWithNameByUserID(ctx, linkGroupCreate.Name, user.ID)
+	// Подгруппа создается в пространстве родителя, корневая группа - в активном пространстве
+	workspaceID := user.WorkspaceID
+	if linkGroupCreate.ParentID != nil {
+		parent, err := s.linkGroupAccess(ctx, *linkGroupCreate.ParentID, models.LinkGroupRoleEditor, op)
+		if err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.NotFound("Родительская группа не найдена", op)
+			}
+			return nil, err
+		}
+		workspaceID = parent.WorkspaceID
+	} else if !user.WorkspaceRole.LinkGroupRole().Allows(models.LinkGroupRoleEditor) {
+		return nil, app_errors.Forbidden(op)
+	}
+
+	// Перед созданием проверим, что нет группы с таким именем в пространстве
+	exists, err := s.repo.HasLinkGroupWithNameByWorkspaceID(ctx, linkGroupCreate.Name, workspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +43,8 @@ func (s *linkService) CreateLinkGroup(ctx context.Context, linkGroupCreate *mode
 
 	linkGroup := &models.LinkGroup{
 		UserID:      user.ID,
+		WorkspaceID: workspaceID,
+		ParentID:    linkGroupCreate.ParentID,
 		Name:        linkGroupCreate.Name,
 		Description: linkGroupCreate.Description,
 		Color:       linkGroupCreate.Color,
@@ -37,33 +54,30 @@ func (s *linkService) CreateLinkGroup(ctx context.Context, linkGroupCreate *mode
 		return nil, err
 	}
 
+	s.publishLinkGroup(ctx, models.EventLinkGroupCreated, linkGroup)
 	return linkGroup, nil
 }
 
-func (s *linkService) GetLinkGroupByID(ctx context.Context, id, userID int) (*models.LinkGroup, error) {
-	return s.repo.GetLinkGroupByID(ctx, id, userID)
+func (s *linkService) GetLinkGroupByID(ctx context.Context, id, workspaceID int) (*models.LinkGroup, error) {
+	return s.repo.GetLinkGroupByID(ctx, id, workspaceID)
 }
 
 func (s *linkService) UpdateLinkGroup(ctx context.Context, linkGroupUpdate *models.LinkGroupUpdate) (*models.LinkGroup, error) {
 	op := "link_service.UpdateLinkGroup"
 
-	user := middleware.GetCurrentUserFromContext(ctx)
-	if user == nil {
-		return nil, app_errors.Unauthorized(op)
-	}
-
-	existsLinkGroup, err := s.repo.GetLinkGroupByID(ctx, linkGroupUpdate.ID, user.ID)
+	existsLinkGroup, err := s.linkGroupAccess(ctx, linkGroupUpdate.ID, models.LinkGroupRoleEditor, op)
 	if err != nil {
 		return nil, err
 	}
 
-	if existsLinkGroup == nil {
-		return nil, app_errors.NotFound("Группа не найдена", op)
-	}
-
 	linkGroup := &models.LinkGroup{
 		ID:          linkGroupUpdate.ID,
-		UserID:      user.ID,
+		UserID:      existsLinkGroup.UserID,
+		WorkspaceID: existsLinkGroup.WorkspaceID,
+		ParentID:    existsLinkGroup.ParentID,
+		Position:    existsLinkGroup.Position,
+		CreatedAt:   existsLinkGroup.CreatedAt,
+		Role:        existsLinkGroup.Role,
 		Name:        linkGroupUpdate.Name,
 		Description: linkGroupUpdate.Description,
 		Color:       linkGroupUpdate.Color,
@@ -73,31 +87,60 @@ func (s *linkService) UpdateLinkGroup(ctx context.Context, linkGroupUpdate *mode
 		return nil, err
 	}
 
+	s.publishLinkGroup(ctx, models.EventLinkGroupUpdated, linkGroup)
 	return linkGroup, nil
 }
 
-func (s *linkService) DeleteLinkGroup(ctx context.Context, id int) error {
+// DeleteLinkGroup удаляет группу, что делать с подгруппами и ссылками - в params
+func (s *linkService) DeleteLinkGroup(ctx context.Context, id int, params *models.LinkGroupDelete) error {
 	op := "link_service.DeleteLinkGroup"
 
-	user := middleware.GetCurrentUserFromContext(ctx)
-	if user == nil {
-		return app_errors.Unauthorized(op)
+	linkGroup, err := s.linkGroupAccess(ctx, id, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return err
 	}
 
-	linkGroup, err := s.repo.GetLinkGroupByID(ctx, id, user.ID)
-	if err != nil {
+	if params.Links == models.LinkGroupDeleteLinksMoveTo {
+		if params.MoveToID == linkGroup.ID {
+			return app_errors.BadRequest("Нельзя перенести ссылки в удаляемую группу", op)
+		}
+
+		if _, err := s.targetLinkGroup(ctx, params.MoveToID, linkGroup.WorkspaceID, op); err != nil {
+			if app_errors.IsNotFound(err) {
+				return app_errors.NotFound("Группа для переноса ссылок не найдена", op)
+			}
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteLinkGroup(ctx, linkGroup.ID, linkGroup.WorkspaceID, params); err != nil {
 		return err
 	}
 
-	if linkGroup == nil {
-		return app_errors.NotFound("Группа не найдена", op)
+	s.publish(ctx, models.EventLinkGroupDeleted, linkGroup.WorkspaceID, &linkGroup.ID, models.EventRef{ID: linkGroup.ID})
+	return nil
+}
+
+// GetLinkGroupDeletePreview сколько подгрупп и ссылок затронет удаление группы
+func (s *linkService) GetLinkGroupDeletePreview(ctx context.Context, id int, liftChildren bool) (*models.LinkGroupDeletePreview, error) {
+	op := "link_service.GetLinkGroupDeletePreview"
+
+	linkGroup, err := s.linkGroupAccess(ctx, id, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return nil, err
+	}
+
+	preview, err := s.repo.GetLinkGroupDeletePreview(ctx, linkGroup.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	if linkGroup.UserID != user.ID {
-		return app_errors.NotFound("Группа не найдена", op)
+	preview.AffectedLinks = preview.Links
+	if !liftChildren {
+		preview.AffectedLinks += preview.SubgroupLinks
 	}
 
-	return s.repo.DeleteLinkGroup(ctx, id)
+	return preview, nil
 }
 
 func (s *linkService) GetLinkGroupsByUserIDWithPagination(ctx context.Context, name string, page, pageSize int) (*response.ListResponse[models.LinkGroup], error) {
@@ -109,5 +152,5 @@ func (s *linkService) GetLinkGroupsByUserIDWithPagination(ctx context.Context, n
 		return nil, app_errors.Unauthorized(op)
 	}
 	offset := pageSize * (page - 1)
-	return s.repo.GetLinkGroupsByUserIDWithPagination(ctx, name, user.ID, pageSize, offset)
+	return s.repo.GetLinkGroupsByWorkspaceIDWithPagination(ctx, name, user.WorkspaceID, user.ID, pageSize, offset)
 }
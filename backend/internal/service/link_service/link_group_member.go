@@ -0,0 +1,150 @@
+package link_service
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/hash"
+	"link-storage/pkg/mailer"
+	"link-storage/pkg/types/app_errors"
+	"strings"
+	"time"
+)
+
+var linkGroupRoleNames = map[models.LinkGroupRole]string{
+	models.LinkGroupRoleViewer: "просмотр",
+	models.LinkGroupRoleEditor: "редактирование",
+	models.LinkGroupRoleOwner:  "управление",
+}
+
+func (s *linkService) GetLinkGroupMembers(ctx context.Context, linkGroupID int) ([]*models.LinkGroupMember, error) {
+	op := "link_service.GetLinkGroupMembers"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetLinkGroupMembers(ctx, linkGroup.ID)
+}
+
+// SetLinkGroupMemberRole меняет роль участника, доступно владельцам группы
+func (s *linkService) SetLinkGroupMemberRole(ctx context.Context, linkGroupID, userID int, memberSet *models.LinkGroupMemberSet) error {
+	op := "link_service.SetLinkGroupMemberRole"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetLinkGroupMemberRole(ctx, linkGroup.ID, userID, memberSet.Role)
+}
+
+// DeleteLinkGroupMember исключает участника. Выйти из группы может любой участник, исключить другого - только владелец
+func (s *linkService) DeleteLinkGroupMember(ctx context.Context, linkGroupID, userID int) error {
+	op := "link_service.DeleteLinkGroupMember"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return app_errors.Unauthorized(op)
+	}
+
+	required := models.LinkGroupRoleOwner
+	if userID == user.ID {
+		required = models.LinkGroupRoleViewer
+	}
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, required, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteLinkGroupMember(ctx, linkGroup.ID, userID)
+}
+
+// InviteToLinkGroup приглашает в группу по email и отправляет письмо со ссылкой на принятие.
+// Ошибка отправки не отменяет приглашение: повторное приглашение заменит его новым
+func (s *linkService) InviteToLinkGroup(ctx context.Context, linkGroupID int, inviteCreate *models.LinkGroupInviteCreate) (*models.LinkGroupInvite, error) {
+	op := "link_service.InviteToLinkGroup"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(inviteCreate.Email, user.Email) {
+		return nil, app_errors.BadRequest("Нельзя пригласить самого себя", op)
+	}
+
+	invite := &models.LinkGroupInvite{
+		LinkGroupID: linkGroup.ID,
+		Email:       inviteCreate.Email,
+		Role:        inviteCreate.Role,
+		Token:       hash.GetRandomString(),
+		InvitedBy:   &user.ID,
+		ExpiresAt:   time.Now().Add(s.options.InviteTTL),
+	}
+
+	if err := s.repo.CreateLinkGroupInvite(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	acceptURL := fmt.Sprintf("%s/invites/%s", strings.TrimRight(s.options.AppURL, "/"), invite.Token)
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      invite.Email,
+		Subject: fmt.Sprintf("Приглашение в группу ссылок «%s»", linkGroup.Name),
+		Text: fmt.Sprintf("%s приглашает вас в группу ссылок «%s» (%s).\n\nПринять приглашение: %s\n\nПриглашение действует до %s.\n",
+			user.Name, linkGroup.Name, linkGroupRoleNames[invite.Role], acceptURL, invite.ExpiresAt.Format("02.01.2006 15:04")),
+	}); err != nil {
+		s.logger.Error(err, op, "invite_id", invite.ID)
+	} else {
+		invite.EmailSent = true
+	}
+
+	return invite, nil
+}
+
+func (s *linkService) GetLinkGroupInvites(ctx context.Context, linkGroupID int) ([]*models.LinkGroupInvite, error) {
+	op := "link_service.GetLinkGroupInvites"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetLinkGroupInvites(ctx, linkGroup.ID)
+}
+
+func (s *linkService) DeleteLinkGroupInvite(ctx context.Context, linkGroupID, inviteID int) error {
+	op := "link_service.DeleteLinkGroupInvite"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteLinkGroupInvite(ctx, inviteID, linkGroup.ID)
+}
+
+// AcceptLinkGroupInvite принимает приглашение. Принять его может только пользователь с адресом из приглашения
+func (s *linkService) AcceptLinkGroupInvite(ctx context.Context, token string) (*models.LinkGroup, error) {
+	op := "link_service.AcceptLinkGroupInvite"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	linkGroupID, err := s.repo.AcceptLinkGroupInvite(ctx, token, user.ID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetLinkGroupForUser(ctx, linkGroupID, user.ID)
+}
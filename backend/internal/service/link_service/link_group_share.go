@@ -0,0 +1,235 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/hash"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"link-storage/pkg/utils/slug"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shareSlugLength длина имени публичной страницы: 22 символа base62 - около 131 бита, имя не подобрать
+const shareSlugLength = 22
+
+// GetLinkGroupShare текущая публикация группы, доступна владельцам группы
+func (s *linkService) GetLinkGroupShare(ctx context.Context, linkGroupID int) (*models.LinkGroupShare, error) {
+	op := "link_service.GetLinkGroupShare"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return nil, err
+	}
+
+	share, err := s.repo.GetLinkGroupShare(ctx, linkGroup.ID)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Группа не опубликована", op)
+		}
+		return nil, err
+	}
+
+	share.Path = "/p/" + share.Slug
+	return share, nil
+}
+
+// SaveLinkGroupShare публикует группу или меняет пароль и срок публикации. Имя страницы
+// сохраняется, пока его не перевыпустят явно
+func (s *linkService) SaveLinkGroupShare(ctx context.Context, linkGroupID int, shareSave *models.LinkGroupShareSave) (*models.LinkGroupShare, error) {
+	op := "link_service.SaveLinkGroupShare"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return nil, err
+	}
+
+	share, err := s.repo.GetLinkGroupShare(ctx, linkGroup.ID)
+	if err != nil {
+		if !app_errors.IsNotFound(err) {
+			return nil, err
+		}
+		share = &models.LinkGroupShare{LinkGroupID: linkGroup.ID, CreatedBy: &user.ID}
+	}
+
+	if share.Slug == "" || shareSave.RegenerateSlug {
+		share.Slug, err = slug.Base62(shareSlugLength)
+		if err != nil {
+			return nil, app_errors.Internal(err, op)
+		}
+	}
+
+	if shareSave.Password != nil {
+		share.PasswordHash = nil
+		if *shareSave.Password != "" {
+			passwordHash, err := hash.HashPassword(*shareSave.Password)
+			if err != nil {
+				return nil, app_errors.Internal(err, op)
+			}
+			share.PasswordHash = &passwordHash
+		}
+	}
+
+	share.ExpiresAt = shareSave.ExpiresAt
+
+	if err := s.repo.SaveLinkGroupShare(ctx, share); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Группа опубликована", op, "link_group_id", linkGroup.ID, "user_id", user.ID)
+
+	share.Path = "/p/" + share.Slug
+	return share, nil
+}
+
+// DeleteLinkGroupShare снимает группу с публикации, ссылка на страницу перестает работать
+func (s *linkService) DeleteLinkGroupShare(ctx context.Context, linkGroupID int) error {
+	op := "link_service.DeleteLinkGroupShare"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteLinkGroupShare(ctx, linkGroup.ID)
+}
+
+// GetPublicLinkGroup опубликованная группа с подгруппами для страницы /p/{slug}, без авторизации.
+// Несуществующая, отозванная и истекшая публикации неотличимы - NotFound. Для защищенной паролем
+// публикации без пароля - Unauthorized, с неверным паролем - Forbidden
+func (s *linkService) GetPublicLinkGroup(ctx context.Context, slug, password string) (*models.PublicLinkGroup, error) {
+	op := "link_service.GetPublicLinkGroup"
+
+	share, err := s.publicShare(ctx, slug, password, op)
+	if err != nil {
+		return nil, err
+	}
+
+	linkGroups, links, err := s.repo.GetPublicLinkGroupContent(ctx, share.LinkGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Картинки страница запрашивает без пароля, поэтому иконки отдаются только открытым публикациям
+	faviconPath := ""
+	if share.PasswordHash == nil {
+		faviconPath = "/p/" + share.Slug + "/favicons/"
+	}
+
+	root := buildPublicLinkGroup(share.LinkGroupID, linkGroups, links, faviconPath)
+	if root == nil {
+		return nil, app_errors.NotFound("Страница не найдена", op)
+	}
+	return root, nil
+}
+
+// GetPublicLinkFavicon путь к сохраненной иконке ссылки linkID опубликованной группы для /p/{slug}/favicons/{link_id}.
+// Ссылка должна входить в публикацию, защищенные паролем публикации иконки не раздают
+func (s *linkService) GetPublicLinkFavicon(ctx context.Context, slug string, linkID int) (string, error) {
+	op := "link_service.GetPublicLinkFavicon"
+
+	share, err := s.publicShare(ctx, slug, "", op)
+	if err != nil {
+		return "", err
+	}
+
+	_, links, err := s.repo.GetPublicLinkGroupContent(ctx, share.LinkGroupID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, link := range links {
+		if link.ID != linkID || link.FaviconURL == "" {
+			continue
+		}
+		// В поле хранится путь к файлу на сервере, за пределы каталога иконок не выходим
+		rel, err := filepath.Rel(s.options.FavIconsPath, link.FaviconURL)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			break
+		}
+		return link.FaviconURL, nil
+	}
+
+	return "", app_errors.NotFound("Иконка не найдена", op)
+}
+
+// publicShare активная публикация slug с проверкой пароля, ошибки - как у GetPublicLinkGroup
+func (s *linkService) publicShare(ctx context.Context, slug, password, op string) (*models.LinkGroupShare, error) {
+	share, err := s.repo.GetLinkGroupShareBySlug(ctx, slug)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Страница не найдена", op)
+		}
+		return nil, err
+	}
+
+	if !share.IsActive(time.Now()) {
+		return nil, app_errors.NotFound("Страница не найдена", op)
+	}
+
+	if share.PasswordHash != nil {
+		if password == "" {
+			return nil, app_errors.Unauthorized(op)
+		}
+		if !hash.CheckPasswordHash(password, *share.PasswordHash) {
+			return nil, app_errors.Forbidden(op)
+		}
+	}
+
+	return share, nil
+}
+
+// buildPublicLinkGroup собирает дерево публичной группы rootID, порядок групп и ссылок сохраняется из списков.
+// Иконки ссылок отдаются по адресу faviconPath + ID ссылки, пустой faviconPath - без иконок
+func buildPublicLinkGroup(rootID int, linkGroups []*models.LinkGroup, links []*models.Link, faviconPath string) *models.PublicLinkGroup {
+	byID := make(map[int]*models.PublicLinkGroup, len(linkGroups))
+	for _, linkGroup := range linkGroups {
+		byID[linkGroup.ID] = &models.PublicLinkGroup{
+			ID:          linkGroup.ID,
+			Name:        linkGroup.Name,
+			Description: linkGroup.Description,
+			Color:       linkGroup.Color,
+			Links:       []*models.PublicLink{},
+			Groups:      []*models.PublicLinkGroup{},
+		}
+	}
+
+	for _, linkGroup := range linkGroups {
+		if linkGroup.ID == rootID || linkGroup.ParentID == nil {
+			continue
+		}
+		if parent, ok := byID[*linkGroup.ParentID]; ok {
+			parent.Groups = append(parent.Groups, byID[linkGroup.ID])
+		}
+	}
+
+	for _, link := range links {
+		if link.LinkGroupID == nil {
+			continue
+		}
+		if group, ok := byID[*link.LinkGroupID]; ok {
+			// Адрес без схемы стал бы относительной ссылкой на саму публичную страницу
+			publicLink := &models.PublicLink{
+				URL:         parseurl.NormalizeURL(link.URL),
+				Title:       link.Title,
+				Description: link.Description,
+			}
+			// Иконка хранится файлом на сервере, наружу отдается только адрес в рамках публикации
+			if faviconPath != "" && link.FaviconURL != "" {
+				publicLink.FaviconURL = faviconPath + strconv.Itoa(link.ID)
+			}
+			group.Links = append(group.Links, publicLink)
+		}
+	}
+
+	return byID[rootID]
+}
@@ -0,0 +1,131 @@
+package link_service
+
+import (
+	"context"
+	"errors"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/link_repository"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"testing"
+)
+
+// publicShareRepo репозиторий публичной страницы: одна публикация группы 1 и ее ссылки
+type publicShareRepo struct {
+	link_repository.LinkRepository
+
+	share *models.LinkGroupShare
+	links []*models.Link
+}
+
+func (r *publicShareRepo) GetLinkGroupShareBySlug(ctx context.Context, slug string) (*models.LinkGroupShare, error) {
+	if slug != r.share.Slug {
+		return nil, app_errors.NotFound("Публикация не найдена", "publicShareRepo.GetLinkGroupShareBySlug")
+	}
+	return r.share, nil
+}
+
+func (r *publicShareRepo) GetPublicLinkGroupContent(ctx context.Context, linkGroupID int) ([]*models.LinkGroup, []*models.Link, error) {
+	return []*models.LinkGroup{{ID: linkGroupID}}, r.links, nil
+}
+
+func TestBuildPublicLinkGroup(t *testing.T) {
+	root, child, other := 1, 2, 3
+	linkGroups := []*models.LinkGroup{
+		{ID: root, Name: "Корень"},
+		{ID: child, Name: "Подгруппа", ParentID: &root},
+		{ID: other, Name: "Чужая"},
+	}
+	links := []*models.Link{
+		{ID: 10, URL: "example.com/a", Title: "Без схемы", LinkGroupID: &root, FaviconURL: "./media/favicons/1/10.ico"},
+		{URL: "http://example.com/b", LinkGroupID: &child},
+		{URL: "https://example.com/c", LinkGroupID: &other},
+		{URL: "https://example.com/d"},
+	}
+
+	group := buildPublicLinkGroup(root, linkGroups, links, "/p/abc/favicons/")
+
+	if len(group.Links) != 1 || len(group.Groups) != 1 {
+		t.Fatalf("ссылок %d, подгрупп %d, ожидалось 1 и 1", len(group.Links), len(group.Groups))
+	}
+	if got := group.Links[0].URL; got != "https://example.com/a" {
+		t.Errorf("адрес без схемы = %q, ожидался абсолютный https://example.com/a", got)
+	}
+	if got := group.Links[0].FaviconURL; got != "/p/abc/favicons/10" {
+		t.Errorf("иконка = %q, ожидался адрес публикации /p/abc/favicons/10", got)
+	}
+
+	subgroup := group.Groups[0]
+	if subgroup.Name != "Подгруппа" || len(subgroup.Links) != 1 || subgroup.Links[0].URL != "http://example.com/b" {
+		t.Errorf("неверная подгруппа: %+v", subgroup)
+	}
+	if got := subgroup.Links[0].FaviconURL; got != "" {
+		t.Errorf("иконка ссылки без иконки = %q", got)
+	}
+
+	// Без адреса иконок (публикация с паролем) иконки не отдаются
+	group = buildPublicLinkGroup(root, linkGroups, links, "")
+	if got := group.Links[0].FaviconURL; got != "" {
+		t.Errorf("иконка без адреса иконок = %q", got)
+	}
+}
+
+func TestGetPublicLinkFavicon(t *testing.T) {
+	groupID := 1
+	passwordHash := "hash"
+	links := []*models.Link{
+		{ID: 10, LinkGroupID: &groupID, FaviconURL: "media/favicons/1/10.ico"},
+		{ID: 11, LinkGroupID: &groupID},
+		{ID: 12, LinkGroupID: &groupID, FaviconURL: "media/favicons/../../etc/passwd"},
+	}
+
+	tests := []struct {
+		name   string
+		share  *models.LinkGroupShare
+		slug   string
+		linkID int
+		want   string
+		// wantCode HTTP код ошибки, 0 - иконка найдена
+		wantCode int
+	}{
+		{name: "иконка ссылки публикации", slug: "abc", linkID: 10, want: "media/favicons/1/10.ico"},
+		{name: "ссылка без иконки", slug: "abc", linkID: 11, wantCode: http.StatusNotFound},
+		{name: "ссылка не из публикации", slug: "abc", linkID: 20, wantCode: http.StatusNotFound},
+		{name: "путь вне каталога иконок", slug: "abc", linkID: 12, wantCode: http.StatusNotFound},
+		{name: "другая публикация", slug: "other", linkID: 10, wantCode: http.StatusNotFound},
+		{
+			name:     "публикация с паролем",
+			share:    &models.LinkGroupShare{LinkGroupID: groupID, Slug: "abc", PasswordHash: &passwordHash},
+			slug:     "abc",
+			linkID:   10,
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			share := tt.share
+			if share == nil {
+				share = &models.LinkGroupShare{LinkGroupID: groupID, Slug: "abc"}
+			}
+			s := &linkService{
+				repo:    &publicShareRepo{share: share, links: links},
+				logger:  logger.New("error"),
+				options: Options{FavIconsPath: "media/favicons"},
+			}
+
+			path, err := s.GetPublicLinkFavicon(context.Background(), tt.slug, tt.linkID)
+			if tt.wantCode != 0 {
+				var appErr *app_errors.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("GetPublicLinkFavicon = %q, %v, ожидался код %d", path, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || path != tt.want {
+				t.Errorf("GetPublicLinkFavicon = %q, %v, ожидалось %q", path, err, tt.want)
+			}
+		})
+	}
+}
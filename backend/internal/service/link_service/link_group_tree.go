@@ -0,0 +1,93 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+)
+
+// GetLinkGroupTree дерево групп активного пространства с количеством ссылок в каждом узле
+func (s *linkService) GetLinkGroupTree(ctx context.Context) ([]*models.LinkGroupNode, error) {
+	op := "link_service.GetLinkGroupTree"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.repo.GetLinkGroupsWithLinkCount(ctx, user.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildLinkGroupTree(nodes), nil
+}
+
+// buildLinkGroupTree собирает дерево из списка групп, порядок детей сохраняется из списка
+func buildLinkGroupTree(nodes []*models.LinkGroupNode) []*models.LinkGroupNode {
+	byID := make(map[int]*models.LinkGroupNode, len(nodes))
+	for _, node := range nodes {
+		node.Children = []*models.LinkGroupNode{}
+		byID[node.ID] = node
+	}
+
+	roots := []*models.LinkGroupNode{}
+	for _, node := range nodes {
+		var parent *models.LinkGroupNode
+		if node.ParentID != nil {
+			parent = byID[*node.ParentID]
+		}
+
+		if parent != nil {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	var countLinks func(node *models.LinkGroupNode) int
+	countLinks = func(node *models.LinkGroupNode) int {
+		node.TotalLinkCount = node.LinkCount
+		for _, child := range node.Children {
+			node.TotalLinkCount += countLinks(child)
+		}
+		return node.TotalLinkCount
+	}
+
+	for _, root := range roots {
+		countLinks(root)
+	}
+
+	return roots
+}
+
+// SetLinkGroupParent переносит группу вместе с подгруппами
+func (s *linkService) SetLinkGroupParent(ctx context.Context, id int, parentSet *models.LinkGroupParentSet) (*models.LinkGroup, error) {
+	op := "link_service.SetLinkGroupParent"
+
+	linkGroup, err := s.linkGroupAccess(ctx, id, models.LinkGroupRoleOwner, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentSet.ParentID != nil {
+		if _, err := s.targetLinkGroup(ctx, *parentSet.ParentID, linkGroup.WorkspaceID, op); err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.NotFound("Родительская группа не найдена", op)
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SetLinkGroupParent(ctx, linkGroup.ID, linkGroup.WorkspaceID, parentSet.ParentID); err != nil {
+		return nil, err
+	}
+
+	moved, err := s.linkGroupAccess(ctx, linkGroup.ID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishLinkGroup(ctx, models.EventLinkGroupUpdated, moved)
+	return moved, nil
+}
@@ -0,0 +1,157 @@
+package link_service
+
+import (
+	"context"
+	"encoding/json"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+)
+
+// linkRevertFields поля ссылки, которые восстанавливает откат к версии. Совпадают с LinkUpdate
+var linkRevertFields = []string{"url", "title", "description", "link_group_id", "is_archived", "is_favorite"}
+
+func (s *linkService) UpdateLink(ctx context.Context, linkID int, linkUpdate *models.LinkUpdate) (*models.Link, error) {
+	op := "link_service.UpdateLink"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	link.LinkGroupID = linkUpdate.LinkGroupID
+	link.URL = linkUpdate.URL
+	link.Title = linkUpdate.Title
+	link.Description = linkUpdate.Description
+	link.IsArchived = linkUpdate.IsArchived
+	link.IsFavorite = linkUpdate.IsFavorite
+
+	return s.saveLink(ctx, link, models.HistoryUpdate, op)
+}
+
+// saveLink сохраняет редактируемые поля ссылки с проверкой группы и дубликатов по каноническому URL
+func (s *linkService) saveLink(ctx context.Context, link *models.Link, action models.HistoryAction, op string) (*models.Link, error) {
+	if link.LinkGroupID != nil {
+		if _, err := s.targetLinkGroup(ctx, *link.LinkGroupID, link.WorkspaceID, op); err != nil {
+			if app_errors.IsNotFound(err) {
+				return nil, app_errors.BadRequest("Группа ссылок не найдена", op)
+			}
+			return nil, err
+		}
+	}
+
+	canonicalURL, err := parseurl.Canonicalize(link.URL)
+	if err != nil {
+		return nil, app_errors.BadRequest("Неверный URL", op)
+	}
+
+	if link.CanonicalURL == nil || *link.CanonicalURL != canonicalURL {
+		if err := s.checkDuplicateLink(ctx, link.WorkspaceID, canonicalURL, op); err != nil {
+			return nil, err
+		}
+		link.CanonicalURL = &canonicalURL
+	}
+
+	if err := s.repo.UpdateLink(ctx, link, action); err != nil {
+		// Параллельное сохранение той же ссылки упрется в уникальный индекс
+		if app_errors.IsConflict(err) {
+			if dupErr := s.checkDuplicateLink(ctx, link.WorkspaceID, canonicalURL, op); dupErr != nil {
+				return nil, dupErr
+			}
+		}
+		return nil, err
+	}
+
+	saved, err := s.repo.GetLinkByID(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishLink(ctx, models.EventLinkUpdated, saved)
+	return saved, nil
+}
+
+func (s *linkService) GetLinkHistory(ctx context.Context, linkID int) ([]*models.HistoryEntry, error) {
+	op := "link_service.GetLinkHistory"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetEntityHistory(ctx, models.EntityLink, link.ID, link.UserID)
+}
+
+func (s *linkService) GetLinkGroupHistory(ctx context.Context, linkGroupID int) ([]*models.HistoryEntry, error) {
+	op := "link_service.GetLinkGroupHistory"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetEntityHistory(ctx, models.EntityLinkGroup, linkGroup.ID, linkGroup.UserID)
+}
+
+// RevertLink возвращает редактируемые поля ссылки к состоянию после записи истории historyID.
+// Откат сам становится записью истории, поэтому его тоже можно отменить
+func (s *linkService) RevertLink(ctx context.Context, linkID int, historyID int64) (*models.Link, error) {
+	op := "link_service.RevertLink"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetEntityHistory(ctx, models.EntityLink, link.ID, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Состояние на момент версии собираем из новых значений всех записей до нее включительно
+	state := map[string]any{}
+	found := false
+	for _, entry := range entries {
+		if entry.Action != models.HistoryDelete && entry.Action != models.HistoryRestore {
+			for field, change := range entry.Changes {
+				state[field] = change.New
+			}
+		}
+		if entry.ID == historyID {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, app_errors.NotFound("Версия ссылки не найдена", op)
+	}
+
+	// Накладываем значения версии на текущую ссылку через JSON: в истории значения хранятся в том же виде
+	current, err := json.Marshal(link)
+	if err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	// Пустые при создании поля в историю не попадают: отсутствующее поле откатывается к пустому значению
+	for _, field := range linkRevertFields {
+		fields[field] = state[field]
+	}
+
+	reverted, err := json.Marshal(fields)
+	if err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	var revertedLink models.Link
+	if err := json.Unmarshal(reverted, &revertedLink); err != nil {
+		return nil, app_errors.BadRequest("Версию ссылки нельзя восстановить", op)
+	}
+
+	return s.saveLink(ctx, &revertedLink, models.HistoryRevert, op)
+}
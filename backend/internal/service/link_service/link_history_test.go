@@ -0,0 +1,83 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/link_repository"
+	"link-storage/pkg/events"
+	"link-storage/pkg/logger"
+	"testing"
+)
+
+// revertLinkRepo репозиторий для RevertLink: одна ссылка и ее история, сохраненная ссылка запоминается
+type revertLinkRepo struct {
+	link_repository.LinkRepository
+
+	link    models.Link
+	history []*models.HistoryEntry
+	saved   *models.Link
+}
+
+func (r *revertLinkRepo) GetLinkByID(ctx context.Context, id int) (*models.Link, error) {
+	link := r.link
+	return &link, nil
+}
+
+func (r *revertLinkRepo) GetEntityHistory(ctx context.Context, entityType models.EntityType, entityID, userID int) ([]*models.HistoryEntry, error) {
+	return r.history, nil
+}
+
+func (r *revertLinkRepo) UpdateLink(ctx context.Context, link *models.Link, action models.HistoryAction) error {
+	r.saved = link
+	r.link = *link
+	return nil
+}
+
+func TestRevertLinkToCreation(t *testing.T) {
+	appLogger := logger.New("error")
+	user := &models.CurrentUser{ID: 1, WorkspaceID: 1, WorkspaceRole: models.WorkspaceRoleOwner}
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, user)
+
+	groupID := 5
+	canonicalURL := "https://example.com/a"
+	// Ссылка создана без группы, затем перенесена в группу. Пустая группа при создании в историю не пишется
+	repo := &revertLinkRepo{
+		link: models.Link{
+			ID: 1, UserID: 1, WorkspaceID: 1, LinkGroupID: &groupID,
+			URL: canonicalURL, CanonicalURL: &canonicalURL, Title: "Новый заголовок", IsFavorite: true,
+		},
+		history: []*models.HistoryEntry{
+			{ID: 1, Action: models.HistoryCreate, Changes: map[string]models.FieldChange{
+				"url":         {New: canonicalURL},
+				"title":       {New: "Заголовок"},
+				"description": {New: ""},
+				"is_archived": {New: false},
+				"is_favorite": {New: false},
+			}},
+			{ID: 2, Action: models.HistoryUpdate, Changes: map[string]models.FieldChange{
+				"link_group_id": {Old: nil, New: float64(groupID)},
+				"title":         {Old: "Заголовок", New: "Новый заголовок"},
+				"is_favorite":   {Old: false, New: true},
+			}},
+		},
+	}
+	s := &linkService{repo: repo, logger: appLogger, events: events.NewBus(appLogger)}
+
+	link, err := s.RevertLink(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.saved == nil {
+		t.Fatal("ссылка не сохранена")
+	}
+	if link.LinkGroupID != nil {
+		t.Errorf("группа после отката %d, ожидалась без группы", *link.LinkGroupID)
+	}
+	if link.Title != "Заголовок" || link.IsFavorite {
+		t.Errorf("после отката %q, избранное %v", link.Title, link.IsFavorite)
+	}
+	if link.ID != 1 || link.WorkspaceID != 1 || link.CanonicalURL == nil || *link.CanonicalURL != canonicalURL {
+		t.Errorf("откат изменил служебные поля: %+v", link)
+	}
+}
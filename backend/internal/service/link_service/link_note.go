@@ -0,0 +1,167 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/markdown"
+)
+
+// Заметки и цитаты личные: пользователь видит и меняет только свои. Для них достаточно права
+// на просмотр ссылки
+
+// GetLinkNotes заметки текущего пользователя к ссылке, renderHTML - с Markdown, переведенным в HTML
+func (s *linkService) GetLinkNotes(ctx context.Context, linkID int, renderHTML bool) ([]*models.LinkNote, error) {
+	op := "link_service.GetLinkNotes"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	notes, err := s.repo.GetLinkNotes(ctx, link.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if renderHTML {
+		for _, note := range notes {
+			renderLinkNote(note)
+		}
+	}
+	return notes, nil
+}
+
+func (s *linkService) CreateLinkNote(ctx context.Context, linkID int, noteSave *models.LinkNoteSave) (*models.LinkNote, error) {
+	op := "link_service.CreateLinkNote"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	note := &models.LinkNote{
+		LinkID: link.ID,
+		UserID: user.ID,
+		Body:   noteSave.Body,
+	}
+	if err := s.repo.CreateLinkNote(ctx, note); err != nil {
+		return nil, err
+	}
+
+	renderLinkNote(note)
+	return note, nil
+}
+
+func (s *linkService) UpdateLinkNote(ctx context.Context, linkID, noteID int, noteSave *models.LinkNoteSave) (*models.LinkNote, error) {
+	op := "link_service.UpdateLinkNote"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	note := &models.LinkNote{
+		ID:     noteID,
+		LinkID: link.ID,
+		UserID: user.ID,
+		Body:   noteSave.Body,
+	}
+	if err := s.repo.UpdateLinkNote(ctx, note); err != nil {
+		return nil, err
+	}
+
+	renderLinkNote(note)
+	return note, nil
+}
+
+func (s *linkService) DeleteLinkNote(ctx context.Context, linkID, noteID int) error {
+	op := "link_service.DeleteLinkNote"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	return s.repo.DeleteLinkNote(ctx, noteID, link.ID, user.ID)
+}
+
+func (s *linkService) GetLinkHighlights(ctx context.Context, linkID int) ([]*models.LinkHighlight, error) {
+	op := "link_service.GetLinkHighlights"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	return s.repo.GetLinkHighlights(ctx, link.ID, user.ID)
+}
+
+// CreateLinkHighlight сохраняет цитату. Текст страницы не хранится, поэтому привязка сохраняется
+// как пришла от клиента и не сверяется со страницей
+func (s *linkService) CreateLinkHighlight(ctx context.Context, linkID int, highlightCreate *models.LinkHighlightCreate) (*models.LinkHighlight, error) {
+	op := "link_service.CreateLinkHighlight"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	highlight := &models.LinkHighlight{
+		LinkID: link.ID,
+		UserID: user.ID,
+		Quote:  highlightCreate.Quote,
+		Anchor: highlightCreate.Anchor,
+	}
+	if err := s.repo.CreateLinkHighlight(ctx, highlight); err != nil {
+		return nil, err
+	}
+	return highlight, nil
+}
+
+func (s *linkService) DeleteLinkHighlight(ctx context.Context, linkID, highlightID int) error {
+	op := "link_service.DeleteLinkHighlight"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	return s.repo.DeleteLinkHighlight(ctx, highlightID, link.ID, user.ID)
+}
+
+// ExportLinks ссылки активного пространства с заметками и цитатами текущего пользователя.
+// Заметки выгружаются и в Markdown, и в HTML
+func (s *linkService) ExportLinks(ctx context.Context) ([]*models.ExportLink, error) {
+	op := "link_service.ExportLinks"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.GetExportLinks(ctx, user.WorkspaceID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		for _, note := range link.Notes {
+			renderLinkNote(note)
+		}
+	}
+
+	s.logger.Info("Выгрузка ссылок", op, "user_id", user.ID, "workspace_id", user.WorkspaceID, "links", len(links))
+	return links, nil
+}
+
+func renderLinkNote(note *models.LinkNote) {
+	note.HTML = markdown.ToHTML(note.Body)
+}
@@ -0,0 +1,28 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+)
+
+func (s *linkService) SetLinkGroupsOrder(ctx context.Context, order *models.PositionOrder) error {
+	op := "link_service.SetLinkGroupsOrder"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetLinkGroupsOrder(ctx, user.WorkspaceID, order)
+}
+
+func (s *linkService) SetLinksOrder(ctx context.Context, linkGroupID int, order *models.PositionOrder) error {
+	op := "link_service.SetLinksOrder"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetLinksOrder(ctx, linkGroup.WorkspaceID, linkGroup.ID, order)
+}
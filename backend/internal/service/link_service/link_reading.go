@@ -0,0 +1,237 @@
+package link_service
+
+import (
+	"context"
+	"fmt"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/pkg/events"
+	"link-storage/pkg/mailer"
+	"link-storage/pkg/types/app_errors"
+	"strings"
+	"time"
+)
+
+const (
+	// readingWordsPerMinute средняя скорость чтения для оценки времени чтения страницы
+	readingWordsPerMinute = 200
+	// minReadingWords меньше слов - страница не статья (приложение, заглушка), время не оцениваем
+	minReadingWords = 50
+)
+
+// readingMinutes оценка времени чтения в минутах с округлением вверх, 0 - не оценивается
+func readingMinutes(words int) int {
+	if words < minReadingWords {
+		return 0
+	}
+	return (words + readingWordsPerMinute - 1) / readingWordsPerMinute
+}
+
+// ReminderNotifier канал доставки напоминаний о ссылках
+type ReminderNotifier interface {
+	NotifyLinkReminder(ctx context.Context, reminder *models.LinkReminder) error
+}
+
+// ReminderOptions настройки отправки напоминаний
+type ReminderOptions struct {
+	// Notifiers каналы доставки, напоминание уходит в каждый. Пусто - напоминания снимаются без отправки
+	Notifiers []ReminderNotifier
+	// BatchSize сколько напоминаний отправляется за один запуск
+	BatchSize int
+	// MaxAttempts сколько раз пробовать отправить напоминание, если канал вернул ошибку
+	MaxAttempts int
+	// RetryBase пауза перед первым повтором, дальше удваивается до RetryMax
+	RetryBase time.Duration
+	RetryMax  time.Duration
+}
+
+type emailReminderNotifier struct {
+	mailer mailer.Mailer
+	appURL string
+}
+
+// NewEmailReminderNotifier напоминания письмом на адрес пользователя
+func NewEmailReminderNotifier(mailer mailer.Mailer, appURL string) ReminderNotifier {
+	return &emailReminderNotifier{mailer: mailer, appURL: strings.TrimRight(appURL, "/")}
+}
+
+func (n *emailReminderNotifier) NotifyLinkReminder(ctx context.Context, reminder *models.LinkReminder) error {
+	link := reminder.Link
+	title := link.Title
+	if title == "" {
+		title = link.URL
+	}
+
+	text := fmt.Sprintf("%s, вы просили напомнить о ссылке:\n\n%s\n%s\n", reminder.UserName, title, link.URL)
+	if link.ReadingMinutes != nil {
+		text += fmt.Sprintf("\nВремя чтения: около %d мин.\n", *link.ReadingMinutes)
+	}
+	text += fmt.Sprintf("\nСписок чтения: %s/links?state=unread\n", n.appURL)
+
+	return n.mailer.Send(ctx, &mailer.Message{
+		To:      reminder.UserEmail,
+		Subject: "Напоминание: " + truncateRunes(title, 150),
+		Text:    text,
+	})
+}
+
+type eventReminderNotifier struct {
+	bus events.Bus
+}
+
+// NewEventReminderNotifier напоминания событием link.reminder в шину: его доставят webhooks пространства
+func NewEventReminderNotifier(bus events.Bus) ReminderNotifier {
+	return &eventReminderNotifier{bus: bus}
+}
+
+func (n *eventReminderNotifier) NotifyLinkReminder(ctx context.Context, reminder *models.LinkReminder) error {
+	n.bus.Publish(ctx, events.Event{
+		Type:        models.EventLinkReminder,
+		WorkspaceID: reminder.Link.WorkspaceID,
+		LinkGroupID: reminder.Link.LinkGroupID,
+		Data: &models.LinkReminderEvent{
+			UserID:   reminder.UserID,
+			RemindAt: reminder.RemindAt,
+			Link:     reminder.Link,
+		},
+	})
+	return nil
+}
+
+// SetLinkReadState отмечает ссылку прочитанной или возвращает ее в список чтения
+func (s *linkService) SetLinkReadState(ctx context.Context, linkID int, state models.LinkReadState) (*models.Link, error) {
+	op := "link_service.SetLinkReadState"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	var readAt *time.Time
+	if state == models.LinkStateRead {
+		now := time.Now()
+		readAt = &now
+	}
+
+	if err := s.repo.SetLinkReadAt(ctx, link.ID, readAt); err != nil {
+		return nil, err
+	}
+
+	return s.getPublishedLink(ctx, link.ID)
+}
+
+// SetLinkReminder ставит текущему пользователю напоминание о ссылке
+func (s *linkService) SetLinkReminder(ctx context.Context, linkID int, reminderSet *models.LinkReminderSet) (*models.Link, error) {
+	op := "link_service.SetLinkReminder"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if err := s.repo.SetLinkReminder(ctx, link.ID, &user.ID, &reminderSet.RemindAt); err != nil {
+		return nil, err
+	}
+
+	return s.getPublishedLink(ctx, link.ID)
+}
+
+func (s *linkService) DeleteLinkReminder(ctx context.Context, linkID int) error {
+	op := "link_service.DeleteLinkReminder"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return err
+	}
+
+	if link.RemindAt == nil {
+		return app_errors.NotFound("У ссылки нет напоминания", op)
+	}
+
+	if err := s.repo.SetLinkReminder(ctx, link.ID, nil, nil); err != nil {
+		return err
+	}
+
+	_, err = s.getPublishedLink(ctx, link.ID)
+	return err
+}
+
+// getPublishedLink перечитывает измененную ссылку и сообщает об изменении
+func (s *linkService) getPublishedLink(ctx context.Context, linkID int) (*models.Link, error) {
+	link, err := s.repo.GetLinkByID(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishLink(ctx, models.EventLinkUpdated, link)
+	return link, nil
+}
+
+// SendLinkReminders отправляет наступившие напоминания во все каналы. Напоминание снимается
+// со ссылки до отправки, чтобы параллельный запуск не отправил его второй раз. Если хоть один канал
+// вернул ошибку, напоминание возвращается на ссылку с отсрочкой и повторяется во всех каналах,
+// пока не кончатся попытки
+func (s *linkService) SendLinkReminders(ctx context.Context) error {
+	op := "link_service.SendLinkReminders"
+
+	opts := s.options.Reminders
+
+	reminders, err := s.repo.ClaimDueLinkReminders(ctx, time.Now(), opts.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	failed := 0
+	for _, reminder := range reminders {
+		var sendErr error
+		for _, notifier := range opts.Notifiers {
+			if err := notifier.NotifyLinkReminder(ctx, reminder); err != nil {
+				s.logger.Error(err, op, "link_id", reminder.Link.ID, "user_id", reminder.UserID)
+				sendErr = err
+			}
+		}
+		if sendErr == nil {
+			continue
+		}
+		failed++
+
+		attempts := reminder.Attempts + 1
+		if attempts >= opts.MaxAttempts {
+			s.logger.Warn("Напоминание не отправлено, попытки исчерпаны", op,
+				"link_id", reminder.Link.ID,
+				"user_id", reminder.UserID,
+				"attempts", attempts)
+			continue
+		}
+
+		// Повтор запоминается отдельно от остальных: ошибка БД не должна терять следующие напоминания
+		if err := s.repo.RetryLinkReminder(ctx, reminder, time.Now().Add(s.reminderRetryDelay(attempts)), attempts); err != nil {
+			s.logger.Error(err, op, "link_id", reminder.Link.ID, "user_id", reminder.UserID)
+		}
+	}
+
+	s.logger.Info("Напоминания отправлены", op,
+		"reminders", len(reminders),
+		"failed", failed)
+
+	return nil
+}
+
+// reminderRetryDelay пауза после attempts неудачных попыток: RetryBase, 2*RetryBase, 4*RetryBase... до RetryMax
+func (s *linkService) reminderRetryDelay(attempts int) time.Duration {
+	opts := s.options.Reminders
+
+	delay := opts.RetryBase
+	for range min(attempts-1, 30) {
+		delay *= 2
+		if delay >= opts.RetryMax {
+			return opts.RetryMax
+		}
+	}
+	return delay
+}
@@ -0,0 +1,113 @@
+package link_service
+
+import (
+	"context"
+	"errors"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/link_repository"
+	"link-storage/pkg/logger"
+	"testing"
+	"time"
+)
+
+// reminderRepo репозиторий для SendLinkReminders: отдает заданные напоминания и запоминает повторы
+type reminderRepo struct {
+	link_repository.LinkRepository
+
+	reminders []*models.LinkReminder
+	retries   map[int]reminderRetry
+}
+
+type reminderRetry struct {
+	remindAt time.Time
+	attempts int
+}
+
+func (r *reminderRepo) ClaimDueLinkReminders(ctx context.Context, now time.Time, limit int) ([]*models.LinkReminder, error) {
+	reminders := r.reminders
+	r.reminders = nil
+	return reminders, nil
+}
+
+func (r *reminderRepo) RetryLinkReminder(ctx context.Context, reminder *models.LinkReminder, remindAt time.Time, attempts int) error {
+	r.retries[reminder.Link.ID] = reminderRetry{remindAt: remindAt, attempts: attempts}
+	return nil
+}
+
+// failingNotifier канал доставки, который не может доставить напоминания о ссылках failIDs
+type failingNotifier struct {
+	failIDs map[int]bool
+	sent    []int
+}
+
+func (n *failingNotifier) NotifyLinkReminder(ctx context.Context, reminder *models.LinkReminder) error {
+	if n.failIDs[reminder.Link.ID] {
+		return errors.New("smtp: 451 временная ошибка")
+	}
+	n.sent = append(n.sent, reminder.Link.ID)
+	return nil
+}
+
+func TestSendLinkReminders(t *testing.T) {
+	const retryBase = time.Minute
+
+	tests := []struct {
+		name     string
+		attempts int
+		fail     bool
+		// wantRetry попыток у возвращенного напоминания, 0 - напоминание не возвращается
+		wantRetry int
+		wantDelay time.Duration
+	}{
+		{name: "отправлено", fail: false},
+		{name: "первая ошибка - повтор через RetryBase", fail: true, wantRetry: 1, wantDelay: retryBase},
+		{name: "повторная ошибка - отсрочка удваивается", attempts: 2, fail: true, wantRetry: 3, wantDelay: 4 * retryBase},
+		{name: "попытки исчерпаны", attempts: 4, fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder := &models.LinkReminder{Link: &models.Link{ID: 1}, UserID: 2, Attempts: tt.attempts}
+			repo := &reminderRepo{reminders: []*models.LinkReminder{reminder}, retries: map[int]reminderRetry{}}
+			notifier := &failingNotifier{failIDs: map[int]bool{1: tt.fail}}
+			// Второй канал доставляет всегда: ошибка одного канала все равно возвращает напоминание
+			other := &failingNotifier{}
+
+			s := &linkService{repo: repo, logger: logger.New("error"), options: Options{
+				Reminders: ReminderOptions{
+					Notifiers:   []ReminderNotifier{notifier, other},
+					BatchSize:   10,
+					MaxAttempts: 5,
+					RetryBase:   retryBase,
+					RetryMax:    time.Hour,
+				},
+			}}
+
+			started := time.Now()
+			if err := s.SendLinkReminders(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(other.sent) != 1 {
+				t.Errorf("второй канал получил %v", other.sent)
+			}
+
+			retry, ok := repo.retries[1]
+			if tt.wantRetry == 0 {
+				if ok {
+					t.Errorf("напоминание возвращено: %+v", retry)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("напоминание не возвращено на ссылку")
+			}
+			if retry.attempts != tt.wantRetry {
+				t.Errorf("попыток %d, ожидалось %d", retry.attempts, tt.wantRetry)
+			}
+			if delay := retry.remindAt.Sub(started); delay < tt.wantDelay || delay > tt.wantDelay+time.Second {
+				t.Errorf("повтор через %s, ожидалось %s", delay, tt.wantDelay)
+			}
+		})
+	}
+}
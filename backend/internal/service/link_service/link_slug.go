@@ -0,0 +1,118 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/slug"
+	"time"
+)
+
+const (
+	generatedSlugLength   = 7
+	generatedSlugAttempts = 5
+)
+
+func (s *linkService) SetLinkSlug(ctx context.Context, linkID int, slugSet *models.LinkSlugSet) (*models.Link, error) {
+	op := "link_service.SetLinkSlug"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return nil, err
+	}
+
+	newSlug := slugSet.Slug
+	switch {
+	case newSlug != "":
+		// Свое имя: занятость проверит уникальный индекс
+	case link.Slug != nil:
+		newSlug = *link.Slug
+	default:
+		newSlug, err = s.generateSlug(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SetLinkSlug(ctx, link.ID, &newSlug, slugSet.ExpiresAt, slugSet.IsPublic); err != nil {
+		if app_errors.IsConflict(err) {
+			return nil, app_errors.Conflict("Короткое имя уже занято", op)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetLinkByID(ctx, link.ID)
+}
+
+func (s *linkService) DeleteLinkSlug(ctx context.Context, linkID int) error {
+	op := "link_service.DeleteLinkSlug"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetLinkSlug(ctx, link.ID, nil, nil, false)
+}
+
+func (s *linkService) GetLinkSlugStats(ctx context.Context, linkID int, period models.VisitPeriod) (*models.LinkSlugStats, error) {
+	op := "link_service.GetLinkSlugStats"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.Slug == nil {
+		return nil, app_errors.NotFound("У ссылки нет короткого имени", op)
+	}
+
+	visits, err := s.repo.GetVisitsPerDay(ctx, link.WorkspaceID, models.VisitsFilter{
+		LinkID:  link.ID,
+		Since:   period.Since(time.Now()),
+		ViaSlug: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.LinkSlugStats{
+		Slug:       *link.Slug,
+		ClickCount: link.ClickCount,
+		Visits:     visits,
+	}
+	if stats.Visits == nil {
+		stats.Visits = []*models.VisitsPerDay{}
+	}
+	for _, visit := range visits {
+		stats.SlugClicks += visit.Visits
+	}
+
+	return stats, nil
+}
+
+// generateSlug случайное свободное короткое имя base62
+func (s *linkService) generateSlug(ctx context.Context) (string, error) {
+	op := "link_service.generateSlug"
+
+	for range generatedSlugAttempts {
+		candidate, err := slug.Base62(generatedSlugLength)
+		if err != nil {
+			return "", app_errors.Internal(err, op)
+		}
+
+		if models.IsReservedSlug(candidate) {
+			continue
+		}
+
+		exists, err := s.repo.HasLinkWithSlug(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+
+	return "", app_errors.Conflict("Не удалось подобрать свободное короткое имя, попробуйте еще раз", op)
+}
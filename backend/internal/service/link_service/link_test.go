@@ -0,0 +1,95 @@
+package link_service
+
+import (
+	"context"
+	"errors"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/link_repository"
+	"link-storage/pkg/events"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/types/app_errors"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// createLinkRepo репозиторий для CreateLink: теги и ссылки не хранятся, запоминаются аргументы создания
+type createLinkRepo struct {
+	link_repository.LinkRepository
+
+	createErr error
+	tagIDs    []int
+	created   bool
+}
+
+func (r *createLinkRepo) GetTagByID(ctx context.Context, id, workspaceID int) (*models.Tag, error) {
+	return &models.Tag{ID: id, WorkspaceID: workspaceID}, nil
+}
+
+func (r *createLinkRepo) GetLinkByCanonicalURL(ctx context.Context, workspaceID int, canonicalURL string) (*models.Link, error) {
+	return nil, app_errors.NotFound("Ссылка не найдена", "createLinkRepo.GetLinkByCanonicalURL")
+}
+
+func (r *createLinkRepo) CreateLink(ctx context.Context, link *models.Link, tagIDs []int) error {
+	r.tagIDs = tagIDs
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.created = true
+	link.ID = 1
+	return nil
+}
+
+// GetLinkByID страница ссылки после создания не загружается
+func (r *createLinkRepo) GetLinkByID(ctx context.Context, id int) (*models.Link, error) {
+	return nil, app_errors.NotFound("Ссылка не найдена", "createLinkRepo.GetLinkByID")
+}
+
+func TestCreateLinkTags(t *testing.T) {
+	appLogger := logger.New("error")
+	user := &models.CurrentUser{ID: 1, WorkspaceID: 1, WorkspaceRole: models.WorkspaceRoleOwner}
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, user)
+
+	tests := []struct {
+		name      string
+		createErr error
+		// wantCode HTTP код ошибки, 0 - ссылка создана
+		wantCode int
+	}{
+		{name: "теги создаются вместе со ссылкой"},
+		{
+			name:      "тег удален до создания",
+			createErr: app_errors.NotFound("Тег не найден", "test"),
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			name:      "ошибка добавления тегов не теряется",
+			createErr: app_errors.Internal(errors.New("db"), "test"),
+			wantCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &createLinkRepo{createErr: tt.createErr}
+			s := &linkService{repo: repo, logger: appLogger, events: events.NewBus(appLogger)}
+
+			link, err := s.CreateLink(ctx, &models.LinkCreate{URL: "https://example.com/a", TagIDs: []int{2, 3}})
+			if !slices.Equal(repo.tagIDs, []int{2, 3}) {
+				t.Errorf("теги при создании %v, ожидалось [2 3]", repo.tagIDs)
+			}
+
+			if tt.wantCode != 0 {
+				var appErr *app_errors.AppError
+				if link != nil || !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("CreateLink = %v, %v, ожидался код %d", link, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || link == nil || link.ID != 1 || !repo.created {
+				t.Errorf("CreateLink = %v, %v", link, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"time"
+)
+
+const (
+	defaultTopVisitedCount    = 10
+	defaultRecentVisitedCount = 20
+)
+
+func (s *linkService) LinkVisitedPlus(ctx context.Context, linkID int, referrer, client string) error {
+	op := "link_service.LinkVisitedPlus"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.LinkVisitedPlus(ctx, &models.LinkVisit{
+		LinkID:   link.ID,
+		UserID:   link.UserID,
+		Referrer: referrer,
+		Client:   client,
+	}); err != nil {
+		return err
+	}
+
+	s.publish(ctx, models.EventLinkVisited, link.WorkspaceID, link.LinkGroupID, models.LinkVisitedEvent{LinkID: link.ID, Client: client})
+	return nil
+}
+
+func (s *linkService) GetLinksTopVisited(ctx context.Context, period models.VisitPeriod) ([]*models.LinkTopVisited, error) {
+	op := "link_service.GetLinksTopVisited"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetLinksTopVisited(ctx, user.WorkspaceID, defaultTopVisitedCount, period.Since(time.Now()))
+}
+
+func (s *linkService) GetLinksRecentVisited(ctx context.Context) ([]*models.LinkRecentVisited, error) {
+	op := "link_service.GetLinksRecentVisited"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetLinksRecentVisited(ctx, user.WorkspaceID, defaultRecentVisitedCount)
+}
+
+func (s *linkService) GetLinkVisitsPerDay(ctx context.Context, linkID int, period models.VisitPeriod) ([]*models.VisitsPerDay, error) {
+	op := "link_service.GetLinkVisitsPerDay"
+
+	link, err := s.linkAccess(ctx, linkID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetVisitsPerDay(ctx, link.WorkspaceID, models.VisitsFilter{
+		LinkID: link.ID,
+		Since:  period.Since(time.Now()),
+	})
+}
+
+func (s *linkService) GetLinkGroupVisitsPerDay(ctx context.Context, linkGroupID int, period models.VisitPeriod) ([]*models.VisitsPerDay, error) {
+	op := "link_service.GetLinkGroupVisitsPerDay"
+
+	linkGroup, err := s.linkGroupAccess(ctx, linkGroupID, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetVisitsPerDay(ctx, linkGroup.WorkspaceID, models.VisitsFilter{
+		LinkGroupID: linkGroup.ID,
+		Since:       period.Since(time.Now()),
+	})
+}
+
+// RollupLinkVisits фоновая задача: сворачивает старые события посещений в дневные агрегаты
+func (s *linkService) RollupLinkVisits(ctx context.Context) error {
+	op := "link_service.RollupLinkVisits"
+
+	now := time.Now()
+
+	// Сворачиваем по границе суток (UTC), чтобы день реже делился между таблицами
+	rawBefore := now.Add(-s.options.VisitsRawRetention).Truncate(24 * time.Hour)
+
+	var dailyBefore *time.Time
+	if s.options.VisitsDailyRetention > 0 {
+		before := now.Add(-s.options.VisitsDailyRetention)
+		dailyBefore = &before
+	}
+
+	rolled, err := s.repo.RollupLinkVisits(ctx, rawBefore, dailyBefore)
+	if err != nil {
+		return err
+	}
+
+	if rolled > 0 {
+		s.logger.Info("Посещения свернуты в дневные агрегаты", op, "aggregates", rolled)
+	}
+	return nil
+}
@@ -4,40 +4,191 @@ import (
 	"context"
 	"link-storage/internal/models"
 	"link-storage/internal/repository/link_repository"
+	"link-storage/pkg/events"
 	"link-storage/pkg/logger"
+	"link-storage/pkg/mailer"
 	"link-storage/pkg/response"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"time"
 )
 
 type LinkService interface {
 	// LinkGroup
 	CreateLinkGroup(ctx context.Context, linkGroupCreate *models.LinkGroupCreate) (*models.LinkGroup, error)
-	GetLinkGroupByID(ctc context.Context, id, userID int) (*models.LinkGroup, error)
+	GetLinkGroupByID(ctc context.Context, id, workspaceID int) (*models.LinkGroup, error)
 	UpdateLinkGroup(ctx context.Context, linkGroupUpdate *models.LinkGroupUpdate) (*models.LinkGroup, error)
-	DeleteLinkGroup(ctx context.Context, id int) error
+	DeleteLinkGroup(ctx context.Context, id int, params *models.LinkGroupDelete) error
+	GetLinkGroupDeletePreview(ctx context.Context, id int, liftChildren bool) (*models.LinkGroupDeletePreview, error)
 	GetLinkGroupsByUserIDWithPagination(ctx context.Context, name string, page, pageSize int) (*response.ListResponse[models.LinkGroup], error)
+	SetLinkGroupsOrder(ctx context.Context, order *models.PositionOrder) error
+	GetLinkGroupTree(ctx context.Context) ([]*models.LinkGroupNode, error)
+	SetLinkGroupParent(ctx context.Context, id int, parentSet *models.LinkGroupParentSet) (*models.LinkGroup, error)
+	SetLinksOrder(ctx context.Context, linkGroupID int, order *models.PositionOrder) error
+
+	// LinkGroupMember
+	GetLinkGroupMembers(ctx context.Context, linkGroupID int) ([]*models.LinkGroupMember, error)
+	SetLinkGroupMemberRole(ctx context.Context, linkGroupID, userID int, memberSet *models.LinkGroupMemberSet) error
+	DeleteLinkGroupMember(ctx context.Context, linkGroupID, userID int) error
+	InviteToLinkGroup(ctx context.Context, linkGroupID int, inviteCreate *models.LinkGroupInviteCreate) (*models.LinkGroupInvite, error)
+	GetLinkGroupInvites(ctx context.Context, linkGroupID int) ([]*models.LinkGroupInvite, error)
+	DeleteLinkGroupInvite(ctx context.Context, linkGroupID, inviteID int) error
+	AcceptLinkGroupInvite(ctx context.Context, token string) (*models.LinkGroup, error)
+
+	// LinkGroupShare
+	GetLinkGroupShare(ctx context.Context, linkGroupID int) (*models.LinkGroupShare, error)
+	SaveLinkGroupShare(ctx context.Context, linkGroupID int, shareSave *models.LinkGroupShareSave) (*models.LinkGroupShare, error)
+	DeleteLinkGroupShare(ctx context.Context, linkGroupID int) error
+	GetPublicLinkGroup(ctx context.Context, slug, password string) (*models.PublicLinkGroup, error)
+	GetPublicLinkFavicon(ctx context.Context, slug string, linkID int) (string, error)
+
+	// Feed
+	GetFeedToken(ctx context.Context, source models.FeedSource) (*models.FeedToken, error)
+	CreateFeedToken(ctx context.Context, source models.FeedSource) (*models.FeedToken, error)
+	DeleteFeedToken(ctx context.Context, source models.FeedSource) error
+	GetFeed(ctx context.Context, token string) (*models.Feed, error)
+
+	// FeedSubscription
+	GetFeedSubscriptions(ctx context.Context, linkGroupID int) ([]*models.FeedSubscription, error)
+	CreateFeedSubscription(ctx context.Context, linkGroupID int, subscriptionCreate *models.FeedSubscriptionCreate) (*models.FeedSubscription, error)
+	DeleteFeedSubscription(ctx context.Context, linkGroupID, id int) error
+	RefreshFeedSubscription(ctx context.Context, linkGroupID, id int) (*models.FeedSubscription, error)
+	PollFeedSubscriptions(ctx context.Context) error
 
 	// Link
 	CreateLink(ctx context.Context, linkCreate *models.LinkCreate) (*models.Link, error)
 	LinkRefreshIcon(ctx context.Context, linkID int) (*models.Link, error)
-	GetLinksByUserIDWithPagination(ctx context.Context, linkGroupID, page, pageSize int, name string) (*response.ListResponse[models.LinkResponse], error)
-	LinkVisitedPlus(ctx context.Context, linkID int) error
-	GetLinksTopVisited(ctx context.Context) ([]*models.Link, error)
+	GetLinksByUserIDWithPagination(ctx context.Context, filter models.LinkFilter, page, pageSize int) (*response.ListResponse[models.LinkResponse], error)
+	LinkVisitedPlus(ctx context.Context, linkID int, referrer, client string) error
+	GetLinksTopVisited(ctx context.Context, period models.VisitPeriod) ([]*models.LinkTopVisited, error)
+	GetLinksRecentVisited(ctx context.Context) ([]*models.LinkRecentVisited, error)
+	GetLinkVisitsPerDay(ctx context.Context, linkID int, period models.VisitPeriod) ([]*models.VisitsPerDay, error)
+	GetLinkGroupVisitsPerDay(ctx context.Context, linkGroupID int, period models.VisitPeriod) ([]*models.VisitsPerDay, error)
+	RollupLinkVisits(ctx context.Context) error
+	RecalculateFrecency(ctx context.Context) error
+	CheckLinks(ctx context.Context) error
+	BackfillCanonicalURLs(ctx context.Context) error
+	GetLinkDuplicates(ctx context.Context) ([]*models.LinkDuplicateGroup, error)
+	MergeLinks(ctx context.Context, linkMerge *models.LinkMerge) (*models.Link, error)
+	GetLinksForLauncher(ctx context.Context, prefix string, limit int) ([]*models.Link, error)
+	GoToLink(ctx context.Context, key, token, referrer, client string) (string, error)
+	GoToShortLink(ctx context.Context, slug, token, referrer, client string) (string, error)
+	GetLinkGoURL(ctx context.Context, linkID int) (*models.LinkGoURL, error)
+	SetLinkSlug(ctx context.Context, linkID int, slugSet *models.LinkSlugSet) (*models.Link, error)
+	DeleteLinkSlug(ctx context.Context, linkID int) error
+	GetLinkSlugStats(ctx context.Context, linkID int, period models.VisitPeriod) (*models.LinkSlugStats, error)
+	DeleteLink(ctx context.Context, id int) error
+	UpdateLink(ctx context.Context, linkID int, linkUpdate *models.LinkUpdate) (*models.Link, error)
+	BulkLinks(ctx context.Context, bulk *models.LinkBulk) (*models.LinkBulkJob, error)
+	GetLinkBulkJob(ctx context.Context, id string) (*models.LinkBulkJob, error)
+
+	// LinkReading
+	SetLinkReadState(ctx context.Context, linkID int, state models.LinkReadState) (*models.Link, error)
+	SetLinkReminder(ctx context.Context, linkID int, reminderSet *models.LinkReminderSet) (*models.Link, error)
+	DeleteLinkReminder(ctx context.Context, linkID int) error
+	SendLinkReminders(ctx context.Context) error
+
+	// LinkNote
+	GetLinkNotes(ctx context.Context, linkID int, renderHTML bool) ([]*models.LinkNote, error)
+	CreateLinkNote(ctx context.Context, linkID int, noteSave *models.LinkNoteSave) (*models.LinkNote, error)
+	UpdateLinkNote(ctx context.Context, linkID, noteID int, noteSave *models.LinkNoteSave) (*models.LinkNote, error)
+	DeleteLinkNote(ctx context.Context, linkID, noteID int) error
+	GetLinkHighlights(ctx context.Context, linkID int) ([]*models.LinkHighlight, error)
+	CreateLinkHighlight(ctx context.Context, linkID int, highlightCreate *models.LinkHighlightCreate) (*models.LinkHighlight, error)
+	DeleteLinkHighlight(ctx context.Context, linkID, highlightID int) error
+	ExportLinks(ctx context.Context) ([]*models.ExportLink, error)
+
+	// Digest
+	GetDigestSettings(ctx context.Context) (*models.DigestSettings, error)
+	SaveDigestSettings(ctx context.Context, settingsSave *models.DigestSettingsSave) (*models.DigestSettings, error)
+	PreviewDigest(ctx context.Context) (*models.DigestMessage, error)
+	UnsubscribeDigest(ctx context.Context, token string) error
+	SendDigests(ctx context.Context) error
+
+	// History
+	GetLinkHistory(ctx context.Context, linkID int) ([]*models.HistoryEntry, error)
+	GetLinkGroupHistory(ctx context.Context, linkGroupID int) ([]*models.HistoryEntry, error)
+	RevertLink(ctx context.Context, linkID int, historyID int64) (*models.Link, error)
+
+	// Trash
+	GetTrash(ctx context.Context, itemType models.TrashItemType, page, pageSize int) (*response.ListResponse[models.TrashItem], error)
+	RestoreFromTrash(ctx context.Context, itemType models.TrashItemType, id int) error
+	PurgeTrash(ctx context.Context) error
+
+	// Tag
+	GetTags(ctx context.Context) ([]*models.Tag, error)
+
+	// InboundEmail
+	GetInboundEmail(ctx context.Context) (*models.InboundEmail, error)
+	CreateInboundEmail(ctx context.Context) (*models.InboundEmail, error)
+	DeleteInboundEmail(ctx context.Context) error
+	IsInboundEmailAddress(address string) bool
+	SaveInboundEmail(ctx context.Context, recipients []string, data []byte) (*models.InboundEmailResult, error)
+
+	// Events
+	SubscribeEvents(ctx context.Context, lastEventID string) (*EventStream, error)
 	//GetLinkByID(ctx context.Context, id, userID int) (*models.Link, error)
-	//UpdateLink(ctx context.Context, linkUpdate *models.LinkUpdate) (*models.Link, error)
-	//DeleteLink(ctx context.Context, id int) error
 	//GetLinksByLinkGroupIDWithPagination(ctx context.Context, linkGroupID, page, pageSize int) (*response.ListResponse[models.Link], error))
 }
 
+// Options настройки сервиса ссылок
+type Options struct {
+	FavIconsPath string
+	// LinkSignSecret секрет для подписи токенов перехода по ссылкам
+	LinkSignSecret string
+	// LinkGoTokenTTL срок действия подписанного токена перехода по ссылке
+	LinkGoTokenTTL time.Duration
+	// UsePageCanonical брать канонический URL из <link rel="canonical"> страницы
+	UsePageCanonical bool
+	// Metadata клиент загрузки страницы и favicon ссылки
+	Metadata parseurl.ClientOptions
+	// VisitsRawRetention сколько хранить сырые события посещений до свертки в дневные агрегаты
+	VisitsRawRetention time.Duration
+	// VisitsDailyRetention сколько хранить дневные агрегаты, 0 - бессрочно
+	VisitsDailyRetention time.Duration
+	LinkCheck            LinkCheckOptions
+	// TrashRetention сколько хранить элементы в корзине до окончательного удаления
+	TrashRetention time.Duration
+	// BulkJobThreshold с какого количества ссылок массовое действие выполняется в фоне
+	BulkJobThreshold int
+	// BulkJobRetention сколько хранить результаты завершенных фоновых массовых действий
+	BulkJobRetention time.Duration
+	// AppURL адрес клиентского приложения для ссылок в письмах
+	AppURL string
+	// InviteTTL срок действия приглашения в общую группу
+	InviteTTL time.Duration
+	FeedPoll  FeedPollOptions
+	Events    EventStreamOptions
+	// InboundEmail сохранение ссылок письмом
+	InboundEmail InboundEmailOptions
+	// Reminders напоминания о ссылках
+	Reminders ReminderOptions
+	Digest    DigestOptions
+}
+
 type linkService struct {
-	repo         link_repository.LinkRepository
-	logger       logger.AppLogger
-	favIconsPath string
+	repo     link_repository.LinkRepository
+	logger   logger.AppLogger
+	options  Options
+	mailer   mailer.Mailer
+	events   events.Bus
+	bulkJobs *linkBulkJobs
+	// metadataClient загружает страницы и favicon ссылок, запросы во внутреннюю сеть запрещены
+	metadataClient *http.Client
+	// broadcaster раздает события шины потокам SSE
+	broadcaster *events.Broadcaster
 }
 
-func New(repo link_repository.LinkRepository, logger logger.AppLogger, favIconsPath string) LinkService {
+func New(repo link_repository.LinkRepository, logger logger.AppLogger, mailer mailer.Mailer, bus events.Bus, options Options) LinkService {
 	return &linkService{
-		repo:         repo,
-		logger:       logger,
-		favIconsPath: favIconsPath,
+		repo:        repo,
+		logger:      logger,
+		options:     options,
+		mailer:      mailer,
+		events:      bus,
+		bulkJobs:    newLinkBulkJobs(),
+		broadcaster: events.NewBroadcaster(bus, options.Events.BufferSize),
+		// Клиент общий для всех загрузок: у него пул соединений
+		metadataClient: parseurl.NewClient(options.Metadata),
 	}
 }
@@ -0,0 +1,18 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+)
+
+// GetTags теги активного пространства
+func (s *linkService) GetTags(ctx context.Context) ([]*models.Tag, error) {
+	op := "link_service.GetTags"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTags(ctx, user.WorkspaceID)
+}
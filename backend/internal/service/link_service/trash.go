@@ -0,0 +1,100 @@
+package link_service
+
+import (
+	"context"
+	"link-storage/internal/models"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"time"
+)
+
+// DeleteLink перемещает ссылку в корзину
+func (s *linkService) DeleteLink(ctx context.Context, id int) error {
+	op := "link_service.DeleteLink"
+
+	link, err := s.linkAccess(ctx, id, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteLink(ctx, link.ID); err != nil {
+		return err
+	}
+
+	s.publish(ctx, models.EventLinkDeleted, link.WorkspaceID, link.LinkGroupID, models.EventRef{ID: link.ID})
+	return nil
+}
+
+func (s *linkService) GetTrash(ctx context.Context, itemType models.TrashItemType, page, pageSize int) (*response.ListResponse[models.TrashItem], error) {
+	op := "link_service.GetTrash"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := pageSize * (page - 1)
+	trash, err := s.repo.GetTrashItems(ctx, user.WorkspaceID, itemType, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.options.TrashRetention > 0 {
+		for _, item := range trash.Data {
+			purgeAt := item.DeletedAt.Add(s.options.TrashRetention)
+			item.PurgeAt = &purgeAt
+		}
+	}
+
+	return trash, nil
+}
+
+func (s *linkService) RestoreFromTrash(ctx context.Context, itemType models.TrashItemType, id int) error {
+	op := "link_service.RestoreFromTrash"
+
+	user, err := s.workspaceUser(ctx, models.LinkGroupRoleEditor, op)
+	if err != nil {
+		return err
+	}
+
+	switch itemType {
+	case models.TrashItemLink:
+		if err := s.repo.RestoreLink(ctx, id, user.WorkspaceID); err != nil {
+			if app_errors.IsNotFound(err) {
+				return app_errors.NotFound("Ссылка не найдена в корзине", op)
+			}
+			if app_errors.IsConflict(err) {
+				return app_errors.Conflict("Такая ссылка уже сохранена", op)
+			}
+			return err
+		}
+	case models.TrashItemLinkGroup:
+		if err := s.repo.RestoreLinkGroup(ctx, id, user.WorkspaceID); err != nil {
+			if app_errors.IsNotFound(err) {
+				return app_errors.NotFound("Группа не найдена в корзине", op)
+			}
+			return err
+		}
+	case models.TrashItemTag:
+		return s.repo.RestoreTag(ctx, id, user.WorkspaceID)
+	default:
+		return app_errors.BadRequest("Неверный тип элемента корзины", op)
+	}
+
+	return nil
+}
+
+// PurgeTrash фоновая задача: окончательно удаляет элементы, пролежавшие в корзине дольше срока хранения
+func (s *linkService) PurgeTrash(ctx context.Context) error {
+	op := "link_service.PurgeTrash"
+
+	purged, err := s.repo.PurgeTrash(ctx, time.Now().Add(-s.options.TrashRetention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		s.logger.Info("Корзина очищена", op, "purged", purged)
+	}
+	return nil
+}
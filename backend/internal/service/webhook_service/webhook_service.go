@@ -0,0 +1,442 @@
+package webhook_service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/webhook_repository"
+	"link-storage/pkg/events"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/response"
+	"link-storage/pkg/types/app_errors"
+	"link-storage/pkg/utils/parseurl"
+	"link-storage/pkg/utils/slug"
+	"link-storage/pkg/webhook"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// webhookSecretLength длина ключа подписи в base62
+const webhookSecretLength = 32
+
+type WebhookService interface {
+	GetWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhookSave *models.WebhookSave) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int, webhookSave *models.WebhookSave) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	PingWebhook(ctx context.Context, id int) (*models.WebhookDelivery, error)
+
+	// WebhookDelivery
+	GetWebhookDeliveries(ctx context.Context, id, page, pageSize int) (*response.ListResponse[models.WebhookDelivery], error)
+	RedeliverWebhookDelivery(ctx context.Context, id int, deliveryID int64) (*models.WebhookDelivery, error)
+	HandleEvent(ctx context.Context, event events.Event)
+	DispatchWebhooks(ctx context.Context) error
+	PurgeWebhookDeliveries(ctx context.Context) error
+}
+
+// Options настройки доставки webhooks
+type Options struct {
+	// MaxAttempts попыток доставки события, после них доставка считается неудачной
+	MaxAttempts int
+	// RetryBase пауза перед первым повтором, дальше удваивается
+	RetryBase time.Duration
+	// RetryMax предел паузы между повторами
+	RetryMax time.Duration
+	// BatchSize сколько доставок отправлять за один запуск
+	BatchSize int
+	// Workers одновременных запросов
+	Workers int
+	// Retention сколько хранить журнал завершенных доставок
+	Retention time.Duration
+	// Client настройки HTTP клиента, AllowPrivateNetworks - доставка во внутреннюю сеть
+	Client parseurl.ClientOptions
+}
+
+type webhookService struct {
+	repo    webhook_repository.WebhookRepository
+	logger  logger.AppLogger
+	options Options
+	client  *http.Client
+}
+
+func New(repo webhook_repository.WebhookRepository, logger logger.AppLogger, options Options) WebhookService {
+	client := parseurl.NewClient(options.Client)
+	// POST после редиректа превратился бы в GET без тела: редирект считаем ответом получателя
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &webhookService{
+		repo:    repo,
+		logger:  logger,
+		options: options,
+		client:  client,
+	}
+}
+
+// workspaceAdmin текущий пользователь, если он администратор активного пространства
+func (s *webhookService) workspaceAdmin(ctx context.Context, op string) (*models.CurrentUser, error) {
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	if !user.WorkspaceRole.Allows(models.WorkspaceRoleAdmin) {
+		return nil, app_errors.Forbidden(op)
+	}
+	return user, nil
+}
+
+// webhookAccess webhook активного пространства, доступный его администратору
+func (s *webhookService) webhookAccess(ctx context.Context, id int, op string) (*models.Webhook, error) {
+	user, err := s.workspaceAdmin(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.repo.GetWebhook(ctx, id, user.WorkspaceID)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Webhook не найден", op)
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) GetWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	op := "webhook_service.GetWebhooks"
+
+	user, err := s.workspaceAdmin(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetWebhooks(ctx, user.WorkspaceID)
+}
+
+// CreateWebhook создает webhook активного пространства. Ключ подписи возвращается только здесь и при смене ключа
+func (s *webhookService) CreateWebhook(ctx context.Context, webhookSave *models.WebhookSave) (*models.Webhook, error) {
+	op := "webhook_service.CreateWebhook"
+
+	user, err := s.workspaceAdmin(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := slug.Base62(webhookSecretLength)
+	if err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	webhook := &models.Webhook{
+		WorkspaceID: user.WorkspaceID,
+		CreatedBy:   &user.ID,
+		URL:         webhookSave.URL,
+		Secret:      secret,
+		Events:      webhookSave.Events,
+		IsActive:    webhookSave.IsActive == nil || *webhookSave.IsActive,
+	}
+	if err := s.repo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, id int, webhookSave *models.WebhookSave) (*models.Webhook, error) {
+	op := "webhook_service.UpdateWebhook"
+
+	webhook, err := s.webhookAccess(ctx, id, op)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.URL = webhookSave.URL
+	webhook.Events = webhookSave.Events
+	if webhookSave.IsActive != nil {
+		webhook.IsActive = *webhookSave.IsActive
+	}
+
+	if webhookSave.RotateSecret {
+		webhook.Secret, err = slug.Base62(webhookSecretLength)
+		if err != nil {
+			return nil, app_errors.Internal(err, op)
+		}
+	}
+
+	if err := s.repo.UpdateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id int) error {
+	op := "webhook_service.DeleteWebhook"
+
+	user, err := s.workspaceAdmin(ctx, op)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteWebhook(ctx, id, user.WorkspaceID)
+}
+
+// PingWebhook отправляет проверочное событие ping сразу, одной попыткой, и возвращает результат доставки
+func (s *webhookService) PingWebhook(ctx context.Context, id int) (*models.WebhookDelivery, error) {
+	op := "webhook_service.PingWebhook"
+
+	webhook, err := s.webhookAccess(ctx, id, op)
+	if err != nil {
+		return nil, err
+	}
+
+	event := events.Event{
+		ID:          uuid.NewString(),
+		Type:        models.EventPing,
+		WorkspaceID: webhook.WorkspaceID,
+		CreatedAt:   time.Now().UTC(),
+		Data:        map[string]any{"webhook_id": webhook.ID, "events": webhook.Events},
+	}
+	if user := middleware.GetCurrentUserFromContext(ctx); user != nil {
+		event.ActorID = user.ID
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, app_errors.Internal(err, op)
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+	}
+	return s.deliverNow(ctx, delivery, op)
+}
+
+func (s *webhookService) GetWebhookDeliveries(ctx context.Context, id, page, pageSize int) (*response.ListResponse[models.WebhookDelivery], error) {
+	op := "webhook_service.GetWebhookDeliveries"
+
+	webhook, err := s.webhookAccess(ctx, id, op)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := pageSize * (page - 1)
+	return s.repo.GetWebhookDeliveries(ctx, webhook.ID, pageSize, offset)
+}
+
+// RedeliverWebhookDelivery повторяет доставку сразу, одной попыткой, новой записью журнала.
+// ID события сохраняется, чтобы получатель мог отбросить дубликат
+func (s *webhookService) RedeliverWebhookDelivery(ctx context.Context, id int, deliveryID int64) (*models.WebhookDelivery, error) {
+	op := "webhook_service.RedeliverWebhookDelivery"
+
+	webhook, err := s.webhookAccess(ctx, id, op)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := s.repo.GetWebhookDelivery(ctx, deliveryID, webhook.ID)
+	if err != nil {
+		if app_errors.IsNotFound(err) {
+			return nil, app_errors.NotFound("Доставка не найдена", op)
+		}
+		return nil, err
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   original.EventID,
+		EventType: original.EventType,
+		Payload:   original.Payload,
+	}
+	return s.deliverNow(ctx, delivery, op)
+}
+
+// deliverNow сохраняет доставку в журнал и сразу отправляет ее одной попыткой, без повторов
+func (s *webhookService) deliverNow(ctx context.Context, delivery *models.WebhookDelivery, op string) (*models.WebhookDelivery, error) {
+	if err := s.repo.CreateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	// Ключ и адрес берем из БД вместе с доставкой: в ответ клиенту ключ не попадает
+	stored, err := s.repo.GetWebhookDelivery(ctx, delivery.ID, delivery.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.send(ctx, stored)
+	if result.Status == models.WebhookDeliveryPending {
+		result.Status = models.WebhookDeliveryFailed
+	}
+
+	if err := s.repo.SetWebhookDeliveryResult(ctx, stored.ID, result); err != nil {
+		return nil, err
+	}
+
+	delivered, err := s.repo.GetWebhookDelivery(ctx, stored.ID, stored.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Webhook отправлен вручную", op,
+		"webhook_id", delivered.WebhookID,
+		"delivery_id", delivered.ID,
+		"status", delivered.Status)
+
+	return delivered, nil
+}
+
+// HandleEvent подписчик шины событий: ставит событие в очередь доставки webhooks его пространства
+func (s *webhookService) HandleEvent(ctx context.Context, event events.Event) {
+	op := "webhook_service.HandleEvent"
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error(err, op, "event", event.Type)
+		return
+	}
+
+	if _, err := s.repo.CreateEventDeliveries(ctx, event.WorkspaceID, event.ID, event.Type, payload); err != nil {
+		s.logger.Error(err, op, "event", event.Type, "event_id", event.ID)
+	}
+}
+
+// DispatchWebhooks фоновая задача: отправляет доставки, которым подошел срок. Неудачные
+// повторяются с экспоненциальной паузой, после MaxAttempts попыток доставка считается неудачной
+func (s *webhookService) DispatchWebhooks(ctx context.Context) error {
+	op := "webhook_service.DispatchWebhooks"
+
+	now := time.Now()
+	// Пока идет отправка, доставки отложены: параллельный запуск их не возьмет
+	lease := now.Add(s.client.Timeout + time.Minute)
+
+	deliveries, err := s.repo.ClaimWebhookDeliveries(ctx, now, lease, s.options.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	workers := make(chan struct{}, max(s.options.Workers, 1))
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+
+	for _, delivery := range deliveries {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case workers <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			result := s.send(ctx, delivery)
+
+			// Прерванную остановкой приложения попытку не засчитываем, доставка уйдет после lease
+			if ctx.Err() != nil {
+				return
+			}
+
+			if result.Status != models.WebhookDeliverySucceeded {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+
+			if err := s.repo.SetWebhookDeliveryResult(ctx, delivery.ID, result); err != nil {
+				s.logger.Error(err, op, "delivery_id", delivery.ID)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	s.logger.Info("Отправка webhooks завершена", op,
+		"sent", len(deliveries),
+		"failed", failed)
+
+	return nil
+}
+
+// send одна попытка доставки. Статус результата: succeeded, pending с временем повтора или failed
+func (s *webhookService) send(ctx context.Context, delivery *models.WebhookDelivery) *models.WebhookDeliveryResult {
+	sent, err := webhook.Send(ctx, s.client, &webhook.Message{
+		URL:        delivery.URL,
+		Secret:     delivery.Secret,
+		EventType:  delivery.EventType,
+		DeliveryID: fmt.Sprintf("%d", delivery.ID),
+		Body:       delivery.Payload,
+	})
+
+	durationMs := int(sent.Duration.Milliseconds())
+	result := &models.WebhookDeliveryResult{
+		Status:        models.WebhookDeliverySucceeded,
+		ResponseBody:  sent.ResponseBody,
+		DurationMs:    durationMs,
+		NextAttemptAt: time.Now(),
+	}
+	if sent.StatusCode != 0 {
+		result.ResponseStatus = &sent.StatusCode
+	}
+
+	if err == nil {
+		return result
+	}
+
+	result.Error = err.Error()
+	attempts := delivery.Attempts + 1
+	if attempts >= s.options.MaxAttempts {
+		result.Status = models.WebhookDeliveryFailed
+		return result
+	}
+
+	result.Status = models.WebhookDeliveryPending
+	result.NextAttemptAt = time.Now().Add(s.retryDelay(attempts))
+	return result
+}
+
+// retryDelay пауза после attempts неудачных попыток: RetryBase, 2*RetryBase, 4*RetryBase... до RetryMax
+func (s *webhookService) retryDelay(attempts int) time.Duration {
+	delay := s.options.RetryBase
+	for range min(attempts-1, 30) {
+		delay *= 2
+		if delay >= s.options.RetryMax {
+			return s.options.RetryMax
+		}
+	}
+	return delay
+}
+
+// PurgeWebhookDeliveries фоновая задача: удаляет журнал завершенных доставок старше Retention
+func (s *webhookService) PurgeWebhookDeliveries(ctx context.Context) error {
+	op := "webhook_service.PurgeWebhookDeliveries"
+
+	deleted, err := s.repo.DeleteWebhookDeliveriesBefore(ctx, time.Now().Add(-s.options.Retention))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Удален старый журнал доставок webhooks", op, "deleted", deleted)
+	}
+	return nil
+}
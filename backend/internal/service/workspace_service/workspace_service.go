@@ -0,0 +1,248 @@
+package workspace_service
+
+import (
+	"context"
+	"link-storage/internal/middleware"
+	"link-storage/internal/models"
+	"link-storage/internal/repository/workspace_repository"
+	"link-storage/pkg/logger"
+	"link-storage/pkg/types/app_errors"
+)
+
+type WorkspaceService interface {
+	GetActiveWorkspace(ctx context.Context, userID, workspaceID int) (*models.Workspace, error)
+	GetWorkspaces(ctx context.Context) ([]*models.Workspace, error)
+	CreateWorkspace(ctx context.Context, workspaceSave *models.WorkspaceSave) (*models.Workspace, error)
+	UpdateWorkspace(ctx context.Context, id int, workspaceSave *models.WorkspaceSave) (*models.Workspace, error)
+	DeleteWorkspace(ctx context.Context, id int) error
+
+	// WorkspaceMember
+	GetWorkspaceMembers(ctx context.Context, id int) ([]*models.WorkspaceMember, error)
+	AddWorkspaceMember(ctx context.Context, id int, memberAdd *models.WorkspaceMemberAdd) (*models.WorkspaceMember, error)
+	SetWorkspaceMemberRole(ctx context.Context, id, userID int, memberSet *models.WorkspaceMemberSet) error
+	DeleteWorkspaceMember(ctx context.Context, id, userID int) error
+	TransferWorkspaceOwnership(ctx context.Context, id int, transfer *models.WorkspaceOwnerTransfer) error
+}
+
+type workspaceService struct {
+	repo   workspace_repository.WorkspaceRepository
+	logger logger.AppLogger
+}
+
+func New(repo workspace_repository.WorkspaceRepository, logger logger.AppLogger) WorkspaceService {
+	return &workspaceService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetActiveWorkspace активное пространство запроса, workspaceID 0 - личное пространство пользователя
+func (s *workspaceService) GetActiveWorkspace(ctx context.Context, userID, workspaceID int) (*models.Workspace, error) {
+	return s.repo.GetActiveWorkspace(ctx, userID, workspaceID)
+}
+
+// workspaceAccess пространство, где у текущего пользователя роль не ниже required.
+// Не участник - NotFound, роль ниже нужной - Forbidden
+func (s *workspaceService) workspaceAccess(ctx context.Context, id int, required models.WorkspaceRole, op string) (*models.CurrentUser, *models.Workspace, error) {
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, nil, app_errors.Unauthorized(op)
+	}
+
+	workspace, err := s.repo.GetActiveWorkspace(ctx, user.ID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !workspace.Role.Allows(required) {
+		return nil, nil, app_errors.Forbidden(op)
+	}
+
+	return user, workspace, nil
+}
+
+func (s *workspaceService) GetWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	op := "workspace_service.GetWorkspaces"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	return s.repo.GetWorkspacesByUserID(ctx, user.ID)
+}
+
+// CreateWorkspace создает командное пространство, текущий пользователь становится владельцем
+func (s *workspaceService) CreateWorkspace(ctx context.Context, workspaceSave *models.WorkspaceSave) (*models.Workspace, error) {
+	op := "workspace_service.CreateWorkspace"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return nil, app_errors.Unauthorized(op)
+	}
+
+	workspace := &models.Workspace{Name: workspaceSave.Name}
+	if err := s.repo.CreateWorkspace(ctx, workspace, user.ID); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+func (s *workspaceService) UpdateWorkspace(ctx context.Context, id int, workspaceSave *models.WorkspaceSave) (*models.Workspace, error) {
+	op := "workspace_service.UpdateWorkspace"
+
+	_, workspace, err := s.workspaceAccess(ctx, id, models.WorkspaceRoleAdmin, op)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace.Name = workspaceSave.Name
+	if err := s.repo.UpdateWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// DeleteWorkspace удаляет командное пространство со всем содержимым, доступно только владельцу
+func (s *workspaceService) DeleteWorkspace(ctx context.Context, id int) error {
+	op := "workspace_service.DeleteWorkspace"
+
+	_, workspace, err := s.workspaceAccess(ctx, id, models.WorkspaceRoleOwner, op)
+	if err != nil {
+		return err
+	}
+
+	if workspace.IsPersonal() {
+		return app_errors.BadRequest("Личное пространство удалить нельзя", op)
+	}
+
+	return s.repo.DeleteWorkspace(ctx, workspace.ID)
+}
+
+func (s *workspaceService) GetWorkspaceMembers(ctx context.Context, id int) ([]*models.WorkspaceMember, error) {
+	op := "workspace_service.GetWorkspaceMembers"
+
+	_, workspace, err := s.workspaceAccess(ctx, id, models.WorkspaceRoleViewer, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetWorkspaceMembers(ctx, workspace.ID)
+}
+
+// AddWorkspaceMember добавляет пользователя в командное пространство. Назначить администратора может только владелец
+func (s *workspaceService) AddWorkspaceMember(ctx context.Context, id int, memberAdd *models.WorkspaceMemberAdd) (*models.WorkspaceMember, error) {
+	op := "workspace_service.AddWorkspaceMember"
+
+	_, workspace, err := s.workspaceAccess(ctx, id, models.WorkspaceRoleAdmin, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if workspace.IsPersonal() {
+		return nil, app_errors.BadRequest("В личное пространство нельзя добавить участников", op)
+	}
+
+	if memberAdd.Role == models.WorkspaceRoleAdmin && workspace.Role != models.WorkspaceRoleOwner {
+		return nil, app_errors.Forbidden(op)
+	}
+
+	return s.repo.AddWorkspaceMember(ctx, workspace.ID, memberAdd.Email, memberAdd.Role)
+}
+
+// SetWorkspaceMemberRole меняет роль участника. Администраторов назначает и снимает только владелец
+func (s *workspaceService) SetWorkspaceMemberRole(ctx context.Context, id, userID int, memberSet *models.WorkspaceMemberSet) error {
+	op := "workspace_service.SetWorkspaceMemberRole"
+
+	user, workspace, err := s.workspaceAccess(ctx, id, models.WorkspaceRoleAdmin, op)
+	if err != nil {
+		return err
+	}
+
+	if userID == user.ID {
+		return app_errors.BadRequest("Нельзя изменить собственную роль", op)
+	}
+
+	member, err := s.repo.GetWorkspaceMember(ctx, workspace.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == models.WorkspaceRoleOwner {
+		return app_errors.BadRequest("Роль владельца меняется передачей владения", op)
+	}
+
+	if (member.Role == models.WorkspaceRoleAdmin || memberSet.Role == models.WorkspaceRoleAdmin) && workspace.Role != models.WorkspaceRoleOwner {
+		return app_errors.Forbidden(op)
+	}
+
+	return s.repo.SetWorkspaceMemberRole(ctx, workspace.ID, userID, memberSet.Role)
+}
+
+// DeleteWorkspaceMember исключает участника или выводит из пространства текущего пользователя.
+// Владелец перед выходом передает владение, администраторов исключает только владелец
+func (s *workspaceService) DeleteWorkspaceMember(ctx context.Context, id, userID int) error {
+	op := "workspace_service.DeleteWorkspaceMember"
+
+	user := middleware.GetCurrentUserFromContext(ctx)
+	if user == nil {
+		return app_errors.Unauthorized(op)
+	}
+
+	required := models.WorkspaceRoleAdmin
+	if userID == user.ID {
+		required = models.WorkspaceRoleViewer
+	}
+
+	_, workspace, err := s.workspaceAccess(ctx, id, required, op)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.repo.GetWorkspaceMember(ctx, workspace.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case member.Role == models.WorkspaceRoleOwner && userID == user.ID:
+		return app_errors.Conflict("Перед выходом из пространства передайте владение другому участнику", op)
+	case member.Role == models.WorkspaceRoleOwner:
+		return app_errors.BadRequest("Владельца пространства исключить нельзя", op)
+	case member.Role == models.WorkspaceRoleAdmin && userID != user.ID && workspace.Role != models.WorkspaceRoleOwner:
+		return app_errors.Forbidden(op)
+	}
+
+	return s.repo.DeleteWorkspaceMember(ctx, workspace.ID, userID)
+}
+
+// TransferWorkspaceOwnership передает владение другому участнику, текущий владелец становится администратором
+func (s *workspaceService) TransferWorkspaceOwnership(ctx context.Context, id int, transfer *models.WorkspaceOwnerTransfer) error {
+	op := "workspace_service.TransferWorkspaceOwnership"
+
+	user, workspace, err := s.workspaceAccess(ctx, id, models.WorkspaceRoleOwner, op)
+	if err != nil {
+		return err
+	}
+
+	if workspace.IsPersonal() {
+		return app_errors.BadRequest("Владение личным пространством не передается", op)
+	}
+
+	if transfer.UserID == user.ID {
+		return app_errors.BadRequest("Вы уже владелец пространства", op)
+	}
+
+	if err := s.repo.TransferWorkspaceOwnership(ctx, workspace.ID, user.ID, transfer.UserID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Владение пространством передано", op,
+		"workspace_id", workspace.ID,
+		"from_user_id", user.ID,
+		"to_user_id", transfer.UserID)
+
+	return nil
+}
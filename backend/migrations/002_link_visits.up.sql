@@ -0,0 +1,25 @@
+-- ===================== TABLE: link_visits ===================
+CREATE TABLE link_visits (
+    id BIGSERIAL PRIMARY KEY,
+    link_id INTEGER NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    referrer TEXT DEFAULT '',
+    client TEXT DEFAULT '',
+    visited_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE link_visits IS 'События посещения ссылок';
+CREATE INDEX idx_link_visits_link_id_visited_at ON link_visits(link_id, visited_at);
+CREATE INDEX idx_link_visits_user_id_visited_at ON link_visits(user_id, visited_at);
+CREATE INDEX idx_link_visits_visited_at ON link_visits(visited_at);
+
+-- ===================== TABLE: link_visits_daily ===================
+CREATE TABLE link_visits_daily (
+    link_id INTEGER NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    visits INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (link_id, day)
+);
+COMMENT ON TABLE link_visits_daily IS 'Посещения ссылок, агрегированные по дням';
+CREATE INDEX idx_link_visits_daily_user_id_day ON link_visits_daily(user_id, day);
+CREATE INDEX idx_link_visits_daily_day ON link_visits_daily(day);
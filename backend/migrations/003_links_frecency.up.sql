@@ -0,0 +1,3 @@
+ALTER TABLE links ADD COLUMN frecency INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN links.frecency IS 'Рейтинг частоты и давности посещений';
+CREATE INDEX idx_links_user_id_frecency ON links(user_id, frecency DESC);
@@ -0,0 +1,11 @@
+ALTER TABLE links
+    ADD COLUMN slug VARCHAR(64),
+    ADD COLUMN slug_expires_at TIMESTAMPTZ,
+    ADD COLUMN slug_is_public BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN links.slug IS 'Короткая ссылка /s/{slug}';
+CREATE UNIQUE INDEX idx_links_slug ON links(lower(slug)) WHERE slug IS NOT NULL;
+
+ALTER TABLE link_visits ADD COLUMN slug VARCHAR(64) DEFAULT '';
+COMMENT ON COLUMN link_visits.slug IS 'Короткая ссылка, через которую был переход';
+
+ALTER TABLE link_visits_daily ADD COLUMN slug_visits INTEGER NOT NULL DEFAULT 0;
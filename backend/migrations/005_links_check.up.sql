@@ -0,0 +1,8 @@
+ALTER TABLE links
+    ADD COLUMN http_status INTEGER,
+    ADD COLUMN last_checked_at TIMESTAMPTZ,
+    ADD COLUMN final_url TEXT DEFAULT '',
+    ADD COLUMN check_failures INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN links.check_failures IS 'Количество проверок подряд, в которых ссылка не открылась';
+CREATE INDEX idx_links_last_checked_at ON links(last_checked_at NULLS FIRST) WHERE is_archived = false;
+CREATE INDEX idx_links_user_id_broken ON links(user_id) WHERE check_failures > 0;
@@ -0,0 +1,3 @@
+ALTER TABLE links ADD COLUMN canonical_url TEXT;
+COMMENT ON COLUMN links.canonical_url IS 'Канонический URL для поиска дубликатов';
+CREATE UNIQUE INDEX idx_links_user_id_canonical_url ON links(user_id, canonical_url) WHERE canonical_url IS NOT NULL;
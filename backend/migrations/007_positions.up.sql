@@ -0,0 +1,22 @@
+-- Позиции с шагом 1024: перемещение занимает промежуток между соседями и не трогает остальные строки
+UPDATE link_groups g
+    SET position = p.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY position, name, id) * 1024 AS position
+    FROM link_groups
+) p
+WHERE g.id = p.id;
+UPDATE link_groups SET position = 0 WHERE position IS NULL;
+ALTER TABLE link_groups ALTER COLUMN position SET NOT NULL;
+CREATE INDEX idx_link_groups_user_id_position ON link_groups(user_id, position);
+
+ALTER TABLE links ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN links.position IS 'Позиция ссылки в группе';
+UPDATE links l
+    SET position = p.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, link_group_id ORDER BY title, id) * 1024 AS position
+    FROM links
+) p
+WHERE l.id = p.id;
+CREATE INDEX idx_links_link_group_id_position ON links(link_group_id, position);
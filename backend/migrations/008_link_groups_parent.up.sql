@@ -0,0 +1,3 @@
+ALTER TABLE link_groups ADD COLUMN parent_id INTEGER REFERENCES link_groups(id) ON DELETE CASCADE;
+COMMENT ON COLUMN link_groups.parent_id IS 'Родительская группа';
+CREATE INDEX idx_link_groups_parent_id ON link_groups(parent_id);
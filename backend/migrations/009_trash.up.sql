@@ -0,0 +1,19 @@
+ALTER TABLE links
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN orphaned_from_group_id INTEGER REFERENCES link_groups(id) ON DELETE SET NULL;
+COMMENT ON COLUMN links.deleted_at IS 'Время перемещения в корзину';
+COMMENT ON COLUMN links.orphaned_from_group_id IS 'Группа, из которой ссылка вышла при удалении группы в корзину';
+CREATE INDEX idx_links_deleted_at ON links(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_links_orphaned_from_group_id ON links(orphaned_from_group_id) WHERE orphaned_from_group_id IS NOT NULL;
+
+-- Ссылка в корзине не мешает сохранить тот же URL заново
+DROP INDEX idx_links_user_id_canonical_url;
+CREATE UNIQUE INDEX idx_links_user_id_canonical_url ON links(user_id, canonical_url) WHERE canonical_url IS NOT NULL AND deleted_at IS NULL;
+
+ALTER TABLE link_groups ADD COLUMN deleted_at TIMESTAMPTZ;
+COMMENT ON COLUMN link_groups.deleted_at IS 'Время перемещения в корзину';
+CREATE INDEX idx_link_groups_deleted_at ON link_groups(deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE tags ADD COLUMN deleted_at TIMESTAMPTZ;
+COMMENT ON COLUMN tags.deleted_at IS 'Время перемещения в корзину';
+CREATE INDEX idx_tags_deleted_at ON tags(deleted_at) WHERE deleted_at IS NOT NULL;
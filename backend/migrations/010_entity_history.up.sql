@@ -0,0 +1,28 @@
+-- ===================== TABLE: entity_history ===================
+CREATE TABLE entity_history (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id INTEGER,
+    action VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE entity_history IS 'История изменений ссылок, групп и тегов';
+COMMENT ON COLUMN entity_history.user_id IS 'Владелец сущности';
+COMMENT ON COLUMN entity_history.actor_id IS 'Кто внес изменение, NULL - система. Без внешнего ключа: запись переживает удаление автора';
+COMMENT ON COLUMN entity_history.changes IS 'Изменения по полям: {"поле": {"old": ..., "new": ...}}';
+CREATE INDEX idx_entity_history_entity ON entity_history(entity_type, entity_id, id);
+CREATE INDEX idx_entity_history_user_id ON entity_history(user_id);
+
+-- История только дополняется
+CREATE FUNCTION entity_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'entity_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_entity_history_append_only
+    BEFORE UPDATE ON entity_history
+    FOR EACH ROW EXECUTE FUNCTION entity_history_append_only();
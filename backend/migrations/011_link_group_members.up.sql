@@ -0,0 +1,29 @@
+-- ===================== TABLE: link_group_members ===================
+CREATE TABLE link_group_members (
+    link_group_id INTEGER NOT NULL REFERENCES link_groups(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (link_group_id, user_id)
+);
+COMMENT ON TABLE link_group_members IS 'Участники общих групп ссылок. Владелец группы (link_groups.user_id) сюда не входит';
+COMMENT ON COLUMN link_group_members.role IS 'viewer - просмотр, editor - изменение ссылок, owner - управление группой и участниками. Роль действует и на подгруппы';
+CREATE INDEX idx_link_group_members_user_id ON link_group_members(user_id);
+
+-- ===================== TABLE: link_group_invites ===================
+CREATE TABLE link_group_invites (
+    id SERIAL PRIMARY KEY,
+    link_group_id INTEGER NOT NULL REFERENCES link_groups(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    token VARCHAR(255) NOT NULL UNIQUE,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    accepted_by INTEGER REFERENCES users(id) ON DELETE SET NULL
+);
+COMMENT ON TABLE link_group_invites IS 'Приглашения в общие группы ссылок по email';
+CREATE UNIQUE INDEX idx_link_group_invites_pending ON link_group_invites(link_group_id, lower(email)) WHERE accepted_at IS NULL;
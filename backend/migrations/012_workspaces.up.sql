@@ -0,0 +1,58 @@
+-- ===================== TABLE: workspaces ===================
+CREATE TABLE workspaces (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    personal_user_id INTEGER UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE workspaces IS 'Рабочие пространства. Ссылки, группы и теги принадлежат пространству';
+COMMENT ON COLUMN workspaces.personal_user_id IS 'Владелец личного пространства. У личного пространства нет других участников';
+
+-- ===================== TABLE: workspace_members ===================
+CREATE TABLE workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'admin', 'owner')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, user_id)
+);
+COMMENT ON TABLE workspace_members IS 'Участники рабочих пространств';
+COMMENT ON COLUMN workspace_members.role IS 'viewer - просмотр, editor - изменение содержимого, admin - управление участниками, owner - единственный владелец пространства';
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+CREATE UNIQUE INDEX idx_workspace_members_owner ON workspace_members(workspace_id) WHERE role = 'owner';
+
+-- Личные пространства существующих пользователей
+INSERT INTO workspaces (name, personal_user_id)
+SELECT name, id FROM users;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, personal_user_id, 'owner' FROM workspaces;
+
+-- Содержимое переходит в личные пространства авторов, user_id остается автором
+ALTER TABLE link_groups ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE links ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE tags ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE link_groups g SET workspace_id = w.id FROM workspaces w WHERE w.personal_user_id = g.user_id;
+UPDATE links l SET workspace_id = w.id FROM workspaces w WHERE w.personal_user_id = l.user_id;
+UPDATE tags t SET workspace_id = w.id FROM workspaces w WHERE w.personal_user_id = t.user_id;
+
+ALTER TABLE link_groups ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE links ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE tags ALTER COLUMN workspace_id SET NOT NULL;
+
+COMMENT ON COLUMN link_groups.user_id IS 'Автор группы';
+COMMENT ON COLUMN links.user_id IS 'Автор ссылки';
+COMMENT ON COLUMN tags.user_id IS 'Автор тега';
+COMMENT ON COLUMN entity_history.user_id IS 'Автор сущности';
+COMMENT ON TABLE link_group_members IS 'Участники общих групп ссылок помимо участников пространства группы';
+
+CREATE INDEX idx_link_groups_workspace_id_position ON link_groups(workspace_id, position);
+CREATE INDEX idx_links_workspace_id_frecency ON links(workspace_id, frecency DESC);
+CREATE INDEX idx_links_workspace_id_broken ON links(workspace_id) WHERE check_failures > 0;
+CREATE INDEX idx_tags_workspace_id ON tags(workspace_id);
+
+DROP INDEX idx_links_user_id_canonical_url;
+CREATE UNIQUE INDEX idx_links_workspace_id_canonical_url ON links(workspace_id, canonical_url) WHERE canonical_url IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,14 @@
+-- ===================== TABLE: link_group_shares ===================
+CREATE TABLE link_group_shares (
+    link_group_id INTEGER PRIMARY KEY REFERENCES link_groups(id) ON DELETE CASCADE,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    password_hash VARCHAR(255),
+    expires_at TIMESTAMPTZ,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE link_group_shares IS 'Публичные страницы групп ссылок только для чтения. Страница показывает группу вместе с подгруппами';
+COMMENT ON COLUMN link_group_shares.slug IS 'Случайное имя страницы /p/{slug}, при перевыпуске старая ссылка перестает работать';
+COMMENT ON COLUMN link_group_shares.password_hash IS 'bcrypt хеш пароля страницы, NULL - без пароля';
+COMMENT ON COLUMN link_group_shares.expires_at IS 'Срок действия публикации, NULL - бессрочно';
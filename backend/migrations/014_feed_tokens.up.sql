@@ -0,0 +1,14 @@
+-- ===================== TABLE: feed_tokens ===================
+CREATE TABLE feed_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    link_group_id INTEGER REFERENCES link_groups(id) ON DELETE CASCADE,
+    tag_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((link_group_id IS NULL) <> (tag_id IS NULL))
+);
+COMMENT ON TABLE feed_tokens IS 'Секретные токены Atom/RSS лент групп и тегов. Лента доступна, пока у пользователя есть доступ к источнику';
+COMMENT ON COLUMN feed_tokens.user_id IS 'Пользователь, от имени которого читается лента';
+CREATE UNIQUE INDEX idx_feed_tokens_user_id_link_group_id ON feed_tokens(user_id, link_group_id) WHERE link_group_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_feed_tokens_user_id_tag_id ON feed_tokens(user_id, tag_id) WHERE tag_id IS NOT NULL;
@@ -0,0 +1,36 @@
+-- ===================== TABLE: feed_subscriptions ===================
+CREATE TABLE feed_subscriptions (
+    id SERIAL PRIMARY KEY,
+    link_group_id INTEGER NOT NULL REFERENCES link_groups(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    title VARCHAR(500) NOT NULL DEFAULT '',
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    last_fetched_at TIMESTAMPTZ,
+    last_success_at TIMESTAMPTZ,
+    last_status INTEGER,
+    last_error TEXT NOT NULL DEFAULT '',
+    error_count INTEGER NOT NULL DEFAULT 0,
+    next_fetch_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE feed_subscriptions IS 'Подписки групп ссылок на внешние RSS/Atom ленты';
+COMMENT ON COLUMN feed_subscriptions.user_id IS 'Автор подписки, он же автор созданных из ленты ссылок';
+COMMENT ON COLUMN feed_subscriptions.etag IS 'ETag прошлого ответа для условного запроса';
+COMMENT ON COLUMN feed_subscriptions.last_modified IS 'Last-Modified прошлого ответа для условного запроса';
+COMMENT ON COLUMN feed_subscriptions.error_count IS 'Ошибок загрузки подряд, от него растет пауза до следующей загрузки';
+CREATE UNIQUE INDEX idx_feed_subscriptions_link_group_id_url ON feed_subscriptions(link_group_id, url);
+CREATE INDEX idx_feed_subscriptions_next_fetch_at ON feed_subscriptions(next_fetch_at);
+
+-- ===================== TABLE: feed_subscription_items ===================
+CREATE TABLE feed_subscription_items (
+    subscription_id INTEGER NOT NULL REFERENCES feed_subscriptions(id) ON DELETE CASCADE,
+    guid TEXT NOT NULL,
+    link_id INTEGER REFERENCES links(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (subscription_id, guid)
+);
+COMMENT ON TABLE feed_subscription_items IS 'Уже обработанные записи лент: запись с тем же GUID повторно не создает ссылку';
+COMMENT ON COLUMN feed_subscription_items.link_id IS 'Созданная или уже существовавшая ссылка с тем же каноническим URL';
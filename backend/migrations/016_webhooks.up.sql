@@ -0,0 +1,41 @@
+-- ===================== TABLE: webhooks ===================
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    events TEXT[] NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE webhooks IS 'Исходящие webhooks рабочего пространства';
+COMMENT ON COLUMN webhooks.secret IS 'Ключ подписи HMAC-SHA256 тела запроса';
+COMMENT ON COLUMN webhooks.events IS 'Типы событий: link.created, категория link.* или *';
+CREATE INDEX idx_webhooks_workspace_id ON webhooks(workspace_id);
+
+-- ===================== TABLE: webhook_deliveries ===================
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    response_status INTEGER,
+    response_body TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+COMMENT ON TABLE webhook_deliveries IS 'Журнал доставок webhooks, он же очередь отправки';
+COMMENT ON COLUMN webhook_deliveries.event_id IS 'ID события, повторная доставка сохраняет его для идемпотентности получателя';
+COMMENT ON COLUMN webhook_deliveries.response_body IS 'Начало тела ответа получателя';
+CREATE INDEX idx_webhook_deliveries_webhook_id_created_at ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
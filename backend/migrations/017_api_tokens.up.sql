@@ -0,0 +1,13 @@
+-- ===================== TABLE: api_tokens ===================
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    hint VARCHAR(16) NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE api_tokens IS 'Персональные токены доступа для букмарклета и скриптов. Хранится только SHA-256 токена';
+COMMENT ON COLUMN api_tokens.hint IS 'Начало токена, чтобы пользователь узнал его в списке';
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
@@ -0,0 +1,10 @@
+-- ===================== TABLE: inbound_email_tokens ===================
+CREATE TABLE inbound_email_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, workspace_id)
+);
+COMMENT ON TABLE inbound_email_tokens IS 'Секретные адреса save+<token>@... для сохранения ссылок письмом. Ссылки попадают в пространство токена от имени пользователя';
@@ -0,0 +1,10 @@
+ALTER TABLE links
+    ADD COLUMN read_at TIMESTAMPTZ,
+    ADD COLUMN reading_minutes INTEGER,
+    ADD COLUMN remind_at TIMESTAMPTZ,
+    ADD COLUMN remind_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+COMMENT ON COLUMN links.read_at IS 'Когда ссылка прочитана, NULL - в списке чтения';
+COMMENT ON COLUMN links.reading_minutes IS 'Оценка времени чтения по тексту страницы, минут';
+COMMENT ON COLUMN links.remind_user_id IS 'Кому отправить напоминание в remind_at';
+CREATE INDEX idx_links_remind_at ON links(remind_at) WHERE remind_at IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX idx_links_workspace_id_unread ON links(workspace_id, created_at DESC) WHERE read_at IS NULL AND deleted_at IS NULL;
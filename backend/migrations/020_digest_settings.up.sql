@@ -0,0 +1,25 @@
+-- ===================== TABLE: digest_settings ===================
+CREATE TABLE digest_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    is_enabled BOOLEAN NOT NULL DEFAULT false,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    hour SMALLINT NOT NULL CHECK (hour BETWEEN 0 AND 23),
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+    last_sent_at TIMESTAMPTZ,
+    next_send_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE digest_settings IS 'Расписание email-дайджеста пользователя';
+COMMENT ON COLUMN digest_settings.hour IS 'Час отправки в часовом поясе timezone';
+COMMENT ON COLUMN digest_settings.weekday IS 'День недели еженедельного дайджеста, 0 - воскресенье';
+COMMENT ON COLUMN digest_settings.next_send_at IS 'Время следующей отправки в UTC, NULL - дайджест выключен';
+CREATE INDEX idx_digest_settings_next_send_at ON digest_settings(next_send_at) WHERE is_enabled = true;
+
+ALTER TABLE links
+    ADD COLUMN broken_at TIMESTAMPTZ;
+COMMENT ON COLUMN links.broken_at IS 'С какой проверки ссылка не открывается, NULL - открывается';
+UPDATE links SET broken_at = last_checked_at WHERE check_failures > 0;
+CREATE INDEX idx_links_broken_at ON links(broken_at) WHERE broken_at IS NOT NULL;
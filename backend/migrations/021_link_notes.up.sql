@@ -0,0 +1,25 @@
+-- ===================== TABLE: link_notes ===================
+CREATE TABLE link_notes (
+    id SERIAL PRIMARY KEY,
+    link_id INTEGER NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE link_notes IS 'Личные заметки пользователя к ссылке';
+COMMENT ON COLUMN link_notes.body IS 'Текст заметки в Markdown как есть, HTML получается при запросе';
+CREATE INDEX idx_link_notes_link_id_user_id ON link_notes(link_id, user_id);
+
+-- ===================== TABLE: link_highlights ===================
+CREATE TABLE link_highlights (
+    id SERIAL PRIMARY KEY,
+    link_id INTEGER NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quote TEXT NOT NULL,
+    anchor JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE link_highlights IS 'Цитаты со страницы ссылки, выделенные пользователем';
+COMMENT ON COLUMN link_highlights.anchor IS 'Положение цитаты в тексте страницы: префикс, суффикс и смещения, NULL - без привязки';
+CREATE INDEX idx_link_highlights_link_id_user_id ON link_highlights(link_id, user_id);
@@ -0,0 +1,6 @@
+-- Поиск лаунчера и списка ссылок идет по ILIKE '%...%' в заголовке и адресе: обычный B-tree
+-- такие шаблоны не использует, триграммный GIN-индекс - использует
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_links_title_trgm ON links USING gin (title gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX idx_links_url_trgm ON links USING gin (url gin_trgm_ops) WHERE deleted_at IS NULL;
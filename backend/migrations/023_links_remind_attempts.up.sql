@@ -0,0 +1,3 @@
+ALTER TABLE links
+    ADD COLUMN remind_attempts INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN links.remind_attempts IS 'Сколько раз подряд не удалось отправить напоминание remind_at';
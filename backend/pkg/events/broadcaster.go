@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Envelope событие с последовательным ID трансляции вида "<epoch>-<seq>"
+type Envelope struct {
+	ID    string
+	Event Event
+}
+
+// Broadcaster раздает события шины потоковым подписчикам (SSE) и хранит последние size событий
+// в кольцевом буфере, чтобы переподключившийся клиент дочитал пропущенное по Last-Event-ID
+type Broadcaster struct {
+	mu sync.Mutex
+	// epoch отличает запуски процесса: ID прошлого запуска продолжить нельзя
+	epoch string
+	seq   uint64
+	ring  []Envelope
+	next  int
+	full  bool
+	subs  map[chan Envelope]struct{}
+}
+
+// NewBroadcaster подписывается на шину, size - размер буфера для продолжения потока
+func NewBroadcaster(bus Bus, size int) *Broadcaster {
+	b := &Broadcaster{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		ring:  make([]Envelope, max(size, 1)),
+		subs:  make(map[chan Envelope]struct{}),
+	}
+	bus.Subscribe(b.publish)
+	return b
+}
+
+func (b *Broadcaster) publish(_ context.Context, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	envelope := Envelope{ID: fmt.Sprintf("%s-%d", b.epoch, b.seq), Event: event}
+
+	b.ring[b.next] = envelope
+	b.next = (b.next + 1) % len(b.ring)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for ch := range b.subs {
+		select {
+		case ch <- envelope:
+		default:
+			// Медленный подписчик не задерживает шину: закрываем его поток,
+			// клиент переподключится и дочитает пропущенное из буфера
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscription потоковая подписка
+type Subscription struct {
+	// Replay пропущенные события из буфера
+	Replay []Envelope
+	// Resumed false - часть событий после Last-Event-ID уже вытеснена из буфера или ID от прошлого запуска
+	Resumed bool
+	// C новые события. Закрывается, если подписчик не успевает читать
+	C <-chan Envelope
+	// Cancel отписка, вызывать обязательно
+	Cancel func()
+}
+
+// Subscribe подписка на события после lastEventID, пустой - только новые
+func (b *Broadcaster) Subscribe(lastEventID string, buffer int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	replay, resumed := b.replay(lastEventID)
+
+	ch := make(chan Envelope, max(buffer, 1))
+	b.subs[ch] = struct{}{}
+
+	return &Subscription{
+		Replay:  replay,
+		Resumed: resumed,
+		C:       ch,
+		Cancel: func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subs[ch]; ok {
+				delete(b.subs, ch)
+				close(ch)
+			}
+		},
+	}
+}
+
+// replay события буфера после lastEventID, вызывается под блокировкой
+func (b *Broadcaster) replay(lastEventID string) ([]Envelope, bool) {
+	if lastEventID == "" {
+		return nil, true
+	}
+
+	epoch, seqPart, ok := strings.Cut(lastEventID, "-")
+	if !ok || epoch != b.epoch {
+		return nil, false
+	}
+	lastSeq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil || lastSeq > b.seq {
+		return nil, false
+	}
+
+	count := b.next
+	if b.full {
+		count = len(b.ring)
+	}
+	// Самое старое событие в буфере имеет номер seq-count+1: между ним и lastSeq не должно быть дыры
+	if b.seq-lastSeq > uint64(count) {
+		return nil, false
+	}
+
+	missed := int(b.seq - lastSeq)
+	replay := make([]Envelope, 0, missed)
+	for i := missed; i > 0; i-- {
+		replay = append(replay, b.ring[(b.next-i+len(b.ring))%len(b.ring)])
+	}
+	return replay, true
+}
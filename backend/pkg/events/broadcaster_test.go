@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"link-storage/pkg/logger"
+	"strconv"
+	"testing"
+)
+
+// newTestBroadcaster буфер на size событий и published опубликованных событий с ID "e1", "e2", ...
+func newTestBroadcaster(t *testing.T, size, published int) (*Broadcaster, Bus) {
+	t.Helper()
+
+	bus := NewBus(logger.New("error"))
+	b := NewBroadcaster(bus, size)
+	for i := 1; i <= published; i++ {
+		bus.Publish(context.Background(), Event{ID: "e" + strconv.Itoa(i), Type: "test"})
+	}
+	return b, bus
+}
+
+func TestBroadcasterReplay(t *testing.T) {
+	const size = 3
+
+	tests := []struct {
+		name      string
+		published int
+		// lastSeq номер последнего полученного события текущего запуска, -1 - lastEventID как есть
+		lastSeq     int
+		lastEventID string
+		want        []string
+		wantResumed bool
+	}{
+		{name: "без Last-Event-ID - только новые", published: 5, lastSeq: -1, wantResumed: true},
+		{name: "буфер не заполнен", published: 2, lastSeq: 1, want: []string{"e2"}, wantResumed: true},
+		{name: "с самого начала, буфер не заполнен", published: 2, lastSeq: 0, want: []string{"e1", "e2"}, wantResumed: true},
+		{name: "буфер заполнен ровно", published: size, lastSeq: 0, want: []string{"e1", "e2", "e3"}, wantResumed: true},
+		{name: "после переполнения кольца", published: 5, lastSeq: 3, want: []string{"e4", "e5"}, wantResumed: true},
+		{name: "все события буфера после переполнения", published: 5, lastSeq: 2, want: []string{"e3", "e4", "e5"}, wantResumed: true},
+		{name: "пропущенное вытеснено из буфера", published: 5, lastSeq: 1, wantResumed: false},
+		{name: "последнее событие - пропусков нет", published: 5, lastSeq: 5, wantResumed: true},
+		{name: "номер из будущего", published: 5, lastSeq: 6, wantResumed: false},
+		{name: "ID прошлого запуска", published: 5, lastSeq: -1, lastEventID: "old-4", wantResumed: false},
+		{name: "ID без номера", published: 5, lastSeq: -1, lastEventID: "e4", wantResumed: false},
+		{name: "нечисловой номер", published: 5, lastSeq: -1, lastEventID: "x-y", wantResumed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := newTestBroadcaster(t, size, tt.published)
+
+			lastEventID := tt.lastEventID
+			if tt.lastSeq >= 0 {
+				lastEventID = b.epoch + "-" + strconv.Itoa(tt.lastSeq)
+			}
+
+			sub := b.Subscribe(lastEventID, 1)
+			defer sub.Cancel()
+
+			if sub.Resumed != tt.wantResumed {
+				t.Errorf("Resumed = %v, ожидалось %v", sub.Resumed, tt.wantResumed)
+			}
+
+			got := make([]string, 0, len(sub.Replay))
+			for _, envelope := range sub.Replay {
+				got = append(got, envelope.Event.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Replay %v, ожидалось %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Replay %v, ожидалось %v", got, tt.want)
+				}
+			}
+
+			// ID событий продолжают друг друга: последний из Replay подходит для следующего переподключения
+			if n := len(sub.Replay); n > 0 {
+				if want := b.epoch + "-" + strconv.Itoa(tt.published); sub.Replay[n-1].ID != want {
+					t.Errorf("ID последнего события %q, ожидался %q", sub.Replay[n-1].ID, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBroadcasterSlowSubscriber(t *testing.T) {
+	b, bus := newTestBroadcaster(t, 10, 0)
+
+	slow := b.Subscribe("", 1)
+	defer slow.Cancel()
+	fast := b.Subscribe("", 10)
+	defer fast.Cancel()
+
+	for i := 1; i <= 3; i++ {
+		bus.Publish(context.Background(), Event{ID: "e" + strconv.Itoa(i), Type: "test"})
+	}
+
+	// Медленный подписчик получает то, что поместилось в буфер, затем поток закрывается
+	if envelope, ok := <-slow.C; !ok || envelope.Event.ID != "e1" {
+		t.Errorf("первое событие медленного подписчика %v, %v", envelope.Event.ID, ok)
+	}
+	if _, ok := <-slow.C; ok {
+		t.Error("поток медленного подписчика не закрыт")
+	}
+
+	// Остальные подписчики получают все события
+	for i := 1; i <= 3; i++ {
+		if envelope := <-fast.C; envelope.Event.ID != "e"+strconv.Itoa(i) {
+			t.Errorf("событие %d: %q", i, envelope.Event.ID)
+		}
+	}
+
+	// Переподключившийся медленный подписчик дочитывает пропущенное из буфера
+	resumed := b.Subscribe(b.epoch+"-1", 1)
+	defer resumed.Cancel()
+	if !resumed.Resumed || len(resumed.Replay) != 2 || resumed.Replay[0].Event.ID != "e2" {
+		t.Errorf("после переподключения Resumed %v, Replay %v", resumed.Resumed, resumed.Replay)
+	}
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"link-storage/pkg/logger"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event событие предметной области. Подписчики вне процесса получают его в JSON
+type Event struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	WorkspaceID int    `json:"workspace_id"`
+	// LinkGroupID группа, к которой относится событие, для проверки доступа подписчиками
+	LinkGroupID *int `json:"link_group_id,omitempty"`
+	// ActorID пользователь, вызвавший событие, 0 - фоновая задача
+	ActorID   int       `json:"actor_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Handler обработчик событий. Вызывается синхронно в Publish, поэтому не должен надолго блокировать
+type Handler func(ctx context.Context, event Event)
+
+// Bus шина событий внутри процесса
+type Bus interface {
+	// Publish доставляет событие всем подписчикам. ID и CreatedAt заполняются, если пустые
+	Publish(ctx context.Context, event Event)
+	// Subscribe подписывает handler на все события, возвращает функцию отписки
+	Subscribe(handler Handler) (unsubscribe func())
+}
+
+type bus struct {
+	mu       sync.RWMutex
+	handlers map[int]Handler
+	nextID   int
+	logger   logger.AppLogger
+}
+
+func NewBus(logger logger.AppLogger) Bus {
+	return &bus{
+		handlers: make(map[int]Handler),
+		logger:   logger,
+	}
+}
+
+func (b *bus) Publish(ctx context.Context, event Event) {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers))
+	for _, handler := range b.handlers {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	// Событие уже произошло: отмена запроса не должна мешать подписчикам его обработать
+	ctx = context.WithoutCancel(ctx)
+	for _, handler := range handlers {
+		b.call(ctx, handler, event)
+	}
+}
+
+func (b *bus) call(ctx context.Context, handler Handler, event Event) {
+	op := "events.Publish"
+
+	defer func() {
+		if p := recover(); p != nil {
+			b.logger.Error(fmt.Errorf("PANIC: %v", p), op, "event", event.Type)
+		}
+	}()
+
+	handler(ctx, event)
+}
+
+func (b *bus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,171 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const mediaNamespace = "http://search.yahoo.com/mrss/"
+
+// Feed лента, общая для форматов Atom и RSS
+type Feed struct {
+	// ID постоянный идентификатор ленты (IRI)
+	ID          string
+	Title       string
+	Description string
+	// Link адрес сайта ленты
+	Link    string
+	Updated time.Time
+	Items   []*Item
+}
+
+type Item struct {
+	// ID постоянный идентификатор записи (IRI)
+	ID          string
+	URL         string
+	Title       string
+	Description string
+	// Image картинка превью, в обоих форматах передается как media:thumbnail
+	Image      string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Media    string      `xml:"xmlns:media,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Summary    string         `xml:"summary,omitempty"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Thumbnail  *mediaThumb    `xml:"media:thumbnail"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type mediaThumb struct {
+	URL string `xml:"url,attr"`
+}
+
+// Atom лента в формате Atom 1.0
+func Atom(f *Feed) ([]byte, error) {
+	feed := atomFeed{
+		Media:    mediaNamespace,
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+	}
+	if f.Link != "" {
+		feed.Links = []atomLink{{Href: f.Link, Rel: "alternate"}}
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Links:     []atomLink{{Href: item.URL, Rel: "alternate"}},
+			Summary:   item.Description,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if item.Image != "" {
+			entry.Thumbnail = &mediaThumb{URL: item.Image}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshal(feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Media   string     `xml:"xmlns:media,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	Description string      `xml:"description,omitempty"`
+	GUID        rssGUID     `xml:"guid"`
+	PubDate     string      `xml:"pubDate"`
+	Categories  []string    `xml:"category"`
+	Thumbnail   *mediaThumb `xml:"media:thumbnail"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// RSS лента в формате RSS 2.0
+func RSS(f *Feed) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Media:   mediaNamespace,
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, item := range f.Items {
+		rssItem := rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			Description: item.Description,
+			GUID:        rssGUID{Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Categories:  item.Categories,
+		}
+		if item.Image != "" {
+			rssItem.Thumbnail = &mediaThumb{URL: item.Image}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem)
+	}
+
+	return marshal(feed)
+}
+
+func marshal(v any) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
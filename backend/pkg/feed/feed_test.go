@@ -0,0 +1,99 @@
+package feed
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	return &Feed{
+		ID:          "urn:link-storage:feed:1",
+		Title:       "Ссылки & <заметки>",
+		Description: "Описание",
+		Link:        "https://app.example.com/groups/1",
+		Updated:     published.Add(time.Hour),
+		Items: []*Item{
+			{
+				ID:          "urn:link-storage:link:2",
+				URL:         "https://example.com/b?x=1&y=2",
+				Title:       "Вторая <b>ссылка</b>",
+				Description: "Текст & описание",
+				Image:       "https://example.com/b.png",
+				Categories:  []string{"go", "db"},
+				Published:   published,
+				Updated:     published.Add(30 * time.Minute),
+			},
+			{
+				ID:        "urn:link-storage:link:1",
+				URL:       "https://example.com/a",
+				Title:     "Первая",
+				Published: published.Add(-24 * time.Hour),
+				Updated:   published.Add(-24 * time.Hour),
+			},
+		},
+	}
+}
+
+// Ленты, которые отдает приложение, должны читаться тем же разбором, что и внешние ленты
+func TestWriteParseRoundTrip(t *testing.T) {
+	for _, format := range []struct {
+		name  string
+		write func(*Feed) ([]byte, error)
+	}{
+		{name: "atom", write: Atom},
+		{name: "rss", write: RSS},
+	} {
+		t.Run(format.name, func(t *testing.T) {
+			source := testFeed()
+			body, err := format.write(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(body, []byte("<?xml")) {
+				t.Errorf("нет XML-заголовка: %.40s", body)
+			}
+
+			parsed, err := Parse(bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("Parse: %v\n%s", err, body)
+			}
+
+			if parsed.Title != source.Title || parsed.Description != source.Description || parsed.Link != source.Link {
+				t.Errorf("лента %q %q %q, ожидалась %q %q %q", parsed.Title, parsed.Description, parsed.Link, source.Title, source.Description, source.Link)
+			}
+			if len(parsed.Items) != len(source.Items) {
+				t.Fatalf("записей %d, ожидалось %d", len(parsed.Items), len(source.Items))
+			}
+
+			for i, want := range source.Items {
+				got := parsed.Items[i]
+				if got.ID != want.ID || got.URL != want.URL || got.Title != want.Title || got.Description != want.Description {
+					t.Errorf("запись %d: %+v, ожидалась %+v", i, got, want)
+				}
+				if got.Image != want.Image {
+					t.Errorf("запись %d: картинка %q, ожидалась %q", i, got.Image, want.Image)
+				}
+				if !slices.Equal(got.Categories, want.Categories) {
+					t.Errorf("запись %d: категории %v, ожидались %v", i, got.Categories, want.Categories)
+				}
+				if !got.Published.Equal(want.Published) {
+					t.Errorf("запись %d: дата %s, ожидалась %s", i, got.Published, want.Published)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteEscapes(t *testing.T) {
+	body, err := RSS(testFeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "<b>ссылка</b>") || !strings.Contains(string(body), "&lt;b&gt;") {
+		t.Errorf("разметка в заголовке не экранирована:\n%s", body)
+	}
+}
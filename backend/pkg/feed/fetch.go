@@ -0,0 +1,81 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+)
+
+// maxFeedSize максимальный размер загружаемой ленты
+const maxFeedSize = 5 << 20
+
+// FetchResult результат загрузки ленты
+type FetchResult struct {
+	StatusCode int
+	// NotModified лента не изменилась с прошлой загрузки (304), Feed пустой
+	NotModified bool
+	// ETag и LastModified для следующего условного запроса
+	ETag         string
+	LastModified string
+	Feed         *Feed
+}
+
+// Fetch загружает ленту клиентом client (обычно parseurl.NewClient) условным запросом:
+// etag и lastModified - значения из прошлого ответа, пустые - безусловный запрос
+func Fetch(ctx context.Context, client *http.Client, rawURL, etag, lastModified string) (*FetchResult, error) {
+	req, err := parseurl.NewRequest(ctx, http.MethodGet, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/rdf+xml;q=0.9, application/xml;q=0.8, text/xml;q=0.8, */*;q=0.1")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &FetchResult{
+		StatusCode:   resp.StatusCode,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		// Сервер может не повторить валидаторы в ответе 304
+		if result.ETag == "" {
+			result.ETag = etag
+		}
+		if result.LastModified == "" {
+			result.LastModified = lastModified
+		}
+		return result, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.CopyN(io.Discard, resp.Body, 64<<10)
+		return result, fmt.Errorf("сервер ответил %d", resp.StatusCode)
+	}
+
+	result.Feed, err = Parse(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return result, err
+	}
+
+	// Относительные адреса записей считаем от адреса ленты после редиректов
+	for _, item := range result.Feed.Items {
+		if itemURL, err := resp.Request.URL.Parse(item.URL); err == nil && item.URL != "" {
+			item.URL = itemURL.String()
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,101 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const relativeFixture = `<rss version="2.0"><channel><title>t</title>
+<item><guid>1</guid><link>/posts/1</link></item>
+</channel></rss>`
+
+func TestFetchConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Mar 2026 10:00:00 GMT"
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(rssFixture))
+	}))
+	defer server.Close()
+
+	client := parseurl.NewClient(parseurl.ClientOptions{AllowPrivateNetworks: true})
+	ctx := context.Background()
+
+	result, err := Fetch(ctx, client, server.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NotModified || result.Feed == nil || len(result.Feed.Items) != 2 {
+		t.Fatalf("первая загрузка: %+v", result)
+	}
+	if result.ETag != etag || result.LastModified != lastModified {
+		t.Errorf("валидаторы %q %q", result.ETag, result.LastModified)
+	}
+
+	// Сервер не повторяет валидаторы в 304: остаются прежние
+	result, err = Fetch(ctx, client, server.URL, result.ETag, result.LastModified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.NotModified || result.Feed != nil || result.StatusCode != http.StatusNotModified {
+		t.Errorf("повторная загрузка: %+v", result)
+	}
+	if result.ETag != etag || result.LastModified != lastModified {
+		t.Errorf("валидаторы после 304 %q %q", result.ETag, result.LastModified)
+	}
+
+	if requests != 2 {
+		t.Errorf("запросов %d, ожидалось 2", requests)
+	}
+}
+
+func TestFetchRelativeURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/feeds/rss.xml", http.StatusMovedPermanently)
+			return
+		}
+		_, _ = w.Write([]byte(relativeFixture))
+	}))
+	defer server.Close()
+
+	client := parseurl.NewClient(parseurl.ClientOptions{AllowPrivateNetworks: true})
+	result, err := Fetch(context.Background(), client, server.URL+"/old", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Feed.Items[0].URL; got != server.URL+"/posts/1" {
+		t.Errorf("адрес записи %q, ожидался %q", got, server.URL+"/posts/1")
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer server.Close()
+
+	result, err := Fetch(context.Background(), parseurl.NewClient(parseurl.ClientOptions{AllowPrivateNetworks: true}), server.URL, "", "")
+	if err == nil || result == nil || result.StatusCode != http.StatusGone {
+		t.Errorf("ответ 410: %+v, %v", result, err)
+	}
+
+	// Без разрешения приватных сетей локальный сервер недоступен
+	_, err = Fetch(context.Background(), parseurl.NewClient(parseurl.ClientOptions{}), server.URL, "", "")
+	if !errors.Is(err, parseurl.ErrPrivateNetwork) {
+		t.Errorf("ошибка %v, ожидалась ErrPrivateNetwork", err)
+	}
+}
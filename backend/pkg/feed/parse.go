@@ -0,0 +1,215 @@
+package feed
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// ErrUnknownFormat документ не является лентой RSS 2.0, RSS 1.0 (RDF) или Atom
+var ErrUnknownFormat = errors.New("неизвестный формат ленты")
+
+type parseDocument struct {
+	XMLName xml.Name
+	// RSS 2.0
+	Channel *struct {
+		Title       string      `xml:"title"`
+		Link        string      `xml:"link"`
+		Description string      `xml:"description"`
+		Items       []parseItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0: элементы лежат рядом с channel
+	Items []parseItem `xml:"item"`
+	// Atom
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle"`
+	ID       string       `xml:"id"`
+	Links    []parseLink  `xml:"link"`
+	Entries  []parseEntry `xml:"entry"`
+}
+
+type parseItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        string   `xml:"guid"`
+	About       string   `xml:"about,attr"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"date"`
+	Categories  []string `xml:"category"`
+	Thumbnail   struct {
+		URL string `xml:"url,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Enclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+type parseLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type parseEntry struct {
+	ID         string      `xml:"id"`
+	Title      string      `xml:"title"`
+	Links      []parseLink `xml:"link"`
+	Summary    string      `xml:"summary"`
+	Content    string      `xml:"content"`
+	Published  string      `xml:"published"`
+	Updated    string      `xml:"updated"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Thumbnail struct {
+		URL string `xml:"url,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// Parse разбирает ленту RSS 2.0, RSS 1.0 или Atom. ID записи - guid/id, без него - ссылка записи
+func Parse(r io.Reader) (*Feed, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charsetReader
+	// Ленты часто содержат HTML-сущности вроде &nbsp;
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	var doc parseDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("разбор ленты: %w", err)
+	}
+
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		if doc.Channel == nil {
+			return nil, ErrUnknownFormat
+		}
+		return parseRSS(doc.Channel.Title, doc.Channel.Link, doc.Channel.Description, doc.Channel.Items), nil
+	case "rdf":
+		title, link, description := "", "", ""
+		if doc.Channel != nil {
+			title, link, description = doc.Channel.Title, doc.Channel.Link, doc.Channel.Description
+		}
+		return parseRSS(title, link, description, doc.Items), nil
+	case "feed":
+		return parseAtom(&doc), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// charsetReader перекодирует ленты не в UTF-8, например windows-1251
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, fmt.Errorf("кодировка %q: %w", label, err)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+func parseRSS(title, link, description string, items []parseItem) *Feed {
+	feed := &Feed{
+		Title:       strings.TrimSpace(title),
+		Link:        strings.TrimSpace(link),
+		Description: strings.TrimSpace(description),
+	}
+
+	for _, item := range items {
+		parsed := &Item{
+			ID:          firstNonEmpty(item.GUID, item.About, item.Link),
+			URL:         strings.TrimSpace(item.Link),
+			Title:       strings.TrimSpace(item.Title),
+			Description: strings.TrimSpace(item.Description),
+			Image:       item.Thumbnail.URL,
+			Published:   parseTime(firstNonEmpty(item.PubDate, item.Date)),
+		}
+		if parsed.Image == "" && strings.HasPrefix(item.Enclosure.Type, "image/") {
+			parsed.Image = item.Enclosure.URL
+		}
+		for _, category := range item.Categories {
+			if category = strings.TrimSpace(category); category != "" {
+				parsed.Categories = append(parsed.Categories, category)
+			}
+		}
+		parsed.Updated = parsed.Published
+		feed.Items = append(feed.Items, parsed)
+	}
+	return feed
+}
+
+func parseAtom(doc *parseDocument) *Feed {
+	feed := &Feed{
+		ID:          strings.TrimSpace(doc.ID),
+		Title:       strings.TrimSpace(doc.Title),
+		Link:        atomAlternate(doc.Links),
+		Description: strings.TrimSpace(doc.Subtitle),
+	}
+
+	for _, entry := range doc.Entries {
+		url := atomAlternate(entry.Links)
+		parsed := &Item{
+			ID:          firstNonEmpty(entry.ID, url),
+			URL:         url,
+			Title:       strings.TrimSpace(entry.Title),
+			Description: firstNonEmpty(entry.Summary, entry.Content),
+			Image:       entry.Thumbnail.URL,
+			Published:   parseTime(firstNonEmpty(entry.Published, entry.Updated)),
+			Updated:     parseTime(firstNonEmpty(entry.Updated, entry.Published)),
+		}
+		for _, category := range entry.Categories {
+			if term := strings.TrimSpace(category.Term); term != "" {
+				parsed.Categories = append(parsed.Categories, term)
+			}
+		}
+		feed.Items = append(feed.Items, parsed)
+	}
+	return feed
+}
+
+// atomAlternate адрес страницы из ссылок Atom: rel="alternate" или ссылка без rel
+func atomAlternate(links []parseLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseTime дата записи в одном из распространенных форматов, нераспознанная - нулевое время
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
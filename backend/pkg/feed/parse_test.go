@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const rssFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+  <title>Блог</title>
+  <link>https://blog.example.com/</link>
+  <description>Записи&nbsp;блога</description>
+  <item>
+    <title>Вторая запись</title>
+    <link>https://blog.example.com/2</link>
+    <guid isPermaLink="false">post-2</guid>
+    <pubDate>Tue, 03 Mar 2026 10:00:00 +0000</pubDate>
+    <category>go</category>
+    <category> </category>
+    <media:thumbnail url="https://blog.example.com/2.png"/>
+  </item>
+  <item>
+    <title>Первая запись</title>
+    <link>https://blog.example.com/1</link>
+    <description>Текст</description>
+    <pubDate>Mon, 2 Mar 2026 10:00:00 GMT</pubDate>
+    <enclosure url="https://blog.example.com/1.jpg" type="image/jpeg"/>
+  </item>
+</channel>
+</rss>`
+
+const atomFixture = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:example:feed</id>
+  <title>Новости</title>
+  <subtitle>Все новости</subtitle>
+  <link rel="self" href="https://news.example.com/atom.xml"/>
+  <link rel="alternate" href="https://news.example.com/"/>
+  <entry>
+    <id>urn:example:entry:1</id>
+    <title>Новость</title>
+    <link rel="alternate" href="https://news.example.com/1"/>
+    <content>Полный текст</content>
+    <updated>2026-03-02T12:00:00Z</updated>
+    <category term="news"/>
+  </entry>
+  <entry>
+    <title>Без id</title>
+    <link href="https://news.example.com/2"/>
+    <summary>Кратко</summary>
+    <published>2026-03-01T12:00:00+03:00</published>
+  </entry>
+</feed>`
+
+const rdfFixture = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://old.example.com/">
+    <title>Старая лента</title>
+    <link>https://old.example.com/</link>
+  </channel>
+  <item rdf:about="https://old.example.com/1">
+    <title>Запись</title>
+    <link>https://old.example.com/1</link>
+    <dc:date>2026-03-01</dc:date>
+  </item>
+</rdf:RDF>`
+
+func TestParseRSS(t *testing.T) {
+	feed, err := Parse(strings.NewReader(rssFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Title != "Блог" || feed.Link != "https://blog.example.com/" {
+		t.Errorf("лента %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("записей %d, ожидалось 2", len(feed.Items))
+	}
+
+	second, first := feed.Items[0], feed.Items[1]
+	if second.ID != "post-2" || second.Image != "https://blog.example.com/2.png" || len(second.Categories) != 1 {
+		t.Errorf("запись с guid: %+v", second)
+	}
+	if !second.Published.Equal(time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("дата %s", second.Published)
+	}
+	// Без guid ID - адрес записи, картинка - из enclosure
+	if first.ID != "https://blog.example.com/1" || first.Image != "https://blog.example.com/1.jpg" || first.Description != "Текст" {
+		t.Errorf("запись без guid: %+v", first)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := Parse(strings.NewReader(atomFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.ID != "urn:example:feed" || feed.Link != "https://news.example.com/" || feed.Description != "Все новости" {
+		t.Errorf("лента %+v", feed)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("записей %d, ожидалось 2", len(feed.Items))
+	}
+
+	entry := feed.Items[0]
+	if entry.ID != "urn:example:entry:1" || entry.URL != "https://news.example.com/1" || entry.Description != "Полный текст" {
+		t.Errorf("запись %+v", entry)
+	}
+	// Без published датой публикации считается updated
+	if !entry.Published.Equal(time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("дата %s", entry.Published)
+	}
+
+	noID := feed.Items[1]
+	if noID.ID != "https://news.example.com/2" || noID.Description != "Кратко" {
+		t.Errorf("запись без id %+v", noID)
+	}
+}
+
+func TestParseRDF(t *testing.T) {
+	feed, err := Parse(strings.NewReader(rdfFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Старая лента" || len(feed.Items) != 1 || feed.Items[0].ID != "https://old.example.com/1" {
+		t.Errorf("лента %+v", feed)
+	}
+	if feed.Items[0].Published.IsZero() {
+		t.Error("дата dc:date не разобрана")
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	// "Лента" в windows-1251
+	body := "<?xml version=\"1.0\" encoding=\"windows-1251\"?><rss version=\"2.0\"><channel><title>\xcb\xe5\xed\xf2\xe0</title></channel></rss>"
+	feed, err := Parse(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Лента" {
+		t.Errorf("заголовок %q, ожидался \"Лента\"", feed.Title)
+	}
+}
+
+func TestParseUnknown(t *testing.T) {
+	for _, body := range []string{
+		`<html><body>Not a feed</body></html>`,
+		`<rss version="2.0"></rss>`,
+	} {
+		if _, err := Parse(strings.NewReader(body)); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("%s: ошибка %v, ожидалась ErrUnknownFormat", body, err)
+		}
+	}
+
+	if _, err := Parse(strings.NewReader("not xml")); err == nil {
+		t.Error("ожидалась ошибка разбора")
+	}
+}
@@ -1,7 +1,10 @@
 package hash
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"math/big"
 
 	"github.com/google/uuid"
@@ -38,3 +41,15 @@ func GetRandomString() string {
 
 	return string(result)
 }
+
+// Sign подписывает сообщение HMAC-SHA256, результат в base64url без паддинга
+func Sign(secret, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CheckSignature проверяет подпись Sign за постоянное время
+func CheckSignature(secret, message, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, message)), []byte(signature))
+}
@@ -0,0 +1,190 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message письмо. HTML необязателен, без него отправляется только текст
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Unsubscribe адрес отписки в один клик для заголовков List-Unsubscribe (RFC 8058)
+	Unsubscribe string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+type Options struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From адрес отправителя, пустой - Username
+	From    string
+	Timeout time.Duration
+}
+
+type smtpMailer struct {
+	options Options
+}
+
+// New отправка писем через SMTP. Порт 465 - TLS с самого начала, иначе STARTTLS, если сервер его поддерживает
+func New(options Options) Mailer {
+	if options.From == "" {
+		options.From = options.Username
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 30 * time.Second
+	}
+	return &smtpMailer{options: options}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message *Message) error {
+	ctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+
+	body, err := m.build(message)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.options.Host, strconv.Itoa(m.options.Port))
+	tlsConfig := &tls.Config{ServerName: m.options.Host}
+
+	var conn net.Conn
+	if m.options.Port == 465 {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("подключение к SMTP %s: %w", addr, err)
+	}
+
+	// Таймаут контекста распространяем на весь SMTP-диалог
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.options.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP: %w", err)
+	}
+	defer client.Close()
+
+	if m.options.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("SMTP STARTTLS: %w", err)
+			}
+		}
+	}
+
+	if m.options.Username != "" {
+		auth := smtp.PlainAuth("", m.options.Username, m.options.Password, m.options.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP авторизация: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.options.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("SMTP RCPT TO: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// build собирает письмо: заголовки в UTF-8, тело в quoted-printable
+func (m *smtpMailer) build(message *Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", m.options.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if message.Unsubscribe != "" {
+		if strings.ContainsAny(message.Unsubscribe, "\r\n<>") {
+			return nil, fmt.Errorf("неверный адрес отписки: %q", message.Unsubscribe)
+		}
+		fmt.Fprintf(&buf, "List-Unsubscribe: <%s>\r\n", message.Unsubscribe)
+		buf.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if message.HTML == "" {
+		if err := writePart(&buf, "text/plain", message.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", message.Text},
+		{"text/html", message.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		if err := writePart(&buf, part.contentType, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func writePart(buf *bytes.Buffer, contentType, body string) error {
+	fmt.Fprintf(buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
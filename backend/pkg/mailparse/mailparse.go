@@ -0,0 +1,253 @@
+package mailparse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	// maxParts сколько частей multipart разбирается, остальные пропускаются
+	maxParts = 50
+	// maxDepth вложенность multipart
+	maxDepth = 5
+	// maxHashtagLength длина тега в символах, как у колонки tags.name
+	maxHashtagLength = 50
+)
+
+var (
+	textURL = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+	hrefURL = regexp.MustCompile(`(?i)href\s*=\s*["'](https?://[^"']+)["']`)
+	// hashtag после пробела или в начале строки, поэтому фрагменты адресов вида /#section не попадают
+	hashtag = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+	// replyPrefix префиксы пересылки и ответа в теме
+	replyPrefix = regexp.MustCompile(`(?i)^\s*(?:fwd?|re|пересл|отв)\s*:\s*`)
+)
+
+// Message разобранное письмо: тема и текст без вложений
+type Message struct {
+	Subject string
+	// Recipients адреса получателей из заголовков Delivered-To, X-Original-To, To и Cc
+	Recipients []string
+	// Text текстовые части письма, HTML - HTML-части, если текстовых нет
+	Text string
+	HTML string
+}
+
+// Parse читает письмо в формате RFC 822 (MIME): декодирует заголовок темы, base64 и quoted-printable,
+// перекодирует в UTF-8 и собирает текстовые части. Вложения пропускаются
+func Parse(r io.Reader) (*Message, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("разбор письма: %w", err)
+	}
+
+	decoder := &mime.WordDecoder{CharsetReader: charsetReader}
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	message := &Message{Subject: strings.TrimSpace(subject)}
+	for _, key := range []string{"Delivered-To", "X-Original-To", "To", "Cc"} {
+		for _, value := range msg.Header[key] {
+			addresses, err := mail.ParseAddressList(value)
+			if err != nil {
+				continue
+			}
+			for _, address := range addresses {
+				message.Recipients = append(message.Recipients, address.Address)
+			}
+		}
+	}
+	var text, htmlText []string
+	parts := 0
+
+	var walk func(header headerGetter, body io.Reader, depth int) error
+	walk = func(header headerGetter, body io.Reader, depth int) error {
+		parts++
+		if parts > maxParts {
+			return nil
+		}
+
+		mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+		if err != nil {
+			mediaType, params = "text/plain", map[string]string{}
+		}
+
+		if disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition")); disposition == "attachment" {
+			return nil
+		}
+
+		if strings.HasPrefix(mediaType, "multipart/") {
+			if depth >= maxDepth || params["boundary"] == "" {
+				return nil
+			}
+			reader := multipart.NewReader(body, params["boundary"])
+			for {
+				part, err := reader.NextPart()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if err := walk(part.Header, part, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+
+		if mediaType != "text/plain" && mediaType != "text/html" {
+			return nil
+		}
+
+		content, err := decodeBody(header.Get("Content-Transfer-Encoding"), params["charset"], body)
+		if err != nil {
+			return err
+		}
+		if mediaType == "text/plain" {
+			text = append(text, content)
+		} else {
+			htmlText = append(htmlText, content)
+		}
+		return nil
+	}
+
+	if err := walk(msg.Header, msg.Body, 0); err != nil {
+		return nil, fmt.Errorf("разбор тела письма: %w", err)
+	}
+
+	message.Text = strings.Join(text, "\n")
+	if len(text) == 0 {
+		message.HTML = strings.Join(htmlText, "\n")
+	}
+	return message, nil
+}
+
+// headerGetter заголовки письма и частей multipart
+type headerGetter interface {
+	Get(key string) string
+}
+
+// decodeBody снимает кодирование передачи и перекодирует текст в UTF-8
+func decodeBody(transferEncoding, charset string, body io.Reader) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: body})
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if charset != "" && !strings.EqualFold(charset, "utf-8") && !strings.EqualFold(charset, "us-ascii") {
+		decoded, err := charsetReader(charset, body)
+		if err == nil {
+			body = decoded
+		}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToValidUTF8(string(data), ""), nil
+}
+
+// base64Cleaner убирает переводы строк, которыми base64 в письмах разбит на строки
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	n = copy(p, bytes.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, p[:n]))
+	return n, err
+}
+
+// charsetReader перекодирует текст не в UTF-8, например koi8-r или windows-1251
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, fmt.Errorf("кодировка %q: %w", label, err)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+// URLs http(s) адреса из текста письма по порядку, без повторов, не больше limit
+func (m *Message) URLs(limit int) []string {
+	var found []string
+	if m.Text != "" {
+		found = textURL.FindAllString(m.Text, -1)
+	} else {
+		for _, match := range hrefURL.FindAllStringSubmatch(m.HTML, -1) {
+			found = append(found, html.UnescapeString(match[1]))
+		}
+	}
+
+	seen := make(map[string]bool)
+	urls := []string{}
+	for _, u := range found {
+		// Знаки препинания в конце предложения к адресу не относятся
+		u = strings.TrimRight(u, ".,;:!?")
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+		if len(urls) == limit {
+			break
+		}
+	}
+	return urls
+}
+
+// Hashtags теги #tag из темы и текста, без повторов без учета регистра
+func (m *Message) Hashtags() []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, source := range []string{m.Subject, m.Text} {
+		for _, match := range hashtag.FindAllStringSubmatch(source, -1) {
+			tag := truncateRunes(match[1], maxHashtagLength)
+			key := strings.ToLower(tag)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Title тема без префиксов пересылки и хештегов
+func (m *Message) Title() string {
+	title := m.Subject
+	for replyPrefix.MatchString(title) {
+		title = replyPrefix.ReplaceAllString(title, "")
+	}
+	title = hashtag.ReplaceAllString(title, "")
+	return strings.Join(strings.Fields(title), " ")
+}
+
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
@@ -0,0 +1,227 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Рендер подмножества Markdown для заметок: заголовки, абзацы, списки, цитаты, блоки кода,
+// горизонтальная линия и в тексте - код, ссылки, жирный, курсив и зачеркнутый.
+// Безопасность по построению: весь исходный текст экранируется, теги создает только рендер,
+// а в ссылках допустимы лишь http, https и mailto
+
+var (
+	heading     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rule        = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_])){2,}\s*$`)
+	bulletItem  = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedItem = regexp.MustCompile(`^\s{0,3}(\d{1,9})[.)]\s+(.*)$`)
+	fence       = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+
+	codeSpan   = regexp.MustCompile("`([^`]+)`")
+	inlineLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	// autoLink ищется в уже экранированном тексте: из сущностей в адресе бывает только &amp;
+	autoLink   = regexp.MustCompile(`https?://(?:[^\s<>&\x00]|&amp;)+`)
+	strong     = regexp.MustCompile(`\*\*(\S(?:[^*]*?\S)?)\*\*`)
+	strike     = regexp.MustCompile(`~~(\S(?:[^~]*?\S)?)~~`)
+	emphasis   = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	underscore = regexp.MustCompile(`(^|[^\p{L}\p{N}_])_(\S(?:[^_]*?\S)?)_($|[^\p{L}\p{N}_])`)
+	// placeholder место готового фрагмента HTML, который не должен обрабатываться дальше
+	placeholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// maxDepth вложенность цитат
+const maxDepth = 5
+
+// ToHTML преобразует Markdown в HTML, безопасный для вставки в страницу
+func ToHTML(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\x00", "")
+
+	var out strings.Builder
+	renderBlocks(&out, strings.Split(source, "\n"), 0)
+	return out.String()
+}
+
+func renderBlocks(out *strings.Builder, lines []string, depth int) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		out.WriteString("<p>")
+		for i, line := range paragraph {
+			if i > 0 {
+				out.WriteString("<br>\n")
+			}
+			out.WriteString(renderInline(strings.TrimSpace(line)))
+		}
+		out.WriteString("</p>\n")
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+
+		case fence.MatchString(line):
+			flush()
+			marker := fence.FindStringSubmatch(line)[1]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), marker); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>")
+			out.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			out.WriteString("</code></pre>\n")
+
+		case heading.MatchString(line):
+			flush()
+			match := heading.FindStringSubmatch(line)
+			fmt.Fprintf(out, "<h%d>%s</h%d>\n", len(match[1]), renderInline(match[2]), len(match[1]))
+
+		case rule.MatchString(line):
+			flush()
+			out.WriteString("<hr>\n")
+
+		case strings.HasPrefix(strings.TrimLeft(line, " "), ">"):
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimLeft(lines[i], " "), ">"); i++ {
+				text := strings.TrimPrefix(strings.TrimLeft(lines[i], " "), ">")
+				quote = append(quote, strings.TrimPrefix(text, " "))
+			}
+			i--
+			out.WriteString("<blockquote>\n")
+			if depth < maxDepth {
+				renderBlocks(out, quote, depth+1)
+			} else {
+				out.WriteString("<p>" + renderInline(strings.Join(quote, " ")) + "</p>\n")
+			}
+			out.WriteString("</blockquote>\n")
+
+		case bulletItem.MatchString(line) || orderedItem.MatchString(line):
+			flush()
+			i = renderList(out, lines, i) - 1
+
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+}
+
+// renderList выводит список, который начинается со строки start, и возвращает индекс строки после него.
+// Строки с отступом продолжают предыдущий пункт
+func renderList(out *strings.Builder, lines []string, start int) int {
+	ordered := orderedItem.MatchString(lines[start])
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		number, _ := strconv.Atoi(orderedItem.FindStringSubmatch(lines[start])[1])
+		if number != 1 {
+			fmt.Fprintf(out, "<ol start=\"%d\">\n", number)
+		} else {
+			out.WriteString("<ol>\n")
+		}
+	} else {
+		out.WriteString("<ul>\n")
+	}
+
+	var items []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if ordered {
+			if match := orderedItem.FindStringSubmatch(line); match != nil {
+				items = append(items, match[2])
+				continue
+			}
+		} else if match := bulletItem.FindStringSubmatch(line); match != nil {
+			items = append(items, match[1])
+			continue
+		}
+		if len(items) > 0 && strings.TrimSpace(line) != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			items[len(items)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		break
+	}
+
+	for _, item := range items {
+		out.WriteString("<li>" + renderInline(strings.TrimSpace(item)) + "</li>\n")
+	}
+	out.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// renderInline форматирование внутри строки. Код и ссылки сразу превращаются в HTML и прячутся
+// за заполнителями, остальной текст экранируется и только потом размечается
+func renderInline(text string) string {
+	var fragments []string
+	hold := func(fragment string) string {
+		fragments = append(fragments, fragment)
+		return "\x00" + strconv.Itoa(len(fragments)-1) + "\x00"
+	}
+	// expand возвращает готовые фрагменты на место заполнителей
+	expand := func(text string) string {
+		return placeholder.ReplaceAllStringFunc(text, func(match string) string {
+			index, _ := strconv.Atoi(placeholder.FindStringSubmatch(match)[1])
+			return fragments[index]
+		})
+	}
+
+	text = codeSpan.ReplaceAllStringFunc(text, func(match string) string {
+		return hold("<code>" + html.EscapeString(codeSpan.FindStringSubmatch(match)[1]) + "</code>")
+	})
+	text = inlineLink.ReplaceAllStringFunc(text, func(match string) string {
+		parts := inlineLink.FindStringSubmatch(match)
+		// Код в подписи уже спрятан за заполнителем: раскрываем его сейчас, внутрь фрагмента замена не заглянет
+		label := expand(html.EscapeString(parts[1]))
+		href, ok := safeURL(parts[2])
+		if !ok {
+			return hold(label)
+		}
+		return hold(anchor(href, label))
+	})
+
+	text = html.EscapeString(text)
+	text = autoLink.ReplaceAllStringFunc(text, func(match string) string {
+		// Знаки препинания в конце предложения к адресу не относятся
+		trimmed := strings.TrimRight(match, ".,;:!?)")
+		href, ok := safeURL(html.UnescapeString(trimmed))
+		if !ok {
+			return match
+		}
+		return hold(anchor(href, trimmed)) + match[len(trimmed):]
+	})
+	text = strong.ReplaceAllString(text, "<strong>$1</strong>")
+	text = strike.ReplaceAllString(text, "<del>$1</del>")
+	text = emphasis.ReplaceAllString(text, "<em>$1</em>")
+	text = underscore.ReplaceAllString(text, "$1<em>$2</em>$3")
+
+	return expand(text)
+}
+
+// safeURL экранированный адрес ссылки, false - схема не из разрешенных (например, javascript:)
+func safeURL(raw string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return html.EscapeString(parsed.String()), true
+	}
+	return "", false
+}
+
+func anchor(href, label string) string {
+	return `<a href="` + href + `" rel="nofollow noopener noreferrer">` + label + `</a>`
+}
@@ -0,0 +1,151 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+const rel = ` rel="nofollow noopener noreferrer"`
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "абзац с переносом строки",
+			source: "первая\nвторая\n\nтретья",
+			want:   "<p>первая<br>\nвторая</p>\n<p>третья</p>\n",
+		},
+		{
+			name:   "заголовок с закрывающими решетками",
+			source: "## Заголовок ##",
+			want:   "<h2>Заголовок</h2>\n",
+		},
+		{
+			name:   "жирный, курсив и зачеркнутый",
+			source: "**a** *b* _c_ ~~d~~ snake_case_name",
+			want:   "<p><strong>a</strong> <em>b</em> <em>c</em> <del>d</del> snake_case_name</p>\n",
+		},
+		{
+			name:   "код в тексте не размечается",
+			source: "`**a** <b>`",
+			want:   "<p><code>**a** &lt;b&gt;</code></p>\n",
+		},
+		{
+			name:   "ссылка",
+			source: "[сайт](https://example.com/a?b=1&c=2)",
+			want:   `<p><a href="https://example.com/a?b=1&amp;c=2"` + rel + ">сайт</a></p>\n",
+		},
+		{
+			name:   "код в подписи ссылки",
+			source: "[`x`](http://a)",
+			want:   `<p><a href="http://a"` + rel + "><code>x</code></a></p>\n",
+		},
+		{
+			name:   "код и текст в подписи ссылки",
+			source: "см. [функция `f()` тут](http://a) и `g`",
+			want:   `<p>см. <a href="http://a"` + rel + ">функция <code>f()</code> тут</a> и <code>g</code></p>\n",
+		},
+		{
+			name:   "автоссылка с амперсандом и точкой в конце",
+			source: "https://example.com/?a=1&b=2.",
+			want:   `<p><a href="https://example.com/?a=1&amp;b=2"` + rel + ">https://example.com/?a=1&amp;b=2</a>.</p>\n",
+		},
+		{
+			name:   "маркированный список с продолжением пункта",
+			source: "- один\n  продолжение\n- два",
+			want:   "<ul>\n<li>один продолжение</li>\n<li>два</li>\n</ul>\n",
+		},
+		{
+			name:   "нумерованный список не с единицы",
+			source: "3. три\n4. четыре",
+			want:   "<ol start=\"3\">\n<li>три</li>\n<li>четыре</li>\n</ol>\n",
+		},
+		{
+			name:   "вложенная цитата",
+			source: "> цитата\n> > вложенная",
+			want:   "<blockquote>\n<p>цитата</p>\n<blockquote>\n<p>вложенная</p>\n</blockquote>\n</blockquote>\n",
+		},
+		{
+			name:   "блок кода экранируется",
+			source: "```\n<script>alert(1)</script>\n**a**\n```",
+			want:   "<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;\n**a**</code></pre>\n",
+		},
+		{
+			name:   "горизонтальная линия",
+			source: "a\n\n* * *",
+			want:   "<p>a</p>\n<hr>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToHTML(tt.source); got != tt.want {
+				t.Errorf("ToHTML(%q)\n получено: %q\nожидалось: %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToHTMLUnsafe(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "тег script в тексте",
+			source: "<script>alert(1)</script>",
+			want:   "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n",
+		},
+		{
+			name:   "тег script в коде",
+			source: "`<script>alert(1)</script>`",
+			want:   "<p><code>&lt;script&gt;alert(1)&lt;/script&gt;</code></p>\n",
+		},
+		{
+			name:   "javascript: в ссылке остается текстом",
+			source: "[нажми](javascript:alert(1))",
+			want:   "<p>нажми)</p>\n",
+		},
+		{
+			name:   "javascript: в другом регистре",
+			source: "[нажми](JavaScript:alert`1`)",
+			want:   "<p>нажми</p>\n",
+		},
+		{
+			name:   "data: в ссылке",
+			source: "[x](data:text/html;base64,PHNjcmlwdD4=)",
+			want:   "<p>x</p>\n",
+		},
+		{
+			name:   "кавычка в адресе не выходит из атрибута",
+			source: `[x](http://a/"onmouseover="alert(1))`,
+			want:   `<p><a href="http://a/%22onmouseover=%22alert%281"` + rel + ">x</a>)</p>\n",
+		},
+		{
+			name:   "HTML в подписи ссылки",
+			source: "[<img src=x onerror=alert(1)>](http://a)",
+			want:   `<p><a href="http://a"` + rel + ">&lt;img src=x onerror=alert(1)&gt;</a></p>\n",
+		},
+		{
+			name:   "заполнитель в исходном тексте",
+			source: "a\x000\x00b `c`",
+			want:   "<p>a0b <code>c</code></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToHTML(tt.source)
+			if got != tt.want {
+				t.Errorf("ToHTML(%q)\n получено: %q\nожидалось: %q", tt.source, got, tt.want)
+			}
+			if strings.Contains(got, "<script") || strings.Contains(got, "javascript:") || strings.Contains(got, "\x00") {
+				t.Errorf("небезопасный результат %q", got)
+			}
+		})
+	}
+}
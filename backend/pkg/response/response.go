@@ -18,6 +18,15 @@ func WriteSuccess(w http.ResponseWriter, data any) {
 	writeJSON(w, http.StatusOK, response)
 }
 
+// WriteAccepted записывает ответ о принятой в фоновую обработку задаче
+func WriteAccepted(w http.ResponseWriter, data any) {
+	response := SuccessResponse{
+		Success: true,
+		Result:  data,
+	}
+	writeJSON(w, http.StatusAccepted, response)
+}
+
 // WriteError записывает ошибку в ответ
 func WriteError(w http.ResponseWriter, err error) {
 	// Проверяем, является ли ошибка ошибкой валидации
@@ -35,13 +44,18 @@ func WriteError(w http.ResponseWriter, err error) {
 			// logger.Error("Internal error", "op", appErr.Op, "error", appErr.Err)
 		}
 
+		errorBody := map[string]interface{}{
+			"type":    appErr.Type,
+			"message": appErr.Message,
+			"code":    appErr.Type,
+		}
+		if appErr.Details != nil {
+			errorBody["details"] = appErr.Details
+		}
+
 		errorResponse := ErrorResponse{
 			Success: false,
-			Error: map[string]interface{}{
-				"type":    appErr.Type,
-				"message": appErr.Message,
-				"code":    appErr.Type,
-			},
+			Error:   errorBody,
 		}
 		writeJSON(w, appErr.Code, errorResponse)
 		return
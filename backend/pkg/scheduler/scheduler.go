@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"link-storage/pkg/logger"
+	"time"
+)
+
+// Every запускает фоновую задачу fn сразу и далее с интервалом interval, пока не отменен ctx.
+// Ошибки и паники задачи логируются и не останавливают расписание
+func Every(ctx context.Context, interval time.Duration, name string, appLogger logger.AppLogger, fn func(ctx context.Context) error) {
+	op := "scheduler." + name
+
+	if interval <= 0 {
+		appLogger.Warn("Фоновая задача отключена", op)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run(ctx, op, appLogger, fn)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func run(ctx context.Context, op string, appLogger logger.AppLogger, fn func(ctx context.Context) error) {
+	defer func() {
+		if p := recover(); p != nil {
+			appLogger.Error(fmt.Errorf("PANIC: %v", p), op)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		appLogger.Error(err, op)
+	}
+}
@@ -0,0 +1,343 @@
+package smtpd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"link-storage/pkg/logger"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxLineLength длина командной строки с запасом к 512 байтам RFC 5321
+	maxLineLength = 4096
+	// maxConnections одновременных соединений, остальные ждут в очереди ОС
+	maxConnections = 100
+	// maxErrors ошибочных команд подряд, после которых соединение закрывается
+	maxErrors = 10
+)
+
+// Envelope принятое письмо: адреса из команд MAIL FROM и RCPT TO и данные после DATA
+type Envelope struct {
+	RemoteAddr string
+	From       string
+	To         []string
+	Data       []byte
+}
+
+// Handler обрабатывает письмо. *Error уходит клиенту как есть, остальные ошибки - временным отказом 451
+type Handler func(ctx context.Context, envelope *Envelope) error
+
+// Error ответ SMTP с кодом, например 550 - постоянный отказ
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Options настройки сервера
+type Options struct {
+	Addr string
+	// Domain имя сервера в приветствии
+	Domain string
+	// MaxSize предельный размер письма в байтах
+	MaxSize       int64
+	MaxRecipients int
+	// Timeout ожидания каждой команды и данных письма
+	Timeout time.Duration
+	// AcceptRecipient проверка адреса в RCPT TO, nil - принимаются все
+	AcceptRecipient func(address string) bool
+}
+
+// Server минимальный SMTP-сервер для приема писем (RFC 5321 без расширений, кроме SIZE и 8BITMIME).
+// Без TLS и AUTH: рассчитан на прием за MTA или в локальной сети
+type Server struct {
+	options Options
+	handler Handler
+	logger  logger.AppLogger
+}
+
+func New(options Options, handler Handler, logger logger.AppLogger) *Server {
+	if options.Domain == "" {
+		options.Domain = "localhost"
+	}
+	return &Server{
+		options: options,
+		handler: handler,
+		logger:  logger,
+	}
+}
+
+// ListenAndServe слушает Options.Addr и принимает соединения до отмены ctx
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	op := "smtpd.ListenAndServe"
+
+	listener, err := net.Listen("tcp", s.options.Addr)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	s.logger.Warn(fmt.Sprintf("Прием писем SMTP на: %s", listener.Addr()), op)
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve принимает соединения listener до отмены ctx, после отмены listener закрывается
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	slots := make(chan struct{}, maxConnections)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return fmt.Errorf("smtp: %w", err)
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			conn.Close()
+			return nil
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.serve(ctx, conn)
+		}()
+	}
+}
+
+// session состояние одного соединения
+type session struct {
+	server   *Server
+	conn     net.Conn
+	reader   *bufio.Reader
+	writer   *bufio.Writer
+	greeted  bool
+	envelope *Envelope
+}
+
+func (s *Server) serve(ctx context.Context, conn net.Conn) {
+	op := "smtpd.serve"
+
+	defer conn.Close()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error(fmt.Errorf("panic: %v", r), op, "remote_addr", conn.RemoteAddr().String())
+		}
+	}()
+
+	sess := &session{
+		server: s,
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, maxLineLength),
+		writer: bufio.NewWriter(conn),
+	}
+
+	sess.reply(220, s.options.Domain+" ESMTP ready")
+	errorCount := 0
+	for errorCount < maxErrors {
+		line, err := sess.readLine()
+		if err != nil {
+			if errors.Is(err, bufio.ErrBufferFull) {
+				sess.reply(500, "Line too long")
+			}
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		code, quit := sess.handle(ctx, strings.ToUpper(verb), strings.TrimSpace(arg))
+		if quit {
+			return
+		}
+		if code >= 500 {
+			errorCount++
+		} else {
+			errorCount = 0
+		}
+	}
+	sess.reply(421, "Too many errors")
+}
+
+// handle выполняет команду, возвращает код ответа и признак закрытия соединения
+func (sess *session) handle(ctx context.Context, verb, arg string) (int, bool) {
+	options := sess.server.options
+
+	switch verb {
+	case "HELO":
+		sess.greeted = true
+		sess.envelope = nil
+		return sess.reply(250, options.Domain), false
+	case "EHLO":
+		sess.greeted = true
+		sess.envelope = nil
+		return sess.reply(250, options.Domain, fmt.Sprintf("SIZE %d", options.MaxSize), "8BITMIME"), false
+	case "MAIL":
+		if !sess.greeted {
+			return sess.reply(503, "Send HELO/EHLO first"), false
+		}
+		if sess.envelope != nil {
+			return sess.reply(503, "Nested MAIL command"), false
+		}
+		from, params, ok := parsePath(arg, "FROM:")
+		if !ok {
+			return sess.reply(501, "Syntax: MAIL FROM:<address>"), false
+		}
+		if size, ok := params["SIZE"]; ok {
+			var n int64
+			if _, err := fmt.Sscan(size, &n); err == nil && n > options.MaxSize {
+				return sess.reply(552, "Message too large"), false
+			}
+		}
+		sess.envelope = &Envelope{RemoteAddr: sess.conn.RemoteAddr().String(), From: from}
+		return sess.reply(250, "OK"), false
+	case "RCPT":
+		if sess.envelope == nil {
+			return sess.reply(503, "Send MAIL first"), false
+		}
+		to, _, ok := parsePath(arg, "TO:")
+		if !ok || to == "" {
+			return sess.reply(501, "Syntax: RCPT TO:<address>"), false
+		}
+		if options.MaxRecipients > 0 && len(sess.envelope.To) >= options.MaxRecipients {
+			return sess.reply(452, "Too many recipients"), false
+		}
+		if options.AcceptRecipient != nil && !options.AcceptRecipient(to) {
+			return sess.reply(550, "Mailbox unavailable"), false
+		}
+		sess.envelope.To = append(sess.envelope.To, to)
+		return sess.reply(250, "OK"), false
+	case "DATA":
+		if sess.envelope == nil || len(sess.envelope.To) == 0 {
+			return sess.reply(503, "Send RCPT first"), false
+		}
+		return sess.data(ctx), false
+	case "RSET":
+		sess.envelope = nil
+		return sess.reply(250, "OK"), false
+	case "NOOP":
+		return sess.reply(250, "OK"), false
+	case "VRFY":
+		return sess.reply(252, "Cannot verify user"), false
+	case "QUIT":
+		sess.reply(221, "Bye")
+		return 221, true
+	}
+	return sess.reply(502, "Command not implemented"), false
+}
+
+// data принимает письмо до строки "." и передает обработчику
+func (sess *session) data(ctx context.Context) int {
+	op := "smtpd.data"
+	server := sess.server
+
+	sess.reply(354, "End data with <CR><LF>.<CR><LF>")
+	sess.conn.SetReadDeadline(time.Now().Add(server.options.Timeout))
+
+	dot := textproto.NewReader(sess.reader).DotReader()
+	data, err := io.ReadAll(io.LimitReader(dot, server.options.MaxSize+1))
+	if err == nil && int64(len(data)) > server.options.MaxSize {
+		// Дочитываем письмо, чтобы ответить в правильном месте протокола
+		_, err = io.Copy(io.Discard, dot)
+		if err == nil {
+			sess.envelope = nil
+			return sess.reply(552, "Message too large")
+		}
+	}
+	if err != nil {
+		sess.envelope = nil
+		return sess.reply(451, "Error reading message")
+	}
+
+	envelope := sess.envelope
+	envelope.Data = bytes.Clone(data)
+	sess.envelope = nil
+
+	if err := server.handler(ctx, envelope); err != nil {
+		var smtpErr *Error
+		if errors.As(err, &smtpErr) {
+			return sess.reply(smtpErr.Code, smtpErr.Message)
+		}
+		server.logger.Error(err, op, "remote_addr", envelope.RemoteAddr)
+		return sess.reply(451, "Temporary failure, try again later")
+	}
+	return sess.reply(250, "OK: message accepted")
+}
+
+func (sess *session) readLine() (string, error) {
+	sess.conn.SetReadDeadline(time.Now().Add(sess.server.options.Timeout))
+
+	line, err := sess.reader.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// reply отправляет ответ, многострочный - если строк несколько
+func (sess *session) reply(code int, lines ...string) int {
+	sess.conn.SetWriteDeadline(time.Now().Add(sess.server.options.Timeout))
+
+	for i, line := range lines {
+		separator := " "
+		if i < len(lines)-1 {
+			separator = "-"
+		}
+		fmt.Fprintf(sess.writer, "%d%s%s\r\n", code, separator, line)
+	}
+	sess.writer.Flush()
+	return code
+}
+
+// parsePath разбирает "FROM:<address> SIZE=123". Пустой адрес <> допустим для MAIL FROM
+func parsePath(arg, prefix string) (string, map[string]string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+
+	path, rest, _ := strings.Cut(arg, " ")
+	if !strings.HasPrefix(path, "<") || !strings.HasSuffix(path, ">") {
+		return "", nil, false
+	}
+	address := path[1 : len(path)-1]
+	if address != "" {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", nil, false
+		}
+		address = parsed.Address
+	}
+
+	params := make(map[string]string)
+	for _, param := range strings.Fields(rest) {
+		key, value, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = value
+	}
+	return address, params, true
+}
@@ -0,0 +1,231 @@
+package smtpd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"link-storage/pkg/logger"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testMessage = "From: sender@example.com\r\n" +
+	"To: inbox@links.test\r\n" +
+	"Subject: Ссылки\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"https://example.com/a\r\n" +
+	".hidden line\r\n"
+
+// startServer запускает сервер на свободном локальном порту, письма складываются в возвращаемый срез
+func startServer(t *testing.T, maxSize int64) (string, func() []*Envelope) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var envelopes []*Envelope
+	handler := func(ctx context.Context, envelope *Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		envelopes = append(envelopes, envelope)
+		return nil
+	}
+
+	server := New(Options{
+		Domain:          "links.test",
+		MaxSize:         maxSize,
+		MaxRecipients:   2,
+		Timeout:         5 * time.Second,
+		AcceptRecipient: func(address string) bool { return strings.HasSuffix(address, "@links.test") },
+	}, handler, logger.New("error"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener) }()
+
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+
+	return listener.Addr().String(), func() []*Envelope {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*Envelope(nil), envelopes...)
+	}
+}
+
+// smtpCode код ответа сервера из ошибки net/smtp, 0 - ошибки нет
+func smtpCode(err error) int {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code
+	}
+	return 0
+}
+
+func TestServerSendMail(t *testing.T) {
+	addr, received := startServer(t, 1<<20)
+
+	if err := smtp.SendMail(addr, nil, "sender@example.com", []string{"inbox@links.test"}, []byte(testMessage)); err != nil {
+		t.Fatal(err)
+	}
+
+	envelopes := received()
+	if len(envelopes) != 1 {
+		t.Fatalf("получено писем %d, ожидалось 1", len(envelopes))
+	}
+	envelope := envelopes[0]
+	if envelope.From != "sender@example.com" || len(envelope.To) != 1 || envelope.To[0] != "inbox@links.test" {
+		t.Errorf("конверт %s -> %v", envelope.From, envelope.To)
+	}
+	if !strings.HasPrefix(envelope.RemoteAddr, "127.0.0.1:") {
+		t.Errorf("RemoteAddr %q", envelope.RemoteAddr)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(string(envelope.Data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject := message.Header.Get("Subject"); subject != "Ссылки" {
+		t.Errorf("Subject %q", subject)
+	}
+	// Точка в начале строки удваивается клиентом и снимается сервером, концы строк - LF
+	body, _ := io.ReadAll(message.Body)
+	if string(body) != "https://example.com/a\n.hidden line\n" {
+		t.Errorf("тело письма %q", body)
+	}
+}
+
+func TestServerRejects(t *testing.T) {
+	const maxSize = 1024
+	addr, received := startServer(t, maxSize)
+
+	client, err := smtp.Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Hello("client.test"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, size := client.Extension("SIZE"); !ok || size != "1024" {
+		t.Errorf("SIZE %v %q", ok, size)
+	}
+
+	// Заявленный размер больше предела - отказ до передачи письма
+	id, err := client.Text.Cmd("MAIL FROM:<sender@example.com> SIZE=%d", maxSize+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Text.StartResponse(id)
+	_, _, err = client.Text.ReadResponse(250)
+	client.Text.EndResponse(id)
+	if code := smtpCode(err); code != 552 {
+		t.Errorf("MAIL FROM SIZE: код %d, ожидался 552 (%v)", code, err)
+	}
+
+	if err := client.Mail("sender@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Неизвестный получатель отклоняется AcceptRecipient, письмо на известный адрес продолжается
+	if code := smtpCode(client.Rcpt("someone@other.test")); code != 550 {
+		t.Errorf("неизвестный получатель: код %d, ожидался 550", code)
+	}
+	if err := client.Rcpt("inbox@links.test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Письмо больше MaxSize дочитывается и отклоняется после точки
+	w, err := client.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(testMessage + strings.Repeat("x", maxSize) + "\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if code := smtpCode(w.Close()); code != 552 {
+		t.Errorf("большое письмо: код %d, ожидался 552", code)
+	}
+
+	// После отказа соединение остается рабочим
+	if err := client.Mail("sender@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, to := range []string{"a@links.test", "b@links.test"} {
+		if err := client.Rcpt(to); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code := smtpCode(client.Rcpt("c@links.test")); code != 452 {
+		t.Errorf("лишний получатель: код %d, ожидался 452", code)
+	}
+	w, err = client.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(testMessage)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Quit(); err != nil {
+		t.Fatal(err)
+	}
+
+	envelopes := received()
+	if len(envelopes) != 1 || len(envelopes[0].To) != 2 {
+		t.Fatalf("получены письма %+v, ожидалось одно на два адреса", envelopes)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		prefix  string
+		address string
+		params  map[string]string
+		ok      bool
+	}{
+		{"адрес", "FROM:<a@example.com>", "FROM:", "a@example.com", map[string]string{}, true},
+		{"регистр и пробел", "from: <a@example.com>", "FROM:", "a@example.com", map[string]string{}, true},
+		{"параметры", "FROM:<a@example.com> size=10 BODY=8BITMIME", "FROM:", "a@example.com", map[string]string{"SIZE": "10", "BODY": "8BITMIME"}, true},
+		{"пустой обратный адрес", "FROM:<>", "FROM:", "", map[string]string{}, true},
+		{"без скобок", "TO:a@example.com", "TO:", "", nil, false},
+		{"неверный адрес", "TO:<not an address>", "TO:", "", nil, false},
+		{"другая команда", "TO:<a@example.com>", "FROM:", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, params, ok := parsePath(tt.arg, tt.prefix)
+			if ok != tt.ok || address != tt.address {
+				t.Fatalf("parsePath(%q) = %q, %v, ожидалось %q, %v", tt.arg, address, ok, tt.address, tt.ok)
+			}
+			if len(params) != len(tt.params) {
+				t.Fatalf("параметры %v, ожидалось %v", params, tt.params)
+			}
+			for key, value := range tt.params {
+				if params[key] != value {
+					t.Errorf("параметр %s = %q, ожидалось %q", key, params[key], value)
+				}
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ type AppError struct {
 	Code    int    `json:"-"`
 	Err     error  `json:"-"`
 	Op      string `json:"-"`
+	// Details дополнительные данные для клиента (например, существующая запись при конфликте)
+	Details any `json:"-"`
 }
 
 func (e *AppError) Error() string {
@@ -77,6 +79,13 @@ func Conflict(message, op string) *AppError {
 	return New(http.StatusConflict, "CONFLICT", message, op)
 }
 
+// ConflictWithDetails конфликт с данными о существующей записи
+func ConflictWithDetails(message, op string, details any) *AppError {
+	appErr := Conflict(message, op)
+	appErr.Details = details
+	return appErr
+}
+
 func Internal(err error, op string) *AppError {
 	return New(http.StatusInternalServerError, "INTERNAL_ERROR",
 		"Внутренняя ошибка сервера", op, err)
@@ -0,0 +1,78 @@
+package parseurl
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// trackingParams параметры запроса, которые не влияют на содержимое страницы
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"gbraid":  true,
+	"wbraid":  true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_ga":     true,
+	"_gl":     true,
+}
+
+// Canonicalize приводит URL к канонической форме для поиска дубликатов: схема https,
+// хост в нижнем регистре, без порта по умолчанию, фрагмента, трекинговых параметров
+// (utm_*, fbclid, gclid...) и завершающего слеша (кроме корня); оставшиеся параметры отсортированы
+func Canonicalize(rawURL string) (string, error) {
+	parsedURL, err := url.Parse(NormalizeURL(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("неверный URL: %w", err)
+	}
+
+	if parsedURL.Host == "" {
+		return "", fmt.Errorf("неверный URL: не указан хост")
+	}
+
+	scheme := strings.ToLower(parsedURL.Scheme)
+	host := strings.ToLower(parsedURL.Hostname())
+	port := parsedURL.Port()
+
+	if port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		// IPv6 без порта все равно пишется в скобках
+		host = "[" + host + "]"
+	}
+
+	// http и https одной страницы считаем одной ссылкой
+	parsedURL.Scheme = "https"
+	parsedURL.Host = host
+	parsedURL.Fragment = ""
+	parsedURL.RawFragment = ""
+
+	parsedURL.Path = strings.TrimRight(parsedURL.Path, "/")
+	if parsedURL.Path == "" {
+		parsedURL.Path = "/"
+	}
+	parsedURL.RawPath = ""
+
+	query := parsedURL.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	// Encode сортирует параметры по ключу
+	parsedURL.RawQuery = query.Encode()
+	parsedURL.ForceQuery = false
+
+	return parsedURL.String(), nil
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
@@ -0,0 +1,47 @@
+package parseurl
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "схема по умолчанию", in: "example.com", want: "https://example.com/"},
+		{name: "http как https", in: "http://example.com/page", want: "https://example.com/page"},
+		{name: "хост в нижнем регистре", in: "https://EXAMPLE.com/Path", want: "https://example.com/Path"},
+		{name: "порт по умолчанию http", in: "http://example.com:80/a", want: "https://example.com/a"},
+		{name: "порт по умолчанию https", in: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "нестандартный порт", in: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{name: "завершающий слеш", in: "https://example.com/a/b/", want: "https://example.com/a/b"},
+		{name: "фрагмент", in: "https://example.com/a#section", want: "https://example.com/a"},
+		{name: "трекинговые параметры", in: "https://example.com/a?utm_source=x&b=2&fbclid=y&a=1", want: "https://example.com/a?a=1&b=2"},
+		{name: "регистр трекинговых параметров", in: "https://example.com/?UTM_Medium=x&GCLID=1", want: "https://example.com/"},
+		{name: "пустой запрос", in: "https://example.com/a?", want: "https://example.com/a"},
+		{name: "пробелы вокруг", in: "  https://example.com/a  ", want: "https://example.com/a"},
+		{name: "IPv6 с портом", in: "http://[::1]:8080/", want: "https://[::1]:8080/"},
+		{name: "IPv6 без порта", in: "http://[2001:DB8::1]/a", want: "https://[2001:db8::1]/a"},
+		{name: "IPv6 с портом по умолчанию", in: "https://[::1]:443/a", want: "https://[::1]/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize(tt.in)
+			if err != nil {
+				t.Fatalf("Canonicalize(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Canonicalize(%q) = %q, ожидалось %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeInvalid(t *testing.T) {
+	for _, in := range []string{"", "https://", "https://exa mple.com/%zz"} {
+		if got, err := Canonicalize(in); err == nil {
+			t.Errorf("Canonicalize(%q) = %q, ожидалась ошибка", in, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package parseurl
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// CheckResult результат проверки доступности ссылки
+type CheckResult struct {
+	StatusCode int
+	// FinalURL адрес после всех редиректов
+	FinalURL string
+	Err      error
+}
+
+// IsBroken ссылка не открывается: ошибка сети/DNS или ответ 4xx/5xx
+func (r *CheckResult) IsBroken() bool {
+	return r.Err != nil || r.StatusCode >= http.StatusBadRequest
+}
+
+// Check проверяет доступность ссылки: сначала HEAD, а если сервер не поддерживает HEAD
+// или отвечает ошибкой - GET (многие сайты отдают на HEAD 403/405)
+func Check(ctx context.Context, client *http.Client, rawURL string) *CheckResult {
+	result := doCheck(ctx, client, http.MethodHead, rawURL)
+	if !result.IsBroken() {
+		return result
+	}
+
+	return doCheck(ctx, client, http.MethodGet, rawURL)
+}
+
+func doCheck(ctx context.Context, client *http.Client, method, rawURL string) *CheckResult {
+	req, err := NewRequest(ctx, method, rawURL)
+	if err != nil {
+		return &CheckResult{Err: err}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &CheckResult{Err: err}
+	}
+	defer resp.Body.Close()
+
+	// Тело не нужно, но дочитываем немного, чтобы соединение можно было переиспользовать
+	_, _ = io.CopyN(io.Discard, resp.Body, 64<<10)
+
+	return &CheckResult{
+		StatusCode: resp.StatusCode,
+		FinalURL:   resp.Request.URL.String(),
+	}
+}
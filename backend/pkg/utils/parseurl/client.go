@@ -0,0 +1,115 @@
+package parseurl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// UserAgent с которым приложение ходит на внешние сайты
+const UserAgent = "Mozilla/5.0 (compatible; LinkStorageBot/1.0; +https://github.com/Voltage11/link-storage)"
+
+// ErrPrivateNetwork запрос к локальному или приватному адресу запрещен
+var ErrPrivateNetwork = errors.New("запрос к приватному адресу запрещен")
+
+// ClientOptions настройки HTTP клиента для запросов к внешним сайтам
+type ClientOptions struct {
+	Timeout      time.Duration
+	MaxRedirects int
+	// AllowPrivateNetworks разрешает запросы к локальным и приватным адресам (тесты, внутренние сети)
+	AllowPrivateNetworks bool
+}
+
+// NewClient HTTP клиент с таймаутами, ограничением редиректов и защитой от запросов
+// во внутреннюю сеть (проверяется фактический IP при подключении, в том числе после редиректов)
+func NewClient(opts ClientOptions) *http.Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = 5
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	proxy := http.ProxyFromEnvironment
+	if !opts.AllowPrivateNetworks {
+		dialer.Control = denyPrivateNetworks
+		// Через прокси проверка увидела бы только адрес прокси, а не сайта: запросы идут напрямую
+		proxy = nil
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= opts.MaxRedirects {
+				return fmt.Errorf("слишком много редиректов (%d)", len(via))
+			}
+			return nil
+		},
+	}
+}
+
+// NewRequest GET/HEAD запрос к внешнему сайту с заголовками приложения
+func NewRequest(ctx context.Context, method, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("неверный URL: %w", err)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	return req, nil
+}
+
+func denyPrivateNetworks(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", ErrPrivateNetwork, host)
+	}
+
+	if isPrivateIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateNetwork, ip)
+	}
+
+	return nil
+}
+
+// nat64Prefix известный префикс NAT64 64:ff9b::/96, в последних 4 байтах - адрес IPv4
+var nat64Prefix = []byte{0, 0x64, 0xff, 0x9b, 0, 0, 0, 0, 0, 0, 0, 0}
+
+// isPrivateIP локальный, приватный или служебный адрес. IPv4 внутри IPv6 (::ffff:a.b.c.d,
+// ::a.b.c.d, NAT64) проверяется как IPv4
+func isPrivateIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else if len(ip) == net.IPv6len && !ip.IsUnspecified() && !ip.IsLoopback() &&
+		(bytes.Equal(ip[:12], nat64Prefix) || bytes.Equal(ip[:12], net.IPv6zero[:12])) {
+		ip = ip[12:]
+	}
+
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
@@ -0,0 +1,74 @@
+package parseurl
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestDenyPrivateNetworks(t *testing.T) {
+	tests := []struct {
+		address string
+		denied  bool
+	}{
+		{"93.184.216.34:80", false},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", false},
+		// loopback
+		{"127.0.0.1:80", true},
+		{"127.1.2.3:80", true},
+		{"[::1]:80", true},
+		// RFC 1918 и ULA
+		{"10.0.0.1:80", true},
+		{"172.16.0.1:80", true},
+		{"172.31.255.255:80", true},
+		{"172.32.0.1:80", false},
+		{"192.168.1.1:80", true},
+		{"[fd00::1]:80", true},
+		// link-local, в том числе метаданные облака
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		// неуказанный и multicast
+		{"0.0.0.0:80", true},
+		{"[::]:80", true},
+		{"224.0.0.1:80", true},
+		{"[ff02::1]:80", true},
+		// IPv4 внутри IPv6
+		{"[::ffff:127.0.0.1]:80", true},
+		{"[::ffff:10.0.0.1]:80", true},
+		{"[::ffff:169.254.169.254]:80", true},
+		{"[::ffff:93.184.216.34]:80", false},
+		{"[::10.0.0.1]:80", true},
+		{"[64:ff9b::192.168.0.1]:80", true},
+		{"[64:ff9b::93.184.216.34]:80", false},
+		// не IP: имя должно быть разрешено до подключения
+		{"localhost:80", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := denyPrivateNetworks("tcp", tt.address, nil)
+			if tt.denied && !errors.Is(err, ErrPrivateNetwork) {
+				t.Errorf("ошибка %v, ожидалась ErrPrivateNetwork", err)
+			}
+			if !tt.denied && err != nil {
+				t.Errorf("адрес запрещен: %v", err)
+			}
+		})
+	}
+
+	if err := denyPrivateNetworks("tcp", "10.0.0.1", nil); err == nil {
+		t.Error("адрес без порта принят")
+	}
+}
+
+func TestNewClientProxy(t *testing.T) {
+	transport := NewClient(ClientOptions{}).Transport.(*http.Transport)
+	if transport.Proxy != nil {
+		t.Error("с защитой от приватных адресов прокси из окружения не используется")
+	}
+
+	transport = NewClient(ClientOptions{AllowPrivateNetworks: true}).Transport.(*http.Transport)
+	if transport.Proxy == nil {
+		t.Error("без защиты прокси из окружения используется")
+	}
+}
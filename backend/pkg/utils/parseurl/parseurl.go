@@ -1,6 +1,7 @@
 package parseurl
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,29 +14,38 @@ import (
 	"time"
 )
 
+// faviconCheckTimeout ограничение HEAD-запроса при поиске favicon
+const faviconCheckTimeout = 5 * time.Second
+
 type UrlInfo interface {
 	GetTitle() string
 	GetFaviconPath() string
-	DownloadFavicon(saveDir string, userID, linkID int) (string, error)
+	GetCanonicalURL() string
+	GetWordCount() int
+	DownloadFavicon(ctx context.Context, client *http.Client, saveDir string, userID, linkID int) (string, error)
 }
 
 type urlInfo struct {
-	url     string
-	title   string
-	favicon string
+	url          string
+	title        string
+	favicon      string
+	canonicalURL string
+	wordCount    int
 }
 
-func New(rawURL string) UrlInfo {
-	out := &urlInfo{url: normalizeURL(rawURL)}
+// New загружает страницу rawURL клиентом client. Клиент должен быть из NewClient: адрес приходит
+// от пользователя или из чужой ленты, запросы во внутреннюю сеть запрещает он
+func New(ctx context.Context, client *http.Client, rawURL string) UrlInfo {
+	out := &urlInfo{url: NormalizeURL(rawURL)}
 	
 	// Загружаем данные синхронно
-	_ = out.loadData()
+	_ = out.loadData(ctx, client)
 	
 	return out
 }
 
-// normalizeURL добавляет схему протокола если отсутствует
-func normalizeURL(rawURL string) string {
+// NormalizeURL добавляет схему протокола если отсутствует
+func NormalizeURL(rawURL string) string {
 	// Удаляем пробелы
 	rawURL = strings.TrimSpace(rawURL)
 	
@@ -56,7 +66,17 @@ func (u *urlInfo) GetFaviconPath() string {
 	return u.favicon
 }
 
-func (u *urlInfo) loadData() error {
+// GetCanonicalURL адрес из <link rel="canonical">, пустая строка если не указан
+func (u *urlInfo) GetCanonicalURL() string {
+	return u.canonicalURL
+}
+
+// GetWordCount количество слов в тексте страницы, 0 если страница не загружена
+func (u *urlInfo) GetWordCount() int {
+	return u.wordCount
+}
+
+func (u *urlInfo) loadData(ctx context.Context, client *http.Client) error {
 	// Проверяем валидность URL
 	parsedURL, err := url.Parse(u.url)
 	if err != nil {
@@ -68,13 +88,12 @@ func (u *urlInfo) loadData() error {
 		u.url = parsedURL.String()
 	}
 
-	// Создаем HTTP клиент с таймаутом
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
 	// Запрос к странице
-	resp, err := client.Get(u.url)
+	req, err := NewRequest(ctx, http.MethodGet, u.url)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("ошибка HTTP запроса: %w", err)
 	}
@@ -101,14 +120,20 @@ func (u *urlInfo) loadData() error {
 	}
 
 	// Находим фавиконку
-	u.favicon = findFavicon(html, parsedURL)
+	u.favicon = findFavicon(ctx, client, html, parsedURL)
+
+	// Канонический адрес страницы
+	u.canonicalURL = findCanonicalURL(html, parsedURL)
+
+	// Объем текста для оценки времени чтения
+	u.wordCount = PageWordCount(html)
 
 	return nil
 }
 
 // Функция для скачивания и сохранения favicon
 // ВОЗВРАЩАЕТ ЛОКАЛЬНЫЙ ПУТЬ К ФАЙЛУ НА ДИСКЕ
-func (u *urlInfo) DownloadFavicon(saveDir string, userID, linkID int) (string, error) {
+func (u *urlInfo) DownloadFavicon(ctx context.Context, client *http.Client, saveDir string, userID, linkID int) (string, error) {
 	if u.favicon == "" {
 		// Если favicon не найден, возвращаем пустую строку
 		return "", nil
@@ -133,7 +158,7 @@ func (u *urlInfo) DownloadFavicon(saveDir string, userID, linkID int) (string, e
 
 	// Если расширение не найдено в пути, пробуем определить из Content-Type
 	if ext == "" {
-		ext = u.detectExtensionFromURL(u.favicon)
+		ext = u.detectExtensionFromURL(ctx, client, u.favicon)
 	}
 
 	// Убираем возможные параметры из расширения (например, .ico?v=2)
@@ -169,13 +194,12 @@ func (u *urlInfo) DownloadFavicon(saveDir string, userID, linkID int) (string, e
 		}
 	}
 
-	// Создаем HTTP клиент с таймаутом
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
 	// Скачиваем favicon
-	resp, err := client.Get(u.favicon)
+	req, err := NewRequest(ctx, http.MethodGet, u.favicon)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("ошибка при загрузке favicon: %w", err)
 	}
@@ -225,12 +249,11 @@ func (u *urlInfo) DownloadFavicon(saveDir string, userID, linkID int) (string, e
 }
 
 // detectExtensionFromURL определяет расширение файла по заголовкам HTTP
-func (u *urlInfo) detectExtensionFromURL(faviconURL string) string {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	
-	req, err := http.NewRequest("HEAD", faviconURL, nil)
+func (u *urlInfo) detectExtensionFromURL(ctx context.Context, client *http.Client, faviconURL string) string {
+	ctx, cancel := context.WithTimeout(ctx, faviconCheckTimeout)
+	defer cancel()
+
+	req, err := NewRequest(ctx, http.MethodHead, faviconURL)
 	if err != nil {
 		return ".ico"
 	}
@@ -263,7 +286,7 @@ func (u *urlInfo) detectExtensionFromURL(faviconURL string) string {
 }
 
 // findFavicon поиск favicon
-func findFavicon(html string, base *url.URL) string {
+func findFavicon(ctx context.Context, client *http.Client, html string, base *url.URL) string {
 	// Список возможных путей к favicon
 	possiblePaths := []string{
 		// Стандартные пути
@@ -300,7 +323,7 @@ func findFavicon(html string, base *url.URL) string {
 				faviconURL, err := url.Parse(match[1])
 				if err == nil {
 					fullURL := base.ResolveReference(faviconURL).String()
-					if checkFaviconExists(fullURL) {
+					if checkFaviconExists(ctx, client, fullURL) {
 						return fullURL
 					}
 				}
@@ -311,34 +334,50 @@ func findFavicon(html string, base *url.URL) string {
 	// Проверяем стандартные пути
 	for _, path := range possiblePaths {
 		faviconURL := base.Scheme + "://" + base.Host + path
-		if checkFaviconExists(faviconURL) {
+		if checkFaviconExists(ctx, client, faviconURL) {
 			return faviconURL
 		}
 	}
 
 	// Пробуем /favicon.ico как последний вариант
 	defaultFavicon := base.Scheme + "://" + base.Host + "/favicon.ico"
-	if checkFaviconExists(defaultFavicon) {
+	if checkFaviconExists(ctx, client, defaultFavicon) {
 		return defaultFavicon
 	}
 
 	return ""
 }
 
-// checkFaviconExists проверка существования favicon
-func checkFaviconExists(faviconURL string) bool {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+// findCanonicalURL поиск <link rel="canonical">
+func findCanonicalURL(html string, base *url.URL) string {
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`(?i)<link[^>]*rel=["']canonical["'][^>]*href=["']([^"']+)["']`),
+		regexp.MustCompile(`(?i)<link[^>]*href=["']([^"']+)["'][^>]*rel=["']canonical["']`),
 	}
-	
-	req, err := http.NewRequest("HEAD", faviconURL, nil)
+
+	for _, pattern := range patterns {
+		match := pattern.FindStringSubmatch(html)
+		if len(match) > 1 && match[1] != "" {
+			canonicalURL, err := url.Parse(strings.TrimSpace(match[1]))
+			if err == nil {
+				return base.ResolveReference(canonicalURL).String()
+			}
+		}
+	}
+
+	return ""
+}
+
+// checkFaviconExists проверка существования favicon
+func checkFaviconExists(ctx context.Context, client *http.Client, faviconURL string) bool {
+	ctx, cancel := context.WithTimeout(ctx, faviconCheckTimeout)
+	defer cancel()
+
+	req, err := NewRequest(ctx, http.MethodHead, faviconURL)
 	if err != nil {
 		return false
 	}
-	
-	// Добавляем User-Agent
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	
+
 	resp, err := client.Do(req)
 	if err != nil {
 		return false
@@ -0,0 +1,58 @@
+package parseurl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNewPrivateNetwork(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/favicon.ico":
+			w.Header().Set("Content-Type", "image/x-icon")
+			_, _ = w.Write([]byte("icon"))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><head><title>Внутренняя страница</title></head><body>текст</body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	// По умолчанию страница во внутренней сети не загружается, запрос до сервера не доходит
+	info := New(ctx, NewClient(ClientOptions{}), server.URL)
+	if info.GetTitle() != "" || info.GetFaviconPath() != "" || requests.Load() != 0 {
+		t.Errorf("загружено %q, %q, запросов %d, ожидалась блокировка", info.GetTitle(), info.GetFaviconPath(), requests.Load())
+	}
+
+	client := NewClient(ClientOptions{AllowPrivateNetworks: true})
+	info = New(ctx, client, server.URL)
+	if info.GetTitle() != "Внутренняя страница" || info.GetFaviconPath() != server.URL+"/favicon.ico" {
+		t.Fatalf("загружено %q, %q", info.GetTitle(), info.GetFaviconPath())
+	}
+
+	dir := t.TempDir()
+	path, err := info.DownloadFavicon(ctx, client, dir, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "1", "2.ico") {
+		t.Errorf("путь иконки %q", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "icon" {
+		t.Errorf("иконка %q, %v", data, err)
+	}
+
+	// Иконка, найденная разрешающим клиентом, не скачивается запрещающим
+	if _, err := info.DownloadFavicon(ctx, NewClient(ClientOptions{}), dir, 1, 3); err == nil {
+		t.Error("иконка из внутренней сети скачана клиентом с запретом")
+	}
+}
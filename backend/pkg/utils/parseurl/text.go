@@ -0,0 +1,40 @@
+package parseurl
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// hiddenBlocks блоки страницы, которые не читают: служебные теги, навигация и формы
+	hiddenBlocks = func() []*regexp.Regexp {
+		tags := []string{"head", "script", "style", "noscript", "template", "svg", "nav", "header", "footer", "aside", "form"}
+		patterns := make([]*regexp.Regexp, 0, len(tags))
+		for _, tag := range tags {
+			patterns = append(patterns, regexp.MustCompile(`(?is)<`+tag+`\b[^>]*>.*?</`+tag+`\s*>`))
+		}
+		return patterns
+	}()
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlTag     = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// PageWordCount количество слов в видимом тексте страницы без служебных блоков и навигации
+func PageWordCount(page string) int {
+	page = htmlComment.ReplaceAllString(page, " ")
+	for _, pattern := range hiddenBlocks {
+		page = pattern.ReplaceAllString(page, " ")
+	}
+	text := html.UnescapeString(htmlTag.ReplaceAllString(page, " "))
+
+	words := 0
+	for _, field := range strings.Fields(text) {
+		// Слово - поле хотя бы с одной буквой или цифрой, отдельные знаки препинания не считаются
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			words++
+		}
+	}
+	return words
+}
@@ -0,0 +1,25 @@
+package slug
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+const base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Base62 генерирует криптографически случайную строку длины length из символов base62
+func Base62(length int) (string, error) {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(base62Chars)))
+
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("ошибка генерации slug: %w", err)
+		}
+		result[i] = base62Chars[n.Int64()]
+	}
+
+	return string(result), nil
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// maxResponseBody сколько байт ответа получателя сохраняется в журнал
+	maxResponseBody = 4 << 10
+)
+
+// Message запрос доставки события
+type Message struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID string
+	Body       []byte
+}
+
+// Result ответ получателя. StatusCode 0 - ответа нет (ошибка сети, таймаут)
+type Result struct {
+	StatusCode   int
+	ResponseBody string
+	Duration     time.Duration
+}
+
+// Sign подпись тела: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+// Метка времени в подписи не дает повторно отправить перехваченный запрос позже
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверка подписи на стороне получателя за постоянное время
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Send отправляет подписанный POST. Ошибка - нет ответа или ответ не 2xx, Result заполнен по возможности
+func Send(ctx context.Context, client *http.Client, msg *Message) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(msg.Body))
+	if err != nil {
+		return &Result{}, fmt.Errorf("неверный URL: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", parseurl.UserAgent)
+	req.Header.Set(HeaderEvent, msg.EventType)
+	req.Header.Set(HeaderDelivery, msg.DeliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(msg.Secret, timestamp, msg.Body))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result := &Result{Duration: time.Since(start)}
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	_, _ = io.CopyN(io.Discard, resp.Body, 64<<10)
+
+	result.StatusCode = resp.StatusCode
+	// Тело сохраняется в текстовую колонку: без нулевых байтов и невалидного UTF-8
+	result.ResponseBody = strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+	result.Duration = time.Since(start)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("получатель ответил %d", resp.StatusCode)
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"link-storage/pkg/utils/parseurl"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestSend(t *testing.T) {
+	const secret = "secret"
+	body := []byte(`{"id":1}`)
+
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		verified = Verify(secret, timestamp, received, r.Header.Get(HeaderSignature)) &&
+			r.Header.Get(HeaderEvent) == "link.created" && r.Header.Get(HeaderDelivery) == "d1"
+		_, _ = w.Write([]byte("ok\x00"))
+	}))
+	defer server.Close()
+
+	msg := &Message{URL: server.URL, Secret: secret, EventType: "link.created", DeliveryID: "d1", Body: body}
+
+	client := parseurl.NewClient(parseurl.ClientOptions{AllowPrivateNetworks: true})
+	result, err := Send(context.Background(), client, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Error("получатель не подтвердил подпись и заголовки")
+	}
+	if result.StatusCode != http.StatusOK || result.ResponseBody != "ok" {
+		t.Errorf("ответ %+v", result)
+	}
+
+	// По умолчанию доставка во внутреннюю сеть запрещена
+	_, err = Send(context.Background(), parseurl.NewClient(parseurl.ClientOptions{}), msg)
+	if !errors.Is(err, parseurl.ErrPrivateNetwork) {
+		t.Errorf("ошибка %v, ожидалась ErrPrivateNetwork", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", 100, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"верная подпись", "secret", 100, body, signature, true},
+		{"другой секрет", "other", 100, body, signature, false},
+		{"другая метка времени", "secret", 101, body, signature, false},
+		{"другое тело", "secret", 100, []byte(`{"id":2}`), signature, false},
+		{"пустая подпись", "secret", 100, body, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.body, tt.signature); got != tt.want {
+				t.Errorf("Verify = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}